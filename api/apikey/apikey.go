@@ -3,6 +3,7 @@ package apikey
 import (
 	"crypto/rand"
 	"io"
+	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 )
@@ -11,12 +12,14 @@ import (
 type APIKeyService interface {
 	HashRaw(rawKey string) []byte
 	GenerateApiKey(user portainer.User, description string) (string, *portainer.APIKey, error)
+	GenerateRestrictedApiKey(user portainer.User, description string, expiresAt int64, restrictions portainer.APIKeyRestrictions) (string, *portainer.APIKey, error)
 	GetAPIKey(apiKeyID portainer.APIKeyID) (*portainer.APIKey, error)
 	GetAPIKeys(userID portainer.UserID) ([]portainer.APIKey, error)
 	GetDigestUserAndKey(digest []byte) (portainer.User, portainer.APIKey, error)
 	UpdateAPIKey(apiKey *portainer.APIKey) error
 	DeleteAPIKey(apiKeyID portainer.APIKeyID) error
 	InvalidateUserKeyCache(userId portainer.UserID) bool
+	ExpireAPIKeys(now time.Time, warningPeriod time.Duration) ([]portainer.APIKey, error)
 }
 
 // generateRandomKey generates a random key of specified length
@@ -0,0 +1,49 @@
+package apikey
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/scheduler"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ExpiryWarningPeriod is the duration before its expiry from which an API key is reported as expiring
+	ExpiryWarningPeriod = 7 * 24 * time.Hour
+	// expiryCheckInterval is the interval between two removals of the expired API keys
+	expiryCheckInterval = time.Hour
+)
+
+// StartExpiryJob periodically removes the expired API keys and notifies the API keys about to expire
+func StartExpiryJob(scheduler *scheduler.Scheduler, service APIKeyService, notificationService portainer.NotificationService) {
+	scheduler.StartJobEvery(expiryCheckInterval, func() error {
+		expiring, err := service.ExpireAPIKeys(time.Now(), ExpiryWarningPeriod)
+		if err != nil {
+			log.Warn().Err(err).Msg("unable to clean up the expired API keys")
+		}
+
+		for _, apiKey := range expiring {
+			expiresAt := time.Unix(apiKey.ExpiresAt, 0).UTC()
+
+			log.Warn().
+				Int("api_key_id", int(apiKey.ID)).
+				Int("user_id", int(apiKey.UserID)).
+				Time("expires_at", expiresAt).
+				Msg("API key about to expire")
+
+			notificationService.Notify(portainer.NotificationEvent{
+				Type:         portainer.NotificationEventAPIKeyExpiring,
+				Title:        "API key about to expire",
+				Message:      fmt.Sprintf("The API key %s (%s...) expires on %s", apiKey.Description, apiKey.Prefix, expiresAt.Format(time.RFC3339)),
+				ResourceID:   strconv.Itoa(int(apiKey.ID)),
+				ResourceName: apiKey.Description,
+			})
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,149 @@
+package apikey
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrAPIKeyExpired is returned when an API key is used after its expiry date
+	ErrAPIKeyExpired = errors.New("API key expired")
+	// ErrAPIKeyReadOnly is returned when a read-only API key is used for a request which is not read-only
+	ErrAPIKeyReadOnly = errors.New("the API key is read-only")
+	// ErrAPIKeyEndpointDenied is returned when an API key is used for an environment(endpoint) it cannot access
+	ErrAPIKeyEndpointDenied = errors.New("the API key cannot access this environment")
+	// ErrAPIKeyScopeDenied is returned when an API key is used for an API path or a method out of its scopes
+	ErrAPIKeyScopeDenied = errors.New("the request is out of the scopes of the API key")
+)
+
+// IsExpired returns whether an API key expired at the time now
+func IsExpired(apiKey *portainer.APIKey, now time.Time) bool {
+	return apiKey.ExpiresAt > 0 && now.Unix() >= apiKey.ExpiresAt
+}
+
+// CheckRestrictions verifies that a request is allowed by the restrictions of an API key. The path is relative
+// to /api and endpointIDs are the environments(endpoints) targeted by the request. A key restricted to some
+// environments(endpoints) cannot send the state-changing requests which do not target any, as the environment
+// of their resources is unknown.
+func CheckRestrictions(restrictions *portainer.APIKeyRestrictions, method, path string, endpointIDs []portainer.EndpointID) error {
+	if restrictions.ReadOnly && (!isReadOnlyMethod(method) || strings.HasPrefix(path, "/websocket/")) {
+		return ErrAPIKeyReadOnly
+	}
+
+	if len(restrictions.EndpointIDs) > 0 {
+		if len(endpointIDs) == 0 && !isReadOnlyMethod(method) {
+			return ErrAPIKeyEndpointDenied
+		}
+
+		for _, endpointID := range endpointIDs {
+			if !containsEndpoint(restrictions.EndpointIDs, endpointID) {
+				return ErrAPIKeyEndpointDenied
+			}
+		}
+	}
+
+	if len(restrictions.Scopes) == 0 {
+		return nil
+	}
+
+	for _, scope := range restrictions.Scopes {
+		if scopeMatches(&scope, method, path) {
+			return nil
+		}
+	}
+
+	return ErrAPIKeyScopeDenied
+}
+
+// ValidateRestrictions checks the restrictions of an API key before it is created
+func ValidateRestrictions(restrictions *portainer.APIKeyRestrictions) error {
+	for _, scope := range restrictions.Scopes {
+		if !strings.HasPrefix(scope.Path, "/") {
+			return errors.Errorf("invalid scope path %q, it must start with /", scope.Path)
+		}
+
+		for _, method := range scope.Methods {
+			if !isHTTPMethod(method) {
+				return errors.Errorf("invalid scope method %q", method)
+			}
+		}
+	}
+
+	for _, endpointID := range restrictions.EndpointIDs {
+		if endpointID <= 0 {
+			return errors.Errorf("invalid environment identifier %d", endpointID)
+		}
+	}
+
+	return nil
+}
+
+// scopeMatches returns whether the path is the path of the scope or one of its sub-paths,
+// and whether the method is allowed by the scope
+func scopeMatches(scope *portainer.APIKeyScope, method, path string) bool {
+	if len(scope.Methods) > 0 {
+		allowed := false
+		for _, m := range scope.Methods {
+			if strings.EqualFold(m, method) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return false
+		}
+	}
+
+	scopeSegments := splitPath(scope.Path)
+	pathSegments := splitPath(path)
+
+	if len(pathSegments) < len(scopeSegments) {
+		return false
+	}
+
+	for i, segment := range scopeSegments {
+		if segment != "*" && segment != pathSegments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+
+	return strings.Split(path, "/")
+}
+
+func containsEndpoint(endpointIDs []portainer.EndpointID, endpointID portainer.EndpointID) bool {
+	for _, id := range endpointIDs {
+		if id == endpointID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func isHTTPMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		return true
+	}
+
+	return false
+}
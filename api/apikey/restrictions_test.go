@@ -0,0 +1,71 @@
+package apikey
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsExpired(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Now()
+
+	is.False(IsExpired(&portainer.APIKey{}, now))
+	is.False(IsExpired(&portainer.APIKey{ExpiresAt: now.Add(time.Minute).Unix()}, now))
+	is.True(IsExpired(&portainer.APIKey{ExpiresAt: now.Unix()}, now))
+}
+
+func Test_CheckRestrictions(t *testing.T) {
+	restrictions := &portainer.APIKeyRestrictions{
+		EndpointIDs: []portainer.EndpointID{1, 3},
+		Scopes: []portainer.APIKeyScope{
+			{Path: "/stacks"},
+			{Path: "/endpoints/*/docker/containers", Methods: []string{"get"}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		restrictions *portainer.APIKeyRestrictions
+		method       string
+		path         string
+		endpointIDs  []portainer.EndpointID
+		want         error
+	}{
+		{"no restrictions", &portainer.APIKeyRestrictions{}, http.MethodDelete, "/users/1", nil, nil},
+		{"read-only allows reads", &portainer.APIKeyRestrictions{ReadOnly: true}, http.MethodGet, "/stacks", nil, nil},
+		{"read-only denies writes", &portainer.APIKeyRestrictions{ReadOnly: true}, http.MethodPut, "/stacks/1", nil, ErrAPIKeyReadOnly},
+		{"read-only denies websockets", &portainer.APIKeyRestrictions{ReadOnly: true}, http.MethodGet, "/websocket/exec", []portainer.EndpointID{1}, ErrAPIKeyReadOnly},
+		{"scope path", restrictions, http.MethodPost, "/stacks", []portainer.EndpointID{1}, nil},
+		{"scope sub-path", restrictions, http.MethodPost, "/stacks/4/start", []portainer.EndpointID{3}, nil},
+		{"scope wildcard and method", restrictions, http.MethodGet, "/endpoints/1/docker/containers/json", []portainer.EndpointID{1}, nil},
+		{"scope method denied", restrictions, http.MethodPost, "/endpoints/1/docker/containers/create", []portainer.EndpointID{1}, ErrAPIKeyScopeDenied},
+		{"scope prefix is not a segment", restrictions, http.MethodGet, "/stacksx", nil, ErrAPIKeyScopeDenied},
+		{"path out of scopes", restrictions, http.MethodGet, "/users", nil, ErrAPIKeyScopeDenied},
+		{"environment denied", restrictions, http.MethodGet, "/endpoints/2/docker/containers/json", []portainer.EndpointID{2}, ErrAPIKeyEndpointDenied},
+		{"one of the environments denied", restrictions, http.MethodPost, "/stacks/4/start", []portainer.EndpointID{1, 2}, ErrAPIKeyEndpointDenied},
+		{"state-changing request without environment denied", restrictions, http.MethodPost, "/stacks", nil, ErrAPIKeyEndpointDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CheckRestrictions(tt.restrictions, tt.method, tt.path, tt.endpointIDs))
+		})
+	}
+}
+
+func Test_ValidateRestrictions(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateRestrictions(&portainer.APIKeyRestrictions{
+		EndpointIDs: []portainer.EndpointID{1},
+		Scopes:      []portainer.APIKeyScope{{Path: "/stacks", Methods: []string{"GET", "post"}}},
+	}))
+	is.Error(ValidateRestrictions(&portainer.APIKeyRestrictions{Scopes: []portainer.APIKeyScope{{Path: "stacks"}}}))
+	is.Error(ValidateRestrictions(&portainer.APIKeyRestrictions{Scopes: []portainer.APIKeyScope{{Path: "/stacks", Methods: []string{"FETCH"}}}}))
+	is.Error(ValidateRestrictions(&portainer.APIKeyRestrictions{EndpointIDs: []portainer.EndpointID{0}}))
+}
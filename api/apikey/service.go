@@ -39,6 +39,13 @@ func (a *apiKeyService) HashRaw(rawKey string) []byte {
 // GenerateApiKey generates a raw API key for a user (for one-time display).
 // The generated API key is stored in the cache and database.
 func (a *apiKeyService) GenerateApiKey(user portainer.User, description string) (string, *portainer.APIKey, error) {
+	return a.GenerateRestrictedApiKey(user, description, 0, portainer.APIKeyRestrictions{})
+}
+
+// GenerateRestrictedApiKey generates a raw API key for a user (for one-time display), which expires at expiresAt
+// (no expiry when 0) and is limited by the restrictions.
+// The generated API key is stored in the cache and database.
+func (a *apiKeyService) GenerateRestrictedApiKey(user portainer.User, description string, expiresAt int64, restrictions portainer.APIKeyRestrictions) (string, *portainer.APIKey, error) {
 	randKey := generateRandomKey(32)
 	encodedRawAPIKey := base64.StdEncoding.EncodeToString(randKey)
 	prefixedAPIKey := portainerAPIKeyPrefix + encodedRawAPIKey
@@ -46,11 +53,13 @@ func (a *apiKeyService) GenerateApiKey(user portainer.User, description string)
 	hashDigest := a.HashRaw(prefixedAPIKey)
 
 	apiKey := &portainer.APIKey{
-		UserID:      user.ID,
-		Description: description,
-		Prefix:      prefixedAPIKey[:7],
-		DateCreated: time.Now().Unix(),
-		Digest:      hashDigest,
+		UserID:       user.ID,
+		Description:  description,
+		Prefix:       prefixedAPIKey[:7],
+		DateCreated:  time.Now().Unix(),
+		Digest:       hashDigest,
+		ExpiresAt:    expiresAt,
+		Restrictions: restrictions,
 	}
 
 	err := a.apiKeyRepository.CreateAPIKey(apiKey)
@@ -76,10 +85,15 @@ func (a *apiKeyService) GetAPIKeys(userID portainer.UserID) ([]portainer.APIKey,
 
 // GetDigestUserAndKey returns the user and api-key associated to a specified hash digest.
 // A cache lookup is performed first; if the user/api-key is not found in the cache, respective database lookups are performed.
+// ErrAPIKeyExpired is returned along the user and api-key when the api-key expired.
 func (a *apiKeyService) GetDigestUserAndKey(digest []byte) (portainer.User, portainer.APIKey, error) {
 	// get api key from cache if possible
 	cachedUser, cachedKey, ok := a.cache.Get(digest)
 	if ok {
+		if IsExpired(&cachedKey, time.Now()) {
+			return cachedUser, cachedKey, ErrAPIKeyExpired
+		}
+
 		return cachedUser, cachedKey, nil
 	}
 
@@ -96,13 +110,17 @@ func (a *apiKeyService) GetDigestUserAndKey(digest []byte) (portainer.User, port
 	// persist api-key to cache - for quicker future lookups
 	a.cache.Set(apiKey.Digest, *user, *apiKey)
 
+	if IsExpired(apiKey, time.Now()) {
+		return *user, *apiKey, ErrAPIKeyExpired
+	}
+
 	return *user, *apiKey, nil
 }
 
 // UpdateAPIKey updates an API key and in cache and database.
 func (a *apiKeyService) UpdateAPIKey(apiKey *portainer.APIKey) error {
 	user, _, err := a.GetDigestUserAndKey(apiKey.Digest)
-	if err != nil {
+	if err != nil && !errors.Is(err, ErrAPIKeyExpired) {
 		return errors.Wrap(err, "Unable to retrieve API key")
 	}
 	a.cache.Set(apiKey.Digest, user, *apiKey)
@@ -125,3 +143,43 @@ func (a *apiKeyService) DeleteAPIKey(apiKeyID portainer.APIKeyID) error {
 func (a *apiKeyService) InvalidateUserKeyCache(userId portainer.UserID) bool {
 	return a.cache.InvalidateUserKeyCache(userId)
 }
+
+// ExpireAPIKeys deletes the API keys which expired at the time now, and returns the API keys which expire within
+// warningPeriod and were not returned yet. The expiry of the returned API keys is marked as notified.
+func (a *apiKeyService) ExpireAPIKeys(now time.Time, warningPeriod time.Duration) ([]portainer.APIKey, error) {
+	apiKeys, err := a.apiKeyRepository.GetAPIKeys()
+	if err != nil {
+		return nil, errors.Wrap(err, "Unable to retrieve the API keys")
+	}
+
+	var expiring []portainer.APIKey
+	for i := range apiKeys {
+		apiKey := &apiKeys[i]
+		if apiKey.ExpiresAt == 0 {
+			continue
+		}
+
+		if IsExpired(apiKey, now) {
+			a.cache.Delete(apiKey.Digest)
+			if err := a.apiKeyRepository.DeleteAPIKey(apiKey.ID); err != nil {
+				return expiring, errors.Wrap(err, fmt.Sprintf("Unable to delete the expired API key: %d", apiKey.ID))
+			}
+
+			continue
+		}
+
+		if apiKey.ExpiryNotified || now.Add(warningPeriod).Unix() < apiKey.ExpiresAt {
+			continue
+		}
+
+		apiKey.ExpiryNotified = true
+		a.cache.Delete(apiKey.Digest)
+		if err := a.apiKeyRepository.UpdateAPIKey(apiKey); err != nil {
+			return expiring, errors.Wrap(err, fmt.Sprintf("Unable to update the API key: %d", apiKey.ID))
+		}
+
+		expiring = append(expiring, *apiKey)
+	}
+
+	return expiring, nil
+}
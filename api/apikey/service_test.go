@@ -307,3 +307,48 @@ func Test_InvalidateUserKeyCache(t *testing.T) {
 		is.True(ok)
 	})
 }
+
+func Test_ExpireAPIKeys(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	service := NewAPIKeyService(store.APIKeyRepository(), store.User())
+
+	user := portainer.User{ID: 1}
+	err := store.User().Create(&user)
+	is.NoError(err)
+
+	now := time.Now()
+
+	_, permanent, err := service.GenerateApiKey(user, "permanent")
+	is.NoError(err)
+	_, expired, err := service.GenerateRestrictedApiKey(user, "expired", now.Add(-time.Hour).Unix(), portainer.APIKeyRestrictions{})
+	is.NoError(err)
+	_, expiring, err := service.GenerateRestrictedApiKey(user, "expiring", now.Add(24*time.Hour).Unix(), portainer.APIKeyRestrictions{})
+	is.NoError(err)
+	_, later, err := service.GenerateRestrictedApiKey(user, "later", now.Add(30*24*time.Hour).Unix(), portainer.APIKeyRestrictions{})
+	is.NoError(err)
+
+	_, _, err = service.GetDigestUserAndKey(expired.Digest)
+	is.ErrorIs(err, ErrAPIKeyExpired)
+
+	notified, err := service.ExpireAPIKeys(now, ExpiryWarningPeriod)
+	is.NoError(err)
+	is.Len(notified, 1)
+	is.Equal(expiring.ID, notified[0].ID)
+
+	keys, err := service.GetAPIKeys(user.ID)
+	is.NoError(err)
+
+	ids := []portainer.APIKeyID{}
+	for _, key := range keys {
+		ids = append(ids, key.ID)
+	}
+	is.ElementsMatch([]portainer.APIKeyID{permanent.ID, expiring.ID, later.ID}, ids)
+
+	notified, err = service.ExpireAPIKeys(now, ExpiryWarningPeriod)
+	is.NoError(err)
+	is.Empty(notified, "the expiry of an API key is notified once")
+}
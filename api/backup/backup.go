@@ -27,9 +27,11 @@ var filesToBackup = []string{
 	"edge_jobs",
 	"edge_stacks",
 	"extensions",
+	"git_credentials.key",
 	"portainer.key",
 	"portainer.pub",
 	"tls",
+	"two_factor.key",
 }
 
 // Creates a tar.gz system archive and encrypts it if password is not empty. Returns a path to the archive file.
@@ -0,0 +1,59 @@
+package backup
+
+import (
+	"io"
+	"path"
+	"strings"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/pkg/errors"
+)
+
+// ArchivePrefix is the prefix of the names of the archives created by the backup schedule
+const ArchivePrefix = "portainer-backup_"
+
+// ErrArchiveNotFound is returned when an archive cannot be found in a destination
+var ErrArchiveNotFound = errors.New("backup archive not found")
+
+// Archive represents a backup archive kept by a destination
+type Archive struct {
+	// Name of the archive
+	Name string `json:"Name" example:"portainer-backup_2023-01-02_15-04-05.tar.gz.encrypted"`
+	// Size of the archive in bytes
+	Size int64 `json:"Size" example:"1024"`
+	// Creation date of the archive (unix timestamp)
+	CreatedAt int64 `json:"CreatedAt" example:"1672671845"`
+}
+
+// BackupDestination represents a storage where the backup archives are kept
+type BackupDestination interface {
+	// Upload stores the content under the given name, replacing any existing archive with the same name
+	Upload(name string, content io.ReadSeeker) error
+	// List returns the archives found in the destination
+	List() ([]Archive, error)
+	// Download returns the content of an archive, the caller is responsible for closing it
+	Download(name string) (io.ReadCloser, error)
+	// Delete removes an archive from the destination
+	Delete(name string) error
+}
+
+// NewDestination returns the destination described by the backup schedule settings
+func NewDestination(settings portainer.BackupScheduleSettings) (BackupDestination, error) {
+	switch settings.DestinationType {
+	case portainer.BackupDestinationLocal:
+		return NewLocalDestination(settings.LocalDirectory)
+	case portainer.BackupDestinationS3:
+		return NewS3Destination(settings.S3Settings)
+	}
+
+	return nil, errors.Errorf("unsupported backup destination type %d", settings.DestinationType)
+}
+
+// ValidateArchiveName makes sure an archive name cannot be used to escape the destination
+func ValidateArchiveName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) || path.Base(name) != name {
+		return errors.Errorf("invalid archive name %q", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package backup
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// LocalDestination keeps the backup archives inside a directory of the local filesystem
+type LocalDestination struct {
+	directory string
+}
+
+// NewLocalDestination creates a destination storing the archives in the given directory
+func NewLocalDestination(directory string) (*LocalDestination, error) {
+	if directory == "" {
+		return nil, errors.New("a directory is required for a local backup destination")
+	}
+
+	return &LocalDestination{directory: directory}, nil
+}
+
+// Upload writes the archive inside the directory, creating the directory if needed
+func (d *LocalDestination) Upload(name string, content io.ReadSeeker) error {
+	if err := ValidateArchiveName(name); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(d.directory, 0700); err != nil {
+		return errors.Wrap(err, "failed to create the backup directory")
+	}
+
+	// write to a temporary file first so that a partial upload is never listed
+	tmpPath := filepath.Join(d.directory, "."+name+".tmp")
+	out, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the archive file")
+	}
+
+	_, err = io.Copy(out, content)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return errors.Wrap(err, "failed to write the archive file")
+	}
+
+	return os.Rename(tmpPath, filepath.Join(d.directory, name))
+}
+
+// List returns the archives found inside the directory
+func (d *LocalDestination) List() ([]Archive, error) {
+	entries, err := os.ReadDir(d.directory)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Archive{}, nil
+		}
+
+		return nil, errors.Wrap(err, "failed to read the backup directory")
+	}
+
+	archives := make([]Archive, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || ValidateArchiveName(entry.Name()) != nil || entry.Name()[0] == '.' {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		archives = append(archives, Archive{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime().Unix(),
+		})
+	}
+
+	return archives, nil
+}
+
+// Download opens the archive file
+func (d *LocalDestination) Download(name string) (io.ReadCloser, error) {
+	if err := ValidateArchiveName(name); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filepath.Join(d.directory, name))
+	if os.IsNotExist(err) {
+		return nil, ErrArchiveNotFound
+	}
+
+	return file, err
+}
+
+// Delete removes the archive file
+func (d *LocalDestination) Delete(name string) error {
+	if err := ValidateArchiveName(name); err != nil {
+		return err
+	}
+
+	err := os.Remove(filepath.Join(d.directory, name))
+	if os.IsNotExist(err) {
+		return ErrArchiveNotFound
+	}
+
+	return err
+}
@@ -0,0 +1,250 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	s3DefaultRegion   = "us-east-1"
+	s3RequestTimeout  = 10 * time.Minute
+	s3ErrorBodyLength = 4096
+)
+
+// S3Destination keeps the backup archives inside a bucket of a S3-compatible object storage (AWS S3, MinIO...).
+// Path-style requests are used so that the destination works with self-hosted services.
+type S3Destination struct {
+	endpoint *url.URL
+	settings portainer.BackupS3Settings
+	client   *http.Client
+	signer   *v4.Signer
+}
+
+type s3ListBucketResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// NewS3Destination creates a destination storing the archives in a S3-compatible bucket
+func NewS3Destination(settings portainer.BackupS3Settings) (*S3Destination, error) {
+	if settings.Endpoint == "" || settings.BucketName == "" {
+		return nil, errors.New("an endpoint and a bucket name are required for a S3 backup destination")
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(settings.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, errors.Errorf("invalid S3 endpoint %q", settings.Endpoint)
+	}
+
+	if settings.Region == "" {
+		settings.Region = s3DefaultRegion
+	}
+
+	return &S3Destination{
+		endpoint: endpoint,
+		settings: settings,
+		client:   &http.Client{Timeout: s3RequestTimeout},
+		signer: v4.NewSigner(func(options *v4.SignerOptions) {
+			options.DisableURIPathEscaping = true
+		}),
+	}, nil
+}
+
+// Upload puts the archive in the bucket
+func (d *S3Destination) Upload(name string, content io.ReadSeeker) error {
+	if err := ValidateArchiveName(name); err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, content)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the archive")
+	}
+
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "failed to read the archive")
+	}
+
+	req, err := http.NewRequest(http.MethodPut, d.objectURL(name), io.NopCloser(content))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := d.do(req, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// List returns the archives found in the bucket under the configured prefix
+func (d *S3Destination) List() ([]Archive, error) {
+	archives := make([]Archive, 0)
+
+	continuationToken := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", d.settings.Prefix)
+		if continuationToken != "" {
+			query.Set("continuation-token", continuationToken)
+		}
+
+		req, err := http.NewRequest(http.MethodGet, d.bucketURL()+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := d.do(req, emptyPayloadHash())
+		if err != nil {
+			return nil, err
+		}
+
+		var result s3ListBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode the S3 object listing")
+		}
+
+		for _, object := range result.Contents {
+			name := strings.TrimPrefix(object.Key, d.settings.Prefix)
+			if ValidateArchiveName(name) != nil {
+				continue
+			}
+
+			archives = append(archives, Archive{
+				Name:      name,
+				Size:      object.Size,
+				CreatedAt: object.LastModified.Unix(),
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return archives, nil
+		}
+		continuationToken = result.NextContinuationToken
+	}
+}
+
+// Download returns the content of the archive stored in the bucket
+func (d *S3Destination) Download(name string) (io.ReadCloser, error) {
+	if err := ValidateArchiveName(name); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, d.objectURL(name), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := d.do(req, emptyPayloadHash())
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// Delete removes the archive from the bucket
+func (d *S3Destination) Delete(name string) error {
+	if err := ValidateArchiveName(name); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, d.objectURL(name), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := d.do(req, emptyPayloadHash())
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+func (d *S3Destination) bucketURL() string {
+	return d.endpoint.String() + "/" + url.PathEscape(d.settings.BucketName)
+}
+
+func (d *S3Destination) objectURL(name string) string {
+	segments := strings.Split(d.settings.Prefix+name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return d.bucketURL() + "/" + strings.Join(segments, "/")
+}
+
+// do signs and sends the request, any response outside of the 2xx range is turned into an error
+func (d *S3Destination) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	credentials := aws.Credentials{
+		AccessKeyID:     d.settings.AccessKeyID,
+		SecretAccessKey: d.settings.SecretAccessKey,
+	}
+
+	err := d.signer.SignHTTP(context.Background(), credentials, req, payloadHash, "s3", d.settings.Region, time.Now().UTC())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign the S3 request")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to reach the S3 endpoint")
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var s3Err s3Error
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, s3ErrorBodyLength))
+	xml.Unmarshal(body, &s3Err)
+
+	if resp.StatusCode == http.StatusNotFound && s3Err.Code != "NoSuchBucket" {
+		return nil, ErrArchiveNotFound
+	}
+
+	if s3Err.Code != "" {
+		return nil, fmt.Errorf("S3 request failed with status %d: %s (%s)", resp.StatusCode, s3Err.Message, s3Err.Code)
+	}
+
+	return nil, fmt.Errorf("S3 request failed with status %d", resp.StatusCode)
+}
+
+func emptyPayloadHash() string {
+	hash := sha256.Sum256(nil)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,222 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// s3Stub is a minimal in-memory S3-compatible server, similar to what MinIO exposes
+type s3Stub struct {
+	t       *testing.T
+	bucket  string
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newS3Stub(t *testing.T, bucket string) *httptest.Server {
+	stub := &s3Stub{t: t, bucket: bucket, objects: make(map[string][]byte)}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access-key/") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	bucket, key, _ := strings.Cut(path, "/")
+	if bucket != s.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet:
+		assert.Equal(s.t, "2", r.URL.Query().Get("list-type"))
+		prefix := r.URL.Query().Get("prefix")
+
+		result := s3ListBucketResult{}
+		keys := make([]string, 0, len(s.objects))
+		for k := range s.objects {
+			if strings.HasPrefix(k, prefix) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			result.Contents = append(result.Contents, struct {
+				Key          string    `xml:"Key"`
+				LastModified time.Time `xml:"LastModified"`
+				Size         int64     `xml:"Size"`
+			}{Key: k, LastModified: time.Now(), Size: int64(len(s.objects[k]))})
+		}
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		content, _ := io.ReadAll(r.Body)
+		s.objects[key] = content
+	case r.Method == http.MethodGet:
+		content, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>")
+			return
+		}
+		w.Write(content)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func testDestination(t *testing.T, destination BackupDestination) {
+	is := assert.New(t)
+
+	archives, err := destination.List()
+	is.NoError(err)
+	is.Empty(archives)
+
+	is.NoError(destination.Upload("archive-1.tar.gz", bytes.NewReader([]byte("first"))))
+	is.NoError(destination.Upload("archive-2.tar.gz", bytes.NewReader([]byte("second"))))
+
+	archives, err = destination.List()
+	is.NoError(err)
+	is.Len(archives, 2)
+
+	reader, err := destination.Download("archive-2.tar.gz")
+	is.NoError(err)
+	content, _ := io.ReadAll(reader)
+	reader.Close()
+	is.Equal("second", string(content))
+
+	_, err = destination.Download("missing.tar.gz")
+	is.ErrorIs(err, ErrArchiveNotFound)
+
+	is.Error(destination.Upload("../escape.tar.gz", bytes.NewReader(nil)), "names escaping the destination should be rejected")
+
+	is.NoError(destination.Delete("archive-1.tar.gz"))
+
+	archives, err = destination.List()
+	is.NoError(err)
+	is.Len(archives, 1)
+	is.Equal("archive-2.tar.gz", archives[0].Name)
+	is.Equal(int64(len("second")), archives[0].Size)
+}
+
+func Test_LocalDestination(t *testing.T) {
+	destination, err := NewLocalDestination(t.TempDir())
+	assert.NoError(t, err)
+
+	testDestination(t, destination)
+}
+
+func Test_S3Destination(t *testing.T) {
+	srv := newS3Stub(t, "backups")
+
+	destination, err := NewS3Destination(portainer.BackupS3Settings{
+		Endpoint:        srv.URL,
+		BucketName:      "backups",
+		Prefix:          "portainer/",
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+	})
+	assert.NoError(t, err)
+
+	testDestination(t, destination)
+}
+
+func Test_S3Destination_UnknownBucket(t *testing.T) {
+	srv := newS3Stub(t, "backups")
+
+	destination, err := NewS3Destination(portainer.BackupS3Settings{
+		Endpoint:        srv.URL,
+		BucketName:      "unknown",
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+	})
+	assert.NoError(t, err)
+
+	_, err = destination.List()
+	assert.ErrorContains(t, err, "NoSuchBucket")
+}
+
+func Test_applyRetention_keepsNewestArchives(t *testing.T) {
+	is := assert.New(t)
+
+	destination, err := NewLocalDestination(t.TempDir())
+	is.NoError(err)
+
+	names := []string{
+		ArchivePrefix + "2023-01-01_02-00-00.tar.gz.encrypted",
+		ArchivePrefix + "2023-01-03_02-00-00.tar.gz.encrypted",
+		ArchivePrefix + "2023-01-02_02-00-00.tar.gz.encrypted",
+		"manual-upload.tar.gz",
+	}
+	for _, name := range names {
+		is.NoError(destination.Upload(name, bytes.NewReader([]byte(name))))
+	}
+
+	is.NoError(applyRetention(destination, 2))
+
+	archives, err := destination.List()
+	is.NoError(err)
+
+	remaining := make([]string, 0, len(archives))
+	for _, archive := range archives {
+		remaining = append(remaining, archive.Name)
+	}
+
+	is.ElementsMatch([]string{
+		ArchivePrefix + "2023-01-03_02-00-00.tar.gz.encrypted",
+		ArchivePrefix + "2023-01-02_02-00-00.tar.gz.encrypted",
+		"manual-upload.tar.gz",
+	}, remaining, "only the newest scheduled archives should be kept, other files left untouched")
+}
+
+func Test_ValidateScheduleSettings(t *testing.T) {
+	valid := portainer.BackupScheduleSettings{
+		Enabled:         true,
+		CronExpression:  "0 2 * * *",
+		Password:        "secret",
+		MaxBackupCount:  7,
+		DestinationType: portainer.BackupDestinationLocal,
+		LocalDirectory:  "/data/backups",
+	}
+	assert.NoError(t, ValidateScheduleSettings(valid))
+
+	invalidCron := valid
+	invalidCron.CronExpression = "every day"
+	assert.Error(t, ValidateScheduleSettings(invalidCron))
+
+	noPassword := valid
+	noPassword.Password = ""
+	assert.Error(t, ValidateScheduleSettings(noPassword))
+
+	noDestination := valid
+	noDestination.DestinationType = 0
+	assert.Error(t, ValidateScheduleSettings(noDestination))
+
+	disabled := portainer.BackupScheduleSettings{}
+	assert.NoError(t, ValidateScheduleSettings(disabled))
+}
@@ -0,0 +1,180 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/http/offlinegate"
+	"github.com/cloudogu/portainer-ce/api/scheduler"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+// ScheduleService creates backup archives following the backup schedule stored in the settings
+type ScheduleService struct {
+	scheduler     *scheduler.Scheduler
+	gate          *offlinegate.OfflineGate
+	dataStore     dataservices.DataStore
+	filestorePath string
+
+	mu      sync.Mutex
+	jobID   string
+	running sync.Mutex
+}
+
+// NewScheduleService creates a new instance of the backup schedule service
+func NewScheduleService(scheduler *scheduler.Scheduler, gate *offlinegate.OfflineGate, dataStore dataservices.DataStore, filestorePath string) *ScheduleService {
+	return &ScheduleService{
+		scheduler:     scheduler,
+		gate:          gate,
+		dataStore:     dataStore,
+		filestorePath: filestorePath,
+	}
+}
+
+// Start schedules the backups according to the settings stored in the database
+func (service *ScheduleService) Start() error {
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	return service.SetSchedule(settings.BackupSchedule)
+}
+
+// SetSchedule replaces the current backup schedule. Nothing is scheduled when the schedule is disabled.
+func (service *ScheduleService) SetSchedule(settings portainer.BackupScheduleSettings) error {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	if service.jobID != "" {
+		if err := service.scheduler.StopJob(service.jobID); err != nil {
+			return err
+		}
+		service.jobID = ""
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	jobID, err := service.scheduler.StartJobWithCronExpression(settings.CronExpression, func() error {
+		if err := service.Run(); err != nil {
+			log.Error().Err(err).Msg("scheduled backup failed")
+		}
+
+		// errors are not returned so that the next backups are still attempted
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	service.jobID = jobID
+
+	return nil
+}
+
+// Run creates a backup archive, uploads it to the configured destination and removes the archives exceeding the retention
+func (service *ScheduleService) Run() error {
+	if !service.running.TryLock() {
+		log.Warn().Msg("a scheduled backup is already running, skipping")
+		return nil
+	}
+	defer service.running.Unlock()
+
+	settings, err := service.dataStore.Settings().Settings()
+	if err != nil {
+		return errors.Wrap(err, "failed to retrieve the settings")
+	}
+
+	schedule := settings.BackupSchedule
+
+	destination, err := NewDestination(schedule)
+	if err != nil {
+		return err
+	}
+
+	archivePath, err := CreateBackupArchive(schedule.Password, service.gate, service.dataStore, service.filestorePath)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(filepath.Dir(archivePath))
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to open the backup archive")
+	}
+	defer archive.Close()
+
+	name := ArchivePrefix + filepath.Base(archivePath)
+	if err := destination.Upload(name, archive); err != nil {
+		return errors.Wrap(err, "failed to upload the backup archive")
+	}
+
+	log.Info().Str("archive", name).Msg("scheduled backup created")
+
+	return applyRetention(destination, schedule.MaxBackupCount)
+}
+
+// applyRetention keeps the newest maxBackupCount archives created by the schedule, a count of 0 keeps all of them
+func applyRetention(destination BackupDestination, maxBackupCount int) error {
+	if maxBackupCount <= 0 {
+		return nil
+	}
+
+	archives, err := destination.List()
+	if err != nil {
+		return errors.Wrap(err, "failed to list the backup archives")
+	}
+
+	names := make([]string, 0, len(archives))
+	for _, archive := range archives {
+		if strings.HasPrefix(archive.Name, ArchivePrefix) {
+			names = append(names, archive.Name)
+		}
+	}
+
+	// archive names embed their creation date, the newest sorts first
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+
+	for i := maxBackupCount; i < len(names); i++ {
+		if err := destination.Delete(names[i]); err != nil {
+			return errors.Wrapf(err, "failed to remove the backup archive %s", names[i])
+		}
+
+		log.Debug().Str("archive", names[i]).Msg("backup archive removed by the retention policy")
+	}
+
+	return nil
+}
+
+// ValidateScheduleSettings makes sure an enabled backup schedule can be run
+func ValidateScheduleSettings(settings portainer.BackupScheduleSettings) error {
+	if settings.MaxBackupCount < 0 {
+		return errors.New("the maximum backup count cannot be negative")
+	}
+
+	if !settings.Enabled {
+		return nil
+	}
+
+	if _, err := cron.ParseStandard(settings.CronExpression); err != nil {
+		return errors.Wrap(err, "invalid cron expression")
+	}
+
+	if settings.Password == "" {
+		return errors.New("a password is required to encrypt the scheduled backups")
+	}
+
+	_, err := NewDestination(settings)
+
+	return err
+}
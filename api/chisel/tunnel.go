@@ -94,6 +94,21 @@ func (service *Service) GetActiveTunnel(endpoint *portainer.Endpoint) (portainer
 	return service.GetTunnelDetails(endpoint.ID), nil
 }
 
+// ActiveTunnelCount returns the number of tunnels currently active.
+func (service *Service) ActiveTunnelCount() int {
+	service.mu.Lock()
+	defer service.mu.Unlock()
+
+	count := 0
+	for _, tunnel := range service.tunnelDetailsMap {
+		if tunnel.Status == portainer.EdgeAgentActive {
+			count++
+		}
+	}
+
+	return count
+}
+
 // SetTunnelStatusToActive update the status of the tunnel associated to the specified environment(endpoint).
 // It sets the status to ACTIVE.
 func (service *Service) SetTunnelStatusToActive(endpointID portainer.EndpointID) {
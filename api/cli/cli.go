@@ -2,6 +2,7 @@ package cli
 
 import (
 	"errors"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,6 +21,9 @@ var (
 	errInvalidEndpointProtocol       = errors.New("Invalid environment protocol: Portainer only supports unix://, npipe:// or tcp://")
 	errSocketOrNamedPipeNotFound     = errors.New("Unable to locate Unix socket or named pipe")
 	errInvalidSnapshotInterval       = errors.New("Invalid snapshot interval")
+	errInvalidSnapshotConcurrency    = errors.New("Invalid snapshot concurrency, at least one environment must be snapshotted at a time")
+	errInvalidSnapshotTimeout        = errors.New("Invalid snapshot timeout")
+	errInvalidCSRFTrustedOrigin      = errors.New("Invalid CSRF trusted origin, it must be a scheme and a host such as https://portainer.example.com")
 	errAdminPassExcludeAdminPassFile = errors.New("Cannot use --admin-password with --admin-password-file")
 )
 
@@ -51,6 +55,9 @@ func (*Service) ParseFlags(version string) (*portainer.CLIFlags, error) {
 		SSLKey:                    kingpin.Flag("sslkey", "Path to the SSL key used to secure the Portainer instance").String(),
 		Rollback:                  kingpin.Flag("rollback", "Rollback the database store to the previous version").Bool(),
 		SnapshotInterval:          kingpin.Flag("snapshot-interval", "Duration between each environment snapshot job").String(),
+		SnapshotConcurrency:       kingpin.Flag("snapshot-concurrency", "Number of environments snapshotted at the same time").Default(defaultSnapshotConcurrency).Int(),
+		SnapshotTimeout:           kingpin.Flag("snapshot-timeout", "Duration after which the snapshot of an environment is considered failed").Default(defaultSnapshotTimeout).Duration(),
+		CSRFTrustedOrigins:        kingpin.Flag("csrf-trusted-origins", "Origins, such as https://portainer.example.com, the state-changing requests of a browser session are accepted from in addition to the one Portainer is served on").Strings(),
 		AdminPassword:             kingpin.Flag("admin-password", "Set admin password with provided hash").String(),
 		AdminPasswordFile:         kingpin.Flag("admin-password-file", "Path to the file containing the password for the admin user").String(),
 		Labels:                    pairs(kingpin.Flag("hide-label", "Hide containers with a specific label in the UI").Short('l')),
@@ -93,6 +100,19 @@ func (*Service) ValidateFlags(flags *portainer.CLIFlags) error {
 		return err
 	}
 
+	if *flags.SnapshotConcurrency < 1 {
+		return errInvalidSnapshotConcurrency
+	}
+
+	if *flags.SnapshotTimeout <= 0 {
+		return errInvalidSnapshotTimeout
+	}
+
+	err = validateCSRFTrustedOrigins(*flags.CSRFTrustedOrigins)
+	if err != nil {
+		return err
+	}
+
 	if *flags.AdminPassword != "" && *flags.AdminPasswordFile != "" {
 		return errAdminPassExcludeAdminPassFile
 	}
@@ -139,3 +159,13 @@ func validateSnapshotInterval(snapshotInterval string) error {
 	}
 	return nil
 }
+
+func validateCSRFTrustedOrigins(origins []string) error {
+	for _, origin := range origins {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || strings.TrimSuffix(u.Path, "/") != "" {
+			return errInvalidCSRFTrustedOrigin
+		}
+	}
+	return nil
+}
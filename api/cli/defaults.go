@@ -18,6 +18,8 @@ const (
 	defaultHTTPDisabled        = "false"
 	defaultHTTPEnabled         = "false"
 	defaultSSL                 = "false"
+	defaultSnapshotConcurrency = "10"
+	defaultSnapshotTimeout     = "1m"
 	defaultBaseURL             = "/"
 	defaultSecretKeyName       = "portainer"
 )
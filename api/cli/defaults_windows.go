@@ -16,6 +16,8 @@ const (
 	defaultHTTPEnabled         = "false"
 	defaultSSL                 = "false"
 	defaultSnapshotInterval    = "5m"
+	defaultSnapshotConcurrency = "10"
+	defaultSnapshotTimeout     = "1m"
 	defaultBaseURL             = "/"
 	defaultSecretKeyName       = "portainer"
 )
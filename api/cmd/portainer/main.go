@@ -32,6 +32,8 @@ import (
 	"github.com/cloudogu/portainer-ce/api/http/client"
 	"github.com/cloudogu/portainer-ce/api/http/proxy"
 	kubeproxy "github.com/cloudogu/portainer-ce/api/http/proxy/factory/kubernetes"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/internal/accessgrant"
 	"github.com/cloudogu/portainer-ce/api/internal/authorization"
 	"github.com/cloudogu/portainer-ce/api/internal/edge"
 	"github.com/cloudogu/portainer-ce/api/internal/edge/edgestacks"
@@ -42,6 +44,7 @@ import (
 	"github.com/cloudogu/portainer-ce/api/kubernetes"
 	kubecli "github.com/cloudogu/portainer-ce/api/kubernetes/cli"
 	"github.com/cloudogu/portainer-ce/api/ldap"
+	"github.com/cloudogu/portainer-ce/api/notifications"
 	"github.com/cloudogu/portainer-ce/api/oauth"
 	"github.com/cloudogu/portainer-ce/api/scheduler"
 	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
@@ -152,8 +155,8 @@ func initDataStore(flags *portainer.CLIFlags, secretKey []byte, fileService port
 	return store
 }
 
-func initComposeStackManager(composeDeployer libstack.Deployer, reverseTunnelService portainer.ReverseTunnelService, proxyManager *proxy.Manager) portainer.ComposeStackManager {
-	composeWrapper, err := exec.NewComposeStackManager(composeDeployer, proxyManager)
+func initComposeStackManager(composeDeployer libstack.Deployer, reverseTunnelService portainer.ReverseTunnelService, proxyManager *proxy.Manager, assetsPath string) portainer.ComposeStackManager {
+	composeWrapper, err := exec.NewComposeStackManager(composeDeployer, proxyManager, assetsPath)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed creating compose manager")
 	}
@@ -172,8 +175,8 @@ func initSwarmStackManager(
 	return exec.NewSwarmStackManager(assetsPath, configPath, signatureService, fileService, reverseTunnelService, dataStore)
 }
 
-func initKubernetesDeployer(kubernetesTokenCacheManager *kubeproxy.TokenCacheManager, kubernetesClientFactory *kubecli.ClientFactory, dataStore dataservices.DataStore, reverseTunnelService portainer.ReverseTunnelService, signatureService portainer.DigitalSignatureService, proxyManager *proxy.Manager, assetsPath string) portainer.KubernetesDeployer {
-	return exec.NewKubernetesDeployer(kubernetesTokenCacheManager, kubernetesClientFactory, dataStore, reverseTunnelService, signatureService, proxyManager, assetsPath)
+func initKubernetesDeployer(kubernetesTokenCacheManager *kubeproxy.TokenCacheManager, kubernetesClientFactory *kubecli.ClientFactory, dataStore dataservices.DataStore, reverseTunnelService portainer.ReverseTunnelService, signatureService portainer.DigitalSignatureService, proxyManager *proxy.Manager, helmPackageManager libhelm.HelmPackageManager, assetsPath string) portainer.KubernetesDeployer {
+	return exec.NewKubernetesDeployer(kubernetesTokenCacheManager, kubernetesClientFactory, dataStore, reverseTunnelService, signatureService, proxyManager, helmPackageManager, assetsPath)
 }
 
 func initHelmPackageManager(assetsPath string) (libhelm.HelmPackageManager, error) {
@@ -213,8 +216,18 @@ func initOAuthService() portainer.OAuthService {
 	return oauth.NewService()
 }
 
-func initGitService(ctx context.Context) portainer.GitService {
-	return git.NewService(ctx)
+func initGitService(ctx context.Context, dataStore dataservices.DataStore) portainer.GitService {
+	service := git.NewService(ctx)
+	service.SetKnownHostsProvider(func() (string, error) {
+		settings, err := dataStore.Settings().Settings()
+		if err != nil {
+			return "", err
+		}
+
+		return settings.GitSSHKnownHosts, nil
+	})
+
+	return service
 }
 
 func initSSLService(addr, certPath, keyPath string, fileService portainer.FileService, dataStore dataservices.DataStore, shutdownTrigger context.CancelFunc) (*ssl.Service, error) {
@@ -244,19 +257,24 @@ func initKubernetesClientFactory(signatureService portainer.DigitalSignatureServ
 
 func initSnapshotService(
 	snapshotIntervalFromFlag string,
+	snapshotConcurrency int,
+	snapshotTimeout time.Duration,
 	dataStore dataservices.DataStore,
 	dockerClientFactory *docker.ClientFactory,
 	kubernetesClientFactory *kubecli.ClientFactory,
 	shutdownCtx context.Context,
+	notificationService portainer.NotificationService,
 ) (portainer.SnapshotService, error) {
 	dockerSnapshotter := docker.NewSnapshotter(dockerClientFactory)
 	kubernetesSnapshotter := kubernetes.NewSnapshotter(kubernetesClientFactory)
 
-	snapshotService, err := snapshot.NewService(snapshotIntervalFromFlag, dataStore, dockerSnapshotter, kubernetesSnapshotter, shutdownCtx)
+	snapshotService, err := snapshot.NewService(snapshotIntervalFromFlag, dataStore, dockerSnapshotter, kubernetesSnapshotter, shutdownCtx, notificationService)
 	if err != nil {
 		return nil, err
 	}
 
+	snapshotService.SetConcurrency(snapshotConcurrency, snapshotTimeout)
+
 	return snapshotService, nil
 }
 
@@ -585,7 +603,7 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 
 	oauthService := initOAuthService()
 
-	gitService := initGitService(shutdownCtx)
+	gitService := initGitService(shutdownCtx, dataStore)
 
 	openAMTService := openamt.NewService()
 
@@ -615,7 +633,9 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 	dockerClientFactory := initDockerClientFactory(digitalSignatureService, reverseTunnelService)
 	kubernetesClientFactory, err := initKubernetesClientFactory(digitalSignatureService, reverseTunnelService, dataStore, instanceID, *flags.AddrHTTPS, settings.UserSessionTimeout)
 
-	snapshotService, err := initSnapshotService(*flags.SnapshotInterval, dataStore, dockerClientFactory, kubernetesClientFactory, shutdownCtx)
+	notificationService := notifications.NewService(dataStore)
+
+	snapshotService, err := initSnapshotService(*flags.SnapshotInterval, *flags.SnapshotConcurrency, *flags.SnapshotTimeout, dataStore, dockerClientFactory, kubernetesClientFactory, shutdownCtx, notificationService)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed initializing snapshot service")
 	}
@@ -639,20 +659,20 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 	//		log.Fatal().Err(err).Msg("failed initializing compose deployer")
 	//	}
 
-	composeStackManager := initComposeStackManager(composeDeployer, reverseTunnelService, proxyManager)
+	composeStackManager := initComposeStackManager(composeDeployer, reverseTunnelService, proxyManager, *flags.Assets)
 
 	swarmStackManager, err := initSwarmStackManager(*flags.Assets, dockerConfigPath, digitalSignatureService, fileService, reverseTunnelService, dataStore)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed initializing swarm stack manager")
 	}
 
-	kubernetesDeployer := initKubernetesDeployer(kubernetesTokenCacheManager, kubernetesClientFactory, dataStore, reverseTunnelService, digitalSignatureService, proxyManager, *flags.Assets)
-
 	helmPackageManager, err := initHelmPackageManager(*flags.Assets)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed initializing helm package manager")
 	}
 
+	kubernetesDeployer := initKubernetesDeployer(kubernetesTokenCacheManager, kubernetesClientFactory, dataStore, reverseTunnelService, digitalSignatureService, proxyManager, helmPackageManager, *flags.Assets)
+
 	err = edge.LoadEdgeJobs(dataStore, reverseTunnelService)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed loading edge jobs from database")
@@ -717,8 +737,16 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 	}
 
 	scheduler := scheduler.NewScheduler(shutdownCtx)
-	stackDeployer := deployments.NewStackDeployer(swarmStackManager, composeStackManager, kubernetesDeployer)
-	deployments.StartStackSchedules(scheduler, stackDeployer, dataStore, gitService)
+	stackDeployer := deployments.NewStackDeployer(swarmStackManager, composeStackManager, kubernetesDeployer, dataStore, dockerClientFactory)
+	if err := stackDeployer.ResumeHealthChecks(); err != nil {
+		log.Error().Err(err).Msg("failed to resume the health checks of the stacks")
+	}
+	deployments.StartStackSchedules(scheduler, stackDeployer, dataStore, gitService, notificationService)
+	notificationService.StartDeliveryLogCleanup(scheduler)
+	apikey.StartExpiryJob(scheduler, apiKeyService, notificationService)
+	jwt.StartSessionCleanup(scheduler, dataStore)
+	security.StartAuditLogCleanup(scheduler, dataStore)
+	accessgrant.StartExpiryJob(scheduler, dataStore, authorizationService)
 
 	sslDBSettings, err := dataStore.SSLSettings().Settings()
 	if err != nil {
@@ -764,6 +792,7 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 		APIKeyService:               apiKeyService,
 		CryptoService:               cryptoService,
 		JWTService:                  jwtService,
+		CSRFTrustedOrigins:          *flags.CSRFTrustedOrigins,
 		FileService:                 fileService,
 		LDAPService:                 ldapService,
 		OAuthService:                oauthService,
@@ -781,12 +810,23 @@ func buildServer(flags *portainer.CLIFlags) portainer.Server {
 		ShutdownCtx:                 shutdownCtx,
 		ShutdownTrigger:             shutdownTrigger,
 		StackDeployer:               stackDeployer,
+		NotificationService:         notificationService,
 		DemoService:                 demoService,
 		UpgradeService:              upgradeService,
 	}
 }
 
 func main() {
+	// helm runs Portainer as the post-renderer of the releases of the Kubernetes stacks, see exec.KubernetesDeployer
+	if labels, ok := os.LookupEnv(kubernetes.HelmPostRendererLabelsEnvVar); ok {
+		if err := kubernetes.RunHelmPostRenderer(os.Stdin, os.Stdout, labels); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	rand.Seed(time.Now().UnixNano())
 
 	configureLogger()
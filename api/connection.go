@@ -33,6 +33,9 @@ type Connection interface {
 	CreateObjectWithStringId(bucketName string, id []byte, obj interface{}) error
 	GetAll(bucketName string, obj interface{}, append func(o interface{}) (interface{}, error)) error
 	GetAllWithJsoniter(bucketName string, obj interface{}, append func(o interface{}) (interface{}, error)) error
+	GetAllWithKeyPrefix(bucketName string, keyPrefix []byte, obj interface{}, append func(o interface{}) (interface{}, error)) error
+	GetLastWithKeyPrefix(bucketName string, keyPrefix []byte, object interface{}) error
+	DeleteKeyRange(bucketName string, from, to []byte) error
 	ConvertToKey(v int) []byte
 
 	BackupMetadata() (map[string]interface{}, error)
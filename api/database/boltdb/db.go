@@ -11,6 +11,7 @@ import (
 	"time"
 
 	dserrors "github.com/cloudogu/portainer-ce/api/dataservices/errors"
+	"github.com/cloudogu/portainer-ce/api/metrics"
 
 	"github.com/rs/zerolog/log"
 	bolt "go.etcd.io/bbolt"
@@ -147,6 +148,28 @@ func (connection *DbConnection) Close() error {
 	return nil
 }
 
+// View executes a read-only transaction, its duration is recorded in the metrics.
+func (connection *DbConnection) View(fn func(*bolt.Tx) error) error {
+	defer metrics.ObserveDuration(metrics.DatabaseTransactionDuration, time.Now(), "read")
+
+	return connection.DB.View(fn)
+}
+
+// Update executes a read-write transaction, its duration is recorded in the metrics.
+func (connection *DbConnection) Update(fn func(*bolt.Tx) error) error {
+	defer metrics.ObserveDuration(metrics.DatabaseTransactionDuration, time.Now(), "write")
+
+	return connection.DB.Update(fn)
+}
+
+// Batch executes a read-write transaction batched with the concurrent ones,
+// its duration, including the time spent waiting for the batch, is recorded in the metrics.
+func (connection *DbConnection) Batch(fn func(*bolt.Tx) error) error {
+	defer metrics.ObserveDuration(metrics.DatabaseTransactionDuration, time.Now(), "batch")
+
+	return connection.DB.Batch(fn)
+}
+
 // BackupTo backs up db to a provided writer.
 // It does hot backup and doesn't block other database reads and writes
 func (connection *DbConnection) BackupTo(w io.Writer) error {
@@ -414,6 +437,79 @@ func (connection *DbConnection) GetAllWithKeyPrefix(bucketName string, keyPrefix
 	})
 }
 
+// GetLastWithKeyPrefix retrieves the object with the greatest key starting with keyPrefix,
+// without reading the other objects of the prefix.
+func (connection *DbConnection) GetLastWithKeyPrefix(bucketName string, keyPrefix []byte, object interface{}) error {
+	var data []byte
+
+	err := connection.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket([]byte(bucketName)).Cursor()
+
+		// the last key of the prefix precedes the first key past the prefix
+		var k, v []byte
+		if upperBound := keyPrefixUpperBound(keyPrefix); upperBound != nil {
+			k, _ = cursor.Seek(upperBound)
+		}
+
+		if k != nil {
+			k, v = cursor.Prev()
+		} else {
+			k, v = cursor.Last()
+		}
+
+		if k == nil || !bytes.HasPrefix(k, keyPrefix) {
+			return dserrors.ErrObjectNotFound
+		}
+
+		data = make([]byte, len(v))
+		copy(data, v)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return connection.UnmarshalObjectWithJsoniter(data, object)
+}
+
+// keyPrefixUpperBound returns the smallest key greater than every key starting with keyPrefix,
+// or nil when there is none
+func keyPrefixUpperBound(keyPrefix []byte) []byte {
+	upperBound := append([]byte{}, keyPrefix...)
+	for i := len(upperBound) - 1; i >= 0; i-- {
+		if upperBound[i] < 0xff {
+			upperBound[i]++
+			return upperBound[:i+1]
+		}
+	}
+
+	return nil
+}
+
+// DeleteKeyRange deletes the objects whose key is greater than or equal to from and less than to,
+// without reading them.
+func (connection *DbConnection) DeleteKeyRange(bucketName string, from, to []byte) error {
+	return connection.Batch(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+
+		// deleting while iterating makes the cursor skip keys
+		var keys [][]byte
+		cursor := bucket.Cursor()
+		for k, _ := cursor.Seek(from); k != nil && bytes.Compare(k, to) < 0; k, _ = cursor.Next() {
+			keys = append(keys, append([]byte{}, k...))
+		}
+
+		for _, k := range keys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
 // BackupMetadata will return a copy of the boltdb sequence numbers for all buckets.
 func (connection *DbConnection) BackupMetadata() (map[string]interface{}, error) {
 	buckets := map[string]interface{}{}
@@ -0,0 +1,97 @@
+package auditlog
+
+import (
+	"fmt"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "audit_logs"
+)
+
+// Service represents a service for managing audit log data.
+type Service struct {
+	connection portainer.Connection
+}
+
+func (service *Service) BucketName() string {
+	return BucketName
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// AuditLogs returns an array containing all the audit log entries.
+func (service *Service) AuditLogs() ([]portainer.AuditLog, error) {
+	var logs = make([]portainer.AuditLog, 0)
+
+	err := service.connection.GetAll(
+		BucketName,
+		&portainer.AuditLog{},
+		func(obj interface{}) (interface{}, error) {
+			entry, ok := obj.(*portainer.AuditLog)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to AuditLog object")
+				return nil, fmt.Errorf("Failed to convert to AuditLog object: %s", obj)
+			}
+
+			logs = append(logs, *entry)
+
+			return &portainer.AuditLog{}, nil
+		})
+
+	return logs, err
+}
+
+// AuditLog returns an audit log entry by ID.
+func (service *Service) AuditLog(ID portainer.AuditLogID) (*portainer.AuditLog, error) {
+	var entry portainer.AuditLog
+	identifier := service.connection.ConvertToKey(int(ID))
+
+	err := service.connection.GetObject(BucketName, identifier, &entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Create assigns an ID to a new audit log entry and saves it.
+func (service *Service) Create(entry *portainer.AuditLog) error {
+	return service.connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			entry.ID = portainer.AuditLogID(id)
+			return int(entry.ID), entry
+		},
+	)
+}
+
+// DeleteAuditLogsBefore removes all the audit log entries recorded before the specified unix timestamp.
+func (service *Service) DeleteAuditLogsBefore(timestamp int64) error {
+	return service.connection.DeleteAllObjects(
+		BucketName,
+		&portainer.AuditLog{},
+		func(obj interface{}) (id int, ok bool) {
+			entry, ok := obj.(*portainer.AuditLog)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to AuditLog object")
+				return -1, false
+			}
+
+			return int(entry.ID), entry.Timestamp < timestamp
+		})
+}
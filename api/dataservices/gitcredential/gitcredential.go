@@ -0,0 +1,229 @@
+package gitcredential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "git_credentials"
+	// KeyFileName is the name of the file, inside the data directory, holding the key the passwords are encrypted with.
+	KeyFileName = "git_credentials.key"
+)
+
+var errCiphertextTooShort = errors.New("encrypted git credential password is too short")
+
+// Service represents a service for managing the git credentials saved by the users.
+// The passwords are encrypted at rest with a key generated on the first start and kept in the data directory.
+type Service struct {
+	connection portainer.Connection
+	gcm        cipher.AEAD
+}
+
+func (service *Service) BucketName() string {
+	return BucketName
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := loadOrCreateKey(filepath.Join(connection.GetStorePath(), KeyFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+		gcm:        gcm,
+	}, nil
+}
+
+func loadOrCreateKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	return key, os.WriteFile(path, key, 0600)
+}
+
+// GitCredential returns a git credential by ID, with its secrets decrypted.
+func (service *Service) GitCredential(ID portainer.GitCredentialID) (*portainer.GitCredential, error) {
+	var credential portainer.GitCredential
+	identifier := service.connection.ConvertToKey(int(ID))
+
+	err := service.connection.GetObject(BucketName, identifier, &credential)
+	if err != nil {
+		return nil, err
+	}
+
+	err = service.decrypt(&credential)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credential, nil
+}
+
+// GitCredentialsByUserID returns the git credentials of a user, with their secrets decrypted.
+func (service *Service) GitCredentialsByUserID(userID portainer.UserID) ([]portainer.GitCredential, error) {
+	var result = make([]portainer.GitCredential, 0)
+
+	err := service.connection.GetAll(
+		BucketName,
+		&portainer.GitCredential{},
+		func(obj interface{}) (interface{}, error) {
+			credential, ok := obj.(*portainer.GitCredential)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to GitCredential object")
+				return nil, fmt.Errorf("Failed to convert to GitCredential object: %s", obj)
+			}
+
+			if credential.UserID == userID {
+				err := service.decrypt(credential)
+				if err != nil {
+					return nil, err
+				}
+
+				result = append(result, *credential)
+			}
+
+			return &portainer.GitCredential{}, nil
+		})
+
+	return result, err
+}
+
+// Create creates a new git credential.
+func (service *Service) Create(credential *portainer.GitCredential) error {
+	encrypted, err := service.encrypted(credential)
+	if err != nil {
+		return err
+	}
+
+	err = service.connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			encrypted.ID = portainer.GitCredentialID(id)
+			return int(encrypted.ID), encrypted
+		},
+	)
+
+	credential.ID = encrypted.ID
+
+	return err
+}
+
+// UpdateGitCredential updates a git credential.
+func (service *Service) UpdateGitCredential(ID portainer.GitCredentialID, credential *portainer.GitCredential) error {
+	encrypted, err := service.encrypted(credential)
+	if err != nil {
+		return err
+	}
+
+	identifier := service.connection.ConvertToKey(int(ID))
+	return service.connection.UpdateObject(BucketName, identifier, encrypted)
+}
+
+// DeleteGitCredential deletes a git credential.
+func (service *Service) DeleteGitCredential(ID portainer.GitCredentialID) error {
+	identifier := service.connection.ConvertToKey(int(ID))
+	return service.connection.DeleteObject(BucketName, identifier)
+}
+
+// encrypted returns a copy of the credential with its password and SSH private key encrypted
+func (service *Service) encrypted(credential *portainer.GitCredential) (*portainer.GitCredential, error) {
+	var err error
+
+	encrypted := *credential
+	encrypted.Password, err = service.seal(credential.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if credential.SSHPrivateKey != "" {
+		encrypted.SSHPrivateKey, err = service.seal(credential.SSHPrivateKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &encrypted, nil
+}
+
+// decrypt decrypts the password and SSH private key of the credential in place
+func (service *Service) decrypt(credential *portainer.GitCredential) error {
+	var err error
+
+	credential.Password, err = service.open(credential.Password)
+	if err != nil {
+		return err
+	}
+
+	if credential.SSHPrivateKey != "" {
+		credential.SSHPrivateKey, err = service.open(credential.SSHPrivateKey)
+	}
+
+	return err
+}
+
+func (service *Service) seal(plaintext string) (string, error) {
+	nonce := make([]byte, service.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(service.gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+func (service *Service) open(value string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := service.gcm.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return "", errCiphertextTooShort
+	}
+
+	plaintext, err := service.gcm.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
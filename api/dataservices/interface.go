@@ -24,6 +24,7 @@ type (
 		BackupTo(w io.Writer) error
 		Export(filename string) (err error)
 		IsErrObjectNotFound(err error) bool
+		AuditLog() AuditLogService
 		CustomTemplate() CustomTemplateService
 		EdgeGroup() EdgeGroupService
 		EdgeJob() EdgeJobService
@@ -32,24 +33,39 @@ type (
 		EndpointGroup() EndpointGroupService
 		EndpointRelation() EndpointRelationService
 		FDOProfile() FDOProfileService
+		GitCredential() GitCredentialService
 		HelmUserRepository() HelmUserRepositoryService
+		NotificationChannel() NotificationChannelService
+		NotificationDelivery() NotificationDeliveryService
 		Registry() RegistryService
 		ResourceControl() ResourceControlService
 		Role() RoleService
 		APIKeyRepository() APIKeyRepository
 		Settings() SettingsService
 		Snapshot() SnapshotService
+		SnapshotHistory() SnapshotHistoryService
 		SSLSettings() SSLSettingsService
 		Stack() StackService
+		StackVersion() StackVersionService
 		Tag() TagService
 		TeamMembership() TeamMembershipService
 		Team() TeamService
 		TunnelServer() TunnelServerService
 		User() UserService
+		UserSession() UserSessionService
 		Version() VersionService
 		Webhook() WebhookService
 	}
 
+	// AuditLogService represents a service to manage audit log entries
+	AuditLogService interface {
+		AuditLogs() ([]portainer.AuditLog, error)
+		AuditLog(ID portainer.AuditLogID) (*portainer.AuditLog, error)
+		Create(entry *portainer.AuditLog) error
+		DeleteAuditLogsBefore(timestamp int64) error
+		BucketName() string
+	}
+
 	// CustomTemplateService represents a service to manage custom templates
 	CustomTemplateService interface {
 		GetNextIdentifier() int
@@ -142,6 +158,16 @@ type (
 		BucketName() string
 	}
 
+	// GitCredentialService represents a service for managing the git credentials saved by the users
+	GitCredentialService interface {
+		GitCredential(ID portainer.GitCredentialID) (*portainer.GitCredential, error)
+		GitCredentialsByUserID(userID portainer.UserID) ([]portainer.GitCredential, error)
+		Create(credential *portainer.GitCredential) error
+		UpdateGitCredential(ID portainer.GitCredentialID, credential *portainer.GitCredential) error
+		DeleteGitCredential(ID portainer.GitCredentialID) error
+		BucketName() string
+	}
+
 	// HelmUserRepositoryService represents a service to manage HelmUserRepositories
 	HelmUserRepositoryService interface {
 		HelmUserRepositories() ([]portainer.HelmUserRepository, error)
@@ -161,12 +187,32 @@ type (
 	// JWTService represents a service for managing JWT tokens
 	JWTService interface {
 		GenerateToken(data *portainer.TokenData) (string, error)
+		GenerateSessionToken(data *portainer.TokenData, ipAddress, userAgent string) (string, error)
 		GenerateTokenForOAuth(data *portainer.TokenData, expiryTime *time.Time) (string, error)
 		GenerateTokenForKubeconfig(data *portainer.TokenData) (string, error)
 		ParseAndVerifyToken(token string) (*portainer.TokenData, error)
 		SetUserSessionDuration(userSessionDuration time.Duration)
 	}
 
+	// NotificationChannelService represents a service to manage notification channels
+	NotificationChannelService interface {
+		NotificationChannels() ([]portainer.NotificationChannel, error)
+		NotificationChannel(ID portainer.NotificationChannelID) (*portainer.NotificationChannel, error)
+		Create(channel *portainer.NotificationChannel) error
+		UpdateNotificationChannel(ID portainer.NotificationChannelID, channel *portainer.NotificationChannel) error
+		DeleteNotificationChannel(ID portainer.NotificationChannelID) error
+		BucketName() string
+	}
+
+	// NotificationDeliveryService represents a service to manage the notification delivery log
+	NotificationDeliveryService interface {
+		NotificationDeliveries() ([]portainer.NotificationDelivery, error)
+		Create(delivery *portainer.NotificationDelivery) error
+		DeleteNotificationDeliveriesBefore(timestamp int64) error
+		DeleteNotificationDeliveriesByChannelID(channelID portainer.NotificationChannelID) error
+		BucketName() string
+	}
+
 	// RegistryService represents a service for managing registry data
 	RegistryService interface {
 		Registry(ID portainer.RegistryID) (*portainer.Registry, error)
@@ -194,6 +240,7 @@ type (
 		Roles() ([]portainer.Role, error)
 		Create(role *portainer.Role) error
 		UpdateRole(ID portainer.RoleID, role *portainer.Role) error
+		DeleteRole(ID portainer.RoleID) error
 		BucketName() string
 	}
 
@@ -203,6 +250,7 @@ type (
 		GetAPIKey(keyID portainer.APIKeyID) (*portainer.APIKey, error)
 		UpdateAPIKey(key *portainer.APIKey) error
 		DeleteAPIKey(ID portainer.APIKeyID) error
+		GetAPIKeys() ([]portainer.APIKey, error)
 		GetAPIKeysByUserID(userID portainer.UserID) ([]portainer.APIKey, error)
 		GetAPIKeyByDigest(digest []byte) (*portainer.APIKey, error)
 	}
@@ -224,6 +272,16 @@ type (
 		BucketName() string
 	}
 
+	// SnapshotHistoryService represents a service for managing the hourly rollups of the environment(endpoint) snapshots
+	SnapshotHistoryService interface {
+		Rollup(endpointID portainer.EndpointID, hour int64) (*portainer.SnapshotRollup, error)
+		Rollups(endpointID portainer.EndpointID, from, to int64) ([]portainer.SnapshotRollup, error)
+		UpdateRollup(rollup *portainer.SnapshotRollup) error
+		DeleteRollupsBefore(endpointID portainer.EndpointID, timestamp int64) error
+		DeleteRollups(endpointID portainer.EndpointID) error
+		BucketName() string
+	}
+
 	// SSLSettingsService represents a service for managing application settings
 	SSLSettingsService interface {
 		Settings() (*portainer.SSLSettings, error)
@@ -246,6 +304,17 @@ type (
 		BucketName() string
 	}
 
+	// StackVersionService represents a service for managing the deployed revisions of the stacks
+	StackVersionService interface {
+		StackVersion(stackID portainer.StackID, version int) (*portainer.StackVersion, error)
+		StackVersions(stackID portainer.StackID) ([]portainer.StackVersion, error)
+		LatestStackVersion(stackID portainer.StackID) (*portainer.StackVersion, error)
+		Create(stackVersion *portainer.StackVersion) error
+		DeleteStackVersionsBefore(stackID portainer.StackID, version int) error
+		DeleteStackVersions(stackID portainer.StackID) error
+		BucketName() string
+	}
+
 	// TagService represents a service for managing tag data
 	TagService interface {
 		Tags() ([]portainer.Tag, error)
@@ -302,6 +371,19 @@ type (
 		BucketName() string
 	}
 
+	// UserSessionService represents a service for managing user session data
+	UserSessionService interface {
+		UserSession(ID portainer.UserSessionID) (*portainer.UserSession, error)
+		UserSessions() ([]portainer.UserSession, error)
+		UserSessionsByUserID(userID portainer.UserID) ([]portainer.UserSession, error)
+		Create(session *portainer.UserSession) error
+		UpdateUserSession(ID portainer.UserSessionID, session *portainer.UserSession) error
+		DeleteUserSession(ID portainer.UserSessionID) error
+		DeleteUserSessionsByUserID(userID portainer.UserID) error
+		DeleteUserSessionsExpiredBefore(timestamp int64) error
+		BucketName() string
+	}
+
 	// VersionService represents a service for managing version data
 	VersionService interface {
 		Edition() (portainer.SoftwareEdition, error)
@@ -0,0 +1,100 @@
+package notificationdelivery
+
+import (
+	"fmt"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "notification_deliveries"
+)
+
+// Service represents a service for managing notification delivery data.
+type Service struct {
+	connection portainer.Connection
+}
+
+func (service *Service) BucketName() string {
+	return BucketName
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// NotificationDeliveries returns an array containing all the notification deliveries.
+func (service *Service) NotificationDeliveries() ([]portainer.NotificationDelivery, error) {
+	var deliveries = make([]portainer.NotificationDelivery, 0)
+
+	err := service.connection.GetAll(
+		BucketName,
+		&portainer.NotificationDelivery{},
+		func(obj interface{}) (interface{}, error) {
+			delivery, ok := obj.(*portainer.NotificationDelivery)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to NotificationDelivery object")
+				return nil, fmt.Errorf("Failed to convert to NotificationDelivery object: %s", obj)
+			}
+
+			deliveries = append(deliveries, *delivery)
+
+			return &portainer.NotificationDelivery{}, nil
+		})
+
+	return deliveries, err
+}
+
+// Create assigns an ID to a new notification delivery and saves it.
+func (service *Service) Create(delivery *portainer.NotificationDelivery) error {
+	return service.connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			delivery.ID = portainer.NotificationDeliveryID(id)
+			return int(delivery.ID), delivery
+		},
+	)
+}
+
+// DeleteNotificationDeliveriesBefore removes all the notification deliveries recorded before the specified unix timestamp.
+func (service *Service) DeleteNotificationDeliveriesBefore(timestamp int64) error {
+	return service.connection.DeleteAllObjects(
+		BucketName,
+		&portainer.NotificationDelivery{},
+		func(obj interface{}) (id int, ok bool) {
+			delivery, ok := obj.(*portainer.NotificationDelivery)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to NotificationDelivery object")
+				return -1, false
+			}
+
+			return int(delivery.ID), delivery.Timestamp < timestamp
+		})
+}
+
+// DeleteNotificationDeliveriesByChannelID removes all the notification deliveries of a notification channel.
+func (service *Service) DeleteNotificationDeliveriesByChannelID(channelID portainer.NotificationChannelID) error {
+	return service.connection.DeleteAllObjects(
+		BucketName,
+		&portainer.NotificationDelivery{},
+		func(obj interface{}) (id int, ok bool) {
+			delivery, ok := obj.(*portainer.NotificationDelivery)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to NotificationDelivery object")
+				return -1, false
+			}
+
+			return int(delivery.ID), delivery.ChannelID == channelID
+		})
+}
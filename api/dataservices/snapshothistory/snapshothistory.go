@@ -0,0 +1,123 @@
+package snapshothistory
+
+import (
+	"fmt"
+	"sort"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "snapshot_history"
+)
+
+// Service represents a service for managing the hourly rollups of the environment(endpoint) snapshots.
+// The rollups are keyed by environment identifier then hour, so the history of an environment is stored contiguously.
+type Service struct {
+	connection portainer.Connection
+}
+
+func (service *Service) BucketName() string {
+	return BucketName
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+func (service *Service) endpointKey(endpointID portainer.EndpointID) []byte {
+	return service.connection.ConvertToKey(int(endpointID))
+}
+
+func (service *Service) rollupKey(endpointID portainer.EndpointID, hour int64) []byte {
+	return append(service.endpointKey(endpointID), service.connection.ConvertToKey(int(hour))...)
+}
+
+// Rollup returns the rollup of an environment for the hour starting at the specified unix timestamp.
+func (service *Service) Rollup(endpointID portainer.EndpointID, hour int64) (*portainer.SnapshotRollup, error) {
+	var rollup portainer.SnapshotRollup
+
+	err := service.connection.GetObject(BucketName, service.rollupKey(endpointID, hour), &rollup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rollup, nil
+}
+
+// Rollups returns the rollups of an environment between the specified unix timestamps (inclusive), oldest first.
+// A zero bound is ignored.
+func (service *Service) Rollups(endpointID portainer.EndpointID, from, to int64) ([]portainer.SnapshotRollup, error) {
+	var rollups = make([]portainer.SnapshotRollup, 0)
+
+	err := service.connection.GetAllWithKeyPrefix(
+		BucketName,
+		service.endpointKey(endpointID),
+		&portainer.SnapshotRollup{},
+		func(obj interface{}) (interface{}, error) {
+			rollup, ok := obj.(*portainer.SnapshotRollup)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to SnapshotRollup object")
+				return nil, fmt.Errorf("Failed to convert to SnapshotRollup object: %s", obj)
+			}
+
+			if (from == 0 || rollup.Time >= from) && (to == 0 || rollup.Time <= to) {
+				rollups = append(rollups, *rollup)
+			}
+
+			return &portainer.SnapshotRollup{}, nil
+		})
+
+	sort.Slice(rollups, func(i, j int) bool {
+		return rollups[i].Time < rollups[j].Time
+	})
+
+	return rollups, err
+}
+
+// UpdateRollup saves the rollup, replacing the existing rollup of the same environment and hour.
+func (service *Service) UpdateRollup(rollup *portainer.SnapshotRollup) error {
+	return service.connection.UpdateObject(BucketName, service.rollupKey(rollup.EndpointID, rollup.Time), rollup)
+}
+
+// DeleteRollupsBefore removes the rollups of an environment older than the specified unix timestamp.
+func (service *Service) DeleteRollupsBefore(endpointID portainer.EndpointID, timestamp int64) error {
+	rollups, err := service.Rollups(endpointID, 0, timestamp-1)
+	if err != nil {
+		return err
+	}
+
+	return service.deleteRollups(rollups)
+}
+
+// DeleteRollups removes the whole history of an environment.
+func (service *Service) DeleteRollups(endpointID portainer.EndpointID) error {
+	rollups, err := service.Rollups(endpointID, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	return service.deleteRollups(rollups)
+}
+
+func (service *Service) deleteRollups(rollups []portainer.SnapshotRollup) error {
+	for _, rollup := range rollups {
+		err := service.connection.DeleteObject(BucketName, service.rollupKey(rollup.EndpointID, rollup.Time))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package stackversion
+
+import (
+	"fmt"
+	"sort"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices/errors"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "stack_versions"
+)
+
+// Service represents a service for managing the deployed revisions of the stacks.
+// The revisions are keyed by stack identifier then version, so the history of a stack is stored contiguously.
+type Service struct {
+	connection portainer.Connection
+}
+
+func (service *Service) BucketName() string {
+	return BucketName
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+func (service *Service) stackKey(stackID portainer.StackID) []byte {
+	return service.connection.ConvertToKey(int(stackID))
+}
+
+func (service *Service) versionKey(stackID portainer.StackID, version int) []byte {
+	return append(service.stackKey(stackID), service.connection.ConvertToKey(version)...)
+}
+
+// StackVersion returns a revision of a stack.
+func (service *Service) StackVersion(stackID portainer.StackID, version int) (*portainer.StackVersion, error) {
+	var stackVersion portainer.StackVersion
+
+	err := service.connection.GetObject(BucketName, service.versionKey(stackID, version), &stackVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stackVersion, nil
+}
+
+// StackVersions returns the revisions of a stack, oldest first.
+func (service *Service) StackVersions(stackID portainer.StackID) ([]portainer.StackVersion, error) {
+	var versions = make([]portainer.StackVersion, 0)
+
+	err := service.connection.GetAllWithKeyPrefix(
+		BucketName,
+		service.stackKey(stackID),
+		&portainer.StackVersion{},
+		func(obj interface{}) (interface{}, error) {
+			version, ok := obj.(*portainer.StackVersion)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to StackVersion object")
+				return nil, fmt.Errorf("Failed to convert to StackVersion object: %s", obj)
+			}
+
+			versions = append(versions, *version)
+
+			return &portainer.StackVersion{}, nil
+		})
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Version < versions[j].Version
+	})
+
+	return versions, err
+}
+
+// LatestStackVersion returns the latest revision of a stack.
+func (service *Service) LatestStackVersion(stackID portainer.StackID) (*portainer.StackVersion, error) {
+	var stackVersion portainer.StackVersion
+
+	err := service.connection.GetLastWithKeyPrefix(BucketName, service.stackKey(stackID), &stackVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stackVersion, nil
+}
+
+// Create saves a new revision of a stack, numbered after the latest revision of the stack.
+func (service *Service) Create(stackVersion *portainer.StackVersion) error {
+	stackVersion.Version = 1
+
+	latest, err := service.LatestStackVersion(stackVersion.StackID)
+	if err == nil {
+		stackVersion.Version = latest.Version + 1
+	} else if err != errors.ErrObjectNotFound {
+		return err
+	}
+
+	return service.connection.CreateObjectWithStringId(BucketName, service.versionKey(stackVersion.StackID, stackVersion.Version), stackVersion)
+}
+
+// DeleteStackVersionsBefore removes the revisions of a stack older than the specified version.
+func (service *Service) DeleteStackVersionsBefore(stackID portainer.StackID, version int) error {
+	if version <= 1 {
+		return nil
+	}
+
+	return service.connection.DeleteKeyRange(BucketName, service.versionKey(stackID, 0), service.versionKey(stackID, version))
+}
+
+// DeleteStackVersions removes the whole history of a stack.
+func (service *Service) DeleteStackVersions(stackID portainer.StackID) error {
+	return service.connection.DeleteKeyRange(BucketName, service.stackKey(stackID), service.stackKey(stackID+1))
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_StackVersionKeys(t *testing.T) {
+	is := assert.New(t)
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	service := store.StackVersion()
+
+	_, err := service.LatestStackVersion(1)
+	is.True(dataservices.IsErrObjectNotFound(err))
+
+	for i := 0; i < 5; i++ {
+		is.NoError(service.Create(&portainer.StackVersion{StackID: 1}))
+		is.NoError(service.Create(&portainer.StackVersion{StackID: 2}))
+	}
+
+	latest, err := service.LatestStackVersion(1)
+	is.NoError(err)
+	is.Equal(5, latest.Version)
+
+	is.NoError(service.DeleteStackVersionsBefore(1, 4))
+	versions, err := service.StackVersions(1)
+	is.NoError(err)
+	if is.Len(versions, 2) {
+		is.Equal(4, versions[0].Version)
+	}
+
+	is.NoError(service.DeleteStackVersions(1))
+	versions, err = service.StackVersions(1)
+	is.NoError(err)
+	is.Empty(versions)
+
+	latest, err = service.LatestStackVersion(2)
+	is.NoError(err)
+	is.Equal(5, latest.Version, "the history of the other stacks should be kept")
+}
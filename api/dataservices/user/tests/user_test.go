@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices/user"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_twoFactorSecretsAreEncrypted(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	u := &portainer.User{
+		Username: "bob",
+		TwoFactor: portainer.UserTwoFactor{
+			Enabled:       true,
+			Secret:        "JBSWY3DPEHPK3PXP",
+			PendingSecret: "KRUGKIDROVUWG2ZA",
+		},
+	}
+	is.NoError(store.User().Create(u))
+	is.NotZero(u.ID)
+	is.Equal("JBSWY3DPEHPK3PXP", u.TwoFactor.Secret, "the created user should not be modified")
+
+	var stored portainer.User
+	is.NoError(store.GetConnection().GetObject(user.BucketName, store.GetConnection().ConvertToKey(int(u.ID)), &stored))
+	is.NotEmpty(stored.TwoFactor.Secret)
+	is.NotEqual("JBSWY3DPEHPK3PXP", stored.TwoFactor.Secret)
+	is.NotEqual("KRUGKIDROVUWG2ZA", stored.TwoFactor.PendingSecret)
+
+	read, err := store.User().User(u.ID)
+	is.NoError(err)
+	is.Equal("JBSWY3DPEHPK3PXP", read.TwoFactor.Secret)
+	is.Equal("KRUGKIDROVUWG2ZA", read.TwoFactor.PendingSecret)
+
+	read, err = store.User().UserByUsername("bob")
+	is.NoError(err)
+	is.Equal("JBSWY3DPEHPK3PXP", read.TwoFactor.Secret)
+
+	read.TwoFactor.PendingSecret = ""
+	is.NoError(store.User().UpdateUser(read.ID, read))
+
+	users, err := store.User().Users()
+	is.NoError(err)
+	is.Len(users, 1)
+	is.Equal("JBSWY3DPEHPK3PXP", users[0].TwoFactor.Secret)
+	is.Empty(users[0].TwoFactor.PendingSecret)
+}
@@ -1,7 +1,15 @@
 package user
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	stderrors "errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
@@ -13,11 +21,17 @@ import (
 const (
 	// BucketName represents the name of the bucket where this service stores data.
 	BucketName = "users"
+	// KeyFileName is the name of the file, inside the data directory, holding the key the TOTP secrets are encrypted with.
+	KeyFileName = "two_factor.key"
 )
 
+var errCiphertextTooShort = stderrors.New("encrypted TOTP secret is too short")
+
 // Service represents a service for managing environment(endpoint) data.
+// The TOTP secrets of the users are encrypted at rest with a key generated on the first start and kept in the data directory.
 type Service struct {
 	connection portainer.Connection
+	gcm        cipher.AEAD
 }
 
 func (service *Service) BucketName() string {
@@ -31,12 +45,44 @@ func NewService(connection portainer.Connection) (*Service, error) {
 		return nil, err
 	}
 
+	key, err := loadOrCreateKey(filepath.Join(connection.GetStorePath(), KeyFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Service{
 		connection: connection,
+		gcm:        gcm,
 	}, nil
 }
 
-// User returns a user by ID
+func loadOrCreateKey(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err == nil {
+		return key, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	return key, os.WriteFile(path, key, 0600)
+}
+
+// User returns a user by ID, with their TOTP secrets decrypted
 func (service *Service) User(ID portainer.UserID) (*portainer.User, error) {
 	var user portainer.User
 	identifier := service.connection.ConvertToKey(int(ID))
@@ -46,10 +92,15 @@ func (service *Service) User(ID portainer.UserID) (*portainer.User, error) {
 		return nil, err
 	}
 
+	err = service.decrypt(&user)
+	if err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
-// UserByUsername returns a user by username.
+// UserByUsername returns a user by username, with their TOTP secrets decrypted.
 func (service *Service) UserByUsername(username string) (*portainer.User, error) {
 	var u *portainer.User
 	stop := fmt.Errorf("ok")
@@ -65,6 +116,11 @@ func (service *Service) UserByUsername(username string) (*portainer.User, error)
 			}
 
 			if strings.EqualFold(user.Username, username) {
+				err := service.decrypt(user)
+				if err != nil {
+					return nil, err
+				}
+
 				u = user
 				return nil, stop
 			}
@@ -83,7 +139,7 @@ func (service *Service) UserByUsername(username string) (*portainer.User, error)
 	return nil, err
 }
 
-// Users return an array containing all the users.
+// Users return an array containing all the users, with their TOTP secrets decrypted.
 func (service *Service) Users() ([]portainer.User, error) {
 	var users = make([]portainer.User, 0)
 
@@ -98,6 +154,11 @@ func (service *Service) Users() ([]portainer.User, error) {
 				return nil, fmt.Errorf("Failed to convert to User object: %s", obj)
 			}
 
+			err := service.decrypt(user)
+			if err != nil {
+				return nil, err
+			}
+
 			users = append(users, *user)
 
 			return &portainer.User{}, nil
@@ -106,7 +167,7 @@ func (service *Service) Users() ([]portainer.User, error) {
 	return users, err
 }
 
-// UsersByRole return an array containing all the users with the specified role.
+// UsersByRole return an array containing all the users with the specified role, with their TOTP secrets decrypted.
 func (service *Service) UsersByRole(role portainer.UserRole) ([]portainer.User, error) {
 	var users = make([]portainer.User, 0)
 
@@ -122,6 +183,11 @@ func (service *Service) UsersByRole(role portainer.UserRole) ([]portainer.User,
 			}
 
 			if user.Role == role {
+				err := service.decrypt(user)
+				if err != nil {
+					return nil, err
+				}
+
 				users = append(users, *user)
 			}
 
@@ -135,20 +201,36 @@ func (service *Service) UsersByRole(role portainer.UserRole) ([]portainer.User,
 func (service *Service) UpdateUser(ID portainer.UserID, user *portainer.User) error {
 	identifier := service.connection.ConvertToKey(int(ID))
 	user.Username = strings.ToLower(user.Username)
-	return service.connection.UpdateObject(BucketName, identifier, user)
+
+	encrypted, err := service.encrypted(user)
+	if err != nil {
+		return err
+	}
+
+	return service.connection.UpdateObject(BucketName, identifier, encrypted)
 }
 
 // CreateUser creates a new user.
 func (service *Service) Create(user *portainer.User) error {
-	return service.connection.CreateObject(
+	user.Username = strings.ToLower(user.Username)
+
+	encrypted, err := service.encrypted(user)
+	if err != nil {
+		return err
+	}
+
+	err = service.connection.CreateObject(
 		BucketName,
 		func(id uint64) (int, interface{}) {
-			user.ID = portainer.UserID(id)
-			user.Username = strings.ToLower(user.Username)
+			encrypted.ID = portainer.UserID(id)
 
-			return int(user.ID), user
+			return int(encrypted.ID), encrypted
 		},
 	)
+
+	user.ID = encrypted.ID
+
+	return err
 }
 
 // DeleteUser deletes a user.
@@ -156,3 +238,71 @@ func (service *Service) DeleteUser(ID portainer.UserID) error {
 	identifier := service.connection.ConvertToKey(int(ID))
 	return service.connection.DeleteObject(BucketName, identifier)
 }
+
+// encrypted returns a copy of the user with their TOTP secrets encrypted
+func (service *Service) encrypted(user *portainer.User) (*portainer.User, error) {
+	var err error
+
+	encrypted := *user
+	if user.TwoFactor.Secret != "" {
+		encrypted.TwoFactor.Secret, err = service.seal(user.TwoFactor.Secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if user.TwoFactor.PendingSecret != "" {
+		encrypted.TwoFactor.PendingSecret, err = service.seal(user.TwoFactor.PendingSecret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &encrypted, nil
+}
+
+// decrypt decrypts the TOTP secrets of the user in place
+func (service *Service) decrypt(user *portainer.User) error {
+	var err error
+
+	if user.TwoFactor.Secret != "" {
+		user.TwoFactor.Secret, err = service.open(user.TwoFactor.Secret)
+		if err != nil {
+			return err
+		}
+	}
+
+	if user.TwoFactor.PendingSecret != "" {
+		user.TwoFactor.PendingSecret, err = service.open(user.TwoFactor.PendingSecret)
+	}
+
+	return err
+}
+
+func (service *Service) seal(plaintext string) (string, error) {
+	nonce := make([]byte, service.gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(service.gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+func (service *Service) open(value string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := service.gcm.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return "", errCiphertextTooShort
+	}
+
+	plaintext, err := service.gcm.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,137 @@
+package usersession
+
+import (
+	"fmt"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// BucketName represents the name of the bucket where this service stores data.
+	BucketName = "user_sessions"
+)
+
+// Service represents a service for managing user session data.
+type Service struct {
+	connection portainer.Connection
+}
+
+func (service *Service) BucketName() string {
+	return BucketName
+}
+
+// NewService creates a new instance of a service.
+func NewService(connection portainer.Connection) (*Service, error) {
+	err := connection.SetServiceName(BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		connection: connection,
+	}, nil
+}
+
+// UserSession returns a user session by ID.
+func (service *Service) UserSession(ID portainer.UserSessionID) (*portainer.UserSession, error) {
+	var session portainer.UserSession
+	identifier := service.connection.ConvertToKey(int(ID))
+
+	err := service.connection.GetObject(BucketName, identifier, &session)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// UserSessions returns an array containing all the user sessions.
+func (service *Service) UserSessions() ([]portainer.UserSession, error) {
+	return service.userSessionsMatching(func(session *portainer.UserSession) bool {
+		return true
+	})
+}
+
+// UserSessionsByUserID returns an array containing all the sessions of a user.
+func (service *Service) UserSessionsByUserID(userID portainer.UserID) ([]portainer.UserSession, error) {
+	return service.userSessionsMatching(func(session *portainer.UserSession) bool {
+		return session.UserID == userID
+	})
+}
+
+func (service *Service) userSessionsMatching(match func(session *portainer.UserSession) bool) ([]portainer.UserSession, error) {
+	var sessions = make([]portainer.UserSession, 0)
+
+	err := service.connection.GetAll(
+		BucketName,
+		&portainer.UserSession{},
+		func(obj interface{}) (interface{}, error) {
+			session, ok := obj.(*portainer.UserSession)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to UserSession object")
+				return nil, fmt.Errorf("Failed to convert to UserSession object: %s", obj)
+			}
+
+			if match(session) {
+				sessions = append(sessions, *session)
+			}
+
+			return &portainer.UserSession{}, nil
+		})
+
+	return sessions, err
+}
+
+// Create assigns an ID to a new user session and saves it.
+func (service *Service) Create(session *portainer.UserSession) error {
+	return service.connection.CreateObject(
+		BucketName,
+		func(id uint64) (int, interface{}) {
+			session.ID = portainer.UserSessionID(id)
+			return int(session.ID), session
+		},
+	)
+}
+
+// UpdateUserSession saves a user session.
+func (service *Service) UpdateUserSession(ID portainer.UserSessionID, session *portainer.UserSession) error {
+	identifier := service.connection.ConvertToKey(int(ID))
+	return service.connection.UpdateObject(BucketName, identifier, session)
+}
+
+// DeleteUserSession deletes a user session, which revokes it.
+func (service *Service) DeleteUserSession(ID portainer.UserSessionID) error {
+	identifier := service.connection.ConvertToKey(int(ID))
+	return service.connection.DeleteObject(BucketName, identifier)
+}
+
+// DeleteUserSessionsByUserID deletes all the sessions of a user.
+func (service *Service) DeleteUserSessionsByUserID(userID portainer.UserID) error {
+	return service.deleteUserSessionsMatching(func(session *portainer.UserSession) bool {
+		return session.UserID == userID
+	})
+}
+
+// DeleteUserSessionsExpiredBefore deletes all the user sessions which expired before the specified unix timestamp.
+func (service *Service) DeleteUserSessionsExpiredBefore(timestamp int64) error {
+	return service.deleteUserSessionsMatching(func(session *portainer.UserSession) bool {
+		return session.ExpiresAt < timestamp
+	})
+}
+
+func (service *Service) deleteUserSessionsMatching(match func(session *portainer.UserSession) bool) error {
+	return service.connection.DeleteAllObjects(
+		BucketName,
+		&portainer.UserSession{},
+		func(obj interface{}) (id int, ok bool) {
+			session, ok := obj.(*portainer.UserSession)
+			if !ok {
+				log.Debug().Str("obj", fmt.Sprintf("%#v", obj)).Msg("failed to convert to UserSession object")
+				return -1, false
+			}
+
+			return int(session.ID), match(session)
+		})
+}
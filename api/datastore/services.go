@@ -9,6 +9,7 @@ import (
 	"github.com/cloudogu/portainer-ce/api/database/models"
 	"github.com/cloudogu/portainer-ce/api/dataservices"
 	"github.com/cloudogu/portainer-ce/api/dataservices/apikeyrepository"
+	"github.com/cloudogu/portainer-ce/api/dataservices/auditlog"
 	"github.com/cloudogu/portainer-ce/api/dataservices/customtemplate"
 	"github.com/cloudogu/portainer-ce/api/dataservices/dockerhub"
 	"github.com/cloudogu/portainer-ce/api/dataservices/edgegroup"
@@ -19,20 +20,26 @@ import (
 	"github.com/cloudogu/portainer-ce/api/dataservices/endpointrelation"
 	"github.com/cloudogu/portainer-ce/api/dataservices/extension"
 	"github.com/cloudogu/portainer-ce/api/dataservices/fdoprofile"
+	"github.com/cloudogu/portainer-ce/api/dataservices/gitcredential"
 	"github.com/cloudogu/portainer-ce/api/dataservices/helmuserrepository"
+	"github.com/cloudogu/portainer-ce/api/dataservices/notificationchannel"
+	"github.com/cloudogu/portainer-ce/api/dataservices/notificationdelivery"
 	"github.com/cloudogu/portainer-ce/api/dataservices/registry"
 	"github.com/cloudogu/portainer-ce/api/dataservices/resourcecontrol"
 	"github.com/cloudogu/portainer-ce/api/dataservices/role"
 	"github.com/cloudogu/portainer-ce/api/dataservices/schedule"
 	"github.com/cloudogu/portainer-ce/api/dataservices/settings"
 	"github.com/cloudogu/portainer-ce/api/dataservices/snapshot"
+	"github.com/cloudogu/portainer-ce/api/dataservices/snapshothistory"
 	"github.com/cloudogu/portainer-ce/api/dataservices/ssl"
 	"github.com/cloudogu/portainer-ce/api/dataservices/stack"
+	"github.com/cloudogu/portainer-ce/api/dataservices/stackversion"
 	"github.com/cloudogu/portainer-ce/api/dataservices/tag"
 	"github.com/cloudogu/portainer-ce/api/dataservices/team"
 	"github.com/cloudogu/portainer-ce/api/dataservices/teammembership"
 	"github.com/cloudogu/portainer-ce/api/dataservices/tunnelserver"
 	"github.com/cloudogu/portainer-ce/api/dataservices/user"
+	"github.com/cloudogu/portainer-ce/api/dataservices/usersession"
 	"github.com/cloudogu/portainer-ce/api/dataservices/version"
 	"github.com/cloudogu/portainer-ce/api/dataservices/webhook"
 
@@ -44,34 +51,41 @@ import (
 type Store struct {
 	connection portainer.Connection
 
-	fileService               portainer.FileService
-	CustomTemplateService     *customtemplate.Service
-	DockerHubService          *dockerhub.Service
-	EdgeGroupService          *edgegroup.Service
-	EdgeJobService            *edgejob.Service
-	EdgeStackService          *edgestack.Service
-	EndpointGroupService      *endpointgroup.Service
-	EndpointService           *endpoint.Service
-	EndpointRelationService   *endpointrelation.Service
-	ExtensionService          *extension.Service
-	FDOProfilesService        *fdoprofile.Service
-	HelmUserRepositoryService *helmuserrepository.Service
-	RegistryService           *registry.Service
-	ResourceControlService    *resourcecontrol.Service
-	RoleService               *role.Service
-	APIKeyRepositoryService   *apikeyrepository.Service
-	ScheduleService           *schedule.Service
-	SettingsService           *settings.Service
-	SnapshotService           *snapshot.Service
-	SSLSettingsService        *ssl.Service
-	StackService              *stack.Service
-	TagService                *tag.Service
-	TeamMembershipService     *teammembership.Service
-	TeamService               *team.Service
-	TunnelServerService       *tunnelserver.Service
-	UserService               *user.Service
-	VersionService            *version.Service
-	WebhookService            *webhook.Service
+	fileService                 portainer.FileService
+	AuditLogService             *auditlog.Service
+	CustomTemplateService       *customtemplate.Service
+	DockerHubService            *dockerhub.Service
+	EdgeGroupService            *edgegroup.Service
+	EdgeJobService              *edgejob.Service
+	EdgeStackService            *edgestack.Service
+	EndpointGroupService        *endpointgroup.Service
+	EndpointService             *endpoint.Service
+	EndpointRelationService     *endpointrelation.Service
+	ExtensionService            *extension.Service
+	FDOProfilesService          *fdoprofile.Service
+	GitCredentialService        *gitcredential.Service
+	HelmUserRepositoryService   *helmuserrepository.Service
+	NotificationChannelService  *notificationchannel.Service
+	NotificationDeliveryService *notificationdelivery.Service
+	RegistryService             *registry.Service
+	ResourceControlService      *resourcecontrol.Service
+	RoleService                 *role.Service
+	APIKeyRepositoryService     *apikeyrepository.Service
+	ScheduleService             *schedule.Service
+	SettingsService             *settings.Service
+	SnapshotService             *snapshot.Service
+	SnapshotHistoryService      *snapshothistory.Service
+	SSLSettingsService          *ssl.Service
+	StackService                *stack.Service
+	StackVersionService         *stackversion.Service
+	TagService                  *tag.Service
+	TeamMembershipService       *teammembership.Service
+	TeamService                 *team.Service
+	TunnelServerService         *tunnelserver.Service
+	UserService                 *user.Service
+	UserSessionService          *usersession.Service
+	VersionService              *version.Service
+	WebhookService              *webhook.Service
 }
 
 func (store *Store) initServices() error {
@@ -81,6 +95,12 @@ func (store *Store) initServices() error {
 	}
 	store.RoleService = authorizationsetService
 
+	auditLogService, err := auditlog.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.AuditLogService = auditLogService
+
 	customTemplateService, err := customtemplate.NewService(store.connection)
 	if err != nil {
 		return err
@@ -142,12 +162,30 @@ func (store *Store) initServices() error {
 	}
 	store.FDOProfilesService = fdoProfilesService
 
+	gitCredentialService, err := gitcredential.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.GitCredentialService = gitCredentialService
+
 	helmUserRepositoryService, err := helmuserrepository.NewService(store.connection)
 	if err != nil {
 		return err
 	}
 	store.HelmUserRepositoryService = helmUserRepositoryService
 
+	notificationChannelService, err := notificationchannel.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.NotificationChannelService = notificationChannelService
+
+	notificationDeliveryService, err := notificationdelivery.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.NotificationDeliveryService = notificationDeliveryService
+
 	registryService, err := registry.NewService(store.connection)
 	if err != nil {
 		return err
@@ -172,6 +210,12 @@ func (store *Store) initServices() error {
 	}
 	store.SnapshotService = snapshotService
 
+	snapshotHistoryService, err := snapshothistory.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.SnapshotHistoryService = snapshotHistoryService
+
 	sslSettingsService, err := ssl.NewService(store.connection)
 	if err != nil {
 		return err
@@ -184,6 +228,12 @@ func (store *Store) initServices() error {
 	}
 	store.StackService = stackService
 
+	stackVersionService, err := stackversion.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.StackVersionService = stackVersionService
+
 	tagService, err := tag.NewService(store.connection)
 	if err != nil {
 		return err
@@ -214,6 +264,12 @@ func (store *Store) initServices() error {
 	}
 	store.UserService = userService
 
+	userSessionService, err := usersession.NewService(store.connection)
+	if err != nil {
+		return err
+	}
+	store.UserSessionService = userSessionService
+
 	apiKeyService, err := apikeyrepository.NewService(store.connection)
 	if err != nil {
 		return err
@@ -241,6 +297,11 @@ func (store *Store) initServices() error {
 	return nil
 }
 
+// AuditLog gives access to the AuditLog data management layer
+func (store *Store) AuditLog() dataservices.AuditLogService {
+	return store.AuditLogService
+}
+
 // CustomTemplate gives access to the CustomTemplate data management layer
 func (store *Store) CustomTemplate() dataservices.CustomTemplateService {
 	return store.CustomTemplateService
@@ -281,11 +342,26 @@ func (store *Store) FDOProfile() dataservices.FDOProfileService {
 	return store.FDOProfilesService
 }
 
+// GitCredential gives access to the GitCredential data management layer
+func (store *Store) GitCredential() dataservices.GitCredentialService {
+	return store.GitCredentialService
+}
+
 // HelmUserRepository access the helm user repository settings
 func (store *Store) HelmUserRepository() dataservices.HelmUserRepositoryService {
 	return store.HelmUserRepositoryService
 }
 
+// NotificationChannel gives access to the NotificationChannel data management layer
+func (store *Store) NotificationChannel() dataservices.NotificationChannelService {
+	return store.NotificationChannelService
+}
+
+// NotificationDelivery gives access to the NotificationDelivery data management layer
+func (store *Store) NotificationDelivery() dataservices.NotificationDeliveryService {
+	return store.NotificationDeliveryService
+}
+
 // Registry gives access to the Registry data management layer
 func (store *Store) Registry() dataservices.RegistryService {
 	return store.RegistryService
@@ -315,6 +391,11 @@ func (store *Store) Snapshot() dataservices.SnapshotService {
 	return store.SnapshotService
 }
 
+// SnapshotHistory gives access to the snapshot history data management layer
+func (store *Store) SnapshotHistory() dataservices.SnapshotHistoryService {
+	return store.SnapshotHistoryService
+}
+
 // SSLSettings gives access to the SSL Settings data management layer
 func (store *Store) SSLSettings() dataservices.SSLSettingsService {
 	return store.SSLSettingsService
@@ -325,6 +406,11 @@ func (store *Store) Stack() dataservices.StackService {
 	return store.StackService
 }
 
+// StackVersion gives access to the stack version history data management layer
+func (store *Store) StackVersion() dataservices.StackVersionService {
+	return store.StackVersionService
+}
+
 // Tag gives access to the Tag data management layer
 func (store *Store) Tag() dataservices.TagService {
 	return store.TagService
@@ -350,6 +436,11 @@ func (store *Store) User() dataservices.UserService {
 	return store.UserService
 }
 
+// UserSession gives access to the UserSession data management layer
+func (store *Store) UserSession() dataservices.UserSessionService {
+	return store.UserSessionService
+}
+
 // Version gives access to the Version data management layer
 func (store *Store) Version() dataservices.VersionService {
 	return store.VersionService
@@ -1,11 +1,14 @@
 package exec
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path"
+	"runtime"
 	"strings"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
@@ -21,14 +24,16 @@ import (
 type ComposeStackManager struct {
 	deployer     libstack.Deployer
 	proxyManager *proxy.Manager
+	binaryPath   string
 }
 
 // NewComposeStackManager returns a docker-compose wrapper if corresponding binary present, otherwise nil
-func NewComposeStackManager(deployer libstack.Deployer, proxyManager *proxy.Manager) (*ComposeStackManager, error) {
+func NewComposeStackManager(deployer libstack.Deployer, proxyManager *proxy.Manager, binaryPath string) (*ComposeStackManager, error) {
 
 	return &ComposeStackManager{
 		deployer:     deployer,
 		proxyManager: proxyManager,
+		binaryPath:   binaryPath,
 	}, nil
 }
 
@@ -118,6 +123,43 @@ func (manager *ComposeStackManager) Pull(ctx context.Context, stack *portainer.S
 	return errors.Wrap(err, "failed to pull images of the stack")
 }
 
+// Config renders the compose files of a stack into a single configuration, with the variables interpolated.
+// Wraps `docker-compose config` command
+func (manager *ComposeStackManager) Config(ctx context.Context, stack *portainer.Stack) ([]byte, error) {
+	envFilePath, err := createEnvFile(stack)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create env file")
+	}
+
+	var args []string
+	for _, filePath := range stackutils.GetStackFilePaths(stack, false) {
+		args = append(args, "-f", filePath)
+	}
+
+	if envFilePath != "" {
+		args = append(args, "--env-file", envFilePath)
+	}
+
+	args = append(args, "-p", stack.Name, "config")
+
+	command := path.Join(manager.binaryPath, "docker-compose")
+	if runtime.GOOS == "windows" {
+		command = path.Join(manager.binaryPath, "docker-compose.exe")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = stack.ProjectPath
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to render the stack configuration: %q", stderr.String())
+	}
+
+	return output, nil
+}
+
 // NormalizeStackName returns a new stack name with unsupported characters replaced
 func (manager *ComposeStackManager) NormalizeStackName(name string) string {
 	return stackNameNormalizeRegex.ReplaceAllString(strings.ToLower(name), "")
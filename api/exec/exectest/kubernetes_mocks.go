@@ -22,3 +22,23 @@ func (deployer *kubernetesMockDeployer) Remove(userID portainer.UserID, endpoint
 func (deployer *kubernetesMockDeployer) ConvertCompose(data []byte) ([]byte, error) {
 	return nil, nil
 }
+
+func (deployer *kubernetesMockDeployer) Kustomize(path string) ([]byte, error) {
+	return nil, nil
+}
+
+func (deployer *kubernetesMockDeployer) DeployHelmChart(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, chartPath string, valuesFiles []string, namespace string, labels map[string]string) (string, error) {
+	return "", nil
+}
+
+func (deployer *kubernetesMockDeployer) RemoveHelmRelease(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, namespace string) (string, error) {
+	return "", nil
+}
+
+func (deployer *kubernetesMockDeployer) Diff(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
+	return "", nil
+}
+
+func (deployer *kubernetesMockDeployer) RenderHelmChart(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, chartPath string, valuesFiles []string, namespace string, labels map[string]string) (string, error) {
+	return "", nil
+}
@@ -2,6 +2,7 @@ package exec
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -14,9 +15,12 @@ import (
 	"github.com/cloudogu/portainer-ce/api/http/proxy"
 	"github.com/cloudogu/portainer-ce/api/http/proxy/factory"
 	"github.com/cloudogu/portainer-ce/api/http/proxy/factory/kubernetes"
+	k "github.com/cloudogu/portainer-ce/api/kubernetes"
 	"github.com/cloudogu/portainer-ce/api/kubernetes/cli"
 
 	"github.com/pkg/errors"
+	"github.com/portainer/portainer/pkg/libhelm"
+	"github.com/portainer/portainer/pkg/libhelm/options"
 )
 
 // KubernetesDeployer represents a service to deploy resources inside a Kubernetes environment(endpoint).
@@ -28,10 +32,11 @@ type KubernetesDeployer struct {
 	kubernetesClientFactory     *cli.ClientFactory
 	kubernetesTokenCacheManager *kubernetes.TokenCacheManager
 	proxyManager                *proxy.Manager
+	helmPackageManager          libhelm.HelmPackageManager
 }
 
 // NewKubernetesDeployer initializes a new KubernetesDeployer service.
-func NewKubernetesDeployer(kubernetesTokenCacheManager *kubernetes.TokenCacheManager, kubernetesClientFactory *cli.ClientFactory, datastore dataservices.DataStore, reverseTunnelService portainer.ReverseTunnelService, signatureService portainer.DigitalSignatureService, proxyManager *proxy.Manager, binaryPath string) *KubernetesDeployer {
+func NewKubernetesDeployer(kubernetesTokenCacheManager *kubernetes.TokenCacheManager, kubernetesClientFactory *cli.ClientFactory, datastore dataservices.DataStore, reverseTunnelService portainer.ReverseTunnelService, signatureService portainer.DigitalSignatureService, proxyManager *proxy.Manager, helmPackageManager libhelm.HelmPackageManager, binaryPath string) *KubernetesDeployer {
 	return &KubernetesDeployer{
 		binaryPath:                  binaryPath,
 		dataStore:                   datastore,
@@ -40,6 +45,7 @@ func NewKubernetesDeployer(kubernetesTokenCacheManager *kubernetes.TokenCacheMan
 		kubernetesClientFactory:     kubernetesClientFactory,
 		kubernetesTokenCacheManager: kubernetesTokenCacheManager,
 		proxyManager:                proxyManager,
+		helmPackageManager:          helmPackageManager,
 	}
 }
 
@@ -87,6 +93,11 @@ func (deployer *KubernetesDeployer) Remove(userID portainer.UserID, endpoint *po
 	return deployer.command("delete", userID, endpoint, manifestFiles, namespace)
 }
 
+// Diff returns the differences between the live resources and the manifest(s), computed by a server-side dry-run apply
+func (deployer *KubernetesDeployer) Diff(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
+	return deployer.command("diff", userID, endpoint, manifestFiles, namespace)
+}
+
 func (deployer *KubernetesDeployer) command(operation string, userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
 	token, err := deployer.getToken(userID, endpoint, endpoint.Type == portainer.KubernetesLocalEnvironment)
 	if err != nil {
@@ -119,6 +130,10 @@ func (deployer *KubernetesDeployer) command(operation string, userID portainer.U
 	}
 
 	args = append(args, operation)
+	if operation == "diff" {
+		args = append(args, "--server-side")
+	}
+
 	for _, path := range manifestFiles {
 		args = append(args, "-f", strings.TrimSpace(path))
 	}
@@ -131,6 +146,12 @@ func (deployer *KubernetesDeployer) command(operation string, userID portainer.U
 
 	output, err := cmd.Output()
 	if err != nil {
+		// kubectl diff exits with 1 when differences are found
+		var exitErr *exec.ExitError
+		if operation == "diff" && errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return string(output), nil
+		}
+
 		return "", errors.Wrapf(err, "failed to execute kubectl command: %q", stderr.String())
 	}
 
@@ -160,6 +181,137 @@ func (deployer *KubernetesDeployer) ConvertCompose(data []byte) ([]byte, error)
 	return output, nil
 }
 
+// Kustomize renders the kustomization located in the specified directory into a multi-document manifest.
+func (deployer *KubernetesDeployer) Kustomize(dir string) ([]byte, error) {
+	command := path.Join(deployer.binaryPath, "kubectl")
+	if runtime.GOOS == "windows" {
+		command = path.Join(deployer.binaryPath, "kubectl.exe")
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(command, "kustomize", dir)
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to render the kustomization: %q", stderr.String())
+	}
+
+	return output, nil
+}
+
+// DeployHelmChart installs or upgrades the release of a chart located on the disk and returns the manifest of the release.
+// The labels are added to the resources of the release by a post-renderer.
+func (deployer *KubernetesDeployer) DeployHelmChart(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, chartPath string, valuesFiles []string, namespace string, labels map[string]string) (string, error) {
+	return deployer.upgradeHelmRelease(userID, endpoint, releaseName, chartPath, valuesFiles, namespace, labels, false)
+}
+
+// RenderHelmChart returns the manifest an install or upgrade of the release of a chart located on the disk would apply,
+// without changing the release.
+func (deployer *KubernetesDeployer) RenderHelmChart(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, chartPath string, valuesFiles []string, namespace string, labels map[string]string) (string, error) {
+	return deployer.upgradeHelmRelease(userID, endpoint, releaseName, chartPath, valuesFiles, namespace, labels, true)
+}
+
+func (deployer *KubernetesDeployer) upgradeHelmRelease(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, chartPath string, valuesFiles []string, namespace string, labels map[string]string, dryRun bool) (string, error) {
+	clusterAccess, closeProxy, err := deployer.helmClusterAccess(userID, endpoint)
+	if err != nil {
+		return "", err
+	}
+	defer closeProxy()
+
+	upgradeOpts := options.UpgradeOptions{
+		Name:                    releaseName,
+		Chart:                   chartPath,
+		Namespace:               helmNamespace(namespace),
+		ValuesFiles:             valuesFiles,
+		Install:                 true,
+		DryRun:                  dryRun,
+		KubernetesClusterAccess: clusterAccess,
+	}
+
+	if len(labels) > 0 {
+		postRenderer, postRendererEnv, err := helmPostRendererLabels(labels)
+		if err != nil {
+			return "", err
+		}
+
+		upgradeOpts.PostRenderer = postRenderer
+		upgradeOpts.PostRendererEnv = postRendererEnv
+	}
+
+	helmRelease, err := deployer.helmPackageManager.Upgrade(upgradeOpts)
+	if err != nil {
+		return "", err
+	}
+
+	return helmRelease.Manifest, nil
+}
+
+// RemoveHelmRelease uninstalls a Helm release.
+func (deployer *KubernetesDeployer) RemoveHelmRelease(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, namespace string) (string, error) {
+	clusterAccess, closeProxy, err := deployer.helmClusterAccess(userID, endpoint)
+	if err != nil {
+		return "", err
+	}
+	defer closeProxy()
+
+	return "", deployer.helmPackageManager.Uninstall(options.UninstallOptions{
+		Name:                    releaseName,
+		Namespace:               helmNamespace(namespace),
+		KubernetesClusterAccess: clusterAccess,
+	})
+}
+
+// helmNamespace returns the namespace of a release, the releases without namespace are deployed
+// in the default namespace rather than in the namespace of Portainer
+func helmNamespace(namespace string) string {
+	if namespace == "" {
+		return "default"
+	}
+
+	return namespace
+}
+
+// helmPostRendererLabels returns the Portainer binary, used as the post-renderer of a release,
+// and the environment making it add the labels to the resources of the release
+func helmPostRendererLabels(labels map[string]string) (string, []string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to locate the helm post-renderer")
+	}
+
+	encodedLabels, err := json.Marshal(labels)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "unable to encode the labels of the helm post-renderer")
+	}
+
+	return executable, []string{k.HelmPostRendererLabelsEnvVar + "=" + string(encodedLabels)}, nil
+}
+
+// helmClusterAccess returns the access of the helm commands to the cluster of an environment, the agent environments
+// are reached through an agent proxy. The returned function releases the proxy once the command has run.
+func (deployer *KubernetesDeployer) helmClusterAccess(userID portainer.UserID, endpoint *portainer.Endpoint) (*options.KubernetesClusterAccess, func(), error) {
+	token, err := deployer.getToken(userID, endpoint, endpoint.Type == portainer.KubernetesLocalEnvironment)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed generating a user token")
+	}
+
+	clusterAccess := &options.KubernetesClusterAccess{AuthToken: token}
+
+	if endpoint.Type == portainer.AgentOnKubernetesEnvironment || endpoint.Type == portainer.EdgeAgentOnKubernetesEnvironment {
+		url, proxy, err := deployer.getAgentURL(endpoint)
+		if err != nil {
+			return nil, nil, errors.WithMessage(err, "failed generating endpoint URL")
+		}
+
+		clusterAccess.ClusterServerURL = url
+		clusterAccess.InsecureSkipTLSVerify = true
+		return clusterAccess, proxy.Close, nil
+	}
+
+	return clusterAccess, func() {}, nil
+}
+
 func (deployer *KubernetesDeployer) getAgentURL(endpoint *portainer.Endpoint) (string, *factory.ProxyServer, error) {
 	proxy, err := deployer.proxyManager.CreateAgentProxyServer(endpoint)
 	if err != nil {
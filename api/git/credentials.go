@@ -0,0 +1,56 @@
+package git
+
+import (
+	"fmt"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
+
+	"github.com/pkg/errors"
+)
+
+// ErrGitCredentialAccessDenied is returned when a user references a git credential saved by another user
+var ErrGitCredentialAccessDenied = errors.New("the git credential does not belong to the user")
+
+// GetCredentials returns the username and password of a git authentication used to access the repository.
+// A saved git credential is resolved every time, so that rotating it applies to everything referencing it.
+func GetCredentials(auth *gittypes.GitAuthentication, repositoryURL string, gitCredentialService dataservices.GitCredentialService) (string, string, error) {
+	if auth == nil {
+		return "", "", nil
+	}
+
+	if auth.GitCredentialID == 0 {
+		return auth.Username, auth.Password, nil
+	}
+
+	credential, err := gitCredentialService.GitCredential(portainer.GitCredentialID(auth.GitCredentialID))
+	if err != nil {
+		return "", "", errors.WithMessage(err, fmt.Sprintf("unable to find the git credential %d", auth.GitCredentialID))
+	}
+
+	// the private key of SSH deploy keys is used in place of the password, it must never be sent as a basic authentication
+	if credential.SSHPrivateKey != "" {
+		if !IsSSHUrl(repositoryURL) {
+			return "", "", ErrSSHKeyRequiresSSHURL
+		}
+
+		return credential.Username, credential.SSHPrivateKey, nil
+	}
+
+	return credential.Username, credential.Password, nil
+}
+
+// CheckCredentialAccess returns an error when the git credential does not exist or is not owned by the user
+func CheckCredentialAccess(gitCredentialID int, userID portainer.UserID, gitCredentialService dataservices.GitCredentialService) error {
+	credential, err := gitCredentialService.GitCredential(portainer.GitCredentialID(gitCredentialID))
+	if err != nil {
+		return errors.WithMessage(err, fmt.Sprintf("unable to find the git credential %d", gitCredentialID))
+	}
+
+	if credential.UserID != userID {
+		return ErrGitCredentialAccessDenied
+	}
+
+	return nil
+}
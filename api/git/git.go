@@ -11,6 +11,7 @@ import (
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/memory"
 	"github.com/pkg/errors"
@@ -27,17 +28,22 @@ func NewGitClient(preserveGitDir bool) *gitClient {
 }
 
 func (c *gitClient) download(ctx context.Context, dst string, opt cloneOption) error {
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return err
+	}
+
 	gitOptions := git.CloneOptions{
 		URL:   opt.repositoryUrl,
 		Depth: opt.depth,
-		Auth:  getAuth(opt.username, opt.password),
+		Auth:  auth,
 	}
 
 	if opt.referenceName != "" {
 		gitOptions.ReferenceName = plumbing.ReferenceName(opt.referenceName)
 	}
 
-	_, err := git.PlainCloneContext(ctx, dst, false, &gitOptions)
+	_, err = git.PlainCloneContext(ctx, dst, false, &gitOptions)
 
 	if err != nil {
 		if err.Error() == "authentication required" {
@@ -54,13 +60,18 @@ func (c *gitClient) download(ctx context.Context, dst string, opt cloneOption) e
 }
 
 func (c *gitClient) latestCommitID(ctx context.Context, opt fetchOption) (string, error) {
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return "", err
+	}
+
 	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
 		URLs: []string{opt.repositoryUrl},
 	})
 
 	listOptions := &git.ListOptions{
-		Auth: getAuth(opt.username, opt.password),
+		Auth: auth,
 	}
 
 	refs, err := remote.List(listOptions)
@@ -89,7 +100,20 @@ func (c *gitClient) latestCommitID(ctx context.Context, opt fetchOption) (string
 	return "", errors.Errorf("could not find ref %q in the repository", opt.referenceName)
 }
 
-func getAuth(username, password string) *githttp.BasicAuth {
+// getAuth returns the authentication of a repository, a SSH key for SSH repository URLs or a basic authentication otherwise
+func getAuth(opt baseOption) (transport.AuthMethod, error) {
+	if IsSSHUrl(opt.repositoryUrl) {
+		return getSSHAuth(opt.username, opt.password, opt.knownHosts)
+	}
+
+	if basicAuth := getBasicAuth(opt.username, opt.password); basicAuth != nil {
+		return basicAuth, nil
+	}
+
+	return nil, nil
+}
+
+func getBasicAuth(username, password string) *githttp.BasicAuth {
 	if password != "" {
 		if username == "" {
 			username = "token"
@@ -104,13 +128,18 @@ func getAuth(username, password string) *githttp.BasicAuth {
 }
 
 func (c *gitClient) listRefs(ctx context.Context, opt baseOption) ([]string, error) {
+	auth, err := getAuth(opt)
+	if err != nil {
+		return nil, err
+	}
+
 	rem := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
 		Name: "origin",
 		URLs: []string{opt.repositoryUrl},
 	})
 
 	listOptions := &git.ListOptions{
-		Auth: getAuth(opt.username, opt.password),
+		Auth: auth,
 	}
 
 	refs, err := rem.List(listOptions)
@@ -131,13 +160,18 @@ func (c *gitClient) listRefs(ctx context.Context, opt baseOption) ([]string, err
 
 // listFiles list all filenames under the specific repository
 func (c *gitClient) listFiles(ctx context.Context, opt fetchOption) ([]string, error) {
+	auth, err := getAuth(opt.baseOption)
+	if err != nil {
+		return nil, err
+	}
+
 	cloneOption := &git.CloneOptions{
 		URL:           opt.repositoryUrl,
 		NoCheckout:    true,
 		Depth:         1,
 		SingleBranch:  true,
 		ReferenceName: plumbing.ReferenceName(opt.referenceName),
-		Auth:          getAuth(opt.username, opt.password),
+		Auth:          auth,
 	}
 
 	repo, err := git.Clone(memory.NewStorage(), nil, cloneOption)
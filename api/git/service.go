@@ -6,7 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cloudogu/portainer-ce/api/metrics"
 	lru "github.com/hashicorp/golang-lru"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
@@ -20,6 +22,8 @@ type baseOption struct {
 	repositoryUrl string
 	username      string
 	password      string
+	// host keys pinned when the repository is accessed over SSH
+	knownHosts string
 }
 
 // fetchOption allows to specify the reference name of the target repository
@@ -48,6 +52,8 @@ type Service struct {
 	git          repoManager
 	timerStopped bool
 	mut          sync.Mutex
+	// knownHosts returns the host keys of the git servers accessed over SSH
+	knownHosts func() (string, error)
 
 	cacheEnabled bool
 	// Cache the result of repository refs, key is repository URL
@@ -90,6 +96,40 @@ func newService(ctx context.Context, cacheSize int, cacheTTL time.Duration) *Ser
 	return service
 }
 
+// SetKnownHostsProvider sets the function returning the host keys, in the known_hosts format,
+// of the git servers accessed over SSH
+func (service *Service) SetKnownHostsProvider(knownHosts func() (string, error)) {
+	service.knownHosts = knownHosts
+}
+
+// prepareAuthentication refuses the SSH keys used with repositories not accessed over SSH, so that they are never sent
+// as a password, and retrieves the known hosts of the repositories accessed over SSH
+func (service *Service) prepareAuthentication(options *baseOption) error {
+	if isSSHPrivateKey(options.password) {
+		if !IsSSHUrl(options.repositoryUrl) {
+			return ErrSSHKeyRequiresSSHURL
+		}
+	}
+
+	if service.knownHosts == nil || !IsSSHUrl(options.repositoryUrl) {
+		return nil
+	}
+
+	knownHosts, err := service.knownHosts()
+	if err != nil {
+		return errors.WithMessage(err, "unable to retrieve the known hosts")
+	}
+
+	options.knownHosts = knownHosts
+	return nil
+}
+
+// useAzureAPI returns true when the repository is accessed through the Azure DevOps HTTP API.
+// The Azure DevOps repositories accessed with a deploy key are cloned over SSH like any other repository.
+func useAzureAPI(options baseOption) bool {
+	return isAzureUrl(options.repositoryUrl) && !isSSHPrivateKey(options.password)
+}
+
 // startCacheCleanTimer starts a timer to purge caches periodically
 func (service *Service) startCacheCleanTimer(d time.Duration) {
 	ticker := time.NewTicker(d)
@@ -132,11 +172,19 @@ func (service *Service) CloneRepository(destination, repositoryURL, referenceNam
 		depth: 1,
 	}
 
+	if err := service.prepareAuthentication(&options.baseOption); err != nil {
+		return err
+	}
+
 	return service.cloneRepository(destination, options)
 }
 
-func (service *Service) cloneRepository(destination string, options cloneOption) error {
-	if isAzureUrl(options.repositoryUrl) {
+func (service *Service) cloneRepository(destination string, options cloneOption) (err error) {
+	defer func(start time.Time) {
+		metrics.ObserveDuration(metrics.GitOperationDuration, start, "clone", metrics.Outcome(err))
+	}(time.Now())
+
+	if useAzureAPI(options.baseOption) {
 		return service.azure.download(context.TODO(), destination, options)
 	}
 
@@ -144,7 +192,11 @@ func (service *Service) cloneRepository(destination string, options cloneOption)
 }
 
 // LatestCommitID returns SHA1 of the latest commit of the specified reference
-func (service *Service) LatestCommitID(repositoryURL, referenceName, username, password string) (string, error) {
+func (service *Service) LatestCommitID(repositoryURL, referenceName, username, password string) (commitID string, err error) {
+	defer func(start time.Time) {
+		metrics.ObserveDuration(metrics.GitOperationDuration, start, "fetch", metrics.Outcome(err))
+	}(time.Now())
+
 	options := fetchOption{
 		baseOption: baseOption{
 			repositoryUrl: repositoryURL,
@@ -154,7 +206,11 @@ func (service *Service) LatestCommitID(repositoryURL, referenceName, username, p
 		referenceName: referenceName,
 	}
 
-	if isAzureUrl(options.repositoryUrl) {
+	if err := service.prepareAuthentication(&options.baseOption); err != nil {
+		return "", err
+	}
+
+	if useAzureAPI(options.baseOption) {
 		return service.azure.latestCommitID(context.TODO(), options)
 	}
 
@@ -195,20 +251,23 @@ func (service *Service) ListRefs(repositoryURL, username, password string, hardR
 		password:      password,
 	}
 
+	if err := service.prepareAuthentication(&options); err != nil {
+		return nil, err
+	}
+
 	var (
 		refs []string
 		err  error
 	)
-	if isAzureUrl(options.repositoryUrl) {
+	start := time.Now()
+	if useAzureAPI(options) {
 		refs, err = service.azure.listRefs(context.TODO(), options)
-		if err != nil {
-			return nil, err
-		}
 	} else {
 		refs, err = service.git.listRefs(context.TODO(), options)
-		if err != nil {
-			return nil, err
-		}
+	}
+	metrics.ObserveDuration(metrics.GitOperationDuration, start, "list_refs", metrics.Outcome(err))
+	if err != nil {
+		return nil, err
 	}
 
 	if service.cacheEnabled && service.repoRefCache != nil {
@@ -250,20 +309,23 @@ func (service *Service) ListFiles(repositoryURL, referenceName, username, passwo
 		referenceName: referenceName,
 	}
 
+	if err := service.prepareAuthentication(&options.baseOption); err != nil {
+		return nil, err
+	}
+
 	var (
 		files []string
 		err   error
 	)
-	if isAzureUrl(options.repositoryUrl) {
+	start := time.Now()
+	if useAzureAPI(options.baseOption) {
 		files, err = service.azure.listFiles(context.TODO(), options)
-		if err != nil {
-			return nil, err
-		}
 	} else {
 		files, err = service.git.listFiles(context.TODO(), options)
-		if err != nil {
-			return nil, err
-		}
+	}
+	metrics.ObserveDuration(metrics.GitOperationDuration, start, "list_files", metrics.Outcome(err))
+	if err != nil {
+		return nil, err
 	}
 
 	includedFiles := filterFiles(files, includedExts)
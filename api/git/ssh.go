@@ -0,0 +1,163 @@
+package git
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const defaultSSHUser = "git"
+
+// scpLikeURLRegex matches the scp-like syntax of the SSH repository URLs, e.g. git@host:org/repo.git
+var scpLikeURLRegex = regexp.MustCompile(`^(?:[^@/]+@)?[^:/]+:[^/]`)
+
+// ErrSSHKeyRequiresSSHURL is returned when an SSH key is used to access a repository with a non SSH URL
+var ErrSSHKeyRequiresSSHURL = errors.New("an SSH key can only be used with an SSH repository URL")
+
+var (
+	errSSHKeyRequired          = errors.New("an SSH key is required to access a repository over SSH")
+	errSSHKnownHostsRequired   = errors.New("no known hosts are configured, the host key of the git server cannot be verified")
+	errInvalidSSHKnownHostLine = errors.New("invalid known_hosts line")
+)
+
+// IsSSHUrl returns true when the repository URL uses the SSH transport, either as ssh://host/repo or git@host:repo
+func IsSSHUrl(url string) bool {
+	if strings.HasPrefix(url, "ssh://") {
+		return true
+	}
+
+	return !strings.Contains(url, "://") && scpLikeURLRegex.MatchString(url)
+}
+
+// isSSHPrivateKey returns true when the password of a repository is a PEM encoded private key
+func isSSHPrivateKey(password string) bool {
+	return strings.Contains(password, "PRIVATE KEY-----")
+}
+
+// IsValidRepositoryURL returns true for the HTTP(S) and SSH repository URLs
+func IsValidRepositoryURL(url string) bool {
+	return IsSSHUrl(url) || govalidator.IsURL(url)
+}
+
+// GenerateSSHKey generates an ED25519 key pair, returning the PEM encoded private key
+// and the public key in the authorized_keys format
+func GenerateSSHKey(comment string) (string, string, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	return string(privateKeyPEM), authorizedKey(sshPublicKey, comment), nil
+}
+
+// SSHPublicKey returns the public key of a PEM encoded private key, in the authorized_keys format
+func SSHPublicKey(privateKey, comment string) (string, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+	if err != nil {
+		return "", errors.WithMessage(err, "unable to parse the SSH private key")
+	}
+
+	return authorizedKey(signer.PublicKey(), comment), nil
+}
+
+func authorizedKey(publicKey ssh.PublicKey, comment string) string {
+	key := strings.TrimSuffix(string(ssh.MarshalAuthorizedKey(publicKey)), "\n")
+	if comment != "" {
+		key += " " + comment
+	}
+
+	return key
+}
+
+// ValidateKnownHosts returns an error when a line of the known hosts cannot be parsed
+func ValidateKnownHosts(knownHosts string) error {
+	for _, line := range strings.Split(knownHosts, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		_, _, _, _, _, err := ssh.ParseKnownHosts([]byte(line))
+		if err != nil {
+			return errors.Wrapf(errInvalidSSHKnownHostLine, "%q", line)
+		}
+	}
+
+	return nil
+}
+
+// getSSHAuth returns the SSH authentication of a repository, the password holding the PEM encoded private key.
+// The host key of the server must match one of the known hosts.
+func getSSHAuth(username, privateKey, knownHosts string) (transport.AuthMethod, error) {
+	if privateKey == "" {
+		return nil, errSSHKeyRequired
+	}
+
+	if strings.TrimSpace(knownHosts) == "" {
+		return nil, errSSHKnownHostsRequired
+	}
+
+	if username == "" {
+		username = defaultSSHUser
+	}
+
+	auth, err := gitssh.NewPublicKeys(username, []byte(privateKey), "")
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to parse the SSH private key")
+	}
+
+	auth.HostKeyCallback, err = hostKeyCallback(knownHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth, nil
+}
+
+// hostKeyCallback creates a callback verifying the host keys against the known hosts,
+// which the knownhosts package only reads from files
+func hostKeyCallback(knownHosts string) (ssh.HostKeyCallback, error) {
+	file, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(knownHosts + "\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := knownhosts.New(file.Name())
+	if err != nil {
+		return nil, errors.WithMessage(err, "unable to parse the known hosts")
+	}
+
+	return callback, nil
+}
@@ -19,6 +19,29 @@ type RepoConfig struct {
 	Authentication *GitAuthentication
 	// Repository hash
 	ConfigHash string `example:"bc4c183d756879ea4d173315338110b31004b8e0"`
+	// Restricts the automatic redeploys to the changes of the watched files, every new commit triggers a redeploy when nil
+	PathFilter *PathFilter `json:",omitempty"`
+	// Files whose changes triggered the last automatic redeploy, when a path filter is set
+	LastRedeployTrigger *RedeployTrigger `json:",omitempty"`
+}
+
+// PathFilter scopes the automatic redeploys of a git stack to the files it is deployed from.
+// The directory of the stack file and the additional files are always watched.
+type PathFilter struct {
+	// Extra watched files, as glob patterns relative to the repository root. A pattern ending with /** matches a whole directory
+	Globs []string `example:"shared/*.env,config/**"`
+}
+
+// RedeployTrigger records the watched files whose changes triggered an automatic redeploy
+type RedeployTrigger struct {
+	// Commit deployed before the redeploy
+	FromHash string `example:"bc4c183d756879ea4d173315338110b31004b8e0"`
+	// Commit deployed by the redeploy
+	ToHash string `example:"68dcaa7bd452494043c64252ab90db0f98ecf8d2"`
+	// Watched files, relative to the repository root, changed between both commits
+	ChangedFiles []string `example:"app/docker-compose.yml"`
+	// Unix timestamp of the redeploy
+	Date int64 `example:"1587399600"`
 }
 
 type GitAuthentication struct {
@@ -0,0 +1,242 @@
+package auditlogs
+
+import (
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type auditLogListQuery struct {
+	userID     portainer.UserID
+	endpointID portainer.EndpointID
+	method     string
+	outcome    portainer.AuditLogOutcome
+	after      int64
+	before     int64
+	search     string
+}
+
+// @id AuditLogList
+// @summary List audit logs
+// @description List the audit log entries, most recent first.
+// @description Every mutating request performed against the API or proxied to an environment(endpoint) is recorded.
+// @description **Access policy**: administrator
+// @tags audit_logs
+// @security ApiKeyAuth
+// @security jwt
+// @produce json,text/csv
+// @param start query int false "Start searching from"
+// @param limit query int false "Limit results to this value"
+// @param userId query int false "List entries of this user"
+// @param endpointId query int false "List entries targeting this environment(endpoint)"
+// @param method query string false "List entries with this HTTP method"
+// @param outcome query string false "List entries with this outcome" Enum("success", "failure")
+// @param after query int false "List entries recorded after this unix timestamp"
+// @param before query int false "List entries recorded before this unix timestamp"
+// @param search query string false "Search query, matched against the username, path and resource identifier"
+// @param format query string false "Export format, defaults to json" Enum("json", "csv")
+// @success 200 {array} portainer.AuditLog "Success"
+// @failure 400 "Invalid query parameters"
+// @failure 500 "Server error"
+// @router /audit_logs [get]
+func (handler *Handler) auditLogList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	start, _ := request.RetrieveNumericQueryParameter(r, "start", true)
+	if start != 0 {
+		start--
+	}
+
+	limit, _ := request.RetrieveNumericQueryParameter(r, "limit", true)
+
+	format, _ := request.RetrieveQueryParameter(r, "format", true)
+	if format != "" && format != "json" && format != "csv" {
+		return httperror.BadRequest("Invalid format query parameter", errors.New("format must be one of json or csv"))
+	}
+
+	query, err := parseQuery(r)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameters", err)
+	}
+
+	auditLogs, err := handler.DataStore.AuditLog().AuditLogs()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve audit logs from the database", err)
+	}
+
+	auditLogs = filterAuditLogs(auditLogs, query)
+
+	sort.Slice(auditLogs, func(i, j int) bool {
+		if auditLogs[i].Timestamp == auditLogs[j].Timestamp {
+			return auditLogs[i].ID > auditLogs[j].ID
+		}
+
+		return auditLogs[i].Timestamp > auditLogs[j].Timestamp
+	})
+
+	totalCount := len(auditLogs)
+	auditLogs = paginateAuditLogs(auditLogs, start, limit)
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(totalCount))
+
+	if format == "csv" {
+		return writeCSV(w, auditLogs)
+	}
+
+	return response.JSON(w, auditLogs)
+}
+
+func parseQuery(r *http.Request) (auditLogListQuery, error) {
+	userID, err := request.RetrieveNumericQueryParameter(r, "userId", true)
+	if err != nil {
+		return auditLogListQuery{}, err
+	}
+
+	endpointID, err := request.RetrieveNumericQueryParameter(r, "endpointId", true)
+	if err != nil {
+		return auditLogListQuery{}, err
+	}
+
+	after, err := request.RetrieveNumericQueryParameter(r, "after", true)
+	if err != nil {
+		return auditLogListQuery{}, err
+	}
+
+	before, err := request.RetrieveNumericQueryParameter(r, "before", true)
+	if err != nil {
+		return auditLogListQuery{}, err
+	}
+
+	method, _ := request.RetrieveQueryParameter(r, "method", true)
+	outcome, _ := request.RetrieveQueryParameter(r, "outcome", true)
+	search, _ := request.RetrieveQueryParameter(r, "search", true)
+
+	return auditLogListQuery{
+		userID:     portainer.UserID(userID),
+		endpointID: portainer.EndpointID(endpointID),
+		method:     strings.ToUpper(method),
+		outcome:    portainer.AuditLogOutcome(outcome),
+		after:      int64(after),
+		before:     int64(before),
+		search:     strings.ToLower(search),
+	}, nil
+}
+
+func filterAuditLogs(auditLogs []portainer.AuditLog, query auditLogListQuery) []portainer.AuditLog {
+	filtered := make([]portainer.AuditLog, 0, len(auditLogs))
+
+	for _, entry := range auditLogs {
+		if query.userID != 0 && entry.UserID != query.userID {
+			continue
+		}
+
+		if query.endpointID != 0 && entry.EndpointID != query.endpointID {
+			continue
+		}
+
+		if query.method != "" && entry.Method != query.method {
+			continue
+		}
+
+		if query.outcome != "" && entry.Outcome != query.outcome {
+			continue
+		}
+
+		if query.after != 0 && entry.Timestamp < query.after {
+			continue
+		}
+
+		if query.before != 0 && entry.Timestamp > query.before {
+			continue
+		}
+
+		if query.search != "" &&
+			!strings.Contains(strings.ToLower(entry.Username), query.search) &&
+			!strings.Contains(strings.ToLower(entry.Path), query.search) &&
+			!strings.Contains(strings.ToLower(entry.ResourceID), query.search) {
+			continue
+		}
+
+		filtered = append(filtered, entry)
+	}
+
+	return filtered
+}
+
+func paginateAuditLogs(auditLogs []portainer.AuditLog, start, limit int) []portainer.AuditLog {
+	if limit == 0 {
+		return auditLogs
+	}
+
+	count := len(auditLogs)
+
+	if start < 0 {
+		start = 0
+	}
+
+	if start > count {
+		start = count
+	}
+
+	end := start + limit
+	if end > count {
+		end = count
+	}
+
+	return auditLogs[start:end]
+}
+
+func writeCSV(w http.ResponseWriter, auditLogs []portainer.AuditLog) *httperror.HandlerError {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=audit_logs.csv")
+
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"Id", "Time", "UserId", "Username", "AuthMethod", "ApiKeyId", "EndpointId", "Method", "Path", "ResourceId", "StatusCode", "Outcome"})
+	if err != nil {
+		return httperror.InternalServerError("Unable to write the audit logs", err)
+	}
+
+	for _, entry := range auditLogs {
+		err := writer.Write([]string{
+			strconv.Itoa(int(entry.ID)),
+			time.Unix(entry.Timestamp, 0).UTC().Format(time.RFC3339),
+			strconv.Itoa(int(entry.UserID)),
+			csvCell(entry.Username),
+			string(entry.AuthMethod),
+			strconv.Itoa(int(entry.APIKeyID)),
+			strconv.Itoa(int(entry.EndpointID)),
+			csvCell(entry.Method),
+			csvCell(entry.Path),
+			csvCell(entry.ResourceID),
+			strconv.Itoa(entry.StatusCode),
+			string(entry.Outcome),
+		})
+		if err != nil {
+			return httperror.InternalServerError("Unable to write the audit logs", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return httperror.InternalServerError("Unable to write the audit logs", err)
+	}
+
+	return nil
+}
+
+// csvCell prefixes the values which spreadsheets would evaluate as formulas
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,95 @@
+package auditlogs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_auditLogList(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	entries := []portainer.AuditLog{
+		{Timestamp: 100, UserID: 1, Username: "admin", Method: http.MethodPost, Path: "/api/stacks", Outcome: portainer.AuditLogOutcomeSuccess},
+		{Timestamp: 200, UserID: 2, Username: "bob", Method: http.MethodDelete, Path: "/api/endpoints/1/docker/containers/abc", EndpointID: 1, ResourceID: "containers/abc", Outcome: portainer.AuditLogOutcomeFailure},
+		{Timestamp: 300, UserID: 1, Username: "admin", Method: http.MethodPut, Path: "/api/settings", Outcome: portainer.AuditLogOutcomeSuccess},
+	}
+	for i := range entries {
+		err := store.AuditLog().Create(&entries[i])
+		is.NoError(err, "error creating audit log")
+	}
+
+	h := &Handler{DataStore: store}
+
+	list := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/audit_logs"+query, nil)
+		rr := httptest.NewRecorder()
+		httperror.LoggerHandler(h.auditLogList).ServeHTTP(rr, req)
+		return rr
+	}
+
+	decode := func(rr *httptest.ResponseRecorder) []portainer.AuditLog {
+		var logs []portainer.AuditLog
+		err := json.NewDecoder(rr.Body).Decode(&logs)
+		is.NoError(err, "response should be json")
+		return logs
+	}
+
+	t.Run("lists the most recent entries first", func(t *testing.T) {
+		rr := list("")
+		is.Equal(http.StatusOK, rr.Code)
+
+		logs := decode(rr)
+		is.Len(logs, 3)
+		is.Equal(int64(300), logs[0].Timestamp)
+		is.Equal("3", rr.Header().Get("X-Total-Count"))
+	})
+
+	t.Run("filters and paginates entries", func(t *testing.T) {
+		rr := list("?userId=1&start=2&limit=1")
+		is.Equal(http.StatusOK, rr.Code)
+
+		logs := decode(rr)
+		is.Len(logs, 1)
+		is.Equal(int64(100), logs[0].Timestamp)
+		is.Equal("2", rr.Header().Get("X-Total-Count"))
+
+		logs = decode(list("?outcome=failure&search=containers"))
+		is.Len(logs, 1)
+		is.Equal("bob", logs[0].Username)
+	})
+
+	t.Run("exports entries as csv", func(t *testing.T) {
+		rr := list("?format=csv&endpointId=1")
+		is.Equal(http.StatusOK, rr.Code)
+		is.Equal("text/csv", rr.Header().Get("Content-Type"))
+
+		records, err := csv.NewReader(rr.Body).ReadAll()
+		is.NoError(err, "response should be csv")
+		is.Len(records, 2, "csv should contain a header and a single entry")
+		is.Equal("containers/abc", records[1][9])
+	})
+
+	t.Run("escapes the csv cells evaluated as formulas", func(t *testing.T) {
+		is.Equal("'=HYPERLINK(\"http://evil\")", csvCell("=HYPERLINK(\"http://evil\")"))
+		is.Equal("'@SUM(A1)", csvCell("@SUM(A1)"))
+		is.Equal("'-1+1", csvCell("-1+1"))
+		is.Equal("/api/stacks", csvCell("/api/stacks"))
+		is.Equal("", csvCell(""))
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		rr := list("?format=xml")
+		is.Equal(http.StatusBadRequest, rr.Code)
+	})
+}
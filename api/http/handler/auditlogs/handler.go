@@ -0,0 +1,27 @@
+package auditlogs
+
+import (
+	"net/http"
+
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/gorilla/mux"
+	httperror "github.com/portainer/libhttp/error"
+)
+
+// Handler is the HTTP handler used to handle audit log operations.
+type Handler struct {
+	*mux.Router
+	DataStore dataservices.DataStore
+}
+
+// NewHandler creates a handler to manage audit log operations.
+func NewHandler(bouncer *security.RequestBouncer) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+	h.Handle("/audit_logs",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.auditLogList))).Methods(http.MethodGet)
+
+	return h
+}
@@ -101,10 +101,10 @@ func (handler *Handler) validateOAuth(w http.ResponseWriter, r *http.Request) *h
 
 	}
 
-	return handler.userProvisioning(w, &userData, settings)
+	return handler.userProvisioning(w, r, &userData, settings)
 }
 
-func (handler *Handler) userProvisioning(w http.ResponseWriter, userData *portainer.OAuthUserData, settings *portainer.Settings) *httperror.HandlerError {
+func (handler *Handler) userProvisioning(w http.ResponseWriter, r *http.Request, userData *portainer.OAuthUserData, settings *portainer.Settings) *httperror.HandlerError {
 	user, err := handler.DataStore.User().UserByUsername(userData.Username)
 	if err != nil && err != bolterrors.ErrObjectNotFound {
 		return &httperror.HandlerError{http.StatusInternalServerError, "Unable to retrieve a user with the specified username from the database", err}
@@ -153,7 +153,7 @@ func (handler *Handler) userProvisioning(w http.ResponseWriter, userData *portai
 
 	user.OAuthToken = userData.OAuthToken
 
-	return handler.writeToken(w, user, false)
+	return handler.writeToken(w, r, user, false)
 }
 
 func (handler *Handler) createUser(userData *portainer.OAuthUserData) (*portainer.User, error) {
@@ -103,5 +103,5 @@ func (handler *Handler) authenticateViaApi(w http.ResponseWriter, r *http.Reques
 
 	user.OAuthToken = &oauth2.Token{AccessToken: string(body)}
 
-	return handler.writeToken(w, user, false)
+	return handler.writeToken(w, r, user, false)
 }
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"sync"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
+	"github.com/cloudogu/portainer-ce/api/internal/passwordpolicy"
+	"github.com/cloudogu/portainer-ce/api/internal/twofactor"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// twoFactorChallengeTimeout is the duration a user has to provide their code after the password check
+	twoFactorChallengeTimeout = 5 * time.Minute
+	// twoFactorChallengeMaxAttempts is the number of invalid codes after which the password must be checked again
+	twoFactorChallengeMaxAttempts = 5
+)
+
+var errTwoFactorChallengeInvalid = errors.New("invalid or expired two-factor authentication token")
+
+type twoFactorPayload struct {
+	// Token returned by the password check
+	Token string `example:"8e0f4b1e3c..." validate:"required"`
+	// TOTP code, or one of the recovery codes
+	Code string `example:"123456" validate:"required"`
+}
+
+func (payload *twoFactorPayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Token) {
+		return errors.New("Invalid two-factor authentication token")
+	}
+
+	if govalidator.IsNull(payload.Code) {
+		return errors.New("Invalid two-factor authentication code")
+	}
+
+	return nil
+}
+
+type twoFactorResponse struct {
+	// JWT token used to authenticate against the API
+	JWT string `json:"jwt" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// Recovery codes generated when the user enrolled while logging in, they are only returned once
+	RecoveryCodes []string `json:"recoveryCodes,omitempty" example:"k3vq2m7x-p4d8w6ta"`
+}
+
+// twoFactorChallenge is the state of a login whose password was checked and which waits for the second factor
+type twoFactorChallenge struct {
+	userID              portainer.UserID
+	forceChangePassword bool
+	expiresAt           time.Time
+	attempts            int
+}
+
+// twoFactorChallenges keeps the pending logins in memory, a restart of Portainer requires the users to log in again
+type twoFactorChallenges struct {
+	mu         sync.Mutex
+	challenges map[string]*twoFactorChallenge
+	// verifyMu serializes the verifications of the codes, so that a TOTP code or a recovery code is accepted only once
+	verifyMu sync.Mutex
+}
+
+func newTwoFactorChallenges() *twoFactorChallenges {
+	return &twoFactorChallenges{challenges: make(map[string]*twoFactorChallenge)}
+}
+
+func (c *twoFactorChallenges) create(userID portainer.UserID, forceChangePassword bool) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for t, challenge := range c.challenges {
+		if now.After(challenge.expiresAt) {
+			delete(c.challenges, t)
+		}
+	}
+
+	c.challenges[token] = &twoFactorChallenge{
+		userID:              userID,
+		forceChangePassword: forceChangePassword,
+		expiresAt:           now.Add(twoFactorChallengeTimeout),
+	}
+
+	return token, nil
+}
+
+// get returns a copy of a pending challenge, or nil when it does not exist or expired
+func (c *twoFactorChallenges) get(token string) *twoFactorChallenge {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	challenge, ok := c.challenges[token]
+	if !ok {
+		return nil
+	}
+
+	if time.Now().After(challenge.expiresAt) {
+		delete(c.challenges, token)
+		return nil
+	}
+
+	challengeCopy := *challenge
+	return &challengeCopy
+}
+
+// fail records an invalid code, the challenge is dropped once it reached the maximum number of attempts
+func (c *twoFactorChallenges) fail(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	challenge, ok := c.challenges[token]
+	if !ok {
+		return
+	}
+
+	challenge.attempts++
+	if challenge.attempts >= twoFactorChallengeMaxAttempts {
+		delete(c.challenges, token)
+	}
+}
+
+func (c *twoFactorChallenges) remove(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.challenges, token)
+}
+
+// writeTokenOrChallenge writes the JWT of a user whose password was checked, or a two-factor challenge when their
+// second factor is enabled or required by the settings. The users who must enroll get their enrollment along the challenge.
+func (handler *Handler) writeTokenOrChallenge(w http.ResponseWriter, r *http.Request, user *portainer.User, forceChangePassword bool) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve settings from the database", err)
+	}
+
+	if !twoFactorRequired(user, settings) {
+		return handler.writeToken(w, r, user, forceChangePassword)
+	}
+
+	resp := &authenticateResponse{TwoFactorRequired: true}
+
+	if !user.TwoFactor.Enabled {
+		resp.TwoFactorEnrollment, err = twofactor.Enroll(user)
+		if err != nil {
+			return httperror.InternalServerError("Unable to enroll the user for two-factor authentication", err)
+		}
+
+		err = handler.DataStore.User().UpdateUser(user.ID, user)
+		if err != nil {
+			return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+		}
+	}
+
+	resp.TwoFactorToken, err = handler.twoFactorChallenges.create(user.ID, forceChangePassword)
+	if err != nil {
+		return httperror.InternalServerError("Unable to create the two-factor authentication challenge", err)
+	}
+
+	return response.JSON(w, resp)
+}
+
+// @id AuthenticateTwoFactor
+// @summary Complete a login with the second factor
+// @description **Access policy**: public
+// @description Use this environment(endpoint) to complete the login of a user whose second factor is enabled or required,
+// @description with the token returned by the password check and a TOTP or recovery code.
+// @description The users who enrolled while logging in get their recovery codes along the JWT.
+// @tags auth
+// @accept json
+// @produce json
+// @param body body twoFactorPayload true "Second factor"
+// @success 200 {object} twoFactorResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Account locked"
+// @failure 422 "Invalid code or expired token"
+// @failure 500 "Server error"
+// @router /auth/2fa [post]
+func (handler *Handler) authenticateTwoFactor(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload twoFactorPayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	challenge := handler.twoFactorChallenges.get(payload.Token)
+	if challenge == nil {
+		return &httperror.HandlerError{StatusCode: http.StatusUnprocessableEntity, Message: "Invalid or expired two-factor authentication token", Err: errTwoFactorChallengeInvalid}
+	}
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve settings from the database", err)
+	}
+
+	// the user is read, verified and persisted at once so that the concurrent requests see the last used code
+	handler.twoFactorChallenges.verifyMu.Lock()
+	defer handler.twoFactorChallenges.verifyMu.Unlock()
+
+	user, err := handler.DataStore.User().User(challenge.userID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the user from the database", err)
+	}
+
+	now := time.Now()
+
+	lastAdministrator, err := handler.isLastAdministrator(user, &settings.InternalAuthSettings, now)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the administrators from the database", err)
+	}
+
+	if passwordpolicy.IsLocked(user, &settings.InternalAuthSettings, now, lastAdministrator) {
+		handler.twoFactorChallenges.remove(payload.Token)
+		return httperror.Forbidden("Account locked", passwordpolicy.ErrAccountLocked)
+	}
+
+	resp := &twoFactorResponse{}
+	if user.TwoFactor.Enabled {
+		err = twofactor.Verify(handler.CryptoService, &user.TwoFactor, payload.Code, now)
+	} else {
+		resp.RecoveryCodes, err = twofactor.Enable(handler.CryptoService, user, payload.Code, now)
+	}
+
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		handler.twoFactorChallenges.fail(payload.Token)
+
+		if passwordpolicy.RecordFailedLogin(user, &settings.InternalAuthSettings, now, lastAdministrator) {
+			if user.LockedAt != 0 {
+				handler.twoFactorChallenges.remove(payload.Token)
+				log.Warn().Str("username", user.Username).Int("failed_attempts", user.FailedLoginAttempts).Msg("account locked after too many failed logins")
+			}
+
+			err = handler.DataStore.User().UpdateUser(user.ID, user)
+			if err != nil {
+				return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+			}
+		}
+
+		return &httperror.HandlerError{StatusCode: http.StatusUnprocessableEntity, Message: "Invalid two-factor authentication code", Err: httperrors.ErrUnauthorized}
+	} else if err != nil {
+		return httperror.BadRequest("Unable to verify the two-factor authentication code", err)
+	}
+
+	handler.twoFactorChallenges.remove(payload.Token)
+
+	passwordpolicy.RecordSuccessfulLogin(user)
+
+	err = handler.DataStore.User().UpdateUser(user.ID, user)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	resp.JWT, err = handler.generateToken(w, r, composeTokenData(user, challenge.forceChangePassword))
+	if err != nil {
+		return httperror.InternalServerError("Unable to generate JWT token", err)
+	}
+
+	return response.JSON(w, resp)
+}
+
+// twoFactorRequired returns whether a user must provide a second factor to log in
+func twoFactorRequired(user *portainer.User, settings *portainer.Settings) bool {
+	return user.TwoFactor.Enabled || settings.TwoFactorRequired
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/apikey"
+	"github.com/cloudogu/portainer-ce/api/crypto"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/internal/twofactor"
+	"github.com/cloudogu/portainer-ce/api/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_authenticateTwoFactor(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	settings, err := store.Settings().Settings()
+	is.NoError(err)
+	settings.InternalAuthSettings.MaxFailedLoginAttempts = 3
+	is.NoError(store.Settings().UpdateSettings(settings))
+
+	secret, err := twofactor.GenerateSecret()
+	is.NoError(err)
+
+	user := &portainer.User{ID: 2, Username: "standard", Role: portainer.StandardUserRole, TwoFactor: portainer.UserTwoFactor{Enabled: true, Secret: secret}}
+	is.NoError(store.User().Create(user))
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	passwordChecker := security.NewPasswordStrengthChecker(store.SettingsService)
+
+	h := NewHandler(requestBouncer, rateLimiter, passwordChecker)
+	h.DataStore = store
+	h.CryptoService = &crypto.Service{}
+	h.JWTService = jwtService
+
+	verify := func(code string) int {
+		token, err := h.twoFactorChallenges.create(user.ID, false)
+		is.NoError(err)
+
+		body, err := json.Marshal(twoFactorPayload{Token: token, Code: code})
+		is.NoError(err)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/auth/2fa", bytes.NewBuffer(body)))
+
+		return rr.Code
+	}
+
+	code, err := twofactor.GenerateCode(secret, time.Now())
+	is.NoError(err)
+
+	is.Equal(http.StatusOK, verify(code))
+	is.Equal(http.StatusUnprocessableEntity, verify(code), "a code should not be accepted twice")
+
+	is.Equal(http.StatusUnprocessableEntity, verify("invalid"))
+
+	locked, err := store.User().User(user.ID)
+	is.NoError(err)
+	is.Zero(locked.LockedAt)
+	is.Equal(2, locked.FailedLoginAttempts, "the replayed and the invalid codes should count as failed logins")
+
+	code, err = twofactor.GenerateCode(secret, time.Now().Add(30*time.Second))
+	is.NoError(err)
+	is.Equal(http.StatusUnprocessableEntity, verify("invalid"))
+	is.Equal(http.StatusForbidden, verify(code), "the account should be locked after too many invalid codes")
+}
@@ -23,7 +23,9 @@ type Handler struct {
 	OAuthService                portainer.OAuthService
 	ProxyManager                *proxy.Manager
 	KubernetesTokenCacheManager *kubernetes.TokenCacheManager
+	CSRFProtection              *security.CSRFProtection
 	passwordStrengthChecker     security.PasswordStrengthChecker
+	twoFactorChallenges         *twoFactorChallenges
 }
 
 // NewHandler creates a handler to manage authentication operations.
@@ -31,6 +33,7 @@ func NewHandler(bouncer *security.RequestBouncer, rateLimiter *security.RateLimi
 	h := &Handler{
 		Router:                  mux.NewRouter(),
 		passwordStrengthChecker: passwordStrengthChecker,
+		twoFactorChallenges:     newTwoFactorChallenges(),
 	}
 
 	h.Handle("/auth/oauth/validate",
@@ -43,6 +46,8 @@ func NewHandler(bouncer *security.RequestBouncer, rateLimiter *security.RateLimi
 		rateLimiter.LimitAccess(bouncer.PublicAccess(httperror.LoggerHandler(h.authenticateViaApi)))).Methods(http.MethodPost)
 	h.Handle("/auth",
 		rateLimiter.LimitAccess(bouncer.PublicAccess(httperror.LoggerHandler(h.authenticate)))).Methods(http.MethodPost)
+	h.Handle("/auth/2fa",
+		rateLimiter.LimitAccess(bouncer.PublicAccess(httperror.LoggerHandler(h.authenticateTwoFactor)))).Methods(http.MethodPost)
 	h.Handle("/auth/logout",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.logout))).Methods(http.MethodPost)
 
@@ -10,6 +10,7 @@ import (
 
 // @id Logout
 // @summary Logout
+// @description Revokes the session of the calling user.
 // @description **Access policy**: authenticated
 // @security ApiKeyAuth
 // @security jwt
@@ -25,5 +26,16 @@ func (handler *Handler) logout(w http.ResponseWriter, r *http.Request) *httperro
 
 	handler.KubernetesTokenCacheManager.RemoveUserFromCache(tokenData.ID)
 
+	if tokenData.SessionID != 0 {
+		err = handler.DataStore.UserSession().DeleteUserSession(tokenData.SessionID)
+		if err != nil && !handler.DataStore.IsErrObjectNotFound(err) {
+			return httperror.InternalServerError("Unable to revoke the session", err)
+		}
+	}
+
+	if handler.CSRFProtection != nil {
+		handler.CSRFProtection.ClearToken(w, r)
+	}
+
 	return response.Empty(w)
 }
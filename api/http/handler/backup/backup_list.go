@@ -0,0 +1,52 @@
+package backup
+
+import (
+	"net/http"
+	"sort"
+
+	operations "github.com/cloudogu/portainer-ce/api/backup"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id BackupList
+// @summary List the stored backups
+// @description List the archives kept in the destination of the scheduled backups, newest first.
+// @description **Access policy**: admin
+// @tags backup
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} operations.Archive "Success"
+// @failure 500 "Server error"
+// @router /backups [get]
+func (h *Handler) backupList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := h.dataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+	}
+
+	if settings.BackupSchedule.DestinationType == 0 {
+		return response.JSON(w, []operations.Archive{})
+	}
+
+	destination, err := operations.NewDestination(settings.BackupSchedule)
+	if err != nil {
+		return httperror.InternalServerError("Invalid backup destination", err)
+	}
+
+	archives, err := destination.List()
+	if err != nil {
+		return httperror.InternalServerError("Unable to list the stored backups", err)
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		if archives[i].CreatedAt == archives[j].CreatedAt {
+			return archives[i].Name > archives[j].Name
+		}
+
+		return archives[i].CreatedAt > archives[j].CreatedAt
+	})
+
+	return response.JSON(w, archives)
+}
@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/adminmonitor"
+	operations "github.com/cloudogu/portainer-ce/api/backup"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/demo"
+	"github.com/cloudogu/portainer-ce/api/http/offlinegate"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_backupList_shouldReturnStoredArchivesNewestFirst(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	backupDir := t.TempDir()
+	settings, err := store.Settings().Settings()
+	is.NoError(err)
+	settings.BackupSchedule = portainer.BackupScheduleSettings{
+		DestinationType: portainer.BackupDestinationLocal,
+		LocalDirectory:  backupDir,
+	}
+	is.NoError(store.Settings().UpdateSettings(settings))
+
+	destination, err := operations.NewLocalDestination(backupDir)
+	is.NoError(err)
+	is.NoError(destination.Upload("portainer-backup_2023-01-01_02-00-00.tar.gz", bytes.NewReader([]byte("old"))))
+	is.NoError(destination.Upload("portainer-backup_2023-01-02_02-00-00.tar.gz", bytes.NewReader([]byte("new"))))
+
+	h := NewHandler(nil, store, offlinegate.NewOfflineGate(), t.TempDir(), func() {}, adminmonitor.New(time.Hour, nil, context.Background()), &demo.Service{})
+
+	r := httptest.NewRequest(http.MethodGet, "/backups", nil)
+	w := httptest.NewRecorder()
+
+	handlerErr := h.backupList(w, r)
+	is.Nil(handlerErr, "Handler should not fail")
+
+	body, _ := io.ReadAll(w.Result().Body)
+
+	var archives []operations.Archive
+	is.NoError(json.Unmarshal(body, &archives))
+	is.Len(archives, 2)
+	is.Equal("portainer-backup_2023-01-02_02-00-00.tar.gz", archives[0].Name)
+}
+
+func Test_backupRestore_shouldFailForUnknownArchive(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	settings, err := store.Settings().Settings()
+	is.NoError(err)
+	settings.BackupSchedule = portainer.BackupScheduleSettings{
+		DestinationType: portainer.BackupDestinationLocal,
+		LocalDirectory:  t.TempDir(),
+	}
+	is.NoError(store.Settings().UpdateSettings(settings))
+
+	h := NewHandler(nil, store, offlinegate.NewOfflineGate(), t.TempDir(), func() {}, adminmonitor.New(time.Hour, nil, context.Background()), &demo.Service{})
+
+	r := httptest.NewRequest(http.MethodPost, "/backups/portainer-backup_missing.tar.gz/restore", nil)
+	r = mux.SetURLVars(r, map[string]string{"name": "portainer-backup_missing.tar.gz"})
+	w := httptest.NewRecorder()
+
+	handlerErr := h.backupRestore(w, r)
+	is.NotNil(handlerErr)
+	is.Equal(http.StatusNotFound, handlerErr.StatusCode)
+}
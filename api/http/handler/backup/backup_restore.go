@@ -0,0 +1,89 @@
+package backup
+
+import (
+	"net/http"
+
+	operations "github.com/cloudogu/portainer-ce/api/backup"
+	"github.com/pkg/errors"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type backupRestorePayload struct {
+	// Password used to decrypt the archive, defaults to the password of the backup schedule
+	Password string
+}
+
+func (p *backupRestorePayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id BackupRestore
+// @summary Restore the system from a stored backup
+// @description Triggers a system restore using one of the archives kept in the destination of the scheduled backups.
+// @description The instance shuts down once the restore is done.
+// @description **Access policy**: admin
+// @tags backup
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @param name path string true "Archive name"
+// @param body body backupRestorePayload false "An object containing the password used to decrypt the archive"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Archive not found"
+// @failure 500 "Server error"
+// @router /backups/{name}/restore [post]
+func (h *Handler) backupRestore(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	name, err := request.RetrieveRouteVariableValue(r, "name")
+	if err != nil {
+		return httperror.BadRequest("Invalid archive name route variable", err)
+	}
+
+	if err := operations.ValidateArchiveName(name); err != nil {
+		return httperror.BadRequest("Invalid archive name route variable", err)
+	}
+
+	var payload backupRestorePayload
+	if r.ContentLength != 0 {
+		err = request.DecodeAndValidateJSONPayload(r, &payload)
+		if err != nil {
+			return httperror.BadRequest("Invalid request payload", err)
+		}
+	}
+
+	settings, err := h.dataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+	}
+
+	if settings.BackupSchedule.DestinationType == 0 {
+		return httperror.BadRequest("No backup destination is configured", errors.New("missing backup destination"))
+	}
+
+	destination, err := operations.NewDestination(settings.BackupSchedule)
+	if err != nil {
+		return httperror.InternalServerError("Invalid backup destination", err)
+	}
+
+	password := payload.Password
+	if password == "" {
+		password = settings.BackupSchedule.Password
+	}
+
+	archive, err := destination.Download(name)
+	if errors.Is(err, operations.ErrArchiveNotFound) {
+		return httperror.NotFound("Unable to find the backup archive", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the backup archive", err)
+	}
+	defer archive.Close()
+
+	err = operations.RestoreArchive(archive, password, h.filestorePath, h.gate, h.dataStore, h.shutdownTrigger)
+	if err != nil {
+		return httperror.InternalServerError("Failed to restore the backup", err)
+	}
+
+	return response.Empty(w)
+}
@@ -52,6 +52,8 @@ func NewHandler(
 
 	demoRestrictedRouter.Handle("/backup", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.backup)))).Methods(http.MethodPost)
 	demoRestrictedRouter.Handle("/restore", bouncer.PublicAccess(httperror.LoggerHandler(h.restore))).Methods(http.MethodPost)
+	demoRestrictedRouter.Handle("/backups", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.backupList)))).Methods(http.MethodGet)
+	demoRestrictedRouter.Handle("/backups/{name}/restore", bouncer.RestrictedAccess(adminAccess(httperror.LoggerHandler(h.backupRestore)))).Methods(http.MethodPost)
 
 	return h
 }
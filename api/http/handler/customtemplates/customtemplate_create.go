@@ -12,6 +12,7 @@ import (
 	"github.com/asaskevich/govalidator"
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/git"
 	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	"github.com/cloudogu/portainer-ce/api/internal/authorization"
@@ -209,6 +210,8 @@ type customTemplateFromGitRepositoryPayload struct {
 	RepositoryUsername string `example:"myGitUsername"`
 	// Password used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Identifier of a saved git credential used instead of the username and password
+	RepositoryGitCredentialID int `example:"1"`
 	// Path to the Stack file inside the Git repository
 	ComposeFilePathInRepository string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// Definitions of variables in the stack file
@@ -222,10 +225,10 @@ func (payload *customTemplateFromGitRepositoryPayload) Validate(r *http.Request)
 	if govalidator.IsNull(payload.Description) {
 		return errors.New("Invalid custom template description")
 	}
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if govalidator.IsNull(payload.RepositoryURL) || !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && (govalidator.IsNull(payload.RepositoryUsername) || govalidator.IsNull(payload.RepositoryPassword)) {
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID == 0 && (govalidator.IsNull(payload.RepositoryUsername) || govalidator.IsNull(payload.RepositoryPassword)) {
 		return errors.New("Invalid repository credentials. Username and password must be specified when authentication is enabled")
 	}
 	if govalidator.IsNull(payload.ComposeFilePathInRepository) {
@@ -272,11 +275,32 @@ func (handler *Handler) createCustomTemplateFromGitRepository(r *http.Request) (
 	projectPath := handler.FileService.GetCustomTemplateProjectPath(strconv.Itoa(customTemplateID))
 	customTemplate.ProjectPath = projectPath
 
-	repositoryUsername := payload.RepositoryUsername
-	repositoryPassword := payload.RepositoryPassword
-	if !payload.RepositoryAuthentication {
-		repositoryUsername = ""
-		repositoryPassword = ""
+	repositoryUsername := ""
+	repositoryPassword := ""
+	if payload.RepositoryAuthentication {
+		auth := &gittypes.GitAuthentication{
+			Username: payload.RepositoryUsername,
+			Password: payload.RepositoryPassword,
+		}
+
+		if payload.RepositoryGitCredentialID != 0 {
+			tokenData, err := security.RetrieveTokenData(r)
+			if err != nil {
+				return nil, err
+			}
+
+			err = git.CheckCredentialAccess(payload.RepositoryGitCredentialID, tokenData.ID, handler.DataStore.GitCredential())
+			if err != nil {
+				return nil, err
+			}
+
+			auth = &gittypes.GitAuthentication{GitCredentialID: payload.RepositoryGitCredentialID}
+		}
+
+		repositoryUsername, repositoryPassword, err = git.GetCredentials(auth, payload.RepositoryURL, handler.DataStore.GitCredential())
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	err = handler.GitService.CloneRepository(projectPath, payload.RepositoryURL, payload.RepositoryReferenceName, repositoryUsername, repositoryPassword)
@@ -7,6 +7,7 @@ import (
 	"github.com/asaskevich/govalidator"
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/git"
 	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	"github.com/pkg/errors"
@@ -207,7 +208,7 @@ func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) err
 	if govalidator.IsNull(payload.Name) {
 		return &InvalidPayloadError{msg: "Invalid stack name"}
 	}
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if govalidator.IsNull(payload.RepositoryURL) || !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return &InvalidPayloadError{msg: "Invalid repository URL. Must correspond to a valid URL format"}
 	}
 	if payload.RepositoryAuthentication && (govalidator.IsNull(payload.RepositoryUsername) || govalidator.IsNull(payload.RepositoryPassword)) {
@@ -2,7 +2,9 @@ package edgestacks
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/portainer/libhttp/request"
@@ -100,5 +102,16 @@ func (handler *Handler) edgeStackStatusUpdate(w http.ResponseWriter, r *http.Req
 		return handler.handlerDBErr(err, "Unable to persist the stack changes inside the database")
 	}
 
+	if *payload.Status == portainer.EdgeStackStatusError && handler.NotificationService != nil {
+		handler.NotificationService.Notify(portainer.NotificationEvent{
+			Type:         portainer.NotificationEventEdgeStackError,
+			Title:        fmt.Sprintf("Edge stack %s failed on %s", stack.Name, endpoint.Name),
+			Message:      payload.Error,
+			EndpointID:   endpoint.ID,
+			ResourceID:   strconv.Itoa(stackID),
+			ResourceName: stack.Name,
+		})
+	}
+
 	return response.JSON(w, stack)
 }
@@ -17,12 +17,13 @@ import (
 // Handler is the HTTP handler used to handle environment(endpoint) group operations.
 type Handler struct {
 	*mux.Router
-	requestBouncer     *security.RequestBouncer
-	DataStore          dataservices.DataStore
-	FileService        portainer.FileService
-	GitService         portainer.GitService
-	edgeStacksService  *edgestackservice.Service
-	KubernetesDeployer portainer.KubernetesDeployer
+	requestBouncer      *security.RequestBouncer
+	DataStore           dataservices.DataStore
+	FileService         portainer.FileService
+	GitService          portainer.GitService
+	edgeStacksService   *edgestackservice.Service
+	KubernetesDeployer  portainer.KubernetesDeployer
+	NotificationService portainer.NotificationService
 }
 
 // NewHandler creates a handler to manage environment(endpoint) group operations.
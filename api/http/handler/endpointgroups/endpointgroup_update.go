@@ -3,8 +3,10 @@ package endpointgroups
 import (
 	"net/http"
 	"reflect"
+	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/internal/accessgrant"
 	"github.com/cloudogu/portainer-ce/api/internal/tag"
 	httperror "github.com/portainer/libhttp/error"
 	"github.com/portainer/libhttp/request"
@@ -29,6 +31,8 @@ func (payload *endpointGroupUpdatePayload) Validate(r *http.Request) error {
 // @id EndpointGroupUpdate
 // @summary Update an environment(endpoint) group
 // @description Update an environment(endpoint) group.
+// @description An access policy with an expiry is revoked automatically once it expires.
+// @description An access policy sent without expiry keeps the expiry of the current policy unless it is sent as permanent.
 // @description **Access policy**: administrator
 // @tags endpoint_groups
 // @security ApiKeyAuth
@@ -107,6 +111,16 @@ func (handler *Handler) endpointGroupUpdate(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
+	err = accessgrant.PreparePolicies(payload.UserAccessPolicies, endpointGroup.UserAccessPolicies, payload.TeamAccessPolicies, endpointGroup.TeamAccessPolicies, time.Now())
+	if err != nil {
+		return httperror.BadRequest("Invalid access policies", err)
+	}
+
+	err = handler.AuthorizationService.ValidateAccessPolicyRoles(payload.UserAccessPolicies, payload.TeamAccessPolicies)
+	if err != nil {
+		return httperror.BadRequest("Invalid access policies", err)
+	}
+
 	updateAuthorizations := false
 	if payload.UserAccessPolicies != nil && !reflect.DeepEqual(payload.UserAccessPolicies, endpointGroup.UserAccessPolicies) {
 		endpointGroup.UserAccessPolicies = payload.UserAccessPolicies
@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/http/middlewares"
 	httperror "github.com/portainer/libhttp/error"
 	"github.com/portainer/libhttp/request"
 
@@ -28,6 +29,8 @@ func (handler *Handler) proxyRequestsToAzureAPI(w http.ResponseWriter, r *http.R
 		return httperror.Forbidden("Permission denied to access environment", err)
 	}
 
+	middlewares.SetProxiedEndpoint(r, endpoint.ID)
+
 	var proxy http.Handler
 	proxy = handler.ProxyManager.GetEndpointProxy(endpoint)
 	if proxy == nil {
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/http/middlewares"
 	httperror "github.com/portainer/libhttp/error"
 	"github.com/portainer/libhttp/request"
 )
@@ -29,6 +30,8 @@ func (handler *Handler) proxyRequestsToKubernetesAPI(w http.ResponseWriter, r *h
 		return httperror.Forbidden("Permission denied to access environment", err)
 	}
 
+	middlewares.SetProxiedEndpoint(r, endpoint.ID)
+
 	if endpoint.Type == portainer.EdgeAgentOnKubernetesEnvironment {
 		if endpoint.EdgeID == "" {
 			return httperror.InternalServerError("No Edge agent registered with the environment", errors.New("No agent available"))
@@ -1,6 +1,7 @@
 package endpoints
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"net/http"
@@ -298,7 +299,7 @@ func (handler *Handler) createEndpoint(payload *endpointCreatePayload) (*portain
 			}
 		}
 
-		agentPlatform, version, err := agent.GetAgentVersionAndPlatform(payload.URL, tlsConfig)
+		agentPlatform, version, err := agent.GetAgentVersionAndPlatform(context.Background(), payload.URL, tlsConfig)
 		if err != nil {
 			return nil, httperror.InternalServerError("Unable to get environment type", err)
 		}
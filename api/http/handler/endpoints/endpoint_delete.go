@@ -55,6 +55,11 @@ func (handler *Handler) endpointDelete(w http.ResponseWriter, r *http.Request) *
 		return httperror.InternalServerError("Unable to remove the snapshot from the database", err)
 	}
 
+	err = handler.DataStore.SnapshotHistory().DeleteRollups(portainer.EndpointID(endpointID))
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the snapshot history from the database", err)
+	}
+
 	err = handler.DataStore.Endpoint().DeleteEndpoint(portainer.EndpointID(endpointID))
 	if err != nil {
 		return httperror.InternalServerError("Unable to remove environment from the database", err)
@@ -54,6 +54,7 @@ func (handler *Handler) endpointSnapshot(w http.ResponseWriter, r *http.Request)
 	}
 
 	latestEndpointReference.Agent.Version = endpoint.Agent.Version
+	latestEndpointReference.SnapshotStatus = endpoint.SnapshotStatus
 
 	err = handler.DataStore.Endpoint().UpdateEndpoint(latestEndpointReference.ID, latestEndpointReference)
 	if err != nil {
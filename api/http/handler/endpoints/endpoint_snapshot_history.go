@@ -0,0 +1,75 @@
+package endpoints
+
+import (
+	"net/http"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+const defaultSnapshotHistoryRange = 24 * time.Hour
+
+// @id EndpointSnapshotHistory
+// @summary Retrieve the snapshot history of an environment(endpoint)
+// @description Retrieve the hourly rollups of the snapshots of an environment(endpoint), oldest first.
+// @description The rollups are kept for 30 days. When no range is specified, the last 24 hours are returned.
+// @description **Access policy**: restricted
+// @tags endpoints
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Environment(Endpoint) identifier"
+// @param from query int false "Start of the range, as a unix timestamp"
+// @param to query int false "End of the range, as a unix timestamp"
+// @success 200 {array} portainer.SnapshotRollup "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Environment(Endpoint) not found"
+// @failure 500 "Server error"
+// @router /endpoints/{id}/snapshots/history [get]
+func (handler *Handler) endpointSnapshotHistory(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	endpointID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid environment identifier route variable", err)
+	}
+
+	from, err := request.RetrieveNumericQueryParameter(r, "from", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: from", err)
+	}
+
+	to, err := request.RetrieveNumericQueryParameter(r, "to", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: to", err)
+	}
+
+	if from == 0 && to == 0 {
+		from = int(time.Now().Add(-defaultSnapshotHistoryRange).Unix())
+	}
+
+	if to != 0 && from > to {
+		return httperror.BadRequest("Invalid range: from must be before to", nil)
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(portainer.EndpointID(endpointID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find an environment with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find an environment with the specified identifier inside the database", err)
+	}
+
+	err = handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
+	if err != nil {
+		return httperror.Forbidden("Permission denied to access environment", err)
+	}
+
+	rollups, err := handler.DataStore.SnapshotHistory().Rollups(endpoint.ID, int64(from), int64(to))
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the snapshot history from the database", err)
+	}
+
+	return response.JSON(w, rollups)
+}
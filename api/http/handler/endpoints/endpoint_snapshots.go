@@ -61,6 +61,7 @@ func (handler *Handler) endpointSnapshots(w http.ResponseWriter, r *http.Request
 		}
 
 		latestEndpointReference.Agent.Version = endpoint.Agent.Version
+		latestEndpointReference.SnapshotStatus = endpoint.SnapshotStatus
 
 		err = handler.DataStore.Endpoint().UpdateEndpoint(latestEndpointReference.ID, latestEndpointReference)
 		if err != nil {
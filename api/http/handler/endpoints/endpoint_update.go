@@ -4,9 +4,11 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/http/client"
+	"github.com/cloudogu/portainer-ce/api/internal/accessgrant"
 	"github.com/cloudogu/portainer-ce/api/internal/edge"
 	"github.com/cloudogu/portainer-ce/api/internal/tag"
 	httperror "github.com/portainer/libhttp/error"
@@ -57,6 +59,8 @@ func (payload *endpointUpdatePayload) Validate(r *http.Request) error {
 // @id EndpointUpdate
 // @summary Update an environment(endpoint)
 // @description Update an environment(endpoint).
+// @description An access policy with an expiry is revoked automatically once it expires.
+// @description An access policy sent without expiry keeps the expiry of the current policy unless it is sent as permanent.
 // @description **Access policy**: authenticated
 // @security ApiKeyAuth
 // @security jwt
@@ -176,6 +180,16 @@ func (handler *Handler) endpointUpdate(w http.ResponseWriter, r *http.Request) *
 		endpoint.Kubernetes = *payload.Kubernetes
 	}
 
+	err = accessgrant.PreparePolicies(payload.UserAccessPolicies, endpoint.UserAccessPolicies, payload.TeamAccessPolicies, endpoint.TeamAccessPolicies, time.Now())
+	if err != nil {
+		return httperror.BadRequest("Invalid access policies", err)
+	}
+
+	err = handler.AuthorizationService.ValidateAccessPolicyRoles(payload.UserAccessPolicies, payload.TeamAccessPolicies)
+	if err != nil {
+		return httperror.BadRequest("Invalid access policies", err)
+	}
+
 	if payload.UserAccessPolicies != nil && !reflect.DeepEqual(payload.UserAccessPolicies, endpoint.UserAccessPolicies) {
 		updateAuthorizations = true
 		endpoint.UserAccessPolicies = payload.UserAccessPolicies
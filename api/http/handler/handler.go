@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cloudogu/portainer-ce/api/http/handler/auditlogs"
 	"github.com/cloudogu/portainer-ce/api/http/handler/auth"
 	"github.com/cloudogu/portainer-ce/api/http/handler/backup"
 	"github.com/cloudogu/portainer-ce/api/http/handler/customtemplates"
@@ -22,7 +23,9 @@ import (
 	"github.com/cloudogu/portainer-ce/api/http/handler/hostmanagement/openamt"
 	"github.com/cloudogu/portainer-ce/api/http/handler/kubernetes"
 	"github.com/cloudogu/portainer-ce/api/http/handler/ldap"
+	"github.com/cloudogu/portainer-ce/api/http/handler/metrics"
 	"github.com/cloudogu/portainer-ce/api/http/handler/motd"
+	"github.com/cloudogu/portainer-ce/api/http/handler/notifications"
 	"github.com/cloudogu/portainer-ce/api/http/handler/registries"
 	"github.com/cloudogu/portainer-ce/api/http/handler/resourcecontrols"
 	"github.com/cloudogu/portainer-ce/api/http/handler/roles"
@@ -43,6 +46,7 @@ import (
 
 // Handler is a collection of all the service handlers.
 type Handler struct {
+	AuditLogHandler        *auditlogs.Handler
 	AuthHandler            *auth.Handler
 	BackupHandler          *backup.Handler
 	CustomTemplatesHandler *customtemplates.Handler
@@ -60,7 +64,9 @@ type Handler struct {
 	KubernetesHandler      *kubernetes.Handler
 	FileHandler            *file.Handler
 	LDAPHandler            *ldap.Handler
+	MetricsHandler         *metrics.Handler
 	MOTDHandler            *motd.Handler
+	NotificationHandler    *notifications.Handler
 	RegistryHandler        *registries.Handler
 	ResourceControlHandler *resourcecontrols.Handler
 	RoleHandler            *roles.Handler
@@ -103,6 +109,8 @@ type Handler struct {
 // @in header
 // @name Authorization
 
+// @tag.name audit_logs
+// @tag.description Browse and export the audit trail of mutating operations
 // @tag.name auth
 // @tag.description Authenticate against Portainer HTTP API
 // @tag.name custom_templates
@@ -123,8 +131,12 @@ type Handler struct {
 // @tag.description Manage environment(endpoint) groups
 // @tag.name kubernetes
 // @tag.description Manage Kubernetes cluster
+// @tag.name metrics
+// @tag.description Monitor the Portainer server
 // @tag.name motd
 // @tag.description Fetch the message of the day
+// @tag.name notifications
+// @tag.description Manage the channels receiving event notifications
 // @tag.name registries
 // @tag.description Manage Docker registries
 // @tag.name resource_controls
@@ -163,6 +175,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	switch {
 	case strings.HasPrefix(r.URL.Path, "/api/endpoints") && strings.Contains(r.URL.Path, "/edge/"):
 		h.EndpointEdgeHandler.ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/audit_logs"):
+		http.StripPrefix("/api", h.AuditLogHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/auth"):
 		http.StripPrefix("/api", h.AuthHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/backup"):
@@ -205,8 +219,12 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 	case strings.HasPrefix(r.URL.Path, "/api/ldap"):
 		http.StripPrefix("/api", h.LDAPHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/metrics"):
+		http.StripPrefix("/api", h.MetricsHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/motd"):
 		http.StripPrefix("/api", h.MOTDHandler).ServeHTTP(w, r)
+	case strings.HasPrefix(r.URL.Path, "/api/notifications"):
+		http.StripPrefix("/api", h.NotificationHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/registries"):
 		http.StripPrefix("/api", h.RegistryHandler).ServeHTTP(w, r)
 	case strings.HasPrefix(r.URL.Path, "/api/resource_controls"):
@@ -51,6 +51,18 @@ func NewHandler(bouncer requestBouncer, dataStore dataservices.DataStore, jwtSer
 	h.Handle("/{id}/kubernetes/helm/{release}",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.helmDelete))).Methods(http.MethodDelete)
 
+	// `helm upgrade RELEASE_NAME [CHART] flags`
+	h.Handle("/{id}/kubernetes/helm/{release}",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.helmUpgrade))).Methods(http.MethodPut)
+
+	// `helm rollback RELEASE_NAME [REVISION]`
+	h.Handle("/{id}/kubernetes/helm/{release}/rollback",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.helmRollback))).Methods(http.MethodPost)
+
+	// `helm history RELEASE_NAME -o json`
+	h.Handle("/{id}/kubernetes/helm/{release}/history",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.helmHistory))).Methods(http.MethodGet)
+
 	// `helm install [NAME] [CHART] flags`
 	h.Handle("/{id}/kubernetes/helm",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.helmInstall))).Methods(http.MethodPost)
@@ -107,21 +107,12 @@ func (handler *Handler) installChart(r *http.Request, p installChartPayload) (*r
 	}
 
 	if p.Values != "" {
-		file, err := os.CreateTemp("", "helm-values")
+		valuesFile, err := createValuesFile(p.Values)
 		if err != nil {
 			return nil, err
 		}
-		defer os.Remove(file.Name())
-		_, err = file.WriteString(p.Values)
-		if err != nil {
-			file.Close()
-			return nil, err
-		}
-		err = file.Close()
-		if err != nil {
-			return nil, err
-		}
-		installOpts.ValuesFile = file.Name()
+		defer os.Remove(valuesFile)
+		installOpts.ValuesFile = valuesFile
 	}
 
 	release, err := handler.helmPackageManager.Install(installOpts)
@@ -129,7 +120,7 @@ func (handler *Handler) installChart(r *http.Request, p installChartPayload) (*r
 		return nil, err
 	}
 
-	manifest, err := handler.applyPortainerLabelsToHelmAppManifest(r, installOpts, release.Manifest)
+	manifest, err := handler.applyPortainerLabelsToHelmAppManifest(r, installOpts.Name, release.Manifest)
 	if err != nil {
 		return nil, err
 	}
@@ -142,10 +133,34 @@ func (handler *Handler) installChart(r *http.Request, p installChartPayload) (*r
 	return release, nil
 }
 
+// createValuesFile writes the provided values into a temporary file which can be passed to helm.
+// The caller is responsible for removing the file.
+func createValuesFile(values string) (string, error) {
+	file, err := os.CreateTemp("", "helm-values")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = file.WriteString(values)
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	err = file.Close()
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+
+	return file.Name(), nil
+}
+
 // applyPortainerLabelsToHelmAppManifest will patch all the resources deployed in the helm release manifest
 // with portainer specific labels. This is to mark the resources as managed by portainer - hence the helm apps
 // wont appear external in the portainer UI.
-func (handler *Handler) applyPortainerLabelsToHelmAppManifest(r *http.Request, installOpts options.InstallOptions, manifest string) ([]byte, error) {
+func (handler *Handler) applyPortainerLabelsToHelmAppManifest(r *http.Request, releaseName string, manifest string) ([]byte, error) {
 	// Patch helm release by adding with portainer labels to all deployed resources
 	tokenData, err := security.RetrieveTokenData(r)
 	if err != nil {
@@ -156,7 +171,7 @@ func (handler *Handler) applyPortainerLabelsToHelmAppManifest(r *http.Request, i
 		return nil, errors.Wrap(err, "unable to load user information from the database")
 	}
 
-	appLabels := kubernetes.GetHelmAppLabels(installOpts.Name, user.Username)
+	appLabels := kubernetes.GetHelmAppLabels(releaseName, user.Username)
 	labeledManifest, err := kubernetes.AddAppLabels([]byte(manifest), appLabels)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to label helm release manifest")
@@ -0,0 +1,74 @@
+package helm
+
+import (
+	"errors"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	"github.com/portainer/portainer/pkg/libhelm/options"
+)
+
+type rollbackPayload struct {
+	Namespace string `json:"namespace"`
+	// Revision to roll back to, the previous revision is used when 0
+	Revision int `json:"revision"`
+}
+
+func (p *rollbackPayload) Validate(_ *http.Request) error {
+	if p.Revision < 0 {
+		return errors.New("invalid revision, must be a positive number")
+	}
+
+	return nil
+}
+
+// @id HelmRollback
+// @summary Rollback Helm Release
+// @description Roll back a release to a previous revision.
+// @description **Access policy**: authenticated
+// @tags helm
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @param id path int true "Environment(Endpoint) identifier"
+// @param release path string true "The name of the release/application to roll back"
+// @param payload body rollbackPayload true "Rollback details"
+// @success 204 "Success"
+// @failure 400 "Invalid request payload"
+// @failure 401 "Unauthorized"
+// @failure 404 "Environment(Endpoint) or ServiceAccount not found"
+// @failure 500 "Server error or helm error"
+// @router /endpoints/{id}/kubernetes/helm/{release}/rollback [post]
+func (handler *Handler) helmRollback(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	release, err := request.RetrieveRouteVariableValue(r, "release")
+	if err != nil {
+		return httperror.BadRequest("No release specified", err)
+	}
+
+	var payload rollbackPayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid Helm rollback payload", err)
+	}
+
+	clusterAccess, httperr := handler.getHelmClusterAccess(r)
+	if httperr != nil {
+		return httperr
+	}
+
+	rollbackOpts := options.RollbackOptions{
+		Name:                    release,
+		Namespace:               payload.Namespace,
+		Revision:                payload.Revision,
+		KubernetesClusterAccess: clusterAccess,
+	}
+
+	err = handler.helmPackageManager.Rollback(rollbackOpts)
+	if err != nil {
+		return httperror.InternalServerError("Helm returned an error", err)
+	}
+
+	return response.Empty(w)
+}
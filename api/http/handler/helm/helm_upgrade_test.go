@@ -0,0 +1,116 @@
+package helm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/exec/exectest"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	helper "github.com/cloudogu/portainer-ce/api/internal/testhelpers"
+	"github.com/cloudogu/portainer-ce/api/jwt"
+	"github.com/cloudogu/portainer-ce/api/kubernetes"
+	"github.com/portainer/portainer/pkg/libhelm/binary/test"
+	"github.com/portainer/portainer/pkg/libhelm/options"
+	"github.com/portainer/portainer/pkg/libhelm/release"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_helmUpgrade(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	err := store.Endpoint().Create(&portainer.Endpoint{ID: 1})
+	is.NoError(err, "error creating environment")
+
+	err = store.User().Create(&portainer.User{Username: "admin", Role: portainer.AdministratorRole})
+	is.NoError(err, "error creating a user")
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+
+	kubernetesDeployer := exectest.NewKubernetesDeployer()
+	helmPackageManager := test.NewMockHelmBinaryPackageManager("")
+	kubeClusterAccessService := kubernetes.NewKubeClusterAccessService("", "", "")
+	h := NewHandler(helper.NewTestRequestBouncer(), store, jwtService, kubernetesDeployer, helmPackageManager, kubeClusterAccessService)
+
+	is.NotNil(h, "Handler should not fail")
+
+	// Install a single chart directly, to be upgraded by the handler
+	installOpts := options.InstallOptions{Name: "nginx-upgrade", Chart: "nginx", Namespace: "default"}
+	h.helmPackageManager.Install(installOpts)
+
+	newRequest := func(method, url string, body io.Reader) *http.Request {
+		req := httptest.NewRequest(method, url, body)
+		ctx := security.StoreTokenData(req, &portainer.TokenData{ID: 1, Username: "admin", Role: 1})
+		req = req.WithContext(ctx)
+		req.Header.Add("Authorization", "Bearer dummytoken")
+		return req
+	}
+
+	t.Run("helmUpgrade fails without a repo", func(t *testing.T) {
+		payload, err := json.Marshal(upgradeChartPayload{Namespace: "default", Chart: "nginx"})
+		is.NoError(err)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, newRequest(http.MethodPut, fmt.Sprintf("/1/kubernetes/helm/%s", installOpts.Name), bytes.NewBuffer(payload)))
+
+		is.Equal(http.StatusBadRequest, rr.Code, "Status should be 400")
+	})
+
+	t.Run("helmUpgrade succeeds with admin user", func(t *testing.T) {
+		payload, err := json.Marshal(upgradeChartPayload{Namespace: "default", Chart: "nginx", Repo: "https://charts.bitnami.com/bitnami", Version: "13.2.0"})
+		is.NoError(err)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, newRequest(http.MethodPut, fmt.Sprintf("/1/kubernetes/helm/%s", installOpts.Name), bytes.NewBuffer(payload)))
+
+		is.Equal(http.StatusOK, rr.Code, "Status should be 200")
+
+		resp := release.Release{}
+		err = json.NewDecoder(rr.Body).Decode(&resp)
+		is.NoError(err, "response should be json")
+		is.EqualValues(installOpts.Name, resp.Name, "Name doesn't match")
+		is.EqualValues(2, resp.Version, "Revision should be incremented")
+	})
+
+	t.Run("helmHistory lists every revision", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, newRequest(http.MethodGet, fmt.Sprintf("/1/kubernetes/helm/%s/history?namespace=default", installOpts.Name), nil))
+
+		is.Equal(http.StatusOK, rr.Code, "Status should be 200")
+
+		history := []release.ReleaseHistoryElement{}
+		err = json.NewDecoder(rr.Body).Decode(&history)
+		is.NoError(err, "response should be json")
+		is.Len(history, 2, "history should contain two revisions")
+	})
+
+	t.Run("helmRollback succeeds with admin user", func(t *testing.T) {
+		payload, err := json.Marshal(rollbackPayload{Namespace: "default", Revision: 1})
+		is.NoError(err)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, newRequest(http.MethodPost, fmt.Sprintf("/1/kubernetes/helm/%s/rollback", installOpts.Name), bytes.NewBuffer(payload)))
+
+		is.Equal(http.StatusNoContent, rr.Code, "Status should be 204")
+	})
+
+	t.Run("helmRollback fails with a negative revision", func(t *testing.T) {
+		payload, err := json.Marshal(rollbackPayload{Namespace: "default", Revision: -1})
+		is.NoError(err)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, newRequest(http.MethodPost, fmt.Sprintf("/1/kubernetes/helm/%s/rollback", installOpts.Name), bytes.NewBuffer(payload)))
+
+		is.Equal(http.StatusBadRequest, rr.Code, "Status should be 400")
+	})
+}
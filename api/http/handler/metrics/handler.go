@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/gorilla/mux"
+	httperror "github.com/portainer/libhttp/error"
+)
+
+// Handler is the HTTP handler used to expose the metrics of the Portainer server.
+type Handler struct {
+	*mux.Router
+	DataStore            dataservices.DataStore
+	ReverseTunnelService portainer.ReverseTunnelService
+}
+
+// NewHandler creates a handler to expose the metrics of the Portainer server.
+func NewHandler(bouncer *security.RequestBouncer) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+	h.Handle("/metrics",
+		bouncer.PublicAccess(httperror.LoggerHandler(h.metricsInspect))).Methods(http.MethodGet)
+	h.Handle("/metrics/token",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.metricsTokenCreate))).Methods(http.MethodPost)
+	h.Handle("/metrics/token",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.metricsTokenDelete))).Methods(http.MethodDelete)
+
+	return h
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudogu/portainer-ce/api/internal/endpointutils"
+	servermetrics "github.com/cloudogu/portainer-ce/api/metrics"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// @id MetricsInspect
+// @summary Retrieve the metrics of the Portainer server
+// @description Retrieve the metrics of the Portainer server in the Prometheus text exposition format.
+// @description **Access policy**: public, the metrics token must be sent as a bearer token
+// @tags metrics
+// @produce plain
+// @success 200 "Success"
+// @failure 401 "Invalid metrics token"
+// @failure 403 "The metrics endpoint is disabled"
+// @failure 500 "Server error"
+// @router /metrics [get]
+func (handler *Handler) metricsInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+	}
+
+	if len(settings.MetricsTokenDigest) == 0 {
+		return httperror.Forbidden("The metrics endpoint is disabled", errors.New("no metrics token was generated"))
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	digest := sha256.Sum256([]byte(token))
+	if token == "" || subtle.ConstantTimeCompare(digest[:], settings.MetricsTokenDigest) != 1 {
+		return &httperror.HandlerError{StatusCode: http.StatusUnauthorized, Message: "Invalid metrics token", Err: errors.New("invalid metrics token")}
+	}
+
+	promhttp.HandlerFor(prometheus.Gatherers{servermetrics.Registry, handler.liveMetrics()}, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+
+	return nil
+}
+
+var (
+	chiselActiveTunnelsDesc = prometheus.NewDesc("portainer_chisel_active_tunnels",
+		"Number of reverse tunnels currently active.", nil, nil)
+
+	edgeCheckInLagDesc = prometheus.NewDesc("portainer_edge_checkin_lag_seconds",
+		"Time elapsed since the last check-in of each edge agent.", []string{"endpoint_id", "endpoint_name"}, nil)
+)
+
+// liveMetrics returns the metrics computed from the current state of the server
+func (handler *Handler) liveMetrics() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(liveCollector{handler})
+
+	return registry
+}
+
+// liveCollector computes the live metrics every time they are collected
+type liveCollector struct {
+	handler *Handler
+}
+
+func (c liveCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- chiselActiveTunnelsDesc
+	ch <- edgeCheckInLagDesc
+}
+
+func (c liveCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.handler.ReverseTunnelService != nil {
+		ch <- prometheus.MustNewConstMetric(chiselActiveTunnelsDesc, prometheus.GaugeValue,
+			float64(c.handler.ReverseTunnelService.ActiveTunnelCount()))
+	}
+
+	c.handler.collectEdgeCheckInLag(ch)
+}
+
+func (handler *Handler) collectEdgeCheckInLag(ch chan<- prometheus.Metric) {
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return
+	}
+
+	now := time.Now().Unix()
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+		if !endpointutils.IsEdgeEndpoint(endpoint) || endpoint.LastCheckInDate == 0 {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(edgeCheckInLagDesc, prometheus.GaugeValue,
+			float64(now-endpoint.LastCheckInDate), strconv.Itoa(int(endpoint.ID)), endpoint.Name)
+	}
+}
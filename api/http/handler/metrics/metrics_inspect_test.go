@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	servermetrics "github.com/cloudogu/portainer-ce/api/metrics"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_metricsInspect(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	err := store.Endpoint().Create(&portainer.Endpoint{
+		ID:              1,
+		Name:            "edge",
+		Type:            portainer.EdgeAgentOnDockerEnvironment,
+		EdgeID:          "edge-id",
+		LastCheckInDate: time.Now().Unix() - 60,
+	})
+	is.NoError(err, "error creating environment")
+
+	servermetrics.HTTPRequestDuration.WithLabelValues("stacks", http.MethodGet, "200").Observe(0.1)
+
+	h := &Handler{DataStore: store}
+
+	scrape := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		httperror.LoggerHandler(h.metricsInspect).ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("is disabled until a token is generated", func(t *testing.T) {
+		is.Equal(http.StatusForbidden, scrape("").Code)
+	})
+
+	rr := httptest.NewRecorder()
+	httperror.LoggerHandler(h.metricsTokenCreate).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/metrics/token", nil))
+	is.Equal(http.StatusOK, rr.Code)
+
+	var tokenResponse metricsTokenResponse
+	is.NoError(json.NewDecoder(rr.Body).Decode(&tokenResponse))
+
+	t.Run("rejects an invalid token", func(t *testing.T) {
+		is.Equal(http.StatusUnauthorized, scrape("").Code)
+		is.Equal(http.StatusUnauthorized, scrape("ptm_invalid").Code)
+	})
+
+	t.Run("exposes the metrics with the token", func(t *testing.T) {
+		rr := scrape(tokenResponse.Token)
+		is.Equal(http.StatusOK, rr.Code)
+		is.Contains(rr.Header().Get("Content-Type"), "text/plain")
+		is.Contains(rr.Body.String(), "# TYPE portainer_http_request_duration_seconds histogram")
+		is.Regexp(`portainer_edge_checkin_lag_seconds\{endpoint_id="1",endpoint_name="edge"\} 6\d`, rr.Body.String())
+	})
+
+	t.Run("is disabled once the token is revoked", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		httperror.LoggerHandler(h.metricsTokenDelete).ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/metrics/token", nil))
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		is.Equal(http.StatusForbidden, scrape(tokenResponse.Token).Code)
+	})
+}
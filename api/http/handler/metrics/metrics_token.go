@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+const metricsTokenPrefix = "ptm_"
+
+type metricsTokenResponse struct {
+	// The token granting access to the metrics endpoint, it is only displayed once
+	Token string `json:"token" example:"ptm_8lBE3nuCwD0pWpOzQnEOc6z0jHt9rlF6ndzgQn6bpYA="`
+}
+
+// @id MetricsTokenCreate
+// @summary Generate the metrics token
+// @description Generate the token granting access to the metrics endpoint, the previous token is revoked.
+// @description The token is only returned once.
+// @description **Access policy**: administrator
+// @tags metrics
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {object} metricsTokenResponse "Success"
+// @failure 500 "Server error"
+// @router /metrics/token [post]
+func (handler *Handler) metricsTokenCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	randomKey := make([]byte, 32)
+	_, err := rand.Read(randomKey)
+	if err != nil {
+		return httperror.InternalServerError("Unable to generate the metrics token", err)
+	}
+
+	token := metricsTokenPrefix + base64.StdEncoding.EncodeToString(randomKey)
+	digest := sha256.Sum256([]byte(token))
+
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+	}
+
+	settings.MetricsTokenDigest = digest[:]
+
+	err = handler.DataStore.Settings().UpdateSettings(settings)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the settings changes inside the database", err)
+	}
+
+	return response.JSON(w, metricsTokenResponse{Token: token})
+}
+
+// @id MetricsTokenDelete
+// @summary Revoke the metrics token
+// @description Revoke the token granting access to the metrics endpoint, which disables the endpoint.
+// @description **Access policy**: administrator
+// @tags metrics
+// @security ApiKeyAuth
+// @security jwt
+// @success 204 "Success"
+// @failure 500 "Server error"
+// @router /metrics/token [delete]
+func (handler *Handler) metricsTokenDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the settings from the database", err)
+	}
+
+	settings.MetricsTokenDigest = nil
+
+	err = handler.DataStore.Settings().UpdateSettings(settings)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the settings changes inside the database", err)
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type channelCreatePayload struct {
+	// Name of the notification channel
+	Name string `validate:"required" example:"ops-alerts"`
+	// Format of the messages sent to the channel. Valid values are: 1 (webhook), 2 (slack) or 3 (teams)
+	Type portainer.NotificationChannelType `validate:"required" example:"1" enums:"1,2,3"`
+	// URL the events are posted to
+	URL string `validate:"required" example:"https://hooks.example.com/portainer"`
+	// Secret used to sign the payloads sent to a generic webhook
+	Secret string `example:"s3cr3t"`
+	// Types of the events sent to the channel
+	EventTypes []portainer.NotificationEventType `example:"endpoint.down"`
+	// Whether events are sent to the channel
+	Enabled bool `example:"true"`
+}
+
+func (payload *channelCreatePayload) Validate(r *http.Request) error {
+	return validateChannel(payload.Name, payload.Type, payload.URL, payload.EventTypes)
+}
+
+// @id NotificationChannelCreate
+// @summary Create a notification channel
+// @description Create a notification channel receiving the events it is subscribed to.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body channelCreatePayload true "Notification channel details"
+// @success 200 {object} portainer.NotificationChannel "Success"
+// @failure 400 "Invalid request"
+// @failure 500 "Server error"
+// @router /notifications/channels [post]
+func (handler *Handler) channelCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload channelCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	eventTypes := payload.EventTypes
+	if eventTypes == nil {
+		eventTypes = []portainer.NotificationEventType{}
+	}
+
+	channel := &portainer.NotificationChannel{
+		Name:       payload.Name,
+		Type:       payload.Type,
+		URL:        payload.URL,
+		Secret:     payload.Secret,
+		EventTypes: eventTypes,
+		Enabled:    payload.Enabled,
+	}
+
+	err = handler.DataStore.NotificationChannel().Create(channel)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the notification channel inside the database", err)
+	}
+
+	hideFields(channel)
+	return response.JSON(w, channel)
+}
@@ -0,0 +1,40 @@
+package notifications
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id NotificationChannelDelete
+// @summary Remove a notification channel
+// @description Remove a notification channel and its delivery log.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Notification channel identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Notification channel not found"
+// @failure 500 "Server error"
+// @router /notifications/channels/{id} [delete]
+func (handler *Handler) channelDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channel, httpErr := handler.channelFromRequest(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	err := handler.DataStore.NotificationChannel().DeleteNotificationChannel(channel.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the notification channel from the database", err)
+	}
+
+	err = handler.DataStore.NotificationDelivery().DeleteNotificationDeliveriesByChannelID(channel.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the notification deliveries from the database", err)
+	}
+
+	return response.Empty(w)
+}
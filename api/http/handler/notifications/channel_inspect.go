@@ -0,0 +1,49 @@
+package notifications
+
+import (
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id NotificationChannelInspect
+// @summary Inspect a notification channel
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Notification channel identifier"
+// @success 200 {object} portainer.NotificationChannel "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Notification channel not found"
+// @failure 500 "Server error"
+// @router /notifications/channels/{id} [get]
+func (handler *Handler) channelInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channel, httpErr := handler.channelFromRequest(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	hideFields(channel)
+	return response.JSON(w, channel)
+}
+
+func (handler *Handler) channelFromRequest(r *http.Request) (*portainer.NotificationChannel, *httperror.HandlerError) {
+	channelID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid notification channel identifier route variable", err)
+	}
+
+	channel, err := handler.DataStore.NotificationChannel().NotificationChannel(portainer.NotificationChannelID(channelID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a notification channel with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a notification channel with the specified identifier inside the database", err)
+	}
+
+	return channel, nil
+}
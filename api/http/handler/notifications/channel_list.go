@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id NotificationChannelList
+// @summary List notification channels
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @success 200 {array} portainer.NotificationChannel "Success"
+// @failure 500 "Server error"
+// @router /notifications/channels [get]
+func (handler *Handler) channelList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channels, err := handler.DataStore.NotificationChannel().NotificationChannels()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve notification channels from the database", err)
+	}
+
+	for i := range channels {
+		hideFields(&channels[i])
+	}
+
+	return response.JSON(w, channels)
+}
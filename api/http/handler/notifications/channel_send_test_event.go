@@ -0,0 +1,45 @@
+package notifications
+
+import (
+	"net/http"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id NotificationChannelTest
+// @summary Send a test event to a notification channel
+// @description Send a test event to a notification channel, whether it is enabled or not, and return the delivery outcome.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Notification channel identifier"
+// @success 200 {object} portainer.NotificationDelivery "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Notification channel not found"
+// @failure 500 "Server error"
+// @router /notifications/channels/{id}/test [post]
+func (handler *Handler) channelTest(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	channel, httpErr := handler.channelFromRequest(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	eventType := portainer.NotificationEventEndpointDown
+	if len(channel.EventTypes) > 0 {
+		eventType = channel.EventTypes[0]
+	}
+
+	delivery := handler.NotificationService.Send(channel, portainer.NotificationEvent{
+		Type:      eventType,
+		Timestamp: time.Now().Unix(),
+		Title:     "Portainer test notification",
+		Message:   "This is a test notification sent from Portainer to the channel " + channel.Name,
+	})
+
+	return response.JSON(w, delivery)
+}
@@ -0,0 +1,94 @@
+package notifications
+
+import (
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type channelUpdatePayload struct {
+	// Name of the notification channel
+	Name *string `example:"ops-alerts"`
+	// Format of the messages sent to the channel. Valid values are: 1 (webhook), 2 (slack) or 3 (teams)
+	Type *portainer.NotificationChannelType `example:"1" enums:"1,2,3"`
+	// URL the events are posted to
+	URL *string `example:"https://hooks.example.com/portainer"`
+	// Secret used to sign the payloads sent to a generic webhook, the current secret is kept when omitted
+	Secret *string `example:"s3cr3t"`
+	// Types of the events sent to the channel
+	EventTypes []portainer.NotificationEventType `example:"endpoint.down"`
+	// Whether events are sent to the channel
+	Enabled *bool `example:"true"`
+}
+
+func (payload *channelUpdatePayload) Validate(r *http.Request) error {
+	return nil
+}
+
+// @id NotificationChannelUpdate
+// @summary Update a notification channel
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Notification channel identifier"
+// @param body body channelUpdatePayload true "Notification channel details"
+// @success 200 {object} portainer.NotificationChannel "Success"
+// @failure 400 "Invalid request"
+// @failure 404 "Notification channel not found"
+// @failure 500 "Server error"
+// @router /notifications/channels/{id} [put]
+func (handler *Handler) channelUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload channelUpdatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	channel, httpErr := handler.channelFromRequest(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if payload.Name != nil {
+		channel.Name = *payload.Name
+	}
+
+	if payload.Type != nil {
+		channel.Type = *payload.Type
+	}
+
+	if payload.URL != nil {
+		channel.URL = *payload.URL
+	}
+
+	if payload.Secret != nil {
+		channel.Secret = *payload.Secret
+	}
+
+	if payload.EventTypes != nil {
+		channel.EventTypes = payload.EventTypes
+	}
+
+	if payload.Enabled != nil {
+		channel.Enabled = *payload.Enabled
+	}
+
+	err = validateChannel(channel.Name, channel.Type, channel.URL, channel.EventTypes)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	err = handler.DataStore.NotificationChannel().UpdateNotificationChannel(channel.ID, channel)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the notification channel changes inside the database", err)
+	}
+
+	hideFields(channel)
+	return response.JSON(w, channel)
+}
@@ -0,0 +1,112 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/gorilla/mux"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_channelUpdate_keepsSecretWhenOmitted(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	channel := &portainer.NotificationChannel{
+		Name:       "ops",
+		Type:       portainer.NotificationChannelWebhook,
+		URL:        "https://hooks.example.com/portainer",
+		Secret:     "secret",
+		EventTypes: []portainer.NotificationEventType{portainer.NotificationEventEndpointDown},
+		Enabled:    true,
+	}
+	is.NoError(store.NotificationChannel().Create(channel))
+
+	h := &Handler{DataStore: store}
+
+	body, _ := json.Marshal(map[string]interface{}{"Name": "ops-alerts", "Enabled": false})
+	r := httptest.NewRequest(http.MethodPut, "/notifications/channels/1", bytes.NewReader(body))
+	r = mux.SetURLVars(r, map[string]string{"id": "1"})
+	w := httptest.NewRecorder()
+
+	handlerErr := h.channelUpdate(w, r)
+	is.Nil(handlerErr, "Handler should not fail")
+
+	var response portainer.NotificationChannel
+	is.NoError(json.NewDecoder(w.Body).Decode(&response))
+	is.Equal("ops-alerts", response.Name)
+	is.Empty(response.Secret, "the secret should not be returned")
+
+	updated, err := store.NotificationChannel().NotificationChannel(channel.ID)
+	is.NoError(err)
+	is.Equal("secret", updated.Secret)
+	is.False(updated.Enabled)
+}
+
+func Test_channelCreate_rejectsInvalidChannels(t *testing.T) {
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	h := &Handler{DataStore: store}
+
+	payloads := map[string]channelCreatePayload{
+		"missing name": {Type: portainer.NotificationChannelSlack, URL: "https://hooks.slack.com/services/x"},
+		"unknown type": {Name: "ops", Type: 9, URL: "https://hooks.slack.com/services/x"},
+		"invalid URL":  {Name: "ops", Type: portainer.NotificationChannelSlack, URL: "hooks.slack.com"},
+		"unknown event": {
+			Name:       "ops",
+			Type:       portainer.NotificationChannelSlack,
+			URL:        "https://hooks.slack.com/services/x",
+			EventTypes: []portainer.NotificationEventType{"stack.deleted"},
+		},
+	}
+
+	for name, payload := range payloads {
+		t.Run(name, func(t *testing.T) {
+			body, _ := json.Marshal(payload)
+			r := httptest.NewRequest(http.MethodPost, "/notifications/channels", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			httperror.LoggerHandler(h.channelCreate).ServeHTTP(w, r)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func Test_deliveryList_filtersByChannelNewestFirst(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	deliveries := []portainer.NotificationDelivery{
+		{ChannelID: 1, Timestamp: 100},
+		{ChannelID: 2, Timestamp: 200},
+		{ChannelID: 1, Timestamp: 300},
+	}
+	for i := range deliveries {
+		is.NoError(store.NotificationDelivery().Create(&deliveries[i]))
+	}
+
+	h := &Handler{DataStore: store}
+
+	r := httptest.NewRequest(http.MethodGet, "/notifications/deliveries?channelId=1", nil)
+	w := httptest.NewRecorder()
+
+	handlerErr := h.deliveryList(w, r)
+	is.Nil(handlerErr, "Handler should not fail")
+
+	var response []portainer.NotificationDelivery
+	is.NoError(json.NewDecoder(w.Body).Decode(&response))
+	is.Len(response, 2)
+	is.Equal(int64(300), response[0].Timestamp)
+	is.Equal("2", w.Header().Get("X-Total-Count"))
+}
@@ -0,0 +1,91 @@
+package notifications
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id NotificationDeliveryList
+// @summary List notification deliveries
+// @description List the delivery log of the notification channels, most recent first.
+// @description **Access policy**: administrator
+// @tags notifications
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param start query int false "Start searching from"
+// @param limit query int false "Limit results to this value"
+// @param channelId query int false "List the deliveries of this notification channel"
+// @success 200 {array} portainer.NotificationDelivery "Success"
+// @failure 400 "Invalid query parameters"
+// @failure 500 "Server error"
+// @router /notifications/deliveries [get]
+func (handler *Handler) deliveryList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	start, _ := request.RetrieveNumericQueryParameter(r, "start", true)
+	if start != 0 {
+		start--
+	}
+
+	limit, _ := request.RetrieveNumericQueryParameter(r, "limit", true)
+
+	channelID, err := request.RetrieveNumericQueryParameter(r, "channelId", true)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: channelId", err)
+	}
+
+	deliveries, err := handler.DataStore.NotificationDelivery().NotificationDeliveries()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve notification deliveries from the database", err)
+	}
+
+	if channelID != 0 {
+		filtered := make([]portainer.NotificationDelivery, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			if delivery.ChannelID == portainer.NotificationChannelID(channelID) {
+				filtered = append(filtered, delivery)
+			}
+		}
+		deliveries = filtered
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if deliveries[i].Timestamp == deliveries[j].Timestamp {
+			return deliveries[i].ID > deliveries[j].ID
+		}
+
+		return deliveries[i].Timestamp > deliveries[j].Timestamp
+	})
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(len(deliveries)))
+
+	return response.JSON(w, paginateDeliveries(deliveries, start, limit))
+}
+
+func paginateDeliveries(deliveries []portainer.NotificationDelivery, start, limit int) []portainer.NotificationDelivery {
+	if limit == 0 {
+		return deliveries
+	}
+
+	count := len(deliveries)
+
+	if start < 0 {
+		start = 0
+	}
+
+	if start > count {
+		start = count
+	}
+
+	end := start + limit
+	if end > count {
+		end = count
+	}
+
+	return deliveries[start:end]
+}
@@ -0,0 +1,73 @@
+package notifications
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/asaskevich/govalidator"
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	notifier "github.com/cloudogu/portainer-ce/api/notifications"
+	"github.com/gorilla/mux"
+	httperror "github.com/portainer/libhttp/error"
+)
+
+// Handler is the HTTP handler used to handle notification channel operations.
+type Handler struct {
+	*mux.Router
+	DataStore           dataservices.DataStore
+	NotificationService *notifier.Service
+}
+
+// NewHandler creates a handler to manage notification channel operations.
+func NewHandler(bouncer *security.RequestBouncer) *Handler {
+	h := &Handler{
+		Router: mux.NewRouter(),
+	}
+	h.Handle("/notifications/channels",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.channelCreate))).Methods(http.MethodPost)
+	h.Handle("/notifications/channels",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.channelList))).Methods(http.MethodGet)
+	h.Handle("/notifications/channels/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.channelInspect))).Methods(http.MethodGet)
+	h.Handle("/notifications/channels/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.channelUpdate))).Methods(http.MethodPut)
+	h.Handle("/notifications/channels/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.channelDelete))).Methods(http.MethodDelete)
+	h.Handle("/notifications/channels/{id}/test",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.channelTest))).Methods(http.MethodPost)
+	h.Handle("/notifications/deliveries",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.deliveryList))).Methods(http.MethodGet)
+
+	return h
+}
+
+func validateChannel(name string, channelType portainer.NotificationChannelType, channelURL string, eventTypes []portainer.NotificationEventType) error {
+	if govalidator.IsNull(name) {
+		return errors.New("Invalid notification channel name")
+	}
+
+	if channelType < portainer.NotificationChannelWebhook || channelType > portainer.NotificationChannelTeams {
+		return errors.New("Invalid notification channel type. Value must be one of: 1 (webhook), 2 (slack) or 3 (teams)")
+	}
+
+	parsedURL, err := url.ParseRequestURI(channelURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return errors.New("Invalid notification channel URL")
+	}
+
+	for _, eventType := range eventTypes {
+		if !notifier.IsValidEventType(eventType) {
+			return errors.New("Invalid event type: " + string(eventType))
+		}
+	}
+
+	return nil
+}
+
+// hideFields removes the secret of the channel from the response
+func hideFields(channel *portainer.NotificationChannel) {
+	channel.Secret = ""
+}
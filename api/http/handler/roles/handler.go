@@ -5,6 +5,7 @@ import (
 
 	"github.com/cloudogu/portainer-ce/api/dataservices"
 	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/kubernetes/cli"
 	"github.com/gorilla/mux"
 	httperror "github.com/portainer/libhttp/error"
 )
@@ -12,7 +13,8 @@ import (
 // Handler is the HTTP handler used to handle role operations.
 type Handler struct {
 	*mux.Router
-	DataStore dataservices.DataStore
+	DataStore               dataservices.DataStore
+	KubernetesClientFactory *cli.ClientFactory
 }
 
 // NewHandler creates a handler to manage role operations.
@@ -22,6 +24,14 @@ func NewHandler(bouncer *security.RequestBouncer) *Handler {
 	}
 	h.Handle("/roles",
 		bouncer.AdminAccess(httperror.LoggerHandler(h.roleList))).Methods(http.MethodGet)
+	h.Handle("/roles",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.roleCreate))).Methods(http.MethodPost)
+	h.Handle("/roles/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.roleInspect))).Methods(http.MethodGet)
+	h.Handle("/roles/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.roleUpdate))).Methods(http.MethodPut)
+	h.Handle("/roles/{id}",
+		bouncer.AdminAccess(httperror.LoggerHandler(h.roleDelete))).Methods(http.MethodDelete)
 
 	return h
 }
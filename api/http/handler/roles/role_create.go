@@ -0,0 +1,95 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/asaskevich/govalidator"
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type roleCreatePayload struct {
+	// Name
+	Name string `validate:"required" example:"Container restarter"`
+	// Description
+	Description string `example:"Can list and restart the containers"`
+	// Authorizations granted by the role, they must be environment(endpoint) authorizations
+	Authorizations portainer.Authorizations `validate:"required"`
+	// Priority of the role when a user is granted several roles on an environment(endpoint), the highest wins
+	Priority int `validate:"required" example:"2"`
+}
+
+func (payload *roleCreatePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) {
+		return errors.New("Invalid role name")
+	}
+	if payload.Priority < 1 {
+		return errors.New("Invalid role priority. must be at least 1")
+	}
+	return authorization.ValidateRoleAuthorizations(payload.Authorizations)
+}
+
+// @id RoleCreate
+// @summary Create a custom role
+// @description Create a custom role granting a set of environment(endpoint) authorizations.
+// @description Custom roles can be used in the access policies of the environments(endpoints) and the environment(endpoint) groups.
+// @description The Docker API requests, the container attach and exec websockets and the stack operations of the users are
+// @description restricted to their authorizations. Custom roles cannot be granted in the access policies of the Kubernetes namespaces.
+// @description **Access policy**: administrator
+// @tags roles
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param body body roleCreatePayload true "Role details"
+// @success 200 {object} portainer.Role "Success"
+// @failure 400 "Invalid request"
+// @failure 409 "Role name exists"
+// @failure 500 "Server error"
+// @router /roles [post]
+func (handler *Handler) roleCreate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload roleCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	if handlerErr := handler.checkUniqueName(payload.Name, 0); handlerErr != nil {
+		return handlerErr
+	}
+
+	role := &portainer.Role{
+		Name:           payload.Name,
+		Description:    payload.Description,
+		Authorizations: payload.Authorizations,
+		Priority:       payload.Priority,
+		Custom:         true,
+	}
+
+	err = handler.DataStore.Role().Create(role)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the role inside the database", err)
+	}
+
+	return response.JSON(w, role)
+}
+
+// checkUniqueName returns a conflict error when another role than roleID already has the name
+func (handler *Handler) checkUniqueName(name string, roleID portainer.RoleID) *httperror.HandlerError {
+	roles, err := handler.DataStore.Role().Roles()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve roles from the database", err)
+	}
+
+	for _, role := range roles {
+		if role.Name == name && role.ID != roleID {
+			return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "This name is already associated to a role", Err: errors.New("A role already exists with this name")}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/internal/endpointutils"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	"github.com/rs/zerolog/log"
+)
+
+// @id RoleDelete
+// @summary Remove a custom role
+// @description Remove a custom role which is not used by the access policies of the environments(endpoints),
+// @description of the environment(endpoint) groups and of the namespaces of the Kubernetes environments(endpoints).
+// @description The built-in roles cannot be removed.
+// @description **Access policy**: administrator
+// @tags roles
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "Role identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Built-in role"
+// @failure 404 "Role not found"
+// @failure 409 "Role in use"
+// @failure 500 "Server error"
+// @router /roles/{id} [delete]
+func (handler *Handler) roleDelete(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	roleID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid role identifier route variable", err)
+	}
+
+	role, handlerErr := handler.customRole(portainer.RoleID(roleID))
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	endpoints, err := handler.DataStore.Endpoint().Endpoints()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve environments from the database", err)
+	}
+
+	for _, endpoint := range endpoints {
+		if policiesUseRole(endpoint.UserAccessPolicies, endpoint.TeamAccessPolicies, role.ID) {
+			return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "The role is used by the access policies of an environment", Err: errors.New("the role is in use")}
+		}
+
+		if !endpointutils.IsKubernetesEndpoint(&endpoint) {
+			continue
+		}
+
+		// an unreachable cluster does not prevent the removal, the role cannot be granted on a namespace anymore
+		inUse, err := handler.namespacePoliciesUseRole(&endpoint, role.ID)
+		if err != nil {
+			log.Warn().Err(err).Int("endpoint_id", int(endpoint.ID)).Msg("unable to check the namespace access policies of the environment")
+		}
+		if inUse {
+			return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "The role is used by the namespace access policies of an environment", Err: errors.New("the role is in use")}
+		}
+	}
+
+	endpointGroups, err := handler.DataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve environment groups from the database", err)
+	}
+
+	for _, endpointGroup := range endpointGroups {
+		if policiesUseRole(endpointGroup.UserAccessPolicies, endpointGroup.TeamAccessPolicies, role.ID) {
+			return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "The role is used by the access policies of an environment group", Err: errors.New("the role is in use")}
+		}
+	}
+
+	err = handler.DataStore.Role().DeleteRole(role.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the role from the database", err)
+	}
+
+	return response.Empty(w)
+}
+
+// namespacePoliciesUseRole checks whether the namespace access policies of a Kubernetes environment(endpoint)
+// grant the role
+func (handler *Handler) namespacePoliciesUseRole(endpoint *portainer.Endpoint, roleID portainer.RoleID) (bool, error) {
+	kubeClient, err := handler.KubernetesClientFactory.GetKubeClient(endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	accessPolicies, err := kubeClient.GetNamespaceAccessPolicies()
+	if err != nil {
+		return false, err
+	}
+
+	for _, policy := range accessPolicies {
+		if policiesUseRole(policy.UserAccessPolicies, policy.TeamAccessPolicies, roleID) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func policiesUseRole(userPolicies portainer.UserAccessPolicies, teamPolicies portainer.TeamAccessPolicies, roleID portainer.RoleID) bool {
+	for _, policy := range userPolicies {
+		if policy.RoleID == roleID {
+			return true
+		}
+	}
+
+	for _, policy := range teamPolicies {
+		if policy.RoleID == roleID {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,141 @@
+package roles
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/apikey"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_customRoles(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	adminUser := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	err := store.User().Create(adminUser)
+	is.NoError(err, "error creating admin user")
+
+	builtinRole := &portainer.Role{Name: "Read-only user", Priority: 4, Authorizations: portainer.Authorizations{portainer.OperationDockerContainerList: true}}
+	err = store.Role().Create(builtinRole)
+	is.NoError(err, "error creating built-in role")
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+
+	h := NewHandler(requestBouncer)
+	h.DataStore = store
+
+	adminJWT, err := jwtService.GenerateToken(&portainer.TokenData{ID: adminUser.ID, Username: adminUser.Username, Role: adminUser.Role})
+	is.NoError(err)
+
+	serve := func(method, path string, payload interface{}) *httptest.ResponseRecorder {
+		var body bytes.Buffer
+		if payload != nil {
+			is.NoError(json.NewEncoder(&body).Encode(payload))
+		}
+
+		req := httptest.NewRequest(method, path, &body)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", adminJWT))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	restarter := map[string]interface{}{
+		"Name":     "Container restarter",
+		"Priority": 2,
+		"Authorizations": map[string]bool{
+			string(portainer.OperationDockerContainerList):    true,
+			string(portainer.OperationDockerContainerRestart): true,
+		},
+	}
+
+	var role portainer.Role
+
+	t.Run("an admin creates a custom role", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/roles", restarter)
+		is.Equal(http.StatusOK, rr.Code)
+		is.NoError(json.NewDecoder(rr.Body).Decode(&role))
+
+		is.True(role.Custom)
+		is.Equal("Container restarter", role.Name)
+		is.Equal(portainer.Authorizations{
+			portainer.OperationDockerContainerList:    true,
+			portainer.OperationDockerContainerRestart: true,
+		}, role.Authorizations)
+	})
+
+	t.Run("a role name is unique", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/roles", restarter)
+		is.Equal(http.StatusConflict, rr.Code)
+	})
+
+	t.Run("the authorizations of a custom role are validated", func(t *testing.T) {
+		for _, authorizations := range []map[string]bool{
+			{},
+			{"DockerContainerTeleport": true},
+			{string(portainer.OperationPortainerUserCreate): true},
+			{string(portainer.OperationDockerContainerList): false},
+		} {
+			rr := serve(http.MethodPost, "/roles", map[string]interface{}{"Name": "Invalid", "Priority": 1, "Authorizations": authorizations})
+			is.Equal(http.StatusBadRequest, rr.Code, authorizations)
+		}
+	})
+
+	t.Run("an admin updates a custom role", func(t *testing.T) {
+		rr := serve(http.MethodPut, fmt.Sprintf("/roles/%d", role.ID), map[string]interface{}{
+			"Authorizations": map[string]bool{string(portainer.OperationDockerContainerLogs): true},
+		})
+		is.Equal(http.StatusOK, rr.Code)
+
+		updated, err := store.Role().Role(role.ID)
+		is.NoError(err)
+		is.Equal("Container restarter", updated.Name)
+		is.Equal(portainer.Authorizations{portainer.OperationDockerContainerLogs: true}, updated.Authorizations)
+	})
+
+	t.Run("the built-in roles cannot be modified", func(t *testing.T) {
+		rr := serve(http.MethodPut, fmt.Sprintf("/roles/%d", builtinRole.ID), map[string]interface{}{"Name": "Renamed"})
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		rr = serve(http.MethodDelete, fmt.Sprintf("/roles/%d", builtinRole.ID), nil)
+		is.Equal(http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("a custom role used by an access policy cannot be deleted", func(t *testing.T) {
+		endpoint := &portainer.Endpoint{
+			ID:                 1,
+			Name:               "local",
+			UserAccessPolicies: portainer.UserAccessPolicies{},
+			TeamAccessPolicies: portainer.TeamAccessPolicies{2: {RoleID: role.ID}},
+		}
+		is.NoError(store.Endpoint().Create(endpoint))
+
+		rr := serve(http.MethodDelete, fmt.Sprintf("/roles/%d", role.ID), nil)
+		is.Equal(http.StatusConflict, rr.Code)
+
+		endpoint.TeamAccessPolicies = portainer.TeamAccessPolicies{}
+		is.NoError(store.Endpoint().UpdateEndpoint(endpoint.ID, endpoint))
+
+		rr = serve(http.MethodDelete, fmt.Sprintf("/roles/%d", role.ID), nil)
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		_, err := store.Role().Role(role.ID)
+		is.True(store.IsErrObjectNotFound(err))
+	})
+}
@@ -0,0 +1,114 @@
+package roles
+
+import (
+	"errors"
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type roleUpdatePayload struct {
+	// Name
+	Name *string `example:"Container restarter"`
+	// Description
+	Description *string `example:"Can list and restart the containers"`
+	// Authorizations granted by the role, they replace the current authorizations
+	Authorizations portainer.Authorizations
+	// Priority of the role when a user is granted several roles on an environment(endpoint), the highest wins
+	Priority *int `example:"2"`
+}
+
+func (payload *roleUpdatePayload) Validate(r *http.Request) error {
+	if payload.Name != nil && *payload.Name == "" {
+		return errors.New("Invalid role name")
+	}
+	if payload.Priority != nil && *payload.Priority < 1 {
+		return errors.New("Invalid role priority. must be at least 1")
+	}
+	if payload.Authorizations != nil {
+		return authorization.ValidateRoleAuthorizations(payload.Authorizations)
+	}
+	return nil
+}
+
+// @id RoleUpdate
+// @summary Update a custom role
+// @description Update a custom role, the built-in roles cannot be updated.
+// @description **Access policy**: administrator
+// @tags roles
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "Role identifier"
+// @param body body roleUpdatePayload true "Role details"
+// @success 200 {object} portainer.Role "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Built-in role"
+// @failure 404 "Role not found"
+// @failure 409 "Role name exists"
+// @failure 500 "Server error"
+// @router /roles/{id} [put]
+func (handler *Handler) roleUpdate(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	roleID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid role identifier route variable", err)
+	}
+
+	var payload roleUpdatePayload
+	err = request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	role, handlerErr := handler.customRole(portainer.RoleID(roleID))
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	if payload.Name != nil {
+		if handlerErr := handler.checkUniqueName(*payload.Name, role.ID); handlerErr != nil {
+			return handlerErr
+		}
+		role.Name = *payload.Name
+	}
+
+	if payload.Description != nil {
+		role.Description = *payload.Description
+	}
+
+	if payload.Authorizations != nil {
+		role.Authorizations = payload.Authorizations
+	}
+
+	if payload.Priority != nil {
+		role.Priority = *payload.Priority
+	}
+
+	err = handler.DataStore.Role().UpdateRole(role.ID, role)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist role changes inside the database", err)
+	}
+
+	return response.JSON(w, role)
+}
+
+// customRole returns the custom role with the identifier, the built-in roles cannot be modified
+func (handler *Handler) customRole(roleID portainer.RoleID) (*portainer.Role, *httperror.HandlerError) {
+	role, err := handler.DataStore.Role().Role(roleID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a role with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a role with the specified identifier inside the database", err)
+	}
+
+	if !role.Custom {
+		return nil, httperror.Forbidden("Built-in roles cannot be modified", errors.New("the role is not a custom role"))
+	}
+
+	return role, nil
+}
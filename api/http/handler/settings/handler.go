@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/backup"
 	"github.com/cloudogu/portainer-ce/api/dataservices"
 	"github.com/cloudogu/portainer-ce/api/demo"
 	"github.com/cloudogu/portainer-ce/api/http/security"
@@ -15,17 +16,21 @@ func hideFields(settings *portainer.Settings) {
 	settings.LDAPSettings.Password = ""
 	settings.OAuthSettings.ClientSecret = ""
 	settings.OAuthSettings.KubeSecretKey = nil
+	settings.BackupSchedule.Password = ""
+	settings.BackupSchedule.S3Settings.SecretAccessKey = ""
+	settings.MetricsTokenDigest = nil
 }
 
 // Handler is the HTTP handler used to handle settings operations.
 type Handler struct {
 	*mux.Router
-	DataStore       dataservices.DataStore
-	FileService     portainer.FileService
-	JWTService      dataservices.JWTService
-	LDAPService     portainer.LDAPService
-	SnapshotService portainer.SnapshotService
-	demoService     *demo.Service
+	DataStore             dataservices.DataStore
+	FileService           portainer.FileService
+	JWTService            dataservices.JWTService
+	LDAPService           portainer.LDAPService
+	SnapshotService       portainer.SnapshotService
+	BackupScheduleService *backup.ScheduleService
+	demoService           *demo.Service
 }
 
 // NewHandler creates a handler to manage settings operations.
@@ -7,8 +7,11 @@ import (
 
 	"github.com/asaskevich/govalidator"
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/backup"
 	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/git"
 	"github.com/cloudogu/portainer-ce/api/internal/edge"
+	"github.com/cloudogu/portainer-ce/api/internal/passwordpolicy"
 	"github.com/pkg/errors"
 	httperror "github.com/portainer/libhttp/error"
 	"github.com/portainer/libhttp/request"
@@ -23,7 +26,7 @@ type settingsUpdatePayload struct {
 	BlackListedLabels []portainer.Pair
 	// Active authentication method for the Portainer instance. Valid values are: 1 for internal, 2 for LDAP, or 3 for oauth
 	AuthenticationMethod *int `example:"1"`
-	InternalAuthSettings *portainer.InternalAuthSettings
+	InternalAuthSettings *internalAuthSettingsPayload
 	LDAPSettings         *portainer.LDAPSettings
 	OAuthSettings        *portainer.OAuthSettings
 	// The interval in which environment(endpoint) snapshots are created
@@ -52,6 +55,67 @@ type settingsUpdatePayload struct {
 	EnforceEdgeID *bool `example:"false"`
 	// EdgePortainerURL is the URL that is exposed to edge agents
 	EdgePortainerURL *string `json:"EdgePortainerURL"`
+	// Scheduled automatic backups
+	BackupSchedule *portainer.BackupScheduleSettings
+	// Host keys of the git servers, in the known_hosts format, pinned when cloning repositories over SSH
+	GitSSHKnownHosts *string `example:"gitea.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAI..."`
+	// Whether the internal and LDAP users must enroll and use a second factor to log in
+	TwoFactorRequired *bool `example:"false"`
+	// Number of days the audit log entries are kept, 90 when 0
+	AuditLogRetentionDays *int `example:"90"`
+}
+
+type internalAuthSettingsPayload struct {
+	// The minimum required length for a password
+	RequiredPasswordLength *int `example:"12"`
+	// Whether the passwords must contain an uppercase letter
+	RequireUppercase *bool `example:"true"`
+	// Whether the passwords must contain a lowercase letter
+	RequireLowercase *bool `example:"true"`
+	// Whether the passwords must contain a digit
+	RequireDigit *bool `example:"true"`
+	// Whether the passwords must contain a character which is neither a letter nor a digit
+	RequireSpecialCharacter *bool `example:"false"`
+	// Number of the last passwords of a user, including the current one, which cannot be reused. 0 disables the check
+	PasswordHistorySize *int `example:"5"`
+	// Number of days after which a user must change their password when they log in. 0 disables the expiry
+	MaxPasswordAgeDays *int `example:"90"`
+	// Number of consecutive failed logins after which an account is locked. 0 disables the lockout
+	MaxFailedLoginAttempts *int `example:"5"`
+	// Number of minutes after which a locked account is unlocked. 0 keeps it locked until an administrator unlocks it
+	// with /users/{id}/unlock, except the last administrator whose account is not locked who is unlocked after 15 minutes
+	LockoutDurationMinutes *int `example:"0"`
+}
+
+// apply updates the internal authentication settings with the fields set in the payload
+func (payload *internalAuthSettingsPayload) apply(settings *portainer.InternalAuthSettings) {
+	if payload.RequiredPasswordLength != nil {
+		settings.RequiredPasswordLength = *payload.RequiredPasswordLength
+	}
+	if payload.RequireUppercase != nil {
+		settings.RequireUppercase = *payload.RequireUppercase
+	}
+	if payload.RequireLowercase != nil {
+		settings.RequireLowercase = *payload.RequireLowercase
+	}
+	if payload.RequireDigit != nil {
+		settings.RequireDigit = *payload.RequireDigit
+	}
+	if payload.RequireSpecialCharacter != nil {
+		settings.RequireSpecialCharacter = *payload.RequireSpecialCharacter
+	}
+	if payload.PasswordHistorySize != nil {
+		settings.PasswordHistorySize = *payload.PasswordHistorySize
+	}
+	if payload.MaxPasswordAgeDays != nil {
+		settings.MaxPasswordAgeDays = *payload.MaxPasswordAgeDays
+	}
+	if payload.MaxFailedLoginAttempts != nil {
+		settings.MaxFailedLoginAttempts = *payload.MaxFailedLoginAttempts
+	}
+	if payload.LockoutDurationMinutes != nil {
+		settings.LockoutDurationMinutes = *payload.LockoutDurationMinutes
+	}
 }
 
 func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
@@ -67,6 +131,12 @@ func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
 	if payload.HelmRepositoryURL != nil && *payload.HelmRepositoryURL != "" && !govalidator.IsURL(*payload.HelmRepositoryURL) {
 		return errors.New("Invalid Helm repository URL. Must correspond to a valid URL format")
 	}
+	if payload.GitSSHKnownHosts != nil {
+		err := git.ValidateKnownHosts(*payload.GitSSHKnownHosts)
+		if err != nil {
+			return errors.WithMessage(err, "Invalid git SSH known hosts")
+		}
+	}
 	if payload.UserSessionTimeout != nil {
 		_, err := time.ParseDuration(*payload.UserSessionTimeout)
 		if err != nil {
@@ -80,6 +150,10 @@ func (payload *settingsUpdatePayload) Validate(r *http.Request) error {
 		}
 	}
 
+	if payload.AuditLogRetentionDays != nil && *payload.AuditLogRetentionDays < 0 {
+		return errors.New("Invalid audit log retention. Must not be negative")
+	}
+
 	if payload.EdgePortainerURL != nil && *payload.EdgePortainerURL != "" {
 		_, err := edge.ParseHostForEdge(*payload.EdgePortainerURL)
 		if err != nil {
@@ -160,7 +234,15 @@ func (handler *Handler) settingsUpdate(w http.ResponseWriter, r *http.Request) *
 	}
 
 	if payload.InternalAuthSettings != nil {
-		settings.InternalAuthSettings.RequiredPasswordLength = payload.InternalAuthSettings.RequiredPasswordLength
+		internalAuthSettings := settings.InternalAuthSettings
+		payload.InternalAuthSettings.apply(&internalAuthSettings)
+
+		err := passwordpolicy.ValidateSettings(&internalAuthSettings)
+		if err != nil {
+			return httperror.BadRequest("Invalid internal authentication settings", err)
+		}
+
+		settings.InternalAuthSettings = internalAuthSettings
 	}
 
 	if payload.LDAPSettings != nil {
@@ -210,6 +292,17 @@ func (handler *Handler) settingsUpdate(w http.ResponseWriter, r *http.Request) *
 		settings.EdgePortainerURL = *payload.EdgePortainerURL
 	}
 
+	if payload.GitSSHKnownHosts != nil {
+		settings.GitSSHKnownHosts = *payload.GitSSHKnownHosts
+	}
+
+	if payload.BackupSchedule != nil {
+		httpErr := handler.updateBackupSchedule(settings, *payload.BackupSchedule)
+		if httpErr != nil {
+			return httpErr
+		}
+	}
+
 	if payload.SnapshotInterval != nil && *payload.SnapshotInterval != settings.SnapshotInterval {
 		err := handler.updateSnapshotInterval(settings, *payload.SnapshotInterval)
 		if err != nil {
@@ -237,6 +330,14 @@ func (handler *Handler) settingsUpdate(w http.ResponseWriter, r *http.Request) *
 		settings.EnableTelemetry = *payload.EnableTelemetry
 	}
 
+	if payload.TwoFactorRequired != nil {
+		settings.TwoFactorRequired = *payload.TwoFactorRequired
+	}
+
+	if payload.AuditLogRetentionDays != nil {
+		settings.AuditLogRetentionDays = *payload.AuditLogRetentionDays
+	}
+
 	tlsError := handler.updateTLS(settings)
 	if tlsError != nil {
 		return tlsError
@@ -260,6 +361,31 @@ func (handler *Handler) updateSnapshotInterval(settings *portainer.Settings, sna
 	return handler.SnapshotService.SetSnapshotInterval(snapshotInterval)
 }
 
+func (handler *Handler) updateBackupSchedule(settings *portainer.Settings, backupSchedule portainer.BackupScheduleSettings) *httperror.HandlerError {
+	// secrets are hidden when the settings are inspected, keep the stored values when they are not provided
+	if backupSchedule.Password == "" {
+		backupSchedule.Password = settings.BackupSchedule.Password
+	}
+
+	if backupSchedule.S3Settings.SecretAccessKey == "" {
+		backupSchedule.S3Settings.SecretAccessKey = settings.BackupSchedule.S3Settings.SecretAccessKey
+	}
+
+	err := backup.ValidateScheduleSettings(backupSchedule)
+	if err != nil {
+		return httperror.BadRequest("Invalid backup schedule", err)
+	}
+
+	settings.BackupSchedule = backupSchedule
+
+	err = handler.BackupScheduleService.SetSchedule(backupSchedule)
+	if err != nil {
+		return httperror.InternalServerError("Unable to update the backup schedule", err)
+	}
+
+	return nil
+}
+
 func (handler *Handler) updateTLS(settings *portainer.Settings) *httperror.HandlerError {
 	if (settings.LDAPSettings.TLSConfig.TLS || settings.LDAPSettings.StartTLS) && !settings.LDAPSettings.TLSConfig.TLSSkipVerify {
 		caCertPath, _ := handler.FileService.GetPathForTLSFile(filesystem.LDAPStorePath, portainer.TLSFileCA)
@@ -6,6 +6,8 @@ import (
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/git"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
 	"github.com/cloudogu/portainer-ce/api/stacks/stackbuilders"
@@ -27,6 +29,9 @@ type composeStackFromFileContentPayload struct {
 	Env []portainer.Pair
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
+	// Optional health check of the containers after the deployment, the previous revision of the stack
+	// is redeployed when they do not become healthy
+	DeployPolicy *portainer.StackDeployPolicy
 }
 
 func (payload *composeStackFromFileContentPayload) Validate(r *http.Request) error {
@@ -37,7 +42,7 @@ func (payload *composeStackFromFileContentPayload) Validate(r *http.Request) err
 	if govalidator.IsNull(payload.StackFileContent) {
 		return errors.New("Invalid stack file content")
 	}
-	return nil
+	return stackutils.ValidateStackDeployPolicy(payload.DeployPolicy)
 }
 
 func createStackPayloadFromComposeFileContentPayload(name string, fileContent string, env []portainer.Pair, fromAppTemplate bool) stackbuilders.StackPayload {
@@ -123,6 +128,7 @@ func (handler *Handler) createComposeStackFromFileContent(w http.ResponseWriter,
 	}
 
 	stackPayload := createStackPayloadFromComposeFileContentPayload(payload.Name, payload.StackFileContent, payload.Env, payload.FromAppTemplate)
+	stackPayload.DeployPolicy = payload.DeployPolicy
 
 	composeStackBuilder := stackbuilders.CreateComposeStackFileContentBuilder(securityContext,
 		handler.DataStore,
@@ -149,18 +155,26 @@ type composeStackFromGitRepositoryPayload struct {
 	RepositoryAuthentication bool `example:"true"`
 	// Username used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryUsername string `example:"myGitUsername"`
-	// Password used in basic authentication. Required when RepositoryAuthentication is true.
+	// Password used in basic authentication. Required when RepositoryAuthentication is true
+	// and RepositoryGitCredentialID is 0.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Identifier of the saved git credential used in basic authentication instead of the username and password
+	RepositoryGitCredentialID int `example:"0"`
 	// Path to the Stack file inside the Git repository
 	ComposeFile string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// Applicable when deploying with multiple stack files
 	AdditionalFiles []string `example:"[nz.compose.yml, uat.compose.yml]"`
 	// Optional auto update configuration
 	AutoUpdate *portainer.StackAutoUpdate
+	// Optional path filter restricting the automatic redeploys to the changes of the files the stack is deployed from
+	PathFilter *gittypes.PathFilter
 	// A list of environment(endpoint) variables used during stack deployment
 	Env []portainer.Pair
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
+	// Optional health check of the containers after the deployment, the previous revision of the stack
+	// is redeployed when they do not become healthy
+	DeployPolicy *portainer.StackDeployPolicy
 }
 
 func createStackPayloadFromComposeGitPayload(name, repoUrl, repoReference, repoUsername, repoPassword string, repoAuthentication bool, composeFile string, additionalFiles []string, autoUpdate *portainer.StackAutoUpdate, env []portainer.Pair, fromAppTemplate bool) stackbuilders.StackPayload {
@@ -185,15 +199,21 @@ func (payload *composeStackFromGitRepositoryPayload) Validate(r *http.Request) e
 	if govalidator.IsNull(payload.Name) {
 		return errors.New("Invalid stack name")
 	}
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if govalidator.IsNull(payload.RepositoryURL) || !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) {
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID == 0 && govalidator.IsNull(payload.RepositoryPassword) {
 		return errors.New("Invalid repository credentials. Password must be specified when authentication is enabled")
 	}
 	if err := stackutils.ValidateStackAutoUpdate(payload.AutoUpdate); err != nil {
 		return err
 	}
+	if err := stackutils.ValidateStackPathFilter(payload.PathFilter); err != nil {
+		return err
+	}
+	if err := stackutils.ValidateStackDeployPolicy(payload.DeployPolicy); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -258,13 +278,21 @@ func (handler *Handler) createComposeStackFromGitRepository(w http.ResponseWrite
 		payload.AutoUpdate,
 		payload.Env,
 		payload.FromAppTemplate)
+	stackPayload.GitCredentialID = payload.RepositoryGitCredentialID
+	stackPayload.PathFilter = payload.PathFilter
+	stackPayload.DeployPolicy = payload.DeployPolicy
+
+	if httpErr := handler.checkGitCredentialAccess(payload.RepositoryGitCredentialID, securityContext.UserID); httpErr != nil {
+		return httpErr
+	}
 
 	composeStackBuilder := stackbuilders.CreateComposeStackGitBuilder(securityContext,
 		handler.DataStore,
 		handler.FileService,
 		handler.GitService,
 		handler.Scheduler,
-		handler.StackDeployer)
+		handler.StackDeployer,
+		handler.NotificationService)
 
 	stackBuilderDirector := stackbuilders.NewStackBuilderDirector(composeStackBuilder)
 	stack, httpErr := stackBuilderDirector.Build(&stackPayload, endpoint)
@@ -279,6 +307,7 @@ type composeStackFromFileUploadPayload struct {
 	Name             string
 	StackFileContent []byte
 	Env              []portainer.Pair
+	DeployPolicy     *portainer.StackDeployPolicy
 }
 
 func createStackPayloadFromComposeFileUploadPayload(name string, fileContentBytes []byte, env []portainer.Pair) stackbuilders.StackPayload {
@@ -309,6 +338,17 @@ func decodeRequestForm(r *http.Request) (*composeStackFromFileUploadPayload, err
 		return nil, errors.New("Invalid Env parameter")
 	}
 	payload.Env = env
+
+	var deployPolicy *portainer.StackDeployPolicy
+	err = request.RetrieveMultiPartFormJSONValue(r, "DeployPolicy", &deployPolicy, true)
+	if err != nil {
+		return nil, errors.New("Invalid DeployPolicy parameter")
+	}
+	if err := stackutils.ValidateStackDeployPolicy(deployPolicy); err != nil {
+		return nil, err
+	}
+	payload.DeployPolicy = deployPolicy
+
 	return payload, nil
 }
 
@@ -348,6 +388,7 @@ func (handler *Handler) createComposeStackFromFileUpload(w http.ResponseWriter,
 	}
 
 	stackPayload := createStackPayloadFromComposeFileUploadPayload(payload.Name, payload.StackFileContent, payload.Env)
+	stackPayload.DeployPolicy = payload.DeployPolicy
 
 	composeStackBuilder := stackbuilders.CreateComposeStackFileUploadBuilder(securityContext,
 		handler.DataStore,
@@ -8,7 +8,11 @@ import (
 	"github.com/pkg/errors"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/git"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	k "github.com/cloudogu/portainer-ce/api/kubernetes"
+	"github.com/cloudogu/portainer-ce/api/kubernetes/validation"
 	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
 	"github.com/cloudogu/portainer-ce/api/stacks/stackbuilders"
 	"github.com/cloudogu/portainer-ce/api/stacks/stackutils"
@@ -17,6 +21,9 @@ import (
 	"github.com/portainer/libhttp/response"
 )
 
+// helmReleaseNameMaxLength is the maximum length of the name of a Helm release
+const helmReleaseNameMaxLength = 53
+
 type kubernetesStringDeploymentPayload struct {
 	StackName        string
 	ComposeFormat    bool
@@ -42,12 +49,20 @@ type kubernetesGitDeploymentPayload struct {
 	RepositoryAuthentication bool
 	RepositoryUsername       string
 	RepositoryPassword       string
-	ManifestFile             string
-	AdditionalFiles          []string
-	AutoUpdate               *portainer.StackAutoUpdate
+	// Identifier of the saved git credential used in basic authentication instead of the username and password
+	RepositoryGitCredentialID int
+	ManifestFile              string
+	AdditionalFiles           []string
+	AutoUpdate                *portainer.StackAutoUpdate
+	// Kustomization rendered server-side instead of deploying the manifest files
+	Kustomize *portainer.KustomizeConfig
+	// Helm chart deployed as a release named after the stack instead of deploying the manifest files
+	HelmChart *portainer.HelmChartConfig
+	// Optional path filter restricting the automatic redeploys to the changes of the files the stack is deployed from
+	PathFilter *gittypes.PathFilter
 }
 
-func createStackPayloadFromK8sGitPayload(name, repoUrl, repoReference, repoUsername, repoPassword string, repoAuthentication, composeFormat bool, namespace, manifest string, additionalFiles []string, autoUpdate *portainer.StackAutoUpdate) stackbuilders.StackPayload {
+func createStackPayloadFromK8sGitPayload(name, repoUrl, repoReference, repoUsername, repoPassword string, repoAuthentication, composeFormat bool, namespace, manifest string, additionalFiles []string, autoUpdate *portainer.StackAutoUpdate, kustomize *portainer.KustomizeConfig, helmChart *portainer.HelmChartConfig) stackbuilders.StackPayload {
 	return stackbuilders.StackPayload{
 		StackName: name,
 		RepositoryConfigPayload: stackbuilders.RepositoryConfigPayload{
@@ -62,6 +77,8 @@ func createStackPayloadFromK8sGitPayload(name, repoUrl, repoReference, repoUsern
 		ManifestFile:    manifest,
 		AdditionalFiles: additionalFiles,
 		AutoUpdate:      autoUpdate,
+		Kustomize:       kustomize,
+		HelmChart:       helmChart,
 	}
 }
 
@@ -92,18 +109,50 @@ func (payload *kubernetesStringDeploymentPayload) Validate(r *http.Request) erro
 }
 
 func (payload *kubernetesGitDeploymentPayload) Validate(r *http.Request) error {
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if govalidator.IsNull(payload.RepositoryURL) || !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) {
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID == 0 && govalidator.IsNull(payload.RepositoryPassword) {
 		return errors.New("Invalid repository credentials. Password must be specified when authentication is enabled")
 	}
-	if govalidator.IsNull(payload.ManifestFile) {
+	if payload.Kustomize != nil {
+		if govalidator.IsNull(payload.Kustomize.Path) {
+			return errors.New("Invalid kustomization path in repository")
+		}
+		if len(payload.AdditionalFiles) > 0 || payload.ComposeFormat {
+			return errors.New("Additional files and compose format cannot be used along with a kustomization")
+		}
+	}
+	if payload.HelmChart != nil {
+		if payload.Kustomize != nil {
+			return errors.New("A Helm chart cannot be used along with a kustomization")
+		}
+		if govalidator.IsNull(payload.HelmChart.ChartPath) {
+			return errors.New("Invalid chart path in repository")
+		}
+		if len(payload.AdditionalFiles) > 0 || payload.ComposeFormat {
+			return errors.New("Additional files and compose format cannot be used along with a Helm chart")
+		}
+		if errs := validation.IsDNS1123Subdomain(payload.StackName); len(errs) > 0 {
+			return errors.New("Invalid stack name. The name of a Helm release must consist of lower case alphanumeric characters, '-' or '.'")
+		}
+		if len(payload.StackName) > helmReleaseNameMaxLength {
+			return errors.Errorf("Invalid stack name. The name of a Helm release must be no more than %d characters", helmReleaseNameMaxLength)
+		}
+		if govalidator.IsNull(payload.ManifestFile) {
+			payload.ManifestFile = filesystem.JoinPaths("", payload.HelmChart.ChartPath, "Chart.yaml")
+		}
+	}
+	// the kustomization file is looked up in the kustomization directory once the repository is cloned
+	if govalidator.IsNull(payload.ManifestFile) && payload.Kustomize == nil {
 		return errors.New("Invalid manifest file in repository")
 	}
 	if err := stackutils.ValidateStackAutoUpdate(payload.AutoUpdate); err != nil {
 		return err
 	}
+	if err := stackutils.ValidateStackPathFilter(payload.PathFilter); err != nil {
+		return err
+	}
 	if govalidator.IsNull(payload.StackName) {
 		return errors.New("Invalid stack name")
 	}
@@ -202,7 +251,15 @@ func (handler *Handler) createKubernetesStackFromGitRepository(w http.ResponseWr
 		payload.Namespace,
 		payload.ManifestFile,
 		payload.AdditionalFiles,
-		payload.AutoUpdate)
+		payload.AutoUpdate,
+		payload.Kustomize,
+		payload.HelmChart)
+	stackPayload.GitCredentialID = payload.RepositoryGitCredentialID
+	stackPayload.PathFilter = payload.PathFilter
+
+	if httpErr := handler.checkGitCredentialAccess(payload.RepositoryGitCredentialID, userID); httpErr != nil {
+		return httpErr
+	}
 
 	k8sStackBuilder := stackbuilders.CreateKubernetesStackGitBuilder(handler.DataStore,
 		handler.FileService,
@@ -210,6 +267,7 @@ func (handler *Handler) createKubernetesStackFromGitRepository(w http.ResponseWr
 		handler.Scheduler,
 		handler.StackDeployer,
 		handler.KubernetesDeployer,
+		handler.NotificationService,
 		user)
 
 	stackBuilderDirector := stackbuilders.NewStackBuilderDirector(k8sStackBuilder)
@@ -0,0 +1,25 @@
+package stacks
+
+import (
+	"strings"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_kubernetesGitDeploymentPayload_Validate_helmReleaseName(t *testing.T) {
+	is := assert.New(t)
+
+	payload := func(stackName string) *kubernetesGitDeploymentPayload {
+		return &kubernetesGitDeploymentPayload{
+			StackName:     stackName,
+			RepositoryURL: "https://github.com/portainer/charts",
+			HelmChart:     &portainer.HelmChartConfig{ChartPath: "charts/portainer"},
+		}
+	}
+
+	is.NoError(payload(strings.Repeat("a", helmReleaseNameMaxLength)).Validate(nil))
+	is.Error(payload(strings.Repeat("a", helmReleaseNameMaxLength+1)).Validate(nil), "helm refuses the release names longer than 53 characters")
+	is.Error(payload("Portainer").Validate(nil))
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/pkg/errors"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/git"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	"github.com/cloudogu/portainer-ce/api/stacks/stackbuilders"
 	"github.com/cloudogu/portainer-ce/api/stacks/stackutils"
@@ -106,8 +108,11 @@ type swarmStackFromGitRepositoryPayload struct {
 	RepositoryAuthentication bool `example:"true"`
 	// Username used in basic authentication. Required when RepositoryAuthentication is true.
 	RepositoryUsername string `example:"myGitUsername"`
-	// Password used in basic authentication. Required when RepositoryAuthentication is true.
+	// Password used in basic authentication. Required when RepositoryAuthentication is true
+	// and RepositoryGitCredentialID is 0.
 	RepositoryPassword string `example:"myGitPassword"`
+	// Identifier of the saved git credential used in basic authentication instead of the username and password
+	RepositoryGitCredentialID int `example:"0"`
 	// Whether the stack is from a app template
 	FromAppTemplate bool `example:"false"`
 	// Path to the Stack file inside the Git repository
@@ -116,6 +121,8 @@ type swarmStackFromGitRepositoryPayload struct {
 	AdditionalFiles []string `example:"[nz.compose.yml, uat.compose.yml]"`
 	// Optional auto update configuration
 	AutoUpdate *portainer.StackAutoUpdate
+	// Optional path filter restricting the automatic redeploys to the changes of the files the stack is deployed from
+	PathFilter *gittypes.PathFilter
 }
 
 func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) error {
@@ -125,15 +132,18 @@ func (payload *swarmStackFromGitRepositoryPayload) Validate(r *http.Request) err
 	if govalidator.IsNull(payload.SwarmID) {
 		return errors.New("Invalid Swarm ID")
 	}
-	if govalidator.IsNull(payload.RepositoryURL) || !govalidator.IsURL(payload.RepositoryURL) {
+	if govalidator.IsNull(payload.RepositoryURL) || !git.IsValidRepositoryURL(payload.RepositoryURL) {
 		return errors.New("Invalid repository URL. Must correspond to a valid URL format")
 	}
-	if payload.RepositoryAuthentication && govalidator.IsNull(payload.RepositoryPassword) {
+	if payload.RepositoryAuthentication && payload.RepositoryGitCredentialID == 0 && govalidator.IsNull(payload.RepositoryPassword) {
 		return errors.New("Invalid repository credentials. Password must be specified when authentication is enabled")
 	}
 	if err := stackutils.ValidateStackAutoUpdate(payload.AutoUpdate); err != nil {
 		return err
 	}
+	if err := stackutils.ValidateStackPathFilter(payload.PathFilter); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -201,13 +211,20 @@ func (handler *Handler) createSwarmStackFromGitRepository(w http.ResponseWriter,
 		payload.AutoUpdate,
 		payload.Env,
 		payload.FromAppTemplate)
+	stackPayload.GitCredentialID = payload.RepositoryGitCredentialID
+	stackPayload.PathFilter = payload.PathFilter
+
+	if httpErr := handler.checkGitCredentialAccess(payload.RepositoryGitCredentialID, securityContext.UserID); httpErr != nil {
+		return httpErr
+	}
 
 	swarmStackBuilder := stackbuilders.CreateSwarmStackGitBuilder(securityContext,
 		handler.DataStore,
 		handler.FileService,
 		handler.GitService,
 		handler.Scheduler,
-		handler.StackDeployer)
+		handler.StackDeployer,
+		handler.NotificationService)
 
 	stackBuilderDirector := stackbuilders.NewStackBuilderDirector(swarmStackBuilder)
 	stack, httpErr := stackBuilderDirector.Build(&stackPayload, endpoint)
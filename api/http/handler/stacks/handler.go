@@ -10,6 +10,8 @@ import (
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/dataservices"
 	"github.com/cloudogu/portainer-ce/api/docker"
+	"github.com/cloudogu/portainer-ce/api/git"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	"github.com/cloudogu/portainer-ce/api/internal/authorization"
 	"github.com/cloudogu/portainer-ce/api/internal/endpointutils"
@@ -30,6 +32,7 @@ type Handler struct {
 	requestBouncer     *security.RequestBouncer
 	*mux.Router
 	DataStore               dataservices.DataStore
+	AuthorizationService    *authorization.Service
 	DockerClientFactory     *docker.ClientFactory
 	FileService             portainer.FileService
 	GitService              portainer.GitService
@@ -39,6 +42,7 @@ type Handler struct {
 	KubernetesClientFactory *cli.ClientFactory
 	Scheduler               *scheduler.Scheduler
 	StackDeployer           deployments.StackDeployer
+	NotificationService     portainer.NotificationService
 }
 
 func stackExistsError(name string) *httperror.HandlerError {
@@ -73,6 +77,14 @@ func NewHandler(bouncer *security.RequestBouncer) *Handler {
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackGitRedeploy))).Methods(http.MethodPut)
 	h.Handle("/stacks/{id}/file",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackFile))).Methods(http.MethodGet)
+	h.Handle("/stacks/{id}/versions",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackVersionList))).Methods(http.MethodGet)
+	h.Handle("/stacks/{id}/versions/diff",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackVersionDiff))).Methods(http.MethodGet)
+	h.Handle("/stacks/{id}/versions/{version}",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackVersionInspect))).Methods(http.MethodGet)
+	h.Handle("/stacks/{id}/versions/{version}/rollback",
+		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackVersionRollback))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}/migrate",
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.stackMigrate))).Methods(http.MethodPost)
 	h.Handle("/stacks/{id}/start",
@@ -143,6 +155,16 @@ func (handler *Handler) userCanManageStacks(securityContext *security.Restricted
 	return true, nil
 }
 
+// userCanRunStackOperation checks that the custom role granted to a non-admin user on the environment(endpoint),
+// if any, grants the stack operation
+func (handler *Handler) userCanRunStackOperation(securityContext *security.RestrictedRequestContext, endpoint *portainer.Endpoint, operation portainer.Authorization) (bool, error) {
+	if securityContext.IsAdmin || endpoint == nil {
+		return true, nil
+	}
+
+	return handler.AuthorizationService.AuthorizedCustomRoleOperation(securityContext.UserID, endpoint, operation)
+}
+
 func (handler *Handler) checkUniqueStackName(endpoint *portainer.Endpoint, name string, stackID portainer.StackID) (bool, error) {
 	stacks, err := handler.DataStore.Stack().Stacks()
 	if err != nil {
@@ -224,6 +246,49 @@ func (handler *Handler) checkUniqueStackNameInDocker(endpoint *portainer.Endpoin
 	return isUniqueStackName, nil
 }
 
+// checkGitCredentialAccess ensures that the saved git credential referenced by a request belongs to the user
+func (handler *Handler) checkGitCredentialAccess(gitCredentialID int, userID portainer.UserID) *httperror.HandlerError {
+	if gitCredentialID == 0 {
+		return nil
+	}
+
+	err := git.CheckCredentialAccess(gitCredentialID, userID, handler.DataStore.GitCredential())
+	if errors.Is(err, git.ErrGitCredentialAccessDenied) {
+		return httperror.Forbidden("Permission denied to use the git credential", err)
+	} else if err != nil {
+		return httperror.BadRequest("Invalid git credential", err)
+	}
+
+	return nil
+}
+
+// gitAuthenticationFromPayload returns the git authentication requested when updating a git stack.
+// A saved git credential takes precedence, and the authentication of the stack is kept when no password is provided.
+func (handler *Handler) gitAuthenticationFromPayload(stack *portainer.Stack, username, password string, gitCredentialID int, userID portainer.UserID) (*gittypes.GitAuthentication, *httperror.HandlerError) {
+	if gitCredentialID != 0 {
+		if httpErr := handler.checkGitCredentialAccess(gitCredentialID, userID); httpErr != nil {
+			return nil, httpErr
+		}
+
+		return &gittypes.GitAuthentication{GitCredentialID: gitCredentialID}, nil
+	}
+
+	auth := &gittypes.GitAuthentication{
+		Username: username,
+		Password: password,
+	}
+
+	if password == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
+		if stack.GitConfig.Authentication.GitCredentialID != 0 {
+			return stack.GitConfig.Authentication, nil
+		}
+
+		auth.Password = stack.GitConfig.Authentication.Password
+	}
+
+	return auth, nil
+}
+
 func (handler *Handler) checkUniqueWebhookID(webhookID string) (bool, error) {
 	_, err := handler.DataStore.Stack().StackByWebhookID(webhookID)
 	if handler.DataStore.IsErrObjectNotFound(err) {
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	"github.com/cloudogu/portainer-ce/api/internal/authorization"
 	"github.com/cloudogu/portainer-ce/api/stacks/stackutils"
@@ -77,6 +78,14 @@ func (handler *Handler) stackCreate(w http.ResponseWriter, r *http.Request) *htt
 		return httperror.Forbidden(errMsg, errors.New(errMsg))
 	}
 
+	canRun, err := handler.userCanRunStackOperation(securityContext, endpoint, portainer.OperationPortainerStackCreate)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to create the stack", httperrors.ErrResourceAccessDenied)
+	}
+
 	err = handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
 	if err != nil {
 		return httperror.Forbidden("Permission denied to access environment", err)
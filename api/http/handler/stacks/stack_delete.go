@@ -112,6 +112,14 @@ func (handler *Handler) stackDelete(w http.ResponseWriter, r *http.Request) *htt
 		return httperror.Forbidden(errMsg, fmt.Errorf(errMsg))
 	}
 
+	canRun, err := handler.userCanRunStackOperation(securityContext, endpoint, portainer.OperationPortainerStackDelete)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to delete the stack", httperrors.ErrResourceAccessDenied)
+	}
+
 	// stop scheduler updates of the stack before removal
 	if stack.AutoUpdate != nil {
 		deployments.StopAutoupdate(stack.ID, stack.AutoUpdate.JobID, handler.Scheduler)
@@ -127,6 +135,11 @@ func (handler *Handler) stackDelete(w http.ResponseWriter, r *http.Request) *htt
 		return httperror.InternalServerError("Unable to remove the stack from the database", err)
 	}
 
+	err = handler.DataStore.StackVersion().DeleteStackVersions(portainer.StackID(id))
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the stack history from the database", err)
+	}
+
 	if resourceControl != nil {
 		err = handler.DataStore.ResourceControl().DeleteResourceControl(resourceControl.ID)
 		if err != nil {
@@ -192,12 +205,34 @@ func (handler *Handler) deleteStack(userID portainer.UserID, stack *portainer.St
 	if stack.Type == portainer.DockerComposeStack {
 		return handler.ComposeStackManager.Down(context.TODO(), stack, endpoint)
 	}
+	if stack.Type == portainer.KubernetesStack && stack.HelmChart != nil {
+		out, err := handler.KubernetesDeployer.RemoveHelmRelease(userID, endpoint, stack.Name, stack.Namespace)
+		return errors.WithMessagef(err, "failed to remove helm release: %q", out)
+	}
 	if stack.Type == portainer.KubernetesStack {
 		var manifestFiles []string
 
 		//if it is a compose format kub stack, create a temp dir and convert the manifest files into it
 		//then process the remove operation
-		if stack.IsComposeFormat {
+		if stack.Kustomize != nil {
+			tmpDir, err := os.MkdirTemp("", "kube_delete")
+			if err != nil {
+				return errors.Wrap(err, "failed to create temp directory for deleting kub stack")
+			}
+			defer os.RemoveAll(tmpDir)
+
+			manifestContent, err := handler.KubernetesDeployer.Kustomize(filesystem.JoinPaths(stack.ProjectPath, stackutils.GetKustomizationPath(stack)))
+			if err != nil {
+				return errors.Wrap(err, "failed to render kustomization")
+			}
+
+			manifestFilePath := filesystem.JoinPaths(tmpDir, "kustomization.yml")
+			err = filesystem.WriteToFile(manifestFilePath, manifestContent)
+			if err != nil {
+				return errors.Wrap(err, "failed to create temp manifest file")
+			}
+			manifestFiles = append(manifestFiles, manifestFilePath)
+		} else if stack.IsComposeFormat {
 			fileNames := stackutils.GetStackFilePaths(stack, false)
 			tmpDir, err := os.MkdirTemp("", "kube_delete")
 			if err != nil {
@@ -97,6 +97,14 @@ func (handler *Handler) stackMigrate(w http.ResponseWriter, r *http.Request) *ht
 		return httperror.Forbidden(errMsg, errors.New(errMsg))
 	}
 
+	canRun, err := handler.userCanRunStackOperation(securityContext, endpoint, portainer.OperationPortainerStackMigrate)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to migrate the stack", httperrors.ErrResourceAccessDenied)
+	}
+
 	resourceControl, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(stackutils.ResourceControlID(stack.EndpointID, stack.Name), portainer.StackResourceControl)
 	if err != nil {
 		return httperror.InternalServerError("Unable to retrieve a resource control associated to the stack", err)
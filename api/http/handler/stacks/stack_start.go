@@ -74,6 +74,14 @@ func (handler *Handler) stackStart(w http.ResponseWriter, r *http.Request) *http
 		return httperror.Forbidden(errMsg, errors.New(errMsg))
 	}
 
+	canRun, err := handler.userCanRunStackOperation(securityContext, endpoint, portainer.OperationPortainerStackUpdate)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to start the stack", httperrors.ErrResourceAccessDenied)
+	}
+
 	isUnique, err := handler.checkUniqueStackNameInDocker(endpoint, stack.Name, stack.ID, stack.SwarmID != "")
 	if err != nil {
 		return httperror.InternalServerError("Unable to check for name collision", err)
@@ -103,7 +111,7 @@ func (handler *Handler) stackStart(w http.ResponseWriter, r *http.Request) *http
 	if stack.AutoUpdate != nil && stack.AutoUpdate.Interval != "" {
 		deployments.StopAutoupdate(stack.ID, stack.AutoUpdate.JobID, handler.Scheduler)
 
-		jobID, e := deployments.StartAutoupdate(stack.ID, stack.AutoUpdate.Interval, handler.Scheduler, handler.StackDeployer, handler.DataStore, handler.GitService)
+		jobID, e := deployments.StartAutoupdate(stack.ID, stack.AutoUpdate.Interval, handler.Scheduler, handler.StackDeployer, handler.DataStore, handler.GitService, handler.NotificationService)
 		if e != nil {
 			return e
 		}
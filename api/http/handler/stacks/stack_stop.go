@@ -85,6 +85,14 @@ func (handler *Handler) stackStop(w http.ResponseWriter, r *http.Request) *httpe
 		return httperror.Forbidden(errMsg, errors.New(errMsg))
 	}
 
+	canRun, err := handler.userCanRunStackOperation(securityContext, endpoint, portainer.OperationPortainerStackUpdate)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to stop the stack", httperrors.ErrResourceAccessDenied)
+	}
+
 	if stack.Status == portainer.StackStatusInactive {
 		return httperror.BadRequest("Stack is already inactive", errors.New("Stack is already inactive"))
 	}
@@ -26,13 +26,21 @@ type updateComposeStackPayload struct {
 	Env []portainer.Pair
 	// Force a pulling to current image with the original tag though the image is already the latest
 	PullImage bool `example:"false"`
+	// Optional health check of the containers after the deployment, the previous revision of the stack
+	// is redeployed when they do not become healthy
+	DeployPolicy *portainer.StackDeployPolicy
+	// Remove the deploy policy of the stack. The deploy policy is kept when neither DeployPolicy nor RemoveDeployPolicy is specified
+	RemoveDeployPolicy bool `example:"false"`
 }
 
 func (payload *updateComposeStackPayload) Validate(r *http.Request) error {
 	if govalidator.IsNull(payload.StackFileContent) {
 		return errors.New("Invalid stack file content")
 	}
-	return nil
+	if payload.DeployPolicy != nil && payload.RemoveDeployPolicy {
+		return errors.New("DeployPolicy and RemoveDeployPolicy cannot be used together")
+	}
+	return stackutils.ValidateStackDeployPolicy(payload.DeployPolicy)
 }
 
 type updateSwarmStackPayload struct {
@@ -56,6 +64,7 @@ func (payload *updateSwarmStackPayload) Validate(r *http.Request) error {
 // @id StackUpdate
 // @summary Update a stack
 // @description Update a stack, only for file based stacks.
+// @description With the preview parameter, the changes the update would apply are returned as a deployments.StackPreview instead.
 // @description **Access policy**: authenticated
 // @tags stacks
 // @security ApiKeyAuth
@@ -64,6 +73,7 @@ func (payload *updateSwarmStackPayload) Validate(r *http.Request) error {
 // @produce json
 // @param id path int true "Stack identifier"
 // @param endpointId query int false "Stacks created before version 1.18.0 might not have an associated environment(endpoint) identifier. Use this optional parameter to set the environment(endpoint) identifier used by the stack."
+// @param preview query bool false "Return the changes the update would apply without persisting nor deploying it"
 // @param body body updateSwarmStackPayload true "Stack details"
 // @success 200 {object} portainer.Stack "Success"
 // @failure 400 "Invalid request"
@@ -138,6 +148,19 @@ func (handler *Handler) stackUpdate(w http.ResponseWriter, r *http.Request) *htt
 		return httperror.Forbidden(errMsg, errors.New(errMsg))
 	}
 
+	canRun, err := handler.userCanRunStackOperation(securityContext, endpoint, portainer.OperationPortainerStackUpdate)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to update the stack", httperrors.ErrResourceAccessDenied)
+	}
+
+	preview, _ := request.RetrieveBooleanQueryParameter(r, "preview", true)
+	if preview {
+		return handler.previewStackUpdate(w, r, stack, endpoint)
+	}
+
 	updateError := handler.updateAndDeployStack(r, stack, endpoint)
 	if updateError != nil {
 		return updateError
@@ -156,6 +179,10 @@ func (handler *Handler) stackUpdate(w http.ResponseWriter, r *http.Request) *htt
 		return httperror.InternalServerError("Unable to persist the stack changes inside the database", err)
 	}
 
+	if err := deployments.RecordStackVersion(handler.DataStore, stack, user.Username); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to record the stack version")
+	}
+
 	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
 		// sanitize password in the http response to minimise possible security leaks
 		stack.GitConfig.Authentication.Password = ""
@@ -193,6 +220,7 @@ func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Sta
 	}
 
 	stack.Env = payload.Env
+	stackutils.UpdateStackDeployPolicy(stack, payload.DeployPolicy, payload.RemoveDeployPolicy)
 
 	stackFolder := strconv.Itoa(int(stack.ID))
 	_, err = handler.FileService.UpdateStoreStackFileFromBytes(stackFolder, stack.EntryPoint, []byte(payload.StackFileContent))
@@ -204,6 +232,14 @@ func (handler *Handler) updateComposeStack(r *http.Request, stack *portainer.Sta
 		return httperror.InternalServerError("Unable to persist updated Compose file on disk", err)
 	}
 
+	if err := deployments.ValidateStackVersionSize(stack); err != nil {
+		if rollbackErr := handler.FileService.RollbackStackFile(stackFolder, stack.EntryPoint); rollbackErr != nil {
+			log.Warn().Err(rollbackErr).Msg("rollback stack file error")
+		}
+
+		return httperror.BadRequest(err.Error(), err)
+	}
+
 	// Create compose deployment config
 	securityContext, err := security.RetrieveRestrictedRequestContext(r)
 	if err != nil {
@@ -269,6 +305,14 @@ func (handler *Handler) updateSwarmStack(r *http.Request, stack *portainer.Stack
 		return httperror.InternalServerError("Unable to persist updated Compose file on disk", err)
 	}
 
+	if err := deployments.ValidateStackVersionSize(stack); err != nil {
+		if rollbackErr := handler.FileService.RollbackStackFile(stackFolder, stack.EntryPoint); rollbackErr != nil {
+			log.Warn().Err(rollbackErr).Msg("rollback stack file error")
+		}
+
+		return httperror.BadRequest(err.Error(), err)
+	}
+
 	// Create swarm deployment config
 	securityContext, err := security.RetrieveRestrictedRequestContext(r)
 	if err != nil {
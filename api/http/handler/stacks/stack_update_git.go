@@ -5,6 +5,7 @@ import (
 	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/git"
 	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
 	"github.com/cloudogu/portainer-ce/api/http/security"
@@ -24,12 +25,30 @@ type stackGitUpdatePayload struct {
 	RepositoryAuthentication bool
 	RepositoryUsername       string
 	RepositoryPassword       string
+	// Identifier of the saved git credential used instead of the username and password
+	RepositoryGitCredentialID int
+	// Optional path filter restricting the automatic redeploys to the changes of the files the stack is deployed from
+	PathFilter *gittypes.PathFilter
+	// Optional health check of the containers of a compose stack after its deployments, the previous revision
+	// of the stack is redeployed when they do not become healthy
+	DeployPolicy *portainer.StackDeployPolicy
+	// Remove the deploy policy of the stack. The deploy policy is kept when neither DeployPolicy nor RemoveDeployPolicy is specified
+	RemoveDeployPolicy bool `example:"false"`
 }
 
 func (payload *stackGitUpdatePayload) Validate(r *http.Request) error {
 	if err := stackutils.ValidateStackAutoUpdate(payload.AutoUpdate); err != nil {
 		return err
 	}
+	if err := stackutils.ValidateStackPathFilter(payload.PathFilter); err != nil {
+		return err
+	}
+	if payload.DeployPolicy != nil && payload.RemoveDeployPolicy {
+		return errors.New("DeployPolicy and RemoveDeployPolicy cannot be used together")
+	}
+	if err := stackutils.ValidateStackDeployPolicy(payload.DeployPolicy); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -130,6 +149,14 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 		return httperror.Forbidden(errMsg, errors.New(errMsg))
 	}
 
+	canRun, err := handler.userCanRunStackOperation(securityContext, endpoint, portainer.OperationPortainerStackUpdate)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to update the stack", httperrors.ErrResourceAccessDenied)
+	}
+
 	//stop the autoupdate job if there is any
 	if stack.AutoUpdate != nil {
 		deployments.StopAutoupdate(stack.ID, stack.AutoUpdate.JobID, handler.Scheduler)
@@ -138,6 +165,7 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 	//update retrieved stack data based on the payload
 	stack.GitConfig.ReferenceName = payload.RepositoryReferenceName
 	stack.AutoUpdate = payload.AutoUpdate
+	stack.GitConfig.PathFilter = payload.PathFilter
 	stack.Env = payload.Env
 	stack.UpdatedBy = user.Username
 	stack.UpdateDate = time.Now().Unix()
@@ -146,18 +174,25 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 		stack.Option = &portainer.StackOption{
 			Prune: payload.Prune,
 		}
+	} else if stack.Type == portainer.DockerComposeStack {
+		stackutils.UpdateStackDeployPolicy(stack, payload.DeployPolicy, payload.RemoveDeployPolicy)
 	}
 
 	if payload.RepositoryAuthentication {
-		password := payload.RepositoryPassword
-		if password == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
-			password = stack.GitConfig.Authentication.Password
+		auth, httpErr := handler.gitAuthenticationFromPayload(stack, payload.RepositoryUsername, payload.RepositoryPassword, payload.RepositoryGitCredentialID, user.ID)
+		if httpErr != nil {
+			return httpErr
 		}
-		stack.GitConfig.Authentication = &gittypes.GitAuthentication{
-			Username: payload.RepositoryUsername,
-			Password: password,
+		stack.GitConfig.Authentication = auth
+
+		username, password, err := git.GetCredentials(auth, stack.GitConfig.URL, handler.DataStore.GitCredential())
+		if errors.Is(err, git.ErrSSHKeyRequiresSSHURL) {
+			return httperror.BadRequest("Invalid git credential", err)
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the git credentials", err)
 		}
-		_, err = handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, stack.GitConfig.Authentication.Username, stack.GitConfig.Authentication.Password)
+
+		_, err = handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, username, password)
 		if err != nil {
 			return httperror.InternalServerError("Unable to fetch git repository", err)
 		}
@@ -166,7 +201,7 @@ func (handler *Handler) stackUpdateGit(w http.ResponseWriter, r *http.Request) *
 	}
 
 	if payload.AutoUpdate != nil && payload.AutoUpdate.Interval != "" {
-		jobID, e := deployments.StartAutoupdate(stack.ID, stack.AutoUpdate.Interval, handler.Scheduler, handler.StackDeployer, handler.DataStore, handler.GitService)
+		jobID, e := deployments.StartAutoupdate(stack.ID, stack.AutoUpdate.Interval, handler.Scheduler, handler.StackDeployer, handler.DataStore, handler.GitService, handler.NotificationService)
 		if e != nil {
 			return e
 		}
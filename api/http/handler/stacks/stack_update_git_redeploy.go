@@ -7,6 +7,7 @@ import (
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/git"
 	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	k "github.com/cloudogu/portainer-ce/api/kubernetes"
@@ -25,8 +26,10 @@ type stackGitRedployPayload struct {
 	RepositoryAuthentication bool
 	RepositoryUsername       string
 	RepositoryPassword       string
-	Env                      []portainer.Pair
-	Prune                    bool
+	// Identifier of the saved git credential used instead of the username and password
+	RepositoryGitCredentialID int
+	Env                       []portainer.Pair
+	Prune                     bool
 	// Force a pulling to current image with the original tag though the image is already the latest
 	PullImage bool `example:"false"`
 }
@@ -38,6 +41,7 @@ func (payload *stackGitRedployPayload) Validate(r *http.Request) error {
 // @id StackGitRedeploy
 // @summary Redeploy a stack
 // @description Pull and redeploy a stack via Git
+// @description With the preview parameter, the changes the redeploy would apply are returned as a deployments.StackPreview instead.
 // @description **Access policy**: authenticated
 // @tags stacks
 // @security ApiKeyAuth
@@ -46,6 +50,7 @@ func (payload *stackGitRedployPayload) Validate(r *http.Request) error {
 // @produce json
 // @param id path int true "Stack identifier"
 // @param endpointId query int false "Stacks created before version 1.18.0 might not have an associated environment(endpoint) identifier. Use this optional parameter to set the environment(endpoint) identifier used by the stack."
+// @param preview query bool false "Return the changes the redeploy would apply without persisting nor deploying it"
 // @param body body stackGitRedployPayload true "Git configs for pull and redeploy a stack"
 // @success 200 {object} portainer.Stack "Success"
 // @failure 400 "Invalid request"
@@ -124,12 +129,25 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		return httperror.Forbidden(errMsg, errors.New(errMsg))
 	}
 
+	canRun, err := handler.userCanRunStackOperation(securityContext, endpoint, portainer.OperationPortainerStackUpdate)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to update the stack", httperrors.ErrResourceAccessDenied)
+	}
+
 	var payload stackGitRedployPayload
 	err = request.DecodeAndValidateJSONPayload(r, &payload)
 	if err != nil {
 		return httperror.BadRequest("Invalid request payload", err)
 	}
 
+	preview, _ := request.RetrieveBooleanQueryParameter(r, "preview", true)
+	if preview {
+		return handler.previewStackGitRedeploy(w, r, stack, endpoint, &payload, securityContext.UserID)
+	}
+
 	stack.GitConfig.ReferenceName = payload.RepositoryReferenceName
 	stack.Env = payload.Env
 	if stack.Type == portainer.DockerSwarmStack {
@@ -147,11 +165,17 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 	repositoryUsername := ""
 	repositoryPassword := ""
 	if payload.RepositoryAuthentication {
-		repositoryPassword = payload.RepositoryPassword
-		if repositoryPassword == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
-			repositoryPassword = stack.GitConfig.Authentication.Password
+		auth, httpErr := handler.gitAuthenticationFromPayload(stack, payload.RepositoryUsername, payload.RepositoryPassword, payload.RepositoryGitCredentialID, securityContext.UserID)
+		if httpErr != nil {
+			return httpErr
+		}
+
+		repositoryUsername, repositoryPassword, err = git.GetCredentials(auth, stack.GitConfig.URL, handler.DataStore.GitCredential())
+		if errors.Is(err, git.ErrSSHKeyRequiresSSHURL) {
+			return httperror.BadRequest("Invalid git credential", err)
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the git credentials", err)
 		}
-		repositoryUsername = payload.RepositoryUsername
 	}
 
 	err = handler.GitService.CloneRepository(stack.ProjectPath, stack.GitConfig.URL, payload.RepositoryReferenceName, repositoryUsername, repositoryPassword)
@@ -164,6 +188,19 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		return httperror.InternalServerError("Unable to clone git repository", err)
 	}
 
+	if err := deployments.ValidateStackVersionSize(stack); err != nil {
+		if removeErr := handler.FileService.RemoveDirectory(stack.ProjectPath); removeErr != nil {
+			log.Warn().Err(removeErr).Msg("unable to remove git repository directory")
+		}
+
+		restoreError := filesystem.MoveDirectory(backupProjectPath, stack.ProjectPath)
+		if restoreError != nil {
+			log.Warn().Err(restoreError).Msg("failed restoring backup folder")
+		}
+
+		return httperror.BadRequest(err.Error(), err)
+	}
+
 	defer func() {
 		err = handler.FileService.RemoveDirectory(backupProjectPath)
 		if err != nil {
@@ -195,6 +232,10 @@ func (handler *Handler) stackGitRedeploy(w http.ResponseWriter, r *http.Request)
 		return httperror.InternalServerError("Unable to persist the stack changes inside the database", errors.Wrap(err, "failed to update the stack"))
 	}
 
+	if err := deployments.RecordStackVersion(handler.DataStore, stack, user.Username); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to record the stack version")
+	}
+
 	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
 		// sanitize password in the http response to minimise possible security leaks
 		stack.GitConfig.Authentication.Password = ""
@@ -0,0 +1,181 @@
+package stacks
+
+import (
+	"net/http"
+	"os"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/git"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	k "github.com/cloudogu/portainer-ce/api/kubernetes"
+	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
+	"github.com/pkg/errors"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+// previewStackUpdate returns the changes an update of a file based stack would apply, without persisting nor deploying it
+func (handler *Handler) previewStackUpdate(w http.ResponseWriter, r *http.Request, stack *portainer.Stack, endpoint *portainer.Endpoint) *httperror.HandlerError {
+	var stackFileContent string
+	env := stack.Env
+
+	switch stack.Type {
+	case portainer.DockerComposeStack:
+		var payload updateComposeStackPayload
+		if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+			return httperror.BadRequest("Invalid request payload", err)
+		}
+		stackFileContent, env = payload.StackFileContent, payload.Env
+	case portainer.DockerSwarmStack:
+		var payload updateSwarmStackPayload
+		if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+			return httperror.BadRequest("Invalid request payload", err)
+		}
+		stackFileContent, env = payload.StackFileContent, payload.Env
+	case portainer.KubernetesStack:
+		if stack.GitConfig != nil {
+			return httperror.BadRequest("The update of a git Kubernetes stack does not deploy it, preview its redeploy instead", errors.New("git Kubernetes stack"))
+		}
+
+		var payload kubernetesFileStackUpdatePayload
+		if err := request.DecodeAndValidateJSONPayload(r, &payload); err != nil {
+			return httperror.BadRequest("Invalid request payload", err)
+		}
+		stackFileContent = payload.StackFileContent
+	default:
+		return httperror.InternalServerError("Unsupported stack", errors.Errorf("unsupported stack type: %v", stack.Type))
+	}
+
+	updatedStack, cleanup, err := copyStackProject(stack)
+	if err != nil {
+		return httperror.InternalServerError("Unable to copy the stack files", err)
+	}
+	defer cleanup()
+
+	updatedStack.Env = env
+
+	err = filesystem.WriteToFile(filesystem.JoinPaths(updatedStack.ProjectPath, updatedStack.EntryPoint), []byte(stackFileContent))
+	if err != nil {
+		return httperror.InternalServerError("Unable to write the updated stack file", err)
+	}
+
+	preview, httpErr := handler.previewStack(r, stack, updatedStack, endpoint, "content")
+	if httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, preview)
+}
+
+// previewStackGitRedeploy returns the changes a redeploy of a git stack would apply, without persisting nor deploying it
+func (handler *Handler) previewStackGitRedeploy(w http.ResponseWriter, r *http.Request, stack *portainer.Stack, endpoint *portainer.Endpoint, payload *stackGitRedployPayload, userID portainer.UserID) *httperror.HandlerError {
+	repositoryUsername := ""
+	repositoryPassword := ""
+	if payload.RepositoryAuthentication {
+		auth, httpErr := handler.gitAuthenticationFromPayload(stack, payload.RepositoryUsername, payload.RepositoryPassword, payload.RepositoryGitCredentialID, userID)
+		if httpErr != nil {
+			return httpErr
+		}
+
+		var err error
+		repositoryUsername, repositoryPassword, err = git.GetCredentials(auth, stack.GitConfig.URL, handler.DataStore.GitCredential())
+		if errors.Is(err, git.ErrSSHKeyRequiresSSHURL) {
+			return httperror.BadRequest("Invalid git credential", err)
+		} else if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the git credentials", err)
+		}
+	}
+
+	cloneDir, err := os.MkdirTemp("", "stack_preview")
+	if err != nil {
+		return httperror.InternalServerError("Unable to create a temporary directory", err)
+	}
+	defer os.RemoveAll(cloneDir)
+
+	err = handler.GitService.CloneRepository(cloneDir, stack.GitConfig.URL, payload.RepositoryReferenceName, repositoryUsername, repositoryPassword)
+	if err != nil {
+		return httperror.InternalServerError("Unable to clone git repository", err)
+	}
+
+	updatedStack := *stack
+	updatedStack.ProjectPath = cloneDir
+	updatedStack.Env = payload.Env
+
+	preview, httpErr := handler.previewStack(r, stack, &updatedStack, endpoint, "git")
+	if httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, preview)
+}
+
+// previewStack compares the deployed stack with its update. The rendered compose configurations are compared for
+// the compose and swarm stacks, while the update of a Kubernetes stack is compared with the live resources
+func (handler *Handler) previewStack(r *http.Request, deployedStack, updatedStack *portainer.Stack, endpoint *portainer.Endpoint, kind string) (*deployments.StackPreview, *httperror.HandlerError) {
+	if updatedStack.Type != portainer.KubernetesStack {
+		preview, err := deployments.PreviewComposeStack(r.Context(), handler.ComposeStackManager, deployedStack, updatedStack)
+		if err != nil {
+			return nil, httperror.InternalServerError("Unable to preview the stack update", err)
+		}
+
+		return preview, nil
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return nil, httperror.BadRequest("Failed to retrieve user token data", err)
+	}
+
+	user := &portainer.User{
+		ID:       tokenData.ID,
+		Username: tokenData.Username,
+	}
+
+	// same labels as the ones applied by the update or the redeploy
+	owner := updatedStack.CreatedBy
+	if kind == "git" {
+		owner = tokenData.Username
+	}
+
+	appLabels := k.KubeAppLabels{
+		StackID:   int(updatedStack.ID),
+		StackName: updatedStack.Name,
+		Owner:     owner,
+		Kind:      kind,
+	}
+
+	deploymentConfig, err := deployments.CreateKubernetesStackDeploymentConfig(updatedStack, handler.KubernetesDeployer, appLabels, user, endpoint)
+	if err != nil {
+		return nil, httperror.InternalServerError(err.Error(), err)
+	}
+
+	diff, err := deploymentConfig.Preview()
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to preview the stack update", err)
+	}
+
+	return &deployments.StackPreview{Diff: diff}, nil
+}
+
+// copyStackProject returns a copy of the stack whose project is copied inside a temporary directory,
+// the returned cleanup function removes it
+func copyStackProject(stack *portainer.Stack) (*portainer.Stack, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "stack_preview")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	if err := filesystem.CopyDir(stack.ProjectPath, tmpDir, false); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	updatedStack := *stack
+	updatedStack.ProjectPath = tmpDir
+
+	return &updatedStack, cleanup, nil
+}
@@ -0,0 +1,161 @@
+package stacks
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/pmezard/go-difflib/difflib"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type stackVersionDiffResponse struct {
+	// Revision the changes are computed from
+	From int `example:"1"`
+	// Revision the changes are computed to
+	To int `example:"2"`
+	// Unified diffs of the files which changed between the revisions
+	Files []stackVersionFileDiff
+	// Unified diff of the environment variables, empty when they did not change
+	Env string `example:"--- env\n+++ env\n@@ -1 +1 @@\n-TAG=1.0\n+TAG=1.1\n"`
+	// Git commit hashes of the revisions, for git stacks
+	FromConfigHash string `json:",omitempty" example:"bc4c183d756879ea4d173315338110b31004b8e0"`
+	ToConfigHash   string `json:",omitempty" example:"8d7e35a19aa1cb67a8c7b0c5eb2e3edc1b4e5fe3"`
+}
+
+type stackVersionFileDiff struct {
+	// Path of the file relative to the stack project path
+	Path string `example:"docker-compose.yml"`
+	// Unified diff of the file
+	Diff string `example:"--- a/docker-compose.yml\n+++ b/docker-compose.yml\n@@ -3 +3 @@\n-    image: nginx:1.24\n+    image: nginx:1.25\n"`
+}
+
+// @id StackVersionDiff
+// @summary Compare two revisions of a stack
+// @description Compute the unified diffs of the files and environment variables between two deployed revisions of a stack.
+// @description **Access policy**: restricted
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack identifier"
+// @param from query int true "Revision the changes are computed from"
+// @param to query int true "Revision the changes are computed to"
+// @success 200 {object} stackVersionDiffResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack or revision not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/versions/diff [get]
+func (handler *Handler) stackVersionDiff(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	from, err := request.RetrieveNumericQueryParameter(r, "from", false)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: from", err)
+	}
+
+	to, err := request.RetrieveNumericQueryParameter(r, "to", false)
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: to", err)
+	}
+
+	stack, _, httpErr := handler.retrieveManagedStack(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	fromVersion, httpErr := handler.retrieveStackVersion(stack, from)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	toVersion, httpErr := handler.retrieveStackVersion(stack, to)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	diff, err := diffStackVersions(fromVersion, toVersion)
+	if err != nil {
+		return httperror.InternalServerError("Unable to compare the stack revisions", err)
+	}
+
+	return response.JSON(w, diff)
+}
+
+func diffStackVersions(from, to *portainer.StackVersion) (*stackVersionDiffResponse, error) {
+	diff := &stackVersionDiffResponse{
+		From:           from.Version,
+		To:             to.Version,
+		Files:          []stackVersionFileDiff{},
+		FromConfigHash: from.ConfigHash,
+		ToConfigHash:   to.ConfigHash,
+	}
+
+	paths := make([]string, 0, len(from.Files)+len(to.Files))
+	for path := range from.Files {
+		paths = append(paths, path)
+	}
+	for path := range to.Files {
+		if _, ok := from.Files[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fromContent, fromOk := from.Files[path]
+		toContent, toOk := to.Files[path]
+
+		fromFile, toFile := "a/"+path, "b/"+path
+		if !fromOk {
+			fromFile = "/dev/null"
+		}
+		if !toOk {
+			toFile = "/dev/null"
+		}
+
+		fileDiff, err := unifiedDiff(fromFile, toFile, fromContent, toContent)
+		if err != nil {
+			return nil, err
+		}
+
+		if fileDiff != "" {
+			diff.Files = append(diff.Files, stackVersionFileDiff{Path: path, Diff: fileDiff})
+		}
+	}
+
+	envDiff, err := unifiedDiff("env", "env", envContent(from.Env), envContent(to.Env))
+	if err != nil {
+		return nil, err
+	}
+	diff.Env = envDiff
+
+	return diff, nil
+}
+
+func unifiedDiff(fromFile, toFile, fromContent, toContent string) (string, error) {
+	if fromContent == toContent {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(fromContent),
+		B:        difflib.SplitLines(toContent),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3,
+	})
+}
+
+// envContent renders the environment variables as the lines of an env file, sorted by name
+func envContent(env []portainer.Pair) string {
+	lines := make([]string, 0, len(env))
+	for _, pair := range env {
+		lines = append(lines, pair.Name+"="+pair.Value+"\n")
+	}
+	sort.Strings(lines)
+
+	return strings.Join(lines, "")
+}
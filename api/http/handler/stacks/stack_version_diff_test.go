@@ -0,0 +1,52 @@
+package stacks
+
+import (
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_diffStackVersions(t *testing.T) {
+	is := assert.New(t)
+
+	from := &portainer.StackVersion{
+		Version: 1,
+		Files: map[string]string{
+			"docker-compose.yml": "services:\n  web:\n    image: nginx:1.24\n",
+			"removed.yml":        "services: {}\n",
+			"unchanged.yml":      "services: {}\n",
+		},
+		Env: []portainer.Pair{{Name: "TAG", Value: "1"}},
+	}
+
+	to := &portainer.StackVersion{
+		Version: 2,
+		Files: map[string]string{
+			"docker-compose.yml": "services:\n  web:\n    image: nginx:1.25\n",
+			"added.yml":          "services: {}\n",
+			"unchanged.yml":      "services: {}\n",
+		},
+		Env: []portainer.Pair{{Name: "TAG", Value: "1"}},
+	}
+
+	diff, err := diffStackVersions(from, to)
+	is.NoError(err)
+	is.Equal(1, diff.From)
+	is.Equal(2, diff.To)
+	is.Empty(diff.Env)
+
+	is.Len(diff.Files, 3)
+	is.Equal("added.yml", diff.Files[0].Path)
+	is.Contains(diff.Files[0].Diff, "--- /dev/null")
+	is.Equal("docker-compose.yml", diff.Files[1].Path)
+	is.Contains(diff.Files[1].Diff, "-    image: nginx:1.24\n+    image: nginx:1.25\n")
+	is.Equal("removed.yml", diff.Files[2].Path)
+	is.Contains(diff.Files[2].Diff, "+++ /dev/null")
+
+	to.Env = []portainer.Pair{{Name: "TAG", Value: "2"}}
+
+	diff, err = diffStackVersions(from, to)
+	is.NoError(err)
+	is.Contains(diff.Env, "-TAG=1\n+TAG=2\n")
+}
@@ -0,0 +1,130 @@
+package stacks
+
+import (
+	"net/http"
+	"os"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
+	"github.com/pkg/errors"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+	"github.com/rs/zerolog/log"
+)
+
+// @id StackVersionRollback
+// @summary Roll a stack back to a previous revision
+// @description Redeploy the files and environment variables of a previous revision of a stack, recorded as a new revision.
+// @description The git commit of a git stack is left unchanged, so the stack is only updated again once a new commit is pushed.
+// @description **Access policy**: restricted
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack identifier"
+// @param version path int true "Revision number"
+// @success 200 {object} portainer.Stack "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack or revision not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/versions/{version}/rollback [post]
+func (handler *Handler) stackVersionRollback(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stack, _, httpErr := handler.retrieveManagedStack(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	version, err := request.RetrieveNumericRouteVariableValue(r, "version")
+	if err != nil {
+		return httperror.BadRequest("Invalid stack revision route variable", err)
+	}
+
+	stackVersion, httpErr := handler.retrieveStackVersion(stack, version)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	user, err := handler.DataStore.User().User(securityContext.UserID)
+	if err != nil {
+		return httperror.BadRequest("Cannot find context user", errors.Wrap(err, "failed to fetch the user"))
+	}
+
+	backup, err := backupStackFiles(stack, stackVersion)
+	if err != nil {
+		return httperror.InternalServerError("Unable to backup the stack files", err)
+	}
+
+	previousStack := *stack
+
+	err = deployments.RestoreStackVersion(stack, stackVersion)
+	if err != nil {
+		restoreStackFiles(stack, backup)
+		return httperror.InternalServerError("Unable to write the files of the stack revision", err)
+	}
+
+	// the registries of the user rolling back are used for the deployment
+	stack.UpdatedBy = user.Username
+	stack.Status = portainer.StackStatusActive
+
+	err = deployments.RedeployStack(stack, deployments.RedeployOptions{}, handler.StackDeployer, handler.DataStore)
+	if err != nil {
+		restoreStackFiles(stack, backup)
+		*stack = previousStack
+
+		var authorMissingErr *deployments.StackAuthorMissingErr
+		if errors.As(err, &authorMissingErr) {
+			return httperror.BadRequest("Cannot find context user", err)
+		}
+
+		return httperror.InternalServerError("Unable to redeploy the stack revision", err)
+	}
+
+	if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.Password != "" {
+		// sanitize password in the http response to minimise possible security leaks
+		stack.GitConfig.Authentication.Password = ""
+	}
+
+	return response.JSON(w, stack)
+}
+
+// backupStackFiles reads the current contents of the stack files overwritten by a revision,
+// a nil content marking a file which does not exist yet
+func backupStackFiles(stack *portainer.Stack, stackVersion *portainer.StackVersion) (map[string][]byte, error) {
+	backup := make(map[string][]byte, len(stackVersion.Files))
+	for path := range stackVersion.Files {
+		content, err := os.ReadFile(filesystem.JoinPaths(stack.ProjectPath, path))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		backup[path] = content
+	}
+
+	return backup, nil
+}
+
+func restoreStackFiles(stack *portainer.Stack, backup map[string][]byte) {
+	for path, content := range backup {
+		filePath := filesystem.JoinPaths(stack.ProjectPath, path)
+
+		var err error
+		if content == nil {
+			err = os.Remove(filePath)
+		} else {
+			err = filesystem.WriteToFile(filePath, content)
+		}
+
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Warn().Err(err).Str("file", filePath).Msg("unable to restore the stack file")
+		}
+	}
+}
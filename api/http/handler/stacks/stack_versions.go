@@ -0,0 +1,152 @@
+package stacks
+
+import (
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/stacks/stackutils"
+	"github.com/pkg/errors"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id StackVersionList
+// @summary List the revisions of a stack
+// @description List the deployed revisions of a stack, oldest first. The file contents are omitted, see StackVersionInspect.
+// @description **Access policy**: restricted
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack identifier"
+// @success 200 {array} portainer.StackVersion "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/versions [get]
+func (handler *Handler) stackVersionList(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stack, _, httpErr := handler.retrieveManagedStack(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	versions, err := handler.DataStore.StackVersion().StackVersions(stack.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the stack history from the database", err)
+	}
+
+	for i := range versions {
+		versions[i].Files = nil
+	}
+
+	return response.JSON(w, versions)
+}
+
+// @id StackVersionInspect
+// @summary Inspect a revision of a stack
+// @description Retrieve a deployed revision of a stack, including the contents of its files.
+// @description **Access policy**: restricted
+// @tags stacks
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "Stack identifier"
+// @param version path int true "Revision number"
+// @success 200 {object} portainer.StackVersion "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Stack or revision not found"
+// @failure 500 "Server error"
+// @router /stacks/{id}/versions/{version} [get]
+func (handler *Handler) stackVersionInspect(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	stack, _, httpErr := handler.retrieveManagedStack(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	version, err := request.RetrieveNumericRouteVariableValue(r, "version")
+	if err != nil {
+		return httperror.BadRequest("Invalid stack revision route variable", err)
+	}
+
+	stackVersion, httpErr := handler.retrieveStackVersion(stack, version)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	return response.JSON(w, stackVersion)
+}
+
+// retrieveManagedStack retrieves the stack of the request once the user is verified to be allowed to manage it
+func (handler *Handler) retrieveManagedStack(r *http.Request) (*portainer.Stack, *portainer.Endpoint, *httperror.HandlerError) {
+	stackID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, nil, httperror.BadRequest("Invalid stack identifier route variable", err)
+	}
+
+	stack, err := handler.DataStore.Stack().Stack(portainer.StackID(stackID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, nil, httperror.NotFound("Unable to find a stack with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	securityContext, err := security.RetrieveRestrictedRequestContext(r)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to retrieve info from request context", err)
+	}
+
+	endpoint, err := handler.DataStore.Endpoint().Endpoint(stack.EndpointID)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, nil, httperror.NotFound("Unable to find an environment with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to find an environment with the specified identifier inside the database", err)
+	}
+
+	err = handler.requestBouncer.AuthorizedEndpointOperation(r, endpoint)
+	if err != nil {
+		return nil, nil, httperror.Forbidden("Permission denied to access environment", err)
+	}
+
+	if stack.Type == portainer.DockerSwarmStack || stack.Type == portainer.DockerComposeStack {
+		resourceControl, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(stackutils.ResourceControlID(stack.EndpointID, stack.Name), portainer.StackResourceControl)
+		if err != nil {
+			return nil, nil, httperror.InternalServerError("Unable to retrieve a resource control associated to the stack", err)
+		}
+
+		access, err := handler.userCanAccessStack(securityContext, endpoint.ID, resourceControl)
+		if err != nil {
+			return nil, nil, httperror.InternalServerError("Unable to verify user authorizations to validate stack access", err)
+		}
+		if !access {
+			return nil, nil, httperror.Forbidden("Access denied to resource", httperrors.ErrResourceAccessDenied)
+		}
+	}
+
+	canManage, err := handler.userCanManageStacks(securityContext, endpoint)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to verify user authorizations to validate stack management", err)
+	}
+	if !canManage {
+		errMsg := "Stack management is disabled for non-admin users"
+		return nil, nil, httperror.Forbidden(errMsg, errors.New(errMsg))
+	}
+
+	return stack, endpoint, nil
+}
+
+// retrieveStackVersion retrieves a revision of a stack
+func (handler *Handler) retrieveStackVersion(stack *portainer.Stack, version int) (*portainer.StackVersion, *httperror.HandlerError) {
+	stackVersion, err := handler.DataStore.StackVersion().StackVersion(stack.ID, version)
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find the stack revision inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find the stack revision inside the database", err)
+	}
+
+	return stackVersion, nil
+}
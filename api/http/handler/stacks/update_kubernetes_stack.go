@@ -8,6 +8,7 @@ import (
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/git"
 	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	k "github.com/cloudogu/portainer-ce/api/kubernetes"
@@ -30,7 +31,11 @@ type kubernetesGitStackUpdatePayload struct {
 	RepositoryAuthentication bool
 	RepositoryUsername       string
 	RepositoryPassword       string
-	AutoUpdate               *portainer.StackAutoUpdate
+	// Identifier of the saved git credential used instead of the username and password
+	RepositoryGitCredentialID int
+	AutoUpdate                *portainer.StackAutoUpdate
+	// Optional path filter restricting the automatic redeploys to the changes of the files the stack is deployed from
+	PathFilter *gittypes.PathFilter
 }
 
 func (payload *kubernetesFileStackUpdatePayload) Validate(r *http.Request) error {
@@ -44,6 +49,9 @@ func (payload *kubernetesGitStackUpdatePayload) Validate(r *http.Request) error
 	if err := stackutils.ValidateStackAutoUpdate(payload.AutoUpdate); err != nil {
 		return err
 	}
+	if err := stackutils.ValidateStackPathFilter(payload.PathFilter); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -63,17 +71,28 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 
 		stack.GitConfig.ReferenceName = payload.RepositoryReferenceName
 		stack.AutoUpdate = payload.AutoUpdate
+		stack.GitConfig.PathFilter = payload.PathFilter
 
 		if payload.RepositoryAuthentication {
-			password := payload.RepositoryPassword
-			if password == "" && stack.GitConfig != nil && stack.GitConfig.Authentication != nil {
-				password = stack.GitConfig.Authentication.Password
+			tokenData, err := security.RetrieveTokenData(r)
+			if err != nil {
+				return httperror.BadRequest("Failed to retrieve user token data", err)
 			}
-			stack.GitConfig.Authentication = &gittypes.GitAuthentication{
-				Username: payload.RepositoryUsername,
-				Password: password,
+
+			auth, httpErr := handler.gitAuthenticationFromPayload(stack, payload.RepositoryUsername, payload.RepositoryPassword, payload.RepositoryGitCredentialID, tokenData.ID)
+			if httpErr != nil {
+				return httpErr
 			}
-			_, err := handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, stack.GitConfig.Authentication.Username, stack.GitConfig.Authentication.Password)
+			stack.GitConfig.Authentication = auth
+
+			username, password, err := git.GetCredentials(auth, stack.GitConfig.URL, handler.DataStore.GitCredential())
+			if errors.Is(err, git.ErrSSHKeyRequiresSSHURL) {
+				return httperror.BadRequest("Invalid git credential", err)
+			} else if err != nil {
+				return httperror.InternalServerError("Unable to retrieve the git credentials", err)
+			}
+
+			_, err = handler.GitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, username, password)
 			if err != nil {
 				return httperror.InternalServerError("Unable to fetch git repository", err)
 			}
@@ -82,7 +101,7 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 		}
 
 		if payload.AutoUpdate != nil && payload.AutoUpdate.Interval != "" {
-			jobID, e := deployments.StartAutoupdate(stack.ID, stack.AutoUpdate.Interval, handler.Scheduler, handler.StackDeployer, handler.DataStore, handler.GitService)
+			jobID, e := deployments.StartAutoupdate(stack.ID, stack.AutoUpdate.Interval, handler.Scheduler, handler.StackDeployer, handler.DataStore, handler.GitService, handler.NotificationService)
 			if e != nil {
 				return e
 			}
@@ -115,6 +134,10 @@ func (handler *Handler) updateKubernetesStack(r *http.Request, stack *portainer.
 	//so if the deployment failed, the original file won't be over-written
 	stack.ProjectPath = tempFileDir
 
+	if err := deployments.ValidateStackVersionSize(stack); err != nil {
+		return httperror.BadRequest(err.Error(), err)
+	}
+
 	_, err = handler.deployKubernetesStack(tokenData.ID, endpoint, stack, k.KubeAppLabels{
 		StackID:   int(stack.ID),
 		StackName: stack.Name,
@@ -3,6 +3,7 @@ package users
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	portainer "github.com/cloudogu/portainer-ce/api"
@@ -70,6 +71,7 @@ func (handler *Handler) adminInit(w http.ResponseWriter, r *http.Request) *httpe
 	if err != nil {
 		return httperror.InternalServerError("Unable to hash user password", errCryptoHashFailure)
 	}
+	user.PasswordChangedAt = time.Now().Unix()
 
 	err = handler.DataStore.User().Create(user)
 	if err != nil {
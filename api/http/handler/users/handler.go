@@ -26,6 +26,8 @@ var (
 
 func hideFields(user *portainer.User) {
 	user.Password = ""
+	user.TwoFactor = portainer.UserTwoFactor{Enabled: user.TwoFactor.Enabled}
+	user.PasswordHistory = nil
 }
 
 // Handler is the HTTP handler used to handle user operations.
@@ -72,7 +74,20 @@ func NewHandler(bouncer *security.RequestBouncer, rateLimiter *security.RateLimi
 	restrictedRouter.Handle("/users/{id}/tokens", httperror.LoggerHandler(h.userGetAccessTokens)).Methods(http.MethodGet)
 	restrictedRouter.Handle("/users/{id}/tokens", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userCreateAccessToken))).Methods(http.MethodPost)
 	restrictedRouter.Handle("/users/{id}/tokens/{keyID}", httperror.LoggerHandler(h.userRemoveAccessToken)).Methods(http.MethodDelete)
+	restrictedRouter.Handle("/users/{id}/sessions", httperror.LoggerHandler(h.userListSessions)).Methods(http.MethodGet)
+	restrictedRouter.Handle("/users/{id}/sessions", httperror.LoggerHandler(h.userRevokeSessions)).Methods(http.MethodDelete)
+	restrictedRouter.Handle("/users/{id}/sessions/{sessionID}", httperror.LoggerHandler(h.userRevokeSession)).Methods(http.MethodDelete)
+	restrictedRouter.Handle("/users/{id}/gitcredentials", httperror.LoggerHandler(h.userGetGitCredentials)).Methods(http.MethodGet)
+	restrictedRouter.Handle("/users/{id}/gitcredentials", httperror.LoggerHandler(h.userCreateGitCredential)).Methods(http.MethodPost)
+	restrictedRouter.Handle("/users/{id}/gitcredentials/{credentialID}", httperror.LoggerHandler(h.userGetGitCredential)).Methods(http.MethodGet)
+	restrictedRouter.Handle("/users/{id}/gitcredentials/{credentialID}", httperror.LoggerHandler(h.userUpdateGitCredential)).Methods(http.MethodPut)
+	restrictedRouter.Handle("/users/{id}/gitcredentials/{credentialID}", httperror.LoggerHandler(h.userRemoveGitCredential)).Methods(http.MethodDelete)
+	restrictedRouter.Handle("/users/{id}/gitcredentials/{credentialID}/publickey", httperror.LoggerHandler(h.userGetGitCredentialPublicKey)).Methods(http.MethodGet)
 	restrictedRouter.Handle("/users/{id}/memberships", httperror.LoggerHandler(h.userMemberships)).Methods(http.MethodGet)
+	authenticatedRouter.Handle("/users/{id}/2fa/enroll", httperror.LoggerHandler(h.userTwoFactorEnroll)).Methods(http.MethodPost)
+	authenticatedRouter.Handle("/users/{id}/2fa/enable", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userTwoFactorEnable))).Methods(http.MethodPost)
+	adminRouter.Handle("/users/{id}/2fa", httperror.LoggerHandler(h.userTwoFactorReset)).Methods(http.MethodDelete)
+	adminRouter.Handle("/users/{id}/unlock", httperror.LoggerHandler(h.userUnlock)).Methods(http.MethodPost)
 	authenticatedRouter.Handle("/users/{id}/passwd", rateLimiter.LimitAccess(httperror.LoggerHandler(h.userUpdatePassword))).Methods(http.MethodPut)
 	publicRouter.Handle("/users/admin/check", httperror.LoggerHandler(h.adminCheck)).Methods(http.MethodGet)
 	publicRouter.Handle("/users/admin/init", httperror.LoggerHandler(h.adminInit)).Methods(http.MethodPost)
@@ -3,6 +3,7 @@ package users
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	portainer "github.com/cloudogu/portainer-ce/api"
@@ -86,6 +87,7 @@ func (handler *Handler) userCreate(w http.ResponseWriter, r *http.Request) *http
 		if err != nil {
 			return httperror.InternalServerError("Unable to hash user password", errCryptoHashFailure)
 		}
+		user.PasswordChangedAt = time.Now().Unix()
 	}
 
 	err = handler.DataStore.User().Create(user)
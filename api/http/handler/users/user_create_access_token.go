@@ -3,9 +3,11 @@ package users
 import (
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/apikey"
 	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	httperror "github.com/portainer/libhttp/error"
@@ -15,6 +17,14 @@ import (
 
 type userAccessTokenCreatePayload struct {
 	Description string `validate:"required" example:"github-api-key" json:"description"`
+	// Unix timestamp (UTC) from which the API key is rejected, the API key never expires when 0
+	ExpiresAt int64 `example:"1704067200" json:"expiresAt"`
+	// Whether the API key is limited to the GET, HEAD and OPTIONS requests
+	ReadOnly bool `example:"true" json:"readOnly"`
+	// Environments(endpoints) the API key can access, all of them when empty
+	EndpointIDs []portainer.EndpointID `example:"1" json:"endpointIds"`
+	// API paths and methods the API key can access, all of them when empty
+	Scopes []portainer.APIKeyScope `json:"scopes"`
 }
 
 func (payload *userAccessTokenCreatePayload) Validate(r *http.Request) error {
@@ -27,7 +37,18 @@ func (payload *userAccessTokenCreatePayload) Validate(r *http.Request) error {
 	if govalidator.MinStringLength(payload.Description, "128") {
 		return errors.New("invalid description. cannot be longer than 128 characters")
 	}
-	return nil
+	if payload.ExpiresAt != 0 && payload.ExpiresAt <= time.Now().Unix() {
+		return errors.New("invalid expiry date. must be in the future")
+	}
+	return apikey.ValidateRestrictions(payload.restrictions())
+}
+
+func (payload *userAccessTokenCreatePayload) restrictions() *portainer.APIKeyRestrictions {
+	return &portainer.APIKeyRestrictions{
+		ReadOnly:    payload.ReadOnly,
+		EndpointIDs: payload.EndpointIDs,
+		Scopes:      payload.Scopes,
+	}
 }
 
 type accessTokenResponse struct {
@@ -39,6 +60,7 @@ type accessTokenResponse struct {
 // @summary Generate an API key for a user
 // @description Generates an API key for a user.
 // @description Only the calling user can generate a token for themselves.
+// @description The API key can expire and be restricted to read-only requests, to some environments and to some API paths and methods.
 // @description **Access policy**: restricted
 // @tags users
 // @security jwt
@@ -84,7 +106,7 @@ func (handler *Handler) userCreateAccessToken(w http.ResponseWriter, r *http.Req
 		return httperror.BadRequest("Unable to find a user", err)
 	}
 
-	rawAPIKey, apiKey, err := handler.apiKeyService.GenerateApiKey(*user, payload.Description)
+	rawAPIKey, apiKey, err := handler.apiKeyService.GenerateRestrictedApiKey(*user, payload.Description, payload.ExpiresAt, *payload.restrictions())
 	if err != nil {
 		return httperror.InternalServerError("Internal Server Error", err)
 	}
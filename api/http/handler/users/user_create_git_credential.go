@@ -0,0 +1,128 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/git"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type userGitCredentialCreatePayload struct {
+	// Name of the credential
+	Name string `validate:"required" example:"github-token" json:"name"`
+	// Username used in basic authentication, or the SSH user (defaults to git) of an SSH deploy key
+	Username string `example:"myGitUsername" json:"username"`
+	// Password or access token used in basic authentication
+	Password string `example:"myGitPassword" json:"password"`
+	// PEM encoded private key of an existing SSH deploy key
+	SSHPrivateKey string `json:"sshPrivateKey"`
+	// Generate an ED25519 SSH deploy key, whose public key is returned
+	GenerateSSHKey bool `example:"false" json:"generateSSHKey"`
+}
+
+func (payload *userGitCredentialCreatePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Name) || govalidator.HasWhitespaceOnly(payload.Name) {
+		return errors.New("invalid name. cannot be empty")
+	}
+	if govalidator.MinStringLength(payload.Name, "128") {
+		return errors.New("invalid name. cannot be longer than 128 characters")
+	}
+
+	secrets := 0
+	for _, provided := range []bool{!govalidator.IsNull(payload.Password), !govalidator.IsNull(payload.SSHPrivateKey), payload.GenerateSSHKey} {
+		if provided {
+			secrets++
+		}
+	}
+	if secrets != 1 {
+		return errors.New("invalid credential. exactly one of password, sshPrivateKey or generateSSHKey must be specified")
+	}
+
+	if !govalidator.IsNull(payload.Password) && govalidator.IsNull(payload.Username) {
+		return errors.New("invalid username. cannot be empty")
+	}
+	return nil
+}
+
+// @id UserCreateGitCredential
+// @summary Save a git credential for a user
+// @description Saves a git credential which can be referenced by the git stacks and custom templates of the user.
+// @description The credential is either a username and password, or an SSH deploy key used with SSH repository URLs.
+// @description An SSH deploy key can be generated, in which case its public key is returned.
+// @description The password and the SSH private key are encrypted at rest and never returned.
+// @description Only the calling user can save a git credential for themselves.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "User identifier"
+// @param body body userGitCredentialCreatePayload true "details"
+// @success 201 {object} portainer.GitCredential "Created"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 409 "A git credential with the same name already exists"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials [post]
+func (handler *Handler) userCreateGitCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload userGitCredentialCreatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	userID, httpErr := handler.retrieveGitCredentialsUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	credentials, err := handler.DataStore.GitCredential().GitCredentialsByUserID(userID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the git credentials from the database", err)
+	}
+
+	for _, credential := range credentials {
+		if credential.Name == payload.Name {
+			return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "A git credential with the same name already exists", Err: errors.New("git credential name must be unique")}
+		}
+	}
+
+	credential := &portainer.GitCredential{
+		UserID:       userID,
+		Name:         payload.Name,
+		Username:     payload.Username,
+		Password:     payload.Password,
+		CreationDate: time.Now().Unix(),
+	}
+
+	switch {
+	case payload.GenerateSSHKey:
+		credential.SSHPrivateKey, credential.SSHPublicKey, err = git.GenerateSSHKey(payload.Name)
+		if err != nil {
+			return httperror.InternalServerError("Unable to generate the SSH key", err)
+		}
+	case payload.SSHPrivateKey != "":
+		credential.SSHPublicKey, err = git.SSHPublicKey(payload.SSHPrivateKey, payload.Name)
+		if err != nil {
+			return httperror.BadRequest("Invalid SSH private key", err)
+		}
+		credential.SSHPrivateKey = payload.SSHPrivateKey
+	}
+
+	err = handler.DataStore.GitCredential().Create(credential)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the git credential inside the database", err)
+	}
+
+	hideGitCredentialFields(credential)
+	w.WriteHeader(http.StatusCreated)
+	return response.JSON(w, credential)
+}
@@ -0,0 +1,40 @@
+package users
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id UserGetGitCredential
+// @summary Inspect a git credential of a user
+// @description Retrieves a git credential saved by a user, without its password.
+// @description Only the calling user can inspect their git credentials.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @param credentialID path int true "Git credential identifier"
+// @success 200 {object} portainer.GitCredential "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials/{credentialID} [get]
+func (handler *Handler) userGetGitCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, httpErr := handler.retrieveGitCredentialsUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	credential, httpErr := handler.retrieveGitCredential(r, userID)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	hideGitCredentialFields(credential)
+	return response.JSON(w, credential)
+}
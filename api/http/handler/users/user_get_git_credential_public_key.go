@@ -0,0 +1,52 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+)
+
+// @id UserGetGitCredentialPublicKey
+// @summary Download the public key of an SSH deploy key
+// @description Downloads the public key of an SSH deploy key saved by a user, in the authorized_keys format.
+// @description It is meant to be registered as a deploy key on the git server.
+// @description Only the calling user can download the public keys of their git credentials.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce plain
+// @param id path int true "User identifier"
+// @param credentialID path int true "Git credential identifier"
+// @success 200 {string} string "Success"
+// @failure 400 "The git credential is not an SSH deploy key"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials/{credentialID}/publickey [get]
+func (handler *Handler) userGetGitCredentialPublicKey(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, httpErr := handler.retrieveGitCredentialsUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	credential, httpErr := handler.retrieveGitCredential(r, userID)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if credential.SSHPublicKey == "" {
+		return httperror.BadRequest("The git credential is not an SSH deploy key", errors.New("no SSH public key"))
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("gitcredential-%d.pub", credential.ID)))
+	_, err := w.Write([]byte(credential.SSHPublicKey + "\n"))
+	if err != nil {
+		return httperror.InternalServerError("Unable to write the public key", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package users
+
+import (
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id UserGetGitCredentials
+// @summary List the git credentials of a user
+// @description Lists the git credentials saved by a user, without their passwords.
+// @description Only the calling user can list their git credentials.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @success 200 {array} portainer.GitCredential "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials [get]
+func (handler *Handler) userGetGitCredentials(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, httpErr := handler.retrieveGitCredentialsUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	credentials, err := handler.DataStore.GitCredential().GitCredentialsByUserID(userID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the git credentials from the database", err)
+	}
+
+	for idx := range credentials {
+		hideGitCredentialFields(&credentials[idx])
+	}
+
+	return response.JSON(w, credentials)
+}
@@ -0,0 +1,66 @@
+package users
+
+import (
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+)
+
+// hideGitCredentialFields remove the password and the SSH private key from the git credential (they are never sent back to the client)
+func hideGitCredentialFields(credential *portainer.GitCredential) {
+	credential.Password = ""
+	credential.SSHPrivateKey = ""
+}
+
+// retrieveGitCredentialsUser returns the user of the route, which must be the calling user
+func (handler *Handler) retrieveGitCredentialsUser(r *http.Request) (portainer.UserID, *httperror.HandlerError) {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return 0, httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return 0, httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.ID != portainer.UserID(userID) {
+		return 0, httperror.Forbidden("Permission denied to manage the git credentials of this user", httperrors.ErrUnauthorized)
+	}
+
+	_, err = handler.DataStore.User().User(portainer.UserID(userID))
+	if err != nil {
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return 0, httperror.NotFound("Unable to find a user with the specified identifier inside the database", err)
+		}
+		return 0, httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	return portainer.UserID(userID), nil
+}
+
+// retrieveGitCredential returns the git credential of the route, which must belong to the user
+func (handler *Handler) retrieveGitCredential(r *http.Request, userID portainer.UserID) (*portainer.GitCredential, *httperror.HandlerError) {
+	credentialID, err := request.RetrieveNumericRouteVariableValue(r, "credentialID")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid git credential identifier route variable", err)
+	}
+
+	credential, err := handler.DataStore.GitCredential().GitCredential(portainer.GitCredentialID(credentialID))
+	if err != nil {
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return nil, httperror.NotFound("Unable to find a git credential with the specified identifier inside the database", err)
+		}
+		return nil, httperror.InternalServerError("Unable to find a git credential with the specified identifier inside the database", err)
+	}
+
+	if credential.UserID != userID {
+		return nil, httperror.Forbidden("Permission denied to access this git credential", httperrors.ErrUnauthorized)
+	}
+
+	return credential, nil
+}
@@ -0,0 +1,159 @@
+package users
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/apikey"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userGitCredentials(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	user := &portainer.User{Username: "standard", Role: portainer.StandardUserRole}
+	err := store.User().Create(user)
+	is.NoError(err, "error creating user")
+
+	otherUser := &portainer.User{Username: "other", Role: portainer.StandardUserRole}
+	err = store.User().Create(otherUser)
+	is.NoError(err, "error creating user")
+
+	// setup services
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	passwordChecker := security.NewPasswordStrengthChecker(store.SettingsService)
+
+	h := NewHandler(requestBouncer, rateLimiter, apiKeyService, nil, passwordChecker)
+	h.DataStore = store
+
+	jwt, _ := jwtService.GenerateToken(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+	otherJWT, _ := jwtService.GenerateToken(&portainer.TokenData{ID: otherUser.ID, Username: otherUser.Username, Role: otherUser.Role})
+
+	var created portainer.GitCredential
+
+	t.Run("user can save a git credential, its password is not returned", func(t *testing.T) {
+		data, _ := json.Marshal(userGitCredentialCreatePayload{Name: "github", Username: "bob", Password: "token-1"})
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/users/%d/gitcredentials", user.ID), bytes.NewBuffer(data))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusCreated, rr.Code)
+		is.NoError(json.NewDecoder(rr.Body).Decode(&created))
+		is.Equal("github", created.Name)
+		is.Empty(created.Password)
+
+		credential, err := store.GitCredential().GitCredential(created.ID)
+		is.NoError(err)
+		is.Equal("token-1", credential.Password)
+	})
+
+	t.Run("user can generate an SSH deploy key and download its public key", func(t *testing.T) {
+		data, _ := json.Marshal(userGitCredentialCreatePayload{Name: "gitea", GenerateSSHKey: true})
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/users/%d/gitcredentials", user.ID), bytes.NewBuffer(data))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusCreated, rr.Code)
+
+		var deployKey portainer.GitCredential
+		is.NoError(json.NewDecoder(rr.Body).Decode(&deployKey))
+		is.Empty(deployKey.SSHPrivateKey)
+		is.Contains(deployKey.SSHPublicKey, "ssh-ed25519 ")
+
+		credential, err := store.GitCredential().GitCredential(deployKey.ID)
+		is.NoError(err)
+		is.Contains(credential.SSHPrivateKey, "PRIVATE KEY")
+
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/users/%d/gitcredentials/%d/publickey", user.ID, deployKey.ID), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusOK, rr.Code)
+		is.Equal(deployKey.SSHPublicKey+"\n", rr.Body.String())
+
+		is.NoError(store.GitCredential().DeleteGitCredential(deployKey.ID))
+	})
+
+	t.Run("user cannot access the git credentials of another user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", otherJWT))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("updating a git credential without password keeps the current one", func(t *testing.T) {
+		data, _ := json.Marshal(userGitCredentialUpdatePayload{Username: "alice"})
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), bytes.NewBuffer(data))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusOK, rr.Code)
+
+		credential, err := store.GitCredential().GitCredential(created.ID)
+		is.NoError(err)
+		is.Equal("alice", credential.Username)
+		is.Equal("token-1", credential.Password)
+	})
+
+	t.Run("a git credential used by a stack cannot be removed", func(t *testing.T) {
+		stack := &portainer.Stack{
+			ID:   1,
+			Name: "stack",
+			GitConfig: &gittypes.RepoConfig{
+				URL:            "https://github.com/portainer/portainer",
+				Authentication: &gittypes.GitAuthentication{GitCredentialID: int(created.ID)},
+			},
+		}
+		is.NoError(store.Stack().Create(stack))
+
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusConflict, rr.Code)
+
+		is.NoError(store.Stack().DeleteStack(stack.ID))
+
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), nil))
+		is.Equal(http.StatusUnauthorized, rr.Code)
+
+		req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/users/%d/gitcredentials/%d", user.ID, created.ID), nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr = httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		is.Equal(http.StatusNoContent, rr.Code)
+	})
+}
@@ -0,0 +1,75 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/internal/passwordpolicy"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+// setPassword hashes the new password of a user after checking that it was not used recently, and records the change
+// in the password history of the user
+func (handler *Handler) setPassword(user *portainer.User, password string) *httperror.HandlerError {
+	settings, err := handler.DataStore.Settings().Settings()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve settings from the database", err)
+	}
+
+	err = passwordpolicy.CheckReuse(handler.CryptoService, user, password, &settings.InternalAuthSettings)
+	if errors.Is(err, passwordpolicy.ErrPasswordReused) {
+		return httperror.BadRequest("Password was used recently", err)
+	}
+
+	hash, err := handler.CryptoService.Hash(password)
+	if err != nil {
+		return httperror.InternalServerError("Unable to hash user password", errCryptoHashFailure)
+	}
+
+	passwordpolicy.SetPassword(user, hash, &settings.InternalAuthSettings, time.Now())
+
+	return nil
+}
+
+// @id UserUnlock
+// @summary Unlock a user account
+// @description Unlocks the account of a user locked after too many failed logins.
+// @description When the accounts stay locked until they are unlocked, the last administrator whose account is not locked
+// @description is only locked for 15 minutes, and can then log in to unlock the other accounts.
+// @description **Access policy**: administrator
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "User identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 500 "Server error"
+// @router /users/{id}/unlock [post]
+func (handler *Handler) userUnlock(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	user, err := handler.DataStore.User().User(portainer.UserID(userID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a user with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	passwordpolicy.Unlock(user)
+
+	err = handler.DataStore.User().UpdateUser(user.ID, user)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	return response.Empty(w)
+}
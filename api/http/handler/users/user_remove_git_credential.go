@@ -0,0 +1,58 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+)
+
+// @id UserRemoveGitCredential
+// @summary Remove a git credential of a user
+// @description Removes a git credential saved by a user.
+// @description A credential still referenced by a git stack cannot be removed.
+// @description Only the calling user can remove their git credentials.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "User identifier"
+// @param credentialID path int true "Git credential identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 409 "The git credential is used by a stack"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials/{credentialID} [delete]
+func (handler *Handler) userRemoveGitCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, httpErr := handler.retrieveGitCredentialsUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	credential, httpErr := handler.retrieveGitCredential(r, userID)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	stacks, err := handler.DataStore.Stack().Stacks()
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve stacks from the database", err)
+	}
+
+	for _, stack := range stacks {
+		if stack.GitConfig != nil && stack.GitConfig.Authentication != nil && stack.GitConfig.Authentication.GitCredentialID == int(credential.ID) {
+			return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: fmt.Sprintf("The git credential is used by the stack %s", stack.Name), Err: errors.New("git credential is in use")}
+		}
+	}
+
+	err = handler.DataStore.GitCredential().DeleteGitCredential(credential.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to remove the git credential from the database", err)
+	}
+
+	return response.Empty(w)
+}
@@ -0,0 +1,176 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type userSessionResponse struct {
+	portainer.UserSession
+	// Whether the request was made with this session
+	Current bool `json:"Current" example:"true"`
+}
+
+// @id UserListSessions
+// @summary List the sessions of a user
+// @description Lists the sessions opened by a user which were not revoked and did not expire.
+// @description Only the calling user or admin can list the sessions.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @success 200 {array} userSessionResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 500 "Server error"
+// @router /users/{id}/sessions [get]
+func (handler *Handler) userListSessions(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	user, tokenData, handlerErr := handler.sessionsOwner(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	sessions, err := handler.DataStore.UserSession().UserSessionsByUserID(user.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the sessions of the user from the database", err)
+	}
+
+	now := time.Now().Unix()
+
+	resp := make([]userSessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		if session.ExpiresAt < now {
+			continue
+		}
+
+		resp = append(resp, userSessionResponse{
+			UserSession: session,
+			Current:     session.ID == tokenData.SessionID,
+		})
+	}
+
+	return response.JSON(w, resp)
+}
+
+// @id UserRevokeSession
+// @summary Revoke a session of a user
+// @description Revokes a session of a user, its token is rejected from then on.
+// @description Only the calling user or admin can revoke a session.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "User identifier"
+// @param sessionID path int true "Session identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User or session not found"
+// @failure 500 "Server error"
+// @router /users/{id}/sessions/{sessionID} [delete]
+func (handler *Handler) userRevokeSession(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	user, _, handlerErr := handler.sessionsOwner(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	sessionID, err := request.RetrieveNumericRouteVariableValue(r, "sessionID")
+	if err != nil {
+		return httperror.BadRequest("Invalid session identifier route variable", err)
+	}
+
+	session, err := handler.DataStore.UserSession().UserSession(portainer.UserSessionID(sessionID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a session with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a session with the specified identifier inside the database", err)
+	}
+
+	if session.UserID != user.ID {
+		return httperror.NotFound("Unable to find a session with the specified identifier inside the database", errors.New("the session belongs to another user"))
+	}
+
+	err = handler.DataStore.UserSession().DeleteUserSession(session.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to revoke the session", err)
+	}
+
+	return response.Empty(w)
+}
+
+// @id UserRevokeSessions
+// @summary Sign a user out everywhere
+// @description Revokes all the sessions of a user, including the session of the request when the user signs themselves out.
+// @description The tokens issued before, such as the kubeconfig tokens, are revoked too.
+// @description Only the calling user or admin can revoke the sessions.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "User identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 500 "Server error"
+// @router /users/{id}/sessions [delete]
+func (handler *Handler) userRevokeSessions(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	user, _, handlerErr := handler.sessionsOwner(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	// the tokens without session, such as the kubeconfig tokens, are only revoked by their issue time
+	user.TokenIssueAt = time.Now().Unix()
+
+	err := handler.DataStore.User().UpdateUser(user.ID, user)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	err = handler.DataStore.UserSession().DeleteUserSessionsByUserID(user.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to revoke the sessions of the user", err)
+	}
+
+	return response.Empty(w)
+}
+
+// sessionsOwner returns the user of the route whose sessions are managed, which must be the calling user
+// unless they are an administrator
+func (handler *Handler) sessionsOwner(r *http.Request) (*portainer.User, *portainer.TokenData, *httperror.HandlerError) {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, nil, httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return nil, nil, httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.Role != portainer.AdministratorRole && tokenData.ID != portainer.UserID(userID) {
+		return nil, nil, httperror.Forbidden("Permission denied to manage the sessions of the user", httperrors.ErrUnauthorized)
+	}
+
+	user, err := handler.DataStore.User().User(portainer.UserID(userID))
+	if err != nil {
+		if handler.DataStore.IsErrObjectNotFound(err) {
+			return nil, nil, httperror.NotFound("Unable to find a user with the specified identifier inside the database", err)
+		}
+		return nil, nil, httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	return user, tokenData, nil
+}
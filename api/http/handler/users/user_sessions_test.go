@@ -0,0 +1,139 @@
+package users
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/apikey"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userSessions(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	adminUser := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	err := store.User().Create(adminUser)
+	is.NoError(err, "error creating admin user")
+
+	user := &portainer.User{ID: 2, Username: "standard", Role: portainer.StandardUserRole}
+	err = store.User().Create(user)
+	is.NoError(err, "error creating user")
+
+	otherUser := &portainer.User{ID: 3, Username: "other", Role: portainer.StandardUserRole}
+	err = store.User().Create(otherUser)
+	is.NoError(err, "error creating user")
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	passwordChecker := security.NewPasswordStrengthChecker(store.SettingsService)
+
+	h := NewHandler(requestBouncer, rateLimiter, apiKeyService, nil, passwordChecker)
+	h.DataStore = store
+
+	login := func(u *portainer.User, userAgent string) string {
+		token, err := jwtService.GenerateSessionToken(&portainer.TokenData{ID: u.ID, Username: u.Username, Role: u.Role}, "10.0.0.10", userAgent)
+		is.NoError(err)
+		return token
+	}
+
+	serve := func(method, path, jwt string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	listSessions := func(path, jwt string) []userSessionResponse {
+		rr := serve(http.MethodGet, path, jwt)
+		is.Equal(http.StatusOK, rr.Code)
+
+		var sessions []userSessionResponse
+		is.NoError(json.NewDecoder(rr.Body).Decode(&sessions))
+
+		return sessions
+	}
+
+	adminJWT := login(adminUser, "firefox")
+	browserJWT := login(user, "firefox")
+	cliJWT := login(user, "curl")
+	otherJWT := login(otherUser, "curl")
+
+	t.Run("a user lists their sessions", func(t *testing.T) {
+		sessions := listSessions("/users/2/sessions", browserJWT)
+		is.Len(sessions, 2)
+
+		for _, session := range sessions {
+			is.Equal(user.ID, session.UserID)
+			is.Equal("10.0.0.10", session.IPAddress)
+			is.Equal(session.UserAgent == "firefox", session.Current)
+		}
+	})
+
+	t.Run("a user cannot manage the sessions of another user", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/users/2/sessions", otherJWT)
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		rr = serve(http.MethodDelete, "/users/2/sessions", otherJWT)
+		is.Equal(http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("a user revokes one of their sessions", func(t *testing.T) {
+		var cliSession portainer.UserSessionID
+		for _, session := range listSessions("/users/2/sessions", browserJWT) {
+			if !session.Current {
+				cliSession = session.ID
+			}
+		}
+
+		otherSessions := listSessions("/users/3/sessions", otherJWT)
+		is.Len(otherSessions, 1)
+
+		rr := serve(http.MethodDelete, fmt.Sprintf("/users/2/sessions/%d", otherSessions[0].ID), browserJWT)
+		is.Equal(http.StatusNotFound, rr.Code)
+
+		rr = serve(http.MethodDelete, fmt.Sprintf("/users/2/sessions/%d", cliSession), browserJWT)
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		rr = serve(http.MethodGet, "/users/2/sessions", cliJWT)
+		is.Equal(http.StatusUnauthorized, rr.Code)
+
+		is.Len(listSessions("/users/2/sessions", browserJWT), 1)
+	})
+
+	t.Run("an administrator signs a user out everywhere", func(t *testing.T) {
+		kubeconfigJWT, err := jwtService.GenerateTokenForKubeconfig(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+		is.NoError(err)
+
+		// the tokens are revoked by their issue time, in seconds
+		time.Sleep(time.Second)
+
+		rr := serve(http.MethodDelete, "/users/2/sessions", adminJWT)
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		rr = serve(http.MethodGet, "/users/2/sessions", browserJWT)
+		is.Equal(http.StatusUnauthorized, rr.Code)
+
+		rr = serve(http.MethodGet, "/users/2/sessions", kubeconfigJWT)
+		is.Equal(http.StatusUnauthorized, rr.Code)
+
+		is.Empty(listSessions("/users/2/sessions", adminJWT))
+		is.Len(listSessions("/users/3/sessions", otherJWT), 1)
+	})
+}
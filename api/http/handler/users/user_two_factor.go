@@ -0,0 +1,187 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	portainer "github.com/cloudogu/portainer-ce/api"
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/internal/twofactor"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type userTwoFactorEnablePayload struct {
+	// TOTP code generated from the secret of the enrollment
+	Code string `validate:"required" example:"123456"`
+}
+
+func (payload *userTwoFactorEnablePayload) Validate(r *http.Request) error {
+	if govalidator.IsNull(payload.Code) {
+		return errors.New("invalid code. cannot be empty")
+	}
+	return nil
+}
+
+type userTwoFactorEnableResponse struct {
+	// Single-use codes which can replace a TOTP code, they are only returned once
+	RecoveryCodes []string `json:"RecoveryCodes" example:"k3vq2m7x-p4d8w6ta"`
+}
+
+// @id UserTwoFactorEnroll
+// @summary Enroll a user for two-factor authentication
+// @description Generates the TOTP secret of a user, the second factor is enabled once a code generated from it is confirmed.
+// @description Only the calling user can enroll themselves.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @produce json
+// @param id path int true "User identifier"
+// @success 200 {object} twofactor.Enrollment "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 409 "Two-factor authentication is already enabled"
+// @failure 500 "Server error"
+// @router /users/{id}/2fa/enroll [post]
+func (handler *Handler) userTwoFactorEnroll(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	user, handlerErr := handler.twoFactorSelf(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	enrollment, err := twofactor.Enroll(user)
+	if errors.Is(err, twofactor.ErrAlreadyEnabled) {
+		return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "Two-factor authentication is already enabled", Err: err}
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to enroll the user for two-factor authentication", err)
+	}
+
+	err = handler.DataStore.User().UpdateUser(user.ID, user)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	return response.JSON(w, enrollment)
+}
+
+// @id UserTwoFactorEnable
+// @summary Enable the two-factor authentication of a user
+// @description Confirms the enrollment of a user with a TOTP code and returns their recovery codes.
+// @description Only the calling user can enable their second factor.
+// @description **Access policy**: authenticated
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "User identifier"
+// @param body body userTwoFactorEnablePayload true "TOTP code"
+// @success 200 {object} userTwoFactorEnableResponse "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 409 "Two-factor authentication is already enabled"
+// @failure 422 "Invalid code"
+// @failure 500 "Server error"
+// @router /users/{id}/2fa/enable [post]
+func (handler *Handler) userTwoFactorEnable(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload userTwoFactorEnablePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	user, handlerErr := handler.twoFactorSelf(r)
+	if handlerErr != nil {
+		return handlerErr
+	}
+
+	recoveryCodes, err := twofactor.Enable(handler.CryptoService, user, payload.Code, time.Now())
+	switch {
+	case errors.Is(err, twofactor.ErrAlreadyEnabled):
+		return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "Two-factor authentication is already enabled", Err: err}
+	case errors.Is(err, twofactor.ErrNoPendingEnrollment):
+		return httperror.BadRequest("The user must enroll before enabling two-factor authentication", err)
+	case errors.Is(err, twofactor.ErrInvalidCode):
+		return &httperror.HandlerError{StatusCode: http.StatusUnprocessableEntity, Message: "Invalid two-factor authentication code", Err: err}
+	case err != nil:
+		return httperror.InternalServerError("Unable to enable two-factor authentication", err)
+	}
+
+	err = handler.DataStore.User().UpdateUser(user.ID, user)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	return response.JSON(w, userTwoFactorEnableResponse{RecoveryCodes: recoveryCodes})
+}
+
+// @id UserTwoFactorReset
+// @summary Reset the two-factor authentication of a user
+// @description Disables the second factor of a user and removes their secret and recovery codes,
+// @description the user must enroll again on their next login when two-factor authentication is required.
+// @description **Access policy**: administrator
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @param id path int true "User identifier"
+// @success 204 "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "User not found"
+// @failure 500 "Server error"
+// @router /users/{id}/2fa [delete]
+func (handler *Handler) userTwoFactorReset(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	user, err := handler.DataStore.User().User(portainer.UserID(userID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a user with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	twofactor.Reset(user)
+
+	err = handler.DataStore.User().UpdateUser(user.ID, user)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
+	}
+
+	return response.Empty(w)
+}
+
+// twoFactorSelf returns the user of the route, who must be the calling user
+func (handler *Handler) twoFactorSelf(r *http.Request) (*portainer.User, *httperror.HandlerError) {
+	userID, err := request.RetrieveNumericRouteVariableValue(r, "id")
+	if err != nil {
+		return nil, httperror.BadRequest("Invalid user identifier route variable", err)
+	}
+
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return nil, httperror.InternalServerError("Unable to retrieve user authentication token", err)
+	}
+
+	if tokenData.ID != portainer.UserID(userID) {
+		return nil, httperror.Forbidden("Permission denied to manage the two-factor authentication of another user", httperrors.ErrUnauthorized)
+	}
+
+	user, err := handler.DataStore.User().User(portainer.UserID(userID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return nil, httperror.NotFound("Unable to find a user with the specified identifier inside the database", err)
+	} else if err != nil {
+		return nil, httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
+	}
+
+	return user, nil
+}
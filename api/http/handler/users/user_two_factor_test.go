@@ -0,0 +1,118 @@
+package users
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/apikey"
+	"github.com/cloudogu/portainer-ce/api/crypto"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/internal/twofactor"
+	"github.com/cloudogu/portainer-ce/api/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_userTwoFactor(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	adminUser := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	err := store.User().Create(adminUser)
+	is.NoError(err, "error creating admin user")
+
+	user := &portainer.User{ID: 2, Username: "standard", Role: portainer.StandardUserRole}
+	err = store.User().Create(user)
+	is.NoError(err, "error creating user")
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	requestBouncer := security.NewRequestBouncer(store, jwtService, apiKeyService)
+	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
+	passwordChecker := security.NewPasswordStrengthChecker(store.SettingsService)
+
+	h := NewHandler(requestBouncer, rateLimiter, apiKeyService, nil, passwordChecker)
+	h.DataStore = store
+	h.CryptoService = &crypto.Service{}
+
+	adminJWT, _ := jwtService.GenerateToken(&portainer.TokenData{ID: adminUser.ID, Username: adminUser.Username, Role: adminUser.Role})
+	userJWT, _ := jwtService.GenerateToken(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role})
+
+	serve := func(method, path, jwt string, payload interface{}) *httptest.ResponseRecorder {
+		body, err := json.Marshal(payload)
+		is.NoError(err)
+
+		req := httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", jwt))
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	var enrollment twofactor.Enrollment
+
+	t.Run("a user cannot enroll another user", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/users/2/2fa/enroll", adminJWT, nil)
+		is.Equal(http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("a user enrolls and enables their second factor", func(t *testing.T) {
+		rr := serve(http.MethodPost, "/users/2/2fa/enroll", userJWT, nil)
+		is.Equal(http.StatusOK, rr.Code)
+		is.NoError(json.NewDecoder(rr.Body).Decode(&enrollment))
+
+		rr = serve(http.MethodPost, "/users/2/2fa/enable", userJWT, userTwoFactorEnablePayload{Code: "000000"})
+		is.Equal(http.StatusUnprocessableEntity, rr.Code)
+
+		u, err := url.Parse(enrollment.ProvisioningURI)
+		is.NoError(err)
+		is.Equal(enrollment.Secret, u.Query().Get("secret"))
+
+		code, err := twofactor.GenerateCode(enrollment.Secret, time.Now())
+		is.NoError(err)
+		rr = serve(http.MethodPost, "/users/2/2fa/enable", userJWT, userTwoFactorEnablePayload{Code: code})
+		is.Equal(http.StatusOK, rr.Code)
+
+		var resp userTwoFactorEnableResponse
+		is.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+		is.Len(resp.RecoveryCodes, twofactor.RecoveryCodeCount)
+
+		rr = serve(http.MethodPost, "/users/2/2fa/enroll", userJWT, nil)
+		is.Equal(http.StatusConflict, rr.Code)
+	})
+
+	t.Run("the secret of a user is not exposed", func(t *testing.T) {
+		rr := serve(http.MethodGet, "/users/2", adminJWT, nil)
+		is.Equal(http.StatusOK, rr.Code)
+
+		var resp portainer.User
+		is.NoError(json.NewDecoder(rr.Body).Decode(&resp))
+		is.True(resp.TwoFactor.Enabled)
+		is.Empty(resp.TwoFactor.Secret)
+		is.Empty(resp.TwoFactor.RecoveryCodes)
+	})
+
+	t.Run("only an administrator can reset the second factor of a user", func(t *testing.T) {
+		rr := serve(http.MethodDelete, "/users/2/2fa", userJWT, nil)
+		is.Equal(http.StatusForbidden, rr.Code)
+
+		rr = serve(http.MethodDelete, "/users/2/2fa", adminJWT, nil)
+		is.Equal(http.StatusNoContent, rr.Code)
+
+		updated, err := store.User().User(user.ID)
+		is.NoError(err)
+		is.Equal(portainer.UserTwoFactor{}, updated.TwoFactor)
+	})
+}
@@ -96,6 +96,8 @@ func (handler *Handler) userUpdate(w http.ResponseWriter, r *http.Request) *http
 		return httperror.InternalServerError("Unable to find a user with the specified identifier inside the database", err)
 	}
 
+	revokeSessions := false
+
 	if payload.Username != "" && payload.Username != user.Username {
 		sameNameUser, err := handler.DataStore.User().UserByUsername(payload.Username)
 		if err != nil && !handler.DataStore.IsErrObjectNotFound(err) {
@@ -109,11 +111,11 @@ func (handler *Handler) userUpdate(w http.ResponseWriter, r *http.Request) *http
 	}
 
 	if payload.Password != "" {
-		user.Password, err = handler.CryptoService.Hash(payload.Password)
-		if err != nil {
-			return httperror.InternalServerError("Unable to hash user password", errCryptoHashFailure)
+		if handlerErr := handler.setPassword(user, payload.Password); handlerErr != nil {
+			return handlerErr
 		}
 		user.TokenIssueAt = time.Now().Unix()
+		revokeSessions = true
 	}
 
 	if payload.Theme != nil {
@@ -129,6 +131,7 @@ func (handler *Handler) userUpdate(w http.ResponseWriter, r *http.Request) *http
 	if payload.Role != 0 {
 		user.Role = portainer.UserRole(payload.Role)
 		user.TokenIssueAt = time.Now().Unix()
+		revokeSessions = true
 	}
 
 	err = handler.DataStore.User().UpdateUser(user.ID, user)
@@ -136,8 +139,17 @@ func (handler *Handler) userUpdate(w http.ResponseWriter, r *http.Request) *http
 		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
 	}
 
+	// the tokens issued before a password or role change are rejected, so are their sessions
+	if revokeSessions {
+		err = handler.DataStore.UserSession().DeleteUserSessionsByUserID(user.ID)
+		if err != nil {
+			return httperror.InternalServerError("Unable to revoke the sessions of the user", err)
+		}
+	}
+
 	// remove all of the users persisted API keys
 	handler.apiKeyService.InvalidateUserKeyCache(user.ID)
 
+	hideFields(user)
 	return response.JSON(w, user)
 }
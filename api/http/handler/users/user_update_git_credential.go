@@ -0,0 +1,130 @@
+package users
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/cloudogu/portainer-ce/api/git"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/request"
+	"github.com/portainer/libhttp/response"
+)
+
+type userGitCredentialUpdatePayload struct {
+	// Name of the credential
+	Name string `example:"github-token" json:"name"`
+	// Username used in basic authentication
+	Username string `example:"myGitUsername" json:"username"`
+	// Password or access token used in basic authentication, the current one is kept when empty
+	Password string `example:"myGitPassword" json:"password"`
+	// PEM encoded private key replacing the SSH deploy key, the current one is kept when empty
+	SSHPrivateKey string `json:"sshPrivateKey"`
+	// Replace the SSH deploy key by a newly generated ED25519 key
+	GenerateSSHKey bool `example:"false" json:"generateSSHKey"`
+}
+
+func (payload *userGitCredentialUpdatePayload) Validate(r *http.Request) error {
+	if govalidator.MinStringLength(payload.Name, "128") {
+		return errors.New("invalid name. cannot be longer than 128 characters")
+	}
+	if payload.GenerateSSHKey && !govalidator.IsNull(payload.SSHPrivateKey) {
+		return errors.New("invalid credential. sshPrivateKey and generateSSHKey cannot be specified together")
+	}
+	return nil
+}
+
+// @id UserUpdateGitCredential
+// @summary Update a git credential of a user
+// @description Updates a git credential saved by a user.
+// @description Every git stack referencing the credential uses the new values from its next deployment.
+// @description The password of a basic authentication can be rotated, the SSH key of a deploy key replaced or regenerated.
+// @description Only the calling user can update their git credentials.
+// @description **Access policy**: restricted
+// @tags users
+// @security ApiKeyAuth
+// @security jwt
+// @accept json
+// @produce json
+// @param id path int true "User identifier"
+// @param credentialID path int true "Git credential identifier"
+// @param body body userGitCredentialUpdatePayload true "details"
+// @success 200 {object} portainer.GitCredential "Success"
+// @failure 400 "Invalid request"
+// @failure 403 "Permission denied"
+// @failure 404 "Not found"
+// @failure 409 "A git credential with the same name already exists"
+// @failure 500 "Server error"
+// @router /users/{id}/gitcredentials/{credentialID} [put]
+func (handler *Handler) userUpdateGitCredential(w http.ResponseWriter, r *http.Request) *httperror.HandlerError {
+	var payload userGitCredentialUpdatePayload
+	err := request.DecodeAndValidateJSONPayload(r, &payload)
+	if err != nil {
+		return httperror.BadRequest("Invalid request payload", err)
+	}
+
+	userID, httpErr := handler.retrieveGitCredentialsUser(r)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	credential, httpErr := handler.retrieveGitCredential(r, userID)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if !govalidator.IsNull(payload.Name) && payload.Name != credential.Name {
+		credentials, err := handler.DataStore.GitCredential().GitCredentialsByUserID(userID)
+		if err != nil {
+			return httperror.InternalServerError("Unable to retrieve the git credentials from the database", err)
+		}
+
+		for _, existing := range credentials {
+			if existing.Name == payload.Name {
+				return &httperror.HandlerError{StatusCode: http.StatusConflict, Message: "A git credential with the same name already exists", Err: errors.New("git credential name must be unique")}
+			}
+		}
+
+		credential.Name = payload.Name
+	}
+
+	if !govalidator.IsNull(payload.Username) {
+		credential.Username = payload.Username
+	}
+
+	isSSHKey := credential.SSHPrivateKey != ""
+
+	if !govalidator.IsNull(payload.Password) {
+		if isSSHKey {
+			return httperror.BadRequest("Invalid request payload", errors.New("the password of an SSH deploy key cannot be set"))
+		}
+		credential.Password = payload.Password
+	}
+
+	if (payload.GenerateSSHKey || !govalidator.IsNull(payload.SSHPrivateKey)) && !isSSHKey {
+		return httperror.BadRequest("Invalid request payload", errors.New("the SSH key of a basic authentication cannot be set"))
+	}
+
+	if payload.GenerateSSHKey {
+		credential.SSHPrivateKey, credential.SSHPublicKey, err = git.GenerateSSHKey(credential.Name)
+		if err != nil {
+			return httperror.InternalServerError("Unable to generate the SSH key", err)
+		}
+	}
+
+	if !govalidator.IsNull(payload.SSHPrivateKey) {
+		credential.SSHPublicKey, err = git.SSHPublicKey(payload.SSHPrivateKey, credential.Name)
+		if err != nil {
+			return httperror.BadRequest("Invalid SSH private key", err)
+		}
+		credential.SSHPrivateKey = payload.SSHPrivateKey
+	}
+
+	err = handler.DataStore.GitCredential().UpdateGitCredential(credential.ID, credential)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the git credential changes inside the database", err)
+	}
+
+	hideGitCredentialFields(credential)
+	return response.JSON(w, credential)
+}
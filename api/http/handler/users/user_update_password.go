@@ -90,9 +90,8 @@ func (handler *Handler) userUpdatePassword(w http.ResponseWriter, r *http.Reques
 		return httperror.BadRequest("Password does not meet the requirements", nil)
 	}
 
-	user.Password, err = handler.CryptoService.Hash(payload.NewPassword)
-	if err != nil {
-		return httperror.InternalServerError("Unable to hash user password", errCryptoHashFailure)
+	if handlerErr := handler.setPassword(user, payload.NewPassword); handlerErr != nil {
+		return handlerErr
 	}
 
 	user.TokenIssueAt = time.Now().Unix()
@@ -102,5 +101,10 @@ func (handler *Handler) userUpdatePassword(w http.ResponseWriter, r *http.Reques
 		return httperror.InternalServerError("Unable to persist user changes inside the database", err)
 	}
 
+	err = handler.DataStore.UserSession().DeleteUserSessionsByUserID(user.ID)
+	if err != nil {
+		return httperror.InternalServerError("Unable to revoke the sessions of the user", err)
+	}
+
 	return response.Empty(w)
 }
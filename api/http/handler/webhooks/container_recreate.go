@@ -0,0 +1,281 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	httperror "github.com/portainer/libhttp/error"
+	"github.com/portainer/libhttp/response"
+	"github.com/rs/zerolog/log"
+)
+
+const swarmServiceIDLabel = "com.docker.swarm.service.id"
+
+// containerRecreateClient is the subset of the docker client used to recreate a container
+type containerRecreateClient interface {
+	ContainerInspect(ctx context.Context, containerID string) (dockertypes.ContainerJSON, error)
+	ImagePull(ctx context.Context, refStr string, options dockertypes.ImagePullOptions) (io.ReadCloser, error)
+	ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error
+	ContainerRename(ctx context.Context, containerID, newContainerName string) error
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error)
+	NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+	ContainerStart(ctx context.Context, containerID string, options dockertypes.ContainerStartOptions) error
+	ContainerRemove(ctx context.Context, containerID string, options dockertypes.ContainerRemoveOptions) error
+}
+
+func (handler *Handler) executeContainerWebhook(w http.ResponseWriter, endpoint *portainer.Endpoint, webhook *portainer.Webhook, imageTag string) *httperror.HandlerError {
+	registryAuth, err := handler.registryAuthHeader(webhook.RegistryID)
+	if err != nil {
+		return httperror.InternalServerError("Error getting registry auth header", err)
+	}
+
+	dockerClient, err := handler.DockerClientFactory.CreateClient(endpoint, "", nil)
+	if err != nil {
+		return httperror.InternalServerError("Error creating docker client", err)
+	}
+	defer dockerClient.Close()
+
+	oldContainerID, newContainerID, httpErr := recreateContainer(context.Background(), dockerClient, webhook.ResourceID, imageTag, registryAuth)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	resourceControl, err := handler.DataStore.ResourceControl().ResourceControlByResourceIDAndType(oldContainerID, portainer.ContainerResourceControl)
+	if err != nil {
+		return httperror.InternalServerError("Unable to retrieve the container resource control from the database", err)
+	}
+
+	if resourceControl != nil {
+		resourceControl.ResourceID = newContainerID
+
+		err = handler.DataStore.ResourceControl().UpdateResourceControl(resourceControl.ID, resourceControl)
+		if err != nil {
+			return httperror.InternalServerError("Unable to persist the container resource control inside the database", err)
+		}
+	}
+
+	// the webhook may target the container by a short identifier or by its name,
+	// it is moved to the new container so that it keeps working after the recreation
+	webhook.ResourceID = newContainerID
+
+	err = handler.DataStore.Webhook().UpdateWebhook(webhook.ID, webhook)
+	if err != nil {
+		return httperror.InternalServerError("Unable to persist the webhook inside the database", err)
+	}
+
+	return response.Empty(w)
+}
+
+// recreateContainer pulls the image of a container (or the given tag of that image) and replaces the container
+// by a new one using the same configuration and networks. The previous container is restored when the new one cannot be started.
+// Returns the identifiers of the removed and of the created containers.
+func recreateContainer(ctx context.Context, cli containerRecreateClient, resourceID, imageTag, registryAuth string) (string, string, *httperror.HandlerError) {
+	inspect, err := cli.ContainerInspect(ctx, resourceID)
+	if err != nil {
+		return "", "", httperror.NotFound("Error looking up container", err)
+	}
+
+	if _, ok := inspect.Config.Labels[swarmServiceIDLabel]; ok {
+		return "", "", httperror.BadRequest("The container is managed by a swarm service, use a service webhook instead", errors.New("swarm service container"))
+	}
+
+	image := strings.Split(inspect.Config.Image, "@sha")[0]
+	if imageTag != "" {
+		image = deployments.ReplaceImageTag(image, imageTag)
+	}
+
+	rc, err := cli.ImagePull(ctx, image, dockertypes.ImagePullOptions{RegistryAuth: registryAuth})
+	if err != nil {
+		return "", "", httperror.NotFound("Error pulling image with the specified tag", err)
+	}
+	// the pull only completes once the progress stream is consumed
+	_, err = io.Copy(io.Discard, rc)
+	rc.Close()
+	if err != nil {
+		return "", "", httperror.InternalServerError(fmt.Sprintf("Unable to pull the image %s", image), err)
+	}
+
+	name := strings.TrimPrefix(inspect.Name, "/")
+	wasRunning := inspect.State != nil && inspect.State.Running
+
+	config := inspect.Config
+	config.Image = image
+	// let docker generate the hostname of the new container unless it was set explicitly
+	if len(inspect.ID) >= 12 && config.Hostname == inspect.ID[:12] {
+		config.Hostname = ""
+	}
+
+	hostConfig := inspect.HostConfig
+	if anonymousVolumes := anonymousVolumeMounts(inspect); len(anonymousVolumes) > 0 {
+		// docker would create new empty volumes for the declared volumes of the image, reuse the previous ones instead
+		copied := *inspect.HostConfig
+		copied.Mounts = append(append([]mount.Mount{}, inspect.HostConfig.Mounts...), anonymousVolumes...)
+		hostConfig = &copied
+	}
+
+	primaryNetwork, networks := containerNetworks(inspect)
+
+	if err := cli.ContainerStop(ctx, inspect.ID, nil); err != nil {
+		return "", "", httperror.InternalServerError("Unable to stop the container", err)
+	}
+
+	backupName := fmt.Sprintf("%s-%d", name, time.Now().Unix())
+	if err := cli.ContainerRename(ctx, inspect.ID, backupName); err != nil {
+		restartContainer(ctx, cli, inspect.ID, wasRunning)
+		return "", "", httperror.InternalServerError("Unable to rename the container", err)
+	}
+
+	restore := func(newContainerID string) {
+		if newContainerID != "" {
+			if err := cli.ContainerRemove(ctx, newContainerID, dockertypes.ContainerRemoveOptions{Force: true}); err != nil {
+				log.Warn().Err(err).Str("container", newContainerID).Msg("unable to remove the recreated container")
+			}
+		}
+
+		if err := cli.ContainerRename(ctx, inspect.ID, name); err != nil {
+			log.Warn().Err(err).Str("container", inspect.ID).Msg("unable to restore the container name")
+		}
+
+		restartContainer(ctx, cli, inspect.ID, wasRunning)
+	}
+
+	var networkingConfig *network.NetworkingConfig
+	if primaryNetwork != "" {
+		networkingConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{primaryNetwork: networks[primaryNetwork]},
+		}
+	}
+
+	created, err := cli.ContainerCreate(ctx, config, hostConfig, networkingConfig, nil, name)
+	if err != nil {
+		restore("")
+		return "", "", httperror.InternalServerError("Unable to create the container", err)
+	}
+
+	for networkName, settings := range networks {
+		if networkName == primaryNetwork {
+			continue
+		}
+
+		if err := cli.NetworkConnect(ctx, networkName, created.ID, settings); err != nil {
+			restore(created.ID)
+			return "", "", httperror.InternalServerError(fmt.Sprintf("Unable to connect the container to the network %s", networkName), err)
+		}
+	}
+
+	if wasRunning {
+		if err := cli.ContainerStart(ctx, created.ID, dockertypes.ContainerStartOptions{}); err != nil {
+			restore(created.ID)
+			return "", "", httperror.InternalServerError("Unable to start the container", err)
+		}
+	}
+
+	if err := cli.ContainerRemove(ctx, inspect.ID, dockertypes.ContainerRemoveOptions{Force: true}); err != nil {
+		log.Warn().Err(err).Str("container", inspect.ID).Msg("unable to remove the previous container")
+	}
+
+	return inspect.ID, created.ID, nil
+}
+
+// containerNetworks returns the network the container is created with and the settings of every network it is connected to
+func containerNetworks(inspect dockertypes.ContainerJSON) (string, map[string]*network.EndpointSettings) {
+	networks := make(map[string]*network.EndpointSettings)
+
+	if inspect.NetworkSettings == nil {
+		return "", networks
+	}
+
+	for networkName, settings := range inspect.NetworkSettings.Networks {
+		if settings == nil {
+			continue
+		}
+
+		aliases := make([]string, 0, len(settings.Aliases))
+		for _, alias := range settings.Aliases {
+			// docker adds the short identifier of the container to its aliases
+			if len(inspect.ID) >= 12 && alias == inspect.ID[:12] {
+				continue
+			}
+			aliases = append(aliases, alias)
+		}
+
+		networks[networkName] = &network.EndpointSettings{
+			IPAMConfig: settings.IPAMConfig,
+			Links:      settings.Links,
+			Aliases:    aliases,
+			DriverOpts: settings.DriverOpts,
+		}
+	}
+
+	primaryNetwork := ""
+	if inspect.HostConfig != nil {
+		primaryNetwork = string(inspect.HostConfig.NetworkMode)
+	}
+
+	// containers created without network use the bridge network
+	if primaryNetwork == "default" {
+		primaryNetwork = "bridge"
+	}
+
+	if _, ok := networks[primaryNetwork]; !ok {
+		primaryNetwork = ""
+	}
+
+	return primaryNetwork, networks
+}
+
+// anonymousVolumeMounts returns the mounts reusing the volumes of a container that are neither bound nor mounted
+// explicitly by its host configuration, i.e. the anonymous volumes created for the VOLUME declarations of its image
+func anonymousVolumeMounts(inspect dockertypes.ContainerJSON) []mount.Mount {
+	declared := make(map[string]bool)
+	if inspect.HostConfig != nil {
+		for _, bind := range inspect.HostConfig.Binds {
+			parts := strings.Split(bind, ":")
+			if len(parts) >= 2 {
+				declared[parts[1]] = true
+			}
+		}
+
+		for _, m := range inspect.HostConfig.Mounts {
+			declared[m.Target] = true
+		}
+	}
+
+	var mounts []mount.Mount
+	for _, m := range inspect.Mounts {
+		if m.Type != mount.TypeVolume || m.Name == "" || declared[m.Destination] {
+			continue
+		}
+
+		mounts = append(mounts, mount.Mount{
+			Type:     mount.TypeVolume,
+			Source:   m.Name,
+			Target:   m.Destination,
+			ReadOnly: !m.RW,
+		})
+	}
+
+	return mounts
+}
+
+func restartContainer(ctx context.Context, cli containerRecreateClient, containerID string, wasRunning bool) {
+	if !wasRunning {
+		return
+	}
+
+	if err := cli.ContainerStart(ctx, containerID, dockertypes.ContainerStartOptions{}); err != nil {
+		log.Warn().Err(err).Str("container", containerID).Msg("unable to restart the previous container")
+	}
+}
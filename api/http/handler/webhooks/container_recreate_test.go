@@ -0,0 +1,174 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	dockertypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+const testContainerID = "0123456789abcdef0123456789abcdef"
+
+type fakeRecreateClient struct {
+	inspect       dockertypes.ContainerJSON
+	createErr     error
+	pulledImage   string
+	registryAuth  string
+	createdConfig *container.Config
+	createdHost   *container.HostConfig
+	createdName   string
+	createdNet    *network.NetworkingConfig
+	connected     []string
+	started       []string
+	removed       []string
+	renames       []string
+}
+
+func (c *fakeRecreateClient) ContainerInspect(ctx context.Context, containerID string) (dockertypes.ContainerJSON, error) {
+	return c.inspect, nil
+}
+
+func (c *fakeRecreateClient) ImagePull(ctx context.Context, refStr string, options dockertypes.ImagePullOptions) (io.ReadCloser, error) {
+	c.pulledImage = refStr
+	c.registryAuth = options.RegistryAuth
+	return io.NopCloser(strings.NewReader("{}")), nil
+}
+
+func (c *fakeRecreateClient) ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error {
+	return nil
+}
+
+func (c *fakeRecreateClient) ContainerRename(ctx context.Context, containerID, newContainerName string) error {
+	c.renames = append(c.renames, newContainerName)
+	return nil
+}
+
+func (c *fakeRecreateClient) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *specs.Platform, containerName string) (container.ContainerCreateCreatedBody, error) {
+	if c.createErr != nil {
+		return container.ContainerCreateCreatedBody{}, c.createErr
+	}
+
+	c.createdConfig = config
+	c.createdHost = hostConfig
+	c.createdName = containerName
+	c.createdNet = networkingConfig
+	return container.ContainerCreateCreatedBody{ID: "new-container"}, nil
+}
+
+func (c *fakeRecreateClient) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	c.connected = append(c.connected, networkID)
+	return nil
+}
+
+func (c *fakeRecreateClient) ContainerStart(ctx context.Context, containerID string, options dockertypes.ContainerStartOptions) error {
+	c.started = append(c.started, containerID)
+	return nil
+}
+
+func (c *fakeRecreateClient) ContainerRemove(ctx context.Context, containerID string, options dockertypes.ContainerRemoveOptions) error {
+	c.removed = append(c.removed, containerID)
+	return nil
+}
+
+func newFakeRecreateClient() *fakeRecreateClient {
+	return &fakeRecreateClient{
+		inspect: dockertypes.ContainerJSON{
+			ContainerJSONBase: &dockertypes.ContainerJSONBase{
+				ID:         testContainerID,
+				Name:       "/web",
+				State:      &dockertypes.ContainerState{Running: true},
+				HostConfig: &container.HostConfig{NetworkMode: "frontend"},
+			},
+			Config: &container.Config{
+				Image:    "registry.local:5000/web:1.0@sha256:abcdef",
+				Hostname: testContainerID[:12],
+				Labels:   map[string]string{"io.portainer.accesscontrol.teams": "dev"},
+			},
+			NetworkSettings: &dockertypes.NetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					"frontend": {Aliases: []string{"web", testContainerID[:12]}},
+					"backend":  {},
+				},
+			},
+		},
+	}
+}
+
+func Test_recreateContainer(t *testing.T) {
+	is := assert.New(t)
+
+	cli := newFakeRecreateClient()
+
+	oldID, newID, httpErr := recreateContainer(context.Background(), cli, "web", "2.0", "auth")
+	is.Nil(httpErr)
+	is.Equal(testContainerID, oldID)
+	is.Equal("new-container", newID)
+
+	is.Equal("registry.local:5000/web:2.0", cli.pulledImage)
+	is.Equal("auth", cli.registryAuth)
+
+	is.Equal("web", cli.createdName)
+	is.Equal("registry.local:5000/web:2.0", cli.createdConfig.Image)
+	is.Empty(cli.createdConfig.Hostname, "the generated hostname should not be reused")
+	is.Equal("dev", cli.createdConfig.Labels["io.portainer.accesscontrol.teams"])
+
+	is.Contains(cli.createdNet.EndpointsConfig, "frontend")
+	is.Equal([]string{"web"}, cli.createdNet.EndpointsConfig["frontend"].Aliases)
+	is.Equal([]string{"backend"}, cli.connected)
+
+	is.Equal([]string{"new-container"}, cli.started)
+	is.Equal([]string{testContainerID}, cli.removed)
+}
+
+func Test_recreateContainer_restoresPreviousContainerOnFailure(t *testing.T) {
+	is := assert.New(t)
+
+	cli := newFakeRecreateClient()
+	cli.createErr = errors.New("create failed")
+
+	_, _, httpErr := recreateContainer(context.Background(), cli, "web", "", "")
+	is.NotNil(httpErr)
+
+	is.Equal("registry.local:5000/web:1.0", cli.pulledImage)
+	is.Len(cli.renames, 2)
+	is.Equal("web", cli.renames[1], "the previous container should get its name back")
+	is.Equal([]string{testContainerID}, cli.started, "the previous container should be restarted")
+	is.Empty(cli.removed)
+}
+
+func Test_recreateContainer_rejectsSwarmContainers(t *testing.T) {
+	cli := newFakeRecreateClient()
+	cli.inspect.Config.Labels[swarmServiceIDLabel] = "service"
+
+	_, _, httpErr := recreateContainer(context.Background(), cli, "web", "", "")
+	assert.NotNil(t, httpErr)
+	assert.Equal(t, 400, httpErr.StatusCode)
+}
+
+func Test_recreateContainer_reusesAnonymousVolumes(t *testing.T) {
+	is := assert.New(t)
+
+	cli := newFakeRecreateClient()
+	cli.inspect.HostConfig.Binds = []string{"data:/data"}
+	cli.inspect.Mounts = []dockertypes.MountPoint{
+		{Type: mount.TypeVolume, Name: "data", Destination: "/data", RW: true},
+		{Type: mount.TypeVolume, Name: "4f1c2d", Destination: "/var/lib/web", RW: true},
+		{Type: mount.TypeBind, Source: "/etc/web", Destination: "/etc/web"},
+	}
+
+	_, _, httpErr := recreateContainer(context.Background(), cli, "web", "", "")
+	is.Nil(httpErr)
+
+	is.Equal([]string{"data:/data"}, cli.createdHost.Binds)
+	is.Equal([]mount.Mount{{Type: mount.TypeVolume, Source: "4f1c2d", Target: "/var/lib/web"}}, cli.createdHost.Mounts)
+	is.Empty(cli.inspect.HostConfig.Mounts, "the inspected configuration should not be modified")
+}
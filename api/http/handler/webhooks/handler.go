@@ -7,6 +7,7 @@ import (
 
 	"github.com/cloudogu/portainer-ce/api/docker"
 	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
 	httperror "github.com/portainer/libhttp/error"
 
 	"github.com/gorilla/mux"
@@ -18,6 +19,7 @@ type Handler struct {
 	requestBouncer      *security.RequestBouncer
 	DataStore           dataservices.DataStore
 	DockerClientFactory *docker.ClientFactory
+	StackDeployer       deployments.StackDeployer
 }
 
 // NewHandler creates a handler to manage webhooks operations.
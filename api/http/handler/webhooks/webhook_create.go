@@ -3,6 +3,7 @@ package webhooks
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/cloudogu/portainer-ce/api/http/security"
 	"github.com/cloudogu/portainer-ce/api/internal/registryutils/access"
@@ -30,8 +31,10 @@ func (payload *webhookCreatePayload) Validate(r *http.Request) error {
 	if payload.EndpointID == 0 {
 		return errors.New("Invalid EndpointID")
 	}
-	if payload.WebhookType != 1 {
-		return errors.New("Invalid WebhookType")
+	switch portainer.WebhookType(payload.WebhookType) {
+	case portainer.ServiceWebhook, portainer.StackWebhook, portainer.ContainerWebhook:
+	default:
+		return errors.New("Invalid WebhookType. Value must be one of: 1 (service), 2 (stack) or 3 (container)")
 	}
 	return nil
 }
@@ -75,6 +78,13 @@ func (handler *Handler) webhookCreate(w http.ResponseWriter, r *http.Request) *h
 		return httperror.Forbidden("Not authorized to create a webhook", errors.New("not authorized to create a webhook"))
 	}
 
+	if portainer.WebhookType(payload.WebhookType) == portainer.StackWebhook {
+		httpErr := handler.checkStackWebhookResource(payload.ResourceID, endpointID)
+		if httpErr != nil {
+			return httpErr
+		}
+	}
+
 	if payload.RegistryID != 0 {
 		tokenData, err := security.RetrieveTokenData(r)
 		if err != nil {
@@ -107,3 +117,24 @@ func (handler *Handler) webhookCreate(w http.ResponseWriter, r *http.Request) *h
 
 	return response.JSON(w, webhook)
 }
+
+// checkStackWebhookResource ensures the resource of a stack webhook is a stack deployed on the webhook environment(endpoint)
+func (handler *Handler) checkStackWebhookResource(resourceID string, endpointID portainer.EndpointID) *httperror.HandlerError {
+	stackID, err := strconv.Atoi(resourceID)
+	if err != nil {
+		return httperror.BadRequest("Invalid ResourceID. Must be a stack identifier", err)
+	}
+
+	stack, err := handler.DataStore.Stack().Stack(portainer.StackID(stackID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a stack with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	if stack.EndpointID != endpointID {
+		return httperror.BadRequest("The stack is not deployed on the specified environment", errors.New("stack environment mismatch"))
+	}
+
+	return nil
+}
@@ -3,11 +3,14 @@ package webhooks
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/cloudogu/portainer-ce/api/internal/registryutils"
+	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	dockertypes "github.com/docker/docker/api/types"
@@ -17,10 +20,12 @@ import (
 )
 
 // @summary Execute a webhook
-// @description Acts on a passed in token UUID to restart the docker service
+// @description Acts on a passed in token UUID to restart the docker service, redeploy the stack or recreate the container
 // @description **Access policy**: public
 // @tags webhooks
 // @param token path string true "Webhook token"
+// @param tag query string false "Image tag to deploy. Stack webhooks expect a service name and a tag (web:1.2.3) and accept the parameter multiple times"
+// @param pullImage query boolean false "Pull the images before redeploying the stack (stack webhooks only)"
 // @success 202 "Webhook executed"
 // @failure 400
 // @failure 500
@@ -58,6 +63,10 @@ func (handler *Handler) webhookExecute(w http.ResponseWriter, r *http.Request) *
 	switch webhookType {
 	case portainer.ServiceWebhook:
 		return handler.executeServiceWebhook(w, endpoint, resourceID, registryID, imageTag)
+	case portainer.StackWebhook:
+		return handler.executeStackWebhook(w, r, endpoint, resourceID)
+	case portainer.ContainerWebhook:
+		return handler.executeContainerWebhook(w, endpoint, webhook, imageTag)
 	default:
 		return httperror.InternalServerError("Unsupported webhook type", errors.New("Webhooks for this resource are not currently supported"))
 	}
@@ -99,19 +108,9 @@ func (handler *Handler) executeServiceWebhook(
 		QueryRegistry: true,
 	}
 
-	if registryID != 0 {
-		registry, err := handler.DataStore.Registry().Registry(registryID)
-		if err != nil {
-			return httperror.InternalServerError("Error getting registry", err)
-		}
-
-		if registry.Authentication {
-			registryutils.EnsureRegTokenValid(handler.DataStore, registry)
-			serviceUpdateOptions.EncodedRegistryAuth, err = registryutils.GetRegistryAuthHeader(registry)
-			if err != nil {
-				return httperror.InternalServerError("Error getting registry auth header", err)
-			}
-		}
+	serviceUpdateOptions.EncodedRegistryAuth, err = handler.registryAuthHeader(registryID)
+	if err != nil {
+		return httperror.InternalServerError("Error getting registry auth header", err)
 	}
 	if imageTag != "" {
 		rc, err := dockerClient.ImagePull(context.Background(), service.Spec.TaskTemplate.ContainerSpec.Image, dockertypes.ImagePullOptions{RegistryAuth: serviceUpdateOptions.EncodedRegistryAuth})
@@ -129,3 +128,84 @@ func (handler *Handler) executeServiceWebhook(
 	}
 	return response.Empty(w)
 }
+
+// registryAuthHeader returns the encoded authentication of the registry associated to a webhook, if any
+func (handler *Handler) registryAuthHeader(registryID portainer.RegistryID) (string, error) {
+	if registryID == 0 {
+		return "", nil
+	}
+
+	registry, err := handler.DataStore.Registry().Registry(registryID)
+	if err != nil {
+		return "", err
+	}
+
+	if !registry.Authentication {
+		return "", nil
+	}
+
+	registryutils.EnsureRegTokenValid(handler.DataStore, registry)
+
+	return registryutils.GetRegistryAuthHeader(registry)
+}
+
+func (handler *Handler) executeStackWebhook(w http.ResponseWriter, r *http.Request, endpoint *portainer.Endpoint, resourceID string) *httperror.HandlerError {
+	stackID, err := strconv.Atoi(resourceID)
+	if err != nil {
+		return httperror.InternalServerError("Invalid stack identifier", err)
+	}
+
+	stack, err := handler.DataStore.Stack().Stack(portainer.StackID(stackID))
+	if handler.DataStore.IsErrObjectNotFound(err) {
+		return httperror.NotFound("Unable to find a stack with the specified identifier inside the database", err)
+	} else if err != nil {
+		return httperror.InternalServerError("Unable to find a stack with the specified identifier inside the database", err)
+	}
+
+	if stack.EndpointID != endpoint.ID {
+		return httperror.BadRequest("The stack is not deployed on the webhook environment", errors.New("stack environment mismatch"))
+	}
+
+	if stack.Status == portainer.StackStatusInactive {
+		return httperror.BadRequest("Unable to redeploy an inactive stack", errors.New("stack is inactive"))
+	}
+
+	pullImage, _ := request.RetrieveBooleanQueryParameter(r, "pullImage", true)
+
+	imageTags, err := parseStackImageTags(r.URL.Query()["tag"])
+	if err != nil {
+		return httperror.BadRequest("Invalid query parameter: tag", err)
+	}
+
+	if len(imageTags) > 0 && stack.Type == portainer.KubernetesStack {
+		return httperror.BadRequest("Image tags cannot be overridden for kubernetes stacks", errors.New("unsupported stack type"))
+	}
+
+	options := deployments.RedeployOptions{
+		PullImage: pullImage,
+		ImageTags: imageTags,
+	}
+
+	err = deployments.RedeployStack(stack, options, handler.StackDeployer, handler.DataStore)
+	if err != nil {
+		return httperror.InternalServerError("Unable to redeploy the stack", err)
+	}
+
+	return response.Empty(w)
+}
+
+// parseStackImageTags parses the service:tag pairs given to a stack webhook
+func parseStackImageTags(values []string) (map[string]string, error) {
+	imageTags := make(map[string]string, len(values))
+
+	for _, value := range values {
+		service, tag, found := strings.Cut(value, ":")
+		if !found || service == "" || tag == "" {
+			return nil, fmt.Errorf("invalid value %q, expected a service name and an image tag (service:tag)", value)
+		}
+
+		imageTags[service] = tag
+	}
+
+	return imageTags, nil
+}
@@ -0,0 +1,86 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+type testStackDeployer struct {
+	deployedStack *portainer.Stack
+	pullImage     bool
+}
+
+func (d *testStackDeployer) DeploySwarmStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, prune bool, pullImage bool) error {
+	d.deployedStack = stack
+	d.pullImage = pullImage
+	return nil
+}
+
+func (d *testStackDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage bool, forceRereate bool) error {
+	d.deployedStack = stack
+	d.pullImage = forcePullImage
+	return nil
+}
+
+func (d *testStackDeployer) DeployKubernetesStack(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User) error {
+	d.deployedStack = stack
+	return nil
+}
+
+func Test_parseStackImageTags(t *testing.T) {
+	is := assert.New(t)
+
+	tags, err := parseStackImageTags([]string{"web:1.2.3", "worker:latest"})
+	is.NoError(err)
+	is.Equal(map[string]string{"web": "1.2.3", "worker": "latest"}, tags)
+
+	_, err = parseStackImageTags([]string{"1.2.3"})
+	is.Error(err, "a value without service name should be rejected")
+
+	_, err = parseStackImageTags([]string{"web:"})
+	is.Error(err, "a value without tag should be rejected")
+}
+
+func Test_webhookExecute_stackWebhookRedeploysTheStack(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	err := store.User().Create(&portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole})
+	is.NoError(err, "error creating an admin")
+
+	err = store.Endpoint().Create(&portainer.Endpoint{ID: 1})
+	is.NoError(err, "error creating environment")
+
+	stack := &portainer.Stack{ID: 1, Name: "stack", CreatedBy: "admin", EndpointID: 1, Type: portainer.DockerSwarmStack, Status: portainer.StackStatusActive}
+	err = store.Stack().Create(stack)
+	is.NoError(err, "error creating stack")
+
+	webhook := &portainer.Webhook{ID: 1, Token: "token", ResourceID: strconv.Itoa(int(stack.ID)), EndpointID: 1, WebhookType: portainer.StackWebhook}
+	err = store.Webhook().Create(webhook)
+	is.NoError(err, "error creating webhook")
+
+	deployer := &testStackDeployer{}
+	h := NewHandler(nil)
+	h.DataStore = store
+	h.StackDeployer = deployer
+
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/token?pullImage=true", nil)
+	r = mux.SetURLVars(r, map[string]string{"token": "token"})
+	w := httptest.NewRecorder()
+
+	handlerErr := h.webhookExecute(w, r)
+	is.Nil(handlerErr)
+	is.Equal(http.StatusNoContent, w.Code)
+	is.NotNil(deployer.deployedStack)
+	is.Equal(stack.ID, deployer.deployedStack.ID)
+	is.True(deployer.pullImage)
+}
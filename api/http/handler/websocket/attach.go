@@ -7,6 +7,7 @@ import (
 	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
 	httperror "github.com/portainer/libhttp/error"
 	"github.com/portainer/libhttp/request"
 
@@ -59,6 +60,14 @@ func (handler *Handler) websocketAttach(w http.ResponseWriter, r *http.Request)
 		return httperror.Forbidden("Permission denied to access environment", err)
 	}
 
+	canRun, err := handler.userCanRunDockerOperation(r, endpoint, portainer.OperationDockerContainerAttachWebsocket)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to attach to the container", httperrors.ErrResourceAccessDenied)
+	}
+
 	params := &webSocketRequestParams{
 		endpoint: endpoint,
 		ID:       attachID,
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
 	httperror "github.com/portainer/libhttp/error"
 	"github.com/portainer/libhttp/request"
 
@@ -65,6 +66,14 @@ func (handler *Handler) websocketExec(w http.ResponseWriter, r *http.Request) *h
 		return httperror.Forbidden("Permission denied to access environment", err)
 	}
 
+	canRun, err := handler.userCanRunDockerOperation(r, endpoint, portainer.OperationDockerExecStart)
+	if err != nil {
+		return httperror.InternalServerError("Unable to verify user authorizations on the environment", err)
+	}
+	if !canRun {
+		return httperror.Forbidden("Permission denied to start the exec instance", httperrors.ErrResourceAccessDenied)
+	}
+
 	params := &webSocketRequestParams{
 		endpoint: endpoint,
 		ID:       execID,
@@ -1,10 +1,13 @@
 package websocket
 
 import (
+	"net/http"
+
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/dataservices"
 	"github.com/cloudogu/portainer-ce/api/http/proxy/factory/kubernetes"
 	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
 	"github.com/cloudogu/portainer-ce/api/kubernetes/cli"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
@@ -18,6 +21,7 @@ type Handler struct {
 	SignatureService            portainer.DigitalSignatureService
 	ReverseTunnelService        portainer.ReverseTunnelService
 	KubernetesClientFactory     *cli.ClientFactory
+	AuthorizationService        *authorization.Service
 	requestBouncer              *security.RequestBouncer
 	connectionUpgrader          websocket.Upgrader
 	kubernetesTokenCacheManager *kubernetes.TokenCacheManager
@@ -41,3 +45,18 @@ func NewHandler(kubernetesTokenCacheManager *kubernetes.TokenCacheManager, bounc
 		bouncer.AuthenticatedAccess(httperror.LoggerHandler(h.websocketShellPodExec)))
 	return h
 }
+
+// userCanRunDockerOperation checks that the custom role granted to a non-administrator user on the
+// environment(endpoint), if any, grants the Docker operation.
+func (handler *Handler) userCanRunDockerOperation(r *http.Request, endpoint *portainer.Endpoint, operation portainer.Authorization) (bool, error) {
+	tokenData, err := security.RetrieveTokenData(r)
+	if err != nil {
+		return false, err
+	}
+
+	if tokenData.Role == portainer.AdministratorRole {
+		return true, nil
+	}
+
+	return handler.AuthorizationService.AuthorizedCustomRoleOperation(tokenData.ID, endpoint, operation)
+}
@@ -0,0 +1,128 @@
+package middlewares
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/metrics"
+)
+
+type metricsContextKey int
+
+const contextProxiedEndpoint metricsContextKey = iota
+
+var proxyTypes = map[string]bool{
+	"docker":     true,
+	"kubernetes": true,
+	"azure":      true,
+	"agent":      true,
+}
+
+// metricsResponseWriter captures the status code written by the downstream handlers
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	hijacked   bool
+}
+
+func (w *metricsResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Flush is required by streamed responses (e.g. docker image pull)
+func (w *metricsResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack is required by upgraded connections (e.g. websockets)
+func (w *metricsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+
+	w.statusCode = http.StatusSwitchingProtocols
+	w.hijacked = true
+	return hijacker.Hijack()
+}
+
+// proxiedEndpoint holds the environment a request has been proxied to
+type proxiedEndpoint struct {
+	endpointID portainer.EndpointID
+}
+
+// SetProxiedEndpoint records that the request is proxied to an existing environment the user is authorized to access.
+// Only these requests are counted per environment, so that the cardinality of the metric is bounded by the number of environments.
+func SetProxiedEndpoint(request *http.Request, endpointID portainer.EndpointID) {
+	if target, ok := request.Context().Value(contextProxiedEndpoint).(*proxiedEndpoint); ok {
+		target.endpointID = endpointID
+	}
+}
+
+// WithMetrics records the latency of every request per handler, as well as the requests proxied to the environments
+func WithMetrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		mw := &metricsResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		target := &proxiedEndpoint{}
+
+		next.ServeHTTP(mw, r.WithContext(context.WithValue(r.Context(), contextProxiedEndpoint, target)))
+
+		code := strconv.Itoa(mw.statusCode)
+
+		if proxyType, ok := proxiedRequest(r.URL.Path); ok && target.endpointID != 0 {
+			metrics.ProxyRequests.WithLabelValues(strconv.Itoa(int(target.endpointID)), proxyType, code).Inc()
+		}
+
+		// the duration of upgraded connections is the lifetime of the connection, not a latency
+		if mw.hijacked {
+			return
+		}
+
+		metrics.ObserveDuration(metrics.HTTPRequestDuration, start, handlerName(r.URL.Path, mw.statusCode), r.Method, code)
+	})
+}
+
+// handlerName returns the name of the API handler serving the path, e.g. "stacks" or "endpoints/docker"
+func handlerName(path string, statusCode int) string {
+	if !strings.HasPrefix(path, "/api/") {
+		return "static"
+	}
+
+	// keep the cardinality of the handler label bounded
+	if statusCode == http.StatusNotFound {
+		return "not_found"
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	if segments[0] == "endpoints" && len(segments) > 2 && (proxyTypes[segments[2]] || segments[2] == "edge") {
+		return "endpoints/" + segments[2]
+	}
+
+	return segments[0]
+}
+
+// proxiedRequest returns the proxy type of a request proxied to an environment
+func proxiedRequest(path string) (string, bool) {
+	segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	if !strings.HasPrefix(path, "/api/") || len(segments) < 3 || segments[0] != "endpoints" || !proxyTypes[segments[2]] {
+		return "", false
+	}
+
+	// helm operations are served by Portainer itself
+	if segments[2] == "kubernetes" && len(segments) > 3 && segments[3] == "helm" {
+		return "", false
+	}
+
+	return segments[2], true
+}
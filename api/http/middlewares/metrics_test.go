@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_handlerName(t *testing.T) {
+	tests := []struct {
+		path       string
+		statusCode int
+		expected   string
+	}{
+		{"/api/stacks/1", http.StatusOK, "stacks"},
+		{"/api/endpoints/1", http.StatusOK, "endpoints"},
+		{"/api/endpoints/1/docker/containers/json", http.StatusOK, "endpoints/docker"},
+		{"/api/endpoints/1/edge/status", http.StatusOK, "endpoints/edge"},
+		{"/api/unknown", http.StatusNotFound, "not_found"},
+		{"/main.js", http.StatusOK, "static"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, handlerName(test.path, test.statusCode), test.path)
+	}
+}
+
+func Test_proxiedRequest(t *testing.T) {
+	is := assert.New(t)
+
+	proxyType, ok := proxiedRequest("/api/endpoints/3/kubernetes/api/v1/pods")
+	is.True(ok)
+	is.Equal("kubernetes", proxyType)
+
+	_, ok = proxiedRequest("/api/endpoints/3/kubernetes/helm")
+	is.False(ok, "helm requests are not proxied")
+
+	_, ok = proxiedRequest("/api/endpoints/snapshot")
+	is.False(ok)
+}
+
+func Test_WithMetrics_countsOnlyProxiedEnvironments(t *testing.T) {
+	is := assert.New(t)
+
+	var endpointIDs []portainer.EndpointID
+	handler := WithMetrics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/endpoints/1/docker/info" {
+			SetProxiedEndpoint(r, 1)
+		}
+
+		target := r.Context().Value(contextProxiedEndpoint).(*proxiedEndpoint)
+		endpointIDs = append(endpointIDs, target.endpointID)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/endpoints/1/docker/info", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/endpoints/999999/docker/info", nil))
+
+	is.Equal([]portainer.EndpointID{1, 0}, endpointIDs, "only the requests reaching an environment should be labelled")
+}
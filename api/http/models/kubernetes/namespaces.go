@@ -1,12 +1,147 @@
 package kubernetes
 
-import "net/http"
+import (
+	"errors"
+	"fmt"
+	"net/http"
 
-type K8sNamespaceDetails struct {
-	Name        string            `json:"Name"`
-	Annotations map[string]string `json:"Annotations"`
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+type (
+	K8sNamespaceDetails struct {
+		Name        string            `json:"Name"`
+		Annotations map[string]string `json:"Annotations"`
+		// Resources the namespace can consume. The quota is left unchanged when it is not defined
+		ResourceQuota *K8sResourceQuota `json:"ResourceQuota,omitempty"`
+		// Default and maximum resources of the containers of the namespace. The limit range is left unchanged
+		// when it is not defined
+		LimitRange *K8sLimitRange `json:"LimitRange,omitempty"`
+		// Remove the resource quota of the namespace, cannot be used with ResourceQuota
+		RemoveResourceQuota bool `json:"RemoveResourceQuota,omitempty" example:"false"`
+		// Remove the limit range of the namespace, cannot be used with LimitRange
+		RemoveLimitRange bool `json:"RemoveLimitRange,omitempty" example:"false"`
+	}
+
+	// K8sResourceQuota represents the resources all the pods of a namespace can consume, as Kubernetes quantities
+	K8sResourceQuota struct {
+		// CPU the containers can request and be limited to
+		CPU string `json:"CPU,omitempty" example:"4"`
+		// Memory the containers can request and be limited to
+		Memory string `json:"Memory,omitempty" example:"8Gi"`
+		// Storage the persistent volume claims can request
+		Storage string `json:"Storage,omitempty" example:"100Gi"`
+		// Maximum number of objects per resource, e.g. pods, services, secrets, configmaps or persistentvolumeclaims
+		Objects map[string]int64 `json:"Objects,omitempty"`
+	}
+
+	// K8sLimitRange represents the default and maximum resources of the containers of a namespace, as Kubernetes quantities
+	K8sLimitRange struct {
+		// CPU limit of the containers which do not define one
+		DefaultCPU string `json:"DefaultCPU,omitempty" example:"500m"`
+		// Memory limit of the containers which do not define one
+		DefaultMemory string `json:"DefaultMemory,omitempty" example:"512Mi"`
+		// CPU request of the containers which do not define one
+		DefaultRequestCPU string `json:"DefaultRequestCPU,omitempty" example:"100m"`
+		// Memory request of the containers which do not define one
+		DefaultRequestMemory string `json:"DefaultRequestMemory,omitempty" example:"128Mi"`
+		// Maximum CPU limit of a container
+		MaxCPU string `json:"MaxCPU,omitempty" example:"2"`
+		// Maximum memory limit of a container
+		MaxMemory string `json:"MaxMemory,omitempty" example:"4Gi"`
+	}
+
+	// K8sResourceQuotaUsage represents the hard limits of the resource quota of a namespace and their current usage,
+	// indexed by resource name
+	K8sResourceQuotaUsage struct {
+		Hard map[string]string `json:"Hard"`
+		Used map[string]string `json:"Used"`
+	}
+)
+
+// QuotaObjectResources are the resources whose number of objects can be limited by a namespace resource quota
+var QuotaObjectResources = map[string]struct{}{
+	"pods":                   {},
+	"services":               {},
+	"services.loadbalancers": {},
+	"services.nodeports":     {},
+	"secrets":                {},
+	"configmaps":             {},
+	"persistentvolumeclaims": {},
+	"replicationcontrollers": {},
 }
 
 func (r *K8sNamespaceDetails) Validate(request *http.Request) error {
+	if r.ResourceQuota != nil && r.RemoveResourceQuota {
+		return errors.New("ResourceQuota and RemoveResourceQuota cannot be used together")
+	}
+
+	if r.LimitRange != nil && r.RemoveLimitRange {
+		return errors.New("LimitRange and RemoveLimitRange cannot be used together")
+	}
+
+	if r.ResourceQuota != nil {
+		if err := r.ResourceQuota.validate(); err != nil {
+			return err
+		}
+	}
+
+	if r.LimitRange != nil {
+		if err := r.LimitRange.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *K8sResourceQuota) validate() error {
+	for name, quantity := range map[string]string{"CPU": q.CPU, "Memory": q.Memory, "Storage": q.Storage} {
+		if err := validateQuantity(name, quantity); err != nil {
+			return err
+		}
+	}
+
+	for resourceName, count := range q.Objects {
+		if _, ok := QuotaObjectResources[resourceName]; !ok {
+			return fmt.Errorf("unsupported resource quota object %q", resourceName)
+		}
+
+		if count < 0 {
+			return fmt.Errorf("invalid resource quota object count for %q, it must not be negative", resourceName)
+		}
+	}
+
+	return nil
+}
+
+func (l *K8sLimitRange) validate() error {
+	quantities := map[string]string{
+		"DefaultCPU":           l.DefaultCPU,
+		"DefaultMemory":        l.DefaultMemory,
+		"DefaultRequestCPU":    l.DefaultRequestCPU,
+		"DefaultRequestMemory": l.DefaultRequestMemory,
+		"MaxCPU":               l.MaxCPU,
+		"MaxMemory":            l.MaxMemory,
+	}
+
+	for name, quantity := range quantities {
+		if err := validateQuantity(name, quantity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateQuantity(name, quantity string) error {
+	if quantity == "" {
+		return nil
+	}
+
+	if _, err := resource.ParseQuantity(quantity); err != nil {
+		return fmt.Errorf("invalid %s quantity %q", name, quantity)
+	}
+
 	return nil
 }
@@ -0,0 +1,38 @@
+package docker
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudogu/portainer-ce/api/http/security"
+)
+
+// setAuditResource enriches the audit log entry of the request with the targeted Docker resource.
+// The resource is identified by its type and identifier, e.g. containers/<id> or volumes/<name>.
+func setAuditResource(request *http.Request, requestPath string) {
+	entry := security.RetrieveAuditLog(request)
+	if entry == nil {
+		return
+	}
+
+	parts := strings.SplitN(strings.Trim(requestPath, "/"), "/", 3)
+	if len(parts) < 2 {
+		entry.ResourceID = parts[0]
+		return
+	}
+
+	switch parts[1] {
+	case "create":
+		name := request.URL.Query().Get("name")
+		if name == "" {
+			entry.ResourceID = parts[0]
+			return
+		}
+
+		entry.ResourceID = parts[0] + "/" + name
+	case "prune":
+		entry.ResourceID = parts[0]
+	default:
+		entry.ResourceID = parts[0] + "/" + parts[1]
+	}
+}
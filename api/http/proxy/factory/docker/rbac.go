@@ -0,0 +1,396 @@
+package docker
+
+import (
+	"net/http"
+	"strings"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/http/proxy/factory/utils"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+)
+
+// customRoleOperation rejects the request when the user is only granted a custom role on the environment(endpoint)
+// and this role does not contain the authorization of the requested Docker operation.
+// It returns nil when the request can go on.
+func (transport *Transport) customRoleOperation(request *http.Request, requestPath string) (*http.Response, error) {
+	tokenData, err := security.RetrieveTokenData(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenData.Role == portainer.AdministratorRole {
+		return nil, nil
+	}
+
+	authorized, err := transport.authorizationService.AuthorizedCustomRoleOperation(tokenData.ID, transport.endpoint, dockerOperation(request.Method, requestPath))
+	if err != nil {
+		return nil, err
+	}
+
+	if !authorized {
+		return utils.WriteAccessDeniedResponse()
+	}
+
+	return nil, nil
+}
+
+// dockerOperation returns the authorization of the Docker (or agent) API operation matching the method and the
+// path of a request. The path must not contain the API version.
+func dockerOperation(method, requestPath string) portainer.Authorization {
+	parts := strings.Split(strings.Trim(requestPath, "/"), "/")
+
+	switch parts[0] {
+	case "_ping":
+		return portainer.OperationDockerPing
+	case "info":
+		return portainer.OperationDockerInfo
+	case "version":
+		return portainer.OperationDockerVersion
+	case "events":
+		return portainer.OperationDockerEvents
+	case "system":
+		return portainer.OperationDockerSystem
+	case "session":
+		return portainer.OperationDockerSessionStart
+	case "commit":
+		return portainer.OperationDockerImageCommit
+	case "distribution":
+		if len(parts) > 2 && parts[len(parts)-1] == "json" {
+			return portainer.OperationDockerDistributionInspect
+		}
+	case "build":
+		return buildRouteOperation(parts[1:])
+	case "containers":
+		return containerOperation(method, parts[1:])
+	case "images":
+		return imageOperation(method, parts[1:])
+	case "networks":
+		return resourceOperation(method, parts[1:], networkOperations)
+	case "volumes":
+		return resourceOperation(method, parts[1:], volumeOperations)
+	case "exec":
+		return execOperation(parts[1:])
+	case "swarm":
+		return swarmOperation(parts[1:])
+	case "nodes":
+		return resourceOperation(method, parts[1:], nodeOperations)
+	case "services":
+		return resourceOperation(method, parts[1:], serviceOperations)
+	case "tasks":
+		return resourceOperation(method, parts[1:], taskOperations)
+	case "secrets":
+		return resourceOperation(method, parts[1:], secretOperations)
+	case "configs":
+		return resourceOperation(method, parts[1:], configOperations)
+	case "plugins":
+		return pluginOperation(method, parts[1:])
+	case "v2":
+		return agentOperation(parts[1:])
+	}
+
+	return portainer.OperationDockerUndefined
+}
+
+// resourceOperations are the operations of a Docker resource managed with the common list, create, prune, inspect,
+// delete and action routes. An empty authorization means the route does not exist.
+type resourceOperations struct {
+	list    portainer.Authorization
+	create  portainer.Authorization
+	prune   portainer.Authorization
+	inspect portainer.Authorization
+	delete  portainer.Authorization
+	actions map[string]portainer.Authorization
+}
+
+var (
+	networkOperations = resourceOperations{
+		list:    portainer.OperationDockerNetworkList,
+		create:  portainer.OperationDockerNetworkCreate,
+		prune:   portainer.OperationDockerNetworkPrune,
+		inspect: portainer.OperationDockerNetworkInspect,
+		delete:  portainer.OperationDockerNetworkDelete,
+		actions: map[string]portainer.Authorization{
+			"connect":    portainer.OperationDockerNetworkConnect,
+			"disconnect": portainer.OperationDockerNetworkDisconnect,
+		},
+	}
+	volumeOperations = resourceOperations{
+		list:    portainer.OperationDockerVolumeList,
+		create:  portainer.OperationDockerVolumeCreate,
+		prune:   portainer.OperationDockerVolumePrune,
+		inspect: portainer.OperationDockerVolumeInspect,
+		delete:  portainer.OperationDockerVolumeDelete,
+	}
+	nodeOperations = resourceOperations{
+		list:    portainer.OperationDockerNodeList,
+		inspect: portainer.OperationDockerNodeInspect,
+		delete:  portainer.OperationDockerNodeDelete,
+		actions: map[string]portainer.Authorization{
+			"update": portainer.OperationDockerNodeUpdate,
+		},
+	}
+	serviceOperations = resourceOperations{
+		list:    portainer.OperationDockerServiceList,
+		create:  portainer.OperationDockerServiceCreate,
+		inspect: portainer.OperationDockerServiceInspect,
+		delete:  portainer.OperationDockerServiceDelete,
+		actions: map[string]portainer.Authorization{
+			"update": portainer.OperationDockerServiceUpdate,
+			"logs":   portainer.OperationDockerServiceLogs,
+		},
+	}
+	taskOperations = resourceOperations{
+		list:    portainer.OperationDockerTaskList,
+		inspect: portainer.OperationDockerTaskInspect,
+		actions: map[string]portainer.Authorization{
+			"logs": portainer.OperationDockerTaskLogs,
+		},
+	}
+	secretOperations = resourceOperations{
+		list:    portainer.OperationDockerSecretList,
+		create:  portainer.OperationDockerSecretCreate,
+		inspect: portainer.OperationDockerSecretInspect,
+		delete:  portainer.OperationDockerSecretDelete,
+		actions: map[string]portainer.Authorization{
+			"update": portainer.OperationDockerSecretUpdate,
+		},
+	}
+	configOperations = resourceOperations{
+		list:    portainer.OperationDockerConfigList,
+		create:  portainer.OperationDockerConfigCreate,
+		inspect: portainer.OperationDockerConfigInspect,
+		delete:  portainer.OperationDockerConfigDelete,
+		actions: map[string]portainer.Authorization{
+			"update": portainer.OperationDockerConfigUpdate,
+		},
+	}
+)
+
+func resourceOperation(method string, parts []string, operations resourceOperations) portainer.Authorization {
+	var operation portainer.Authorization
+
+	switch {
+	case len(parts) == 0:
+		operation = operations.list
+	case len(parts) == 1 && parts[0] == "create" && method == http.MethodPost:
+		operation = operations.create
+	case len(parts) == 1 && parts[0] == "prune" && method == http.MethodPost:
+		operation = operations.prune
+	case len(parts) == 1 && method == http.MethodDelete:
+		operation = operations.delete
+	case len(parts) == 1:
+		operation = operations.inspect
+	case len(parts) == 2:
+		operation = operations.actions[parts[1]]
+	}
+
+	if operation == "" {
+		return portainer.OperationDockerUndefined
+	}
+
+	return operation
+}
+
+var containerActions = map[string]portainer.Authorization{
+	"json":    portainer.OperationDockerContainerInspect,
+	"top":     portainer.OperationDockerContainerTop,
+	"logs":    portainer.OperationDockerContainerLogs,
+	"changes": portainer.OperationDockerContainerChanges,
+	"export":  portainer.OperationDockerContainerExport,
+	"stats":   portainer.OperationDockerContainerStats,
+	"resize":  portainer.OperationDockerContainerResize,
+	"start":   portainer.OperationDockerContainerStart,
+	"stop":    portainer.OperationDockerContainerStop,
+	"restart": portainer.OperationDockerContainerRestart,
+	"kill":    portainer.OperationDockerContainerKill,
+	"update":  portainer.OperationDockerContainerUpdate,
+	"rename":  portainer.OperationDockerContainerRename,
+	"pause":   portainer.OperationDockerContainerPause,
+	"unpause": portainer.OperationDockerContainerUnpause,
+	"attach":  portainer.OperationDockerContainerAttach,
+	"wait":    portainer.OperationDockerContainerWait,
+	"exec":    portainer.OperationDockerContainerExec,
+}
+
+func containerOperation(method string, parts []string) portainer.Authorization {
+	switch {
+	case len(parts) == 1 && parts[0] == "json":
+		return portainer.OperationDockerContainerList
+	case len(parts) == 1 && parts[0] == "create":
+		return portainer.OperationDockerContainerCreate
+	case len(parts) == 1 && parts[0] == "prune":
+		return portainer.OperationDockerContainerPrune
+	case len(parts) == 1 && method == http.MethodDelete:
+		return portainer.OperationDockerContainerDelete
+	case len(parts) == 2 && parts[1] == "archive":
+		switch method {
+		case http.MethodHead:
+			return portainer.OperationDockerContainerArchiveInfo
+		case http.MethodPut:
+			return portainer.OperationDockerContainerPutContainerArchive
+		default:
+			return portainer.OperationDockerContainerArchive
+		}
+	case len(parts) == 2:
+		if operation, ok := containerActions[parts[1]]; ok {
+			return operation
+		}
+	case len(parts) == 3 && parts[1] == "attach" && parts[2] == "ws":
+		return portainer.OperationDockerContainerAttachWebsocket
+	}
+
+	return portainer.OperationDockerUndefined
+}
+
+var imageActions = map[string]portainer.Authorization{
+	"json":    portainer.OperationDockerImageInspect,
+	"history": portainer.OperationDockerImageHistory,
+	"get":     portainer.OperationDockerImageGet,
+	"push":    portainer.OperationDockerImagePush,
+	"tag":     portainer.OperationDockerImageTag,
+}
+
+// imageOperation maps the image routes, the image names can contain slashes
+func imageOperation(method string, parts []string) portainer.Authorization {
+	if len(parts) == 1 {
+		switch parts[0] {
+		case "json":
+			return portainer.OperationDockerImageList
+		case "search":
+			return portainer.OperationDockerImageSearch
+		case "get":
+			return portainer.OperationDockerImageGetAll
+		case "create":
+			return portainer.OperationDockerImageCreate
+		case "load":
+			return portainer.OperationDockerImageLoad
+		case "prune":
+			return portainer.OperationDockerImagePrune
+		}
+	}
+
+	if len(parts) == 0 {
+		return portainer.OperationDockerUndefined
+	}
+
+	if method == http.MethodDelete {
+		return portainer.OperationDockerImageDelete
+	}
+
+	if operation, ok := imageActions[parts[len(parts)-1]]; ok && len(parts) > 1 {
+		return operation
+	}
+
+	return portainer.OperationDockerUndefined
+}
+
+func buildRouteOperation(parts []string) portainer.Authorization {
+	if len(parts) == 0 {
+		return portainer.OperationDockerImageBuild
+	}
+
+	switch parts[0] {
+	case "prune":
+		return portainer.OperationDockerBuildPrune
+	case "cancel":
+		return portainer.OperationDockerBuildCancel
+	}
+
+	return portainer.OperationDockerUndefined
+}
+
+func execOperation(parts []string) portainer.Authorization {
+	if len(parts) != 2 {
+		return portainer.OperationDockerUndefined
+	}
+
+	switch parts[1] {
+	case "json":
+		return portainer.OperationDockerExecInspect
+	case "start":
+		return portainer.OperationDockerExecStart
+	case "resize":
+		return portainer.OperationDockerExecResize
+	}
+
+	return portainer.OperationDockerUndefined
+}
+
+func swarmOperation(parts []string) portainer.Authorization {
+	if len(parts) == 0 {
+		return portainer.OperationDockerSwarmInspect
+	}
+
+	switch parts[0] {
+	case "init":
+		return portainer.OperationDockerSwarmInit
+	case "join":
+		return portainer.OperationDockerSwarmJoin
+	case "leave":
+		return portainer.OperationDockerSwarmLeave
+	case "update":
+		return portainer.OperationDockerSwarmUpdate
+	case "unlockkey":
+		return portainer.OperationDockerSwarmUnlockKey
+	case "unlock":
+		return portainer.OperationDockerSwarmUnlock
+	}
+
+	return portainer.OperationDockerUndefined
+}
+
+var pluginActions = map[string]portainer.Authorization{
+	"json":    portainer.OperationDockerPluginInspect,
+	"enable":  portainer.OperationDockerPluginEnable,
+	"disable": portainer.OperationDockerPluginDisable,
+	"push":    portainer.OperationDockerPluginPush,
+	"upgrade": portainer.OperationDockerPluginUpgrade,
+	"set":     portainer.OperationDockerPluginSet,
+}
+
+// pluginOperation maps the plugin routes, the plugin names can contain slashes
+func pluginOperation(method string, parts []string) portainer.Authorization {
+	switch {
+	case len(parts) == 0:
+		return portainer.OperationDockerPluginList
+	case len(parts) == 1 && parts[0] == "privileges":
+		return portainer.OperationDockerPluginPrivileges
+	case len(parts) == 1 && parts[0] == "pull":
+		return portainer.OperationDockerPluginPull
+	case len(parts) == 1 && parts[0] == "create":
+		return portainer.OperationDockerPluginCreate
+	case method == http.MethodDelete:
+		return portainer.OperationDockerPluginDelete
+	}
+
+	if operation, ok := pluginActions[parts[len(parts)-1]]; ok && len(parts) > 1 {
+		return operation
+	}
+
+	return portainer.OperationDockerUndefined
+}
+
+var agentBrowseOperations = map[string]portainer.Authorization{
+	"ls":     portainer.OperationDockerAgentBrowseList,
+	"get":    portainer.OperationDockerAgentBrowseGet,
+	"put":    portainer.OperationDockerAgentBrowsePut,
+	"delete": portainer.OperationDockerAgentBrowseDelete,
+	"rename": portainer.OperationDockerAgentBrowseRename,
+}
+
+func agentOperation(parts []string) portainer.Authorization {
+	switch {
+	case len(parts) == 1 && parts[0] == "ping":
+		return portainer.OperationDockerAgentPing
+	case len(parts) == 1 && parts[0] == "agents":
+		return portainer.OperationDockerAgentList
+	case len(parts) == 2 && parts[0] == "host" && parts[1] == "info":
+		return portainer.OperationDockerAgentHostInfo
+	case len(parts) == 2 && parts[0] == "browse":
+		if operation, ok := agentBrowseOperations[parts[1]]; ok {
+			return operation
+		}
+	}
+
+	return portainer.OperationDockerAgentUndefined
+}
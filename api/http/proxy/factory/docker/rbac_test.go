@@ -0,0 +1,120 @@
+package docker
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_dockerOperation(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		expected portainer.Authorization
+	}{
+		{http.MethodGet, "/_ping", portainer.OperationDockerPing},
+		{http.MethodGet, "/info", portainer.OperationDockerInfo},
+		{http.MethodGet, "/system/df", portainer.OperationDockerSystem},
+		{http.MethodGet, "/containers/json", portainer.OperationDockerContainerList},
+		{http.MethodPost, "/containers/create", portainer.OperationDockerContainerCreate},
+		{http.MethodPost, "/containers/abc/restart", portainer.OperationDockerContainerRestart},
+		{http.MethodGet, "/containers/abc/logs", portainer.OperationDockerContainerLogs},
+		{http.MethodGet, "/containers/abc/attach/ws", portainer.OperationDockerContainerAttachWebsocket},
+		{http.MethodHead, "/containers/abc/archive", portainer.OperationDockerContainerArchiveInfo},
+		{http.MethodPut, "/containers/abc/archive", portainer.OperationDockerContainerPutContainerArchive},
+		{http.MethodDelete, "/containers/abc", portainer.OperationDockerContainerDelete},
+		{http.MethodPost, "/containers/abc/teleport", portainer.OperationDockerUndefined},
+		{http.MethodGet, "/images/json", portainer.OperationDockerImageList},
+		{http.MethodGet, "/images/docker.io/library/nginx:latest/json", portainer.OperationDockerImageInspect},
+		{http.MethodDelete, "/images/portainer/agent", portainer.OperationDockerImageDelete},
+		{http.MethodPost, "/build", portainer.OperationDockerImageBuild},
+		{http.MethodPost, "/build/prune", portainer.OperationDockerBuildPrune},
+		{http.MethodGet, "/networks", portainer.OperationDockerNetworkList},
+		{http.MethodPost, "/networks/abc/connect", portainer.OperationDockerNetworkConnect},
+		{http.MethodGet, "/volumes/data", portainer.OperationDockerVolumeInspect},
+		{http.MethodDelete, "/volumes/data", portainer.OperationDockerVolumeDelete},
+		{http.MethodPost, "/volumes/prune", portainer.OperationDockerVolumePrune},
+		{http.MethodPost, "/exec/abc/start", portainer.OperationDockerExecStart},
+		{http.MethodPost, "/swarm/leave", portainer.OperationDockerSwarmLeave},
+		{http.MethodPost, "/services/abc/update", portainer.OperationDockerServiceUpdate},
+		{http.MethodGet, "/tasks/abc/logs", portainer.OperationDockerTaskLogs},
+		{http.MethodPost, "/nodes/create", portainer.OperationDockerUndefined},
+		{http.MethodPost, "/plugins/vieux/sshfs:latest/enable", portainer.OperationDockerPluginEnable},
+		{http.MethodGet, "/v2/browse/ls", portainer.OperationDockerAgentBrowseList},
+		{http.MethodGet, "/v2/dockerhub/1", portainer.OperationDockerAgentUndefined},
+		{http.MethodGet, "/", portainer.OperationDockerUndefined},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, dockerOperation(test.method, test.path), "%s %s", test.method, test.path)
+	}
+}
+
+func Test_customRoleOperation(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	builtinRole := &portainer.Role{Name: "Read-only user", Priority: 4, Authorizations: portainer.Authorizations{portainer.OperationDockerContainerList: true}}
+	is.NoError(store.Role().Create(builtinRole))
+
+	restarterRole := &portainer.Role{Name: "Container restarter", Priority: 2, Custom: true, Authorizations: portainer.Authorizations{
+		portainer.OperationDockerContainerList:    true,
+		portainer.OperationDockerContainerRestart: true,
+	}}
+	is.NoError(store.Role().Create(restarterRole))
+
+	for _, user := range []*portainer.User{
+		{ID: 1, Username: "admin", Role: portainer.AdministratorRole},
+		{ID: 2, Username: "restarter", Role: portainer.StandardUserRole},
+		{ID: 3, Username: "reader", Role: portainer.StandardUserRole},
+	} {
+		is.NoError(store.User().Create(user))
+	}
+
+	endpoint := &portainer.Endpoint{
+		ID:   1,
+		Name: "local",
+		UserAccessPolicies: portainer.UserAccessPolicies{
+			2: {RoleID: restarterRole.ID},
+			3: {RoleID: builtinRole.ID},
+		},
+		TeamAccessPolicies: portainer.TeamAccessPolicies{},
+	}
+	is.NoError(store.Endpoint().Create(endpoint))
+
+	transport := &Transport{
+		endpoint:             endpoint,
+		dataStore:            store,
+		authorizationService: authorization.NewService(store),
+	}
+
+	allowed := func(userID portainer.UserID, role portainer.UserRole, method, path string) bool {
+		request := httptest.NewRequest(method, path, nil)
+		request = request.WithContext(security.StoreTokenData(request, &portainer.TokenData{ID: userID, Role: role}))
+
+		response, err := transport.customRoleOperation(request, path)
+		is.NoError(err)
+		if response != nil {
+			is.Equal(http.StatusForbidden, response.StatusCode)
+		}
+
+		return response == nil
+	}
+
+	is.True(allowed(2, portainer.StandardUserRole, http.MethodPost, "/containers/abc/restart"))
+	is.True(allowed(2, portainer.StandardUserRole, http.MethodGet, "/containers/json"))
+	is.False(allowed(2, portainer.StandardUserRole, http.MethodPost, "/containers/abc/stop"))
+	is.False(allowed(2, portainer.StandardUserRole, http.MethodGet, "/containers/abc/teleport"))
+
+	// the authorizations of the built-in roles are not enforced by the proxy
+	is.True(allowed(3, portainer.StandardUserRole, http.MethodPost, "/containers/abc/stop"))
+	is.True(allowed(1, portainer.AdministratorRole, http.MethodPost, "/containers/abc/stop"))
+}
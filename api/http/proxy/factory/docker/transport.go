@@ -37,6 +37,7 @@ type (
 		reverseTunnelService portainer.ReverseTunnelService
 		dockerClientFactory  *docker.ClientFactory
 		gitService           portainer.GitService
+		authorizationService *authorization.Service
 	}
 
 	// TransportParameters is used to create a new Transport
@@ -73,6 +74,7 @@ func NewTransport(parameters *TransportParameters, httpTransport *http.Transport
 		dockerClientFactory:  parameters.DockerClientFactory,
 		HTTPTransport:        httpTransport,
 		gitService:           gitService,
+		authorizationService: authorization.NewService(parameters.DataStore),
 	}
 
 	return transport, nil
@@ -89,6 +91,13 @@ func (transport *Transport) ProxyDockerRequest(request *http.Request) (*http.Res
 	requestPath := apiVersionRe.ReplaceAllString(request.URL.Path, "")
 	request.URL.Path = requestPath
 
+	setAuditResource(request, requestPath)
+
+	response, err := transport.customRoleOperation(request, requestPath)
+	if response != nil || err != nil {
+		return response, err
+	}
+
 	if transport.endpoint.Type == portainer.AgentOnDockerEnvironment || transport.endpoint.Type == portainer.EdgeAgentOnDockerEnvironment {
 		signature, err := transport.signatureService.CreateSignature(portainer.PortainerAgentSignatureMessage)
 		if err != nil {
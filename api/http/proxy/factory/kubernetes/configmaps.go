@@ -0,0 +1,71 @@
+package kubernetes
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cloudogu/portainer-ce/api/http/proxy/factory/utils"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
+	"github.com/cloudogu/portainer-ce/api/kubernetes/cli"
+)
+
+type configMapPayload struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data"`
+}
+
+// proxyConfigMapsRequest validates the roles of the namespace access policies written to the config map
+// storing them before forwarding the request
+func (transport *baseTransport) proxyConfigMapsRequest(request *http.Request, namespace, requestPath string) (*http.Response, error) {
+	switch request.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return transport.executeKubernetesRequest(request)
+	}
+
+	name := strings.Trim(strings.TrimPrefix(requestPath, "configmaps"), "/")
+	if request.Method != http.MethodPost && !cli.IsNamespaceAccessPoliciesConfigMap(namespace, name) {
+		return transport.executeKubernetesRequest(request)
+	}
+
+	body, err := io.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	request.Body = io.NopCloser(bytes.NewReader(body))
+
+	var payload configMapPayload
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		if request.Method == http.MethodPost {
+			return transport.executeKubernetesRequest(request)
+		}
+
+		return utils.WriteBadRequestResponse("the namespace access policies can only be updated with a JSON object")
+	}
+
+	if name == "" {
+		name = payload.Metadata.Name
+	}
+
+	if !cli.IsNamespaceAccessPoliciesConfigMap(namespace, name) {
+		return transport.executeKubernetesRequest(request)
+	}
+
+	policies, err := cli.NamespaceAccessPoliciesFromConfigMapData(payload.Data)
+	if err != nil {
+		return utils.WriteBadRequestResponse("invalid namespace access policies")
+	}
+
+	err = authorization.NewService(transport.dataStore).ValidateNamespaceAccessPolicyRoles(policies)
+	if err != nil {
+		return utils.WriteBadRequestResponse(err.Error())
+	}
+
+	return transport.executeKubernetesRequest(request)
+}
@@ -57,6 +57,10 @@ func (transport *baseTransport) proxyNamespaceDeleteOperation(request *http.Requ
 			if err := transport.dataStore.Stack().DeleteStack(s.ID); err != nil {
 				return nil, err
 			}
+
+			if err := transport.dataStore.StackVersion().DeleteStackVersions(s.ID); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -49,6 +49,10 @@ func (transport *baseTransport) proxyKubernetesRequest(request *http.Request) (*
 	apiVersionRe := regexp.MustCompile(`^(/kubernetes)?/(api|apis/apps)/v[0-9](\.[0-9])?`)
 	requestPath := apiVersionRe.ReplaceAllString(request.URL.Path, "")
 
+	if entry := security.RetrieveAuditLog(request); entry != nil {
+		entry.ResourceID = strings.Trim(requestPath, "/")
+	}
+
 	switch {
 	case strings.EqualFold(requestPath, "/namespaces"):
 		return transport.executeKubernetesRequest(request)
@@ -74,6 +78,8 @@ func (transport *baseTransport) proxyNamespacedRequest(request *http.Request, fu
 		return transport.proxyPodsRequest(request, namespace, requestPath)
 	case strings.HasPrefix(requestPath, "deployments"):
 		return transport.proxyDeploymentsRequest(request, namespace, requestPath)
+	case strings.HasPrefix(requestPath, "configmaps"):
+		return transport.proxyConfigMapsRequest(request, namespace, requestPath)
 	case requestPath == "" && request.Method == "DELETE":
 		return transport.proxyNamespaceDeleteOperation(request, namespace)
 	default:
@@ -69,6 +69,11 @@ func getBody(body io.ReadCloser, contentType string, isGzip bool) (interface{},
 }
 
 func marshal(contentType string, data interface{}) ([]byte, error) {
+	// responses built by the proxy itself have no content type yet
+	if contentType == "" {
+		return json.Marshal(data)
+	}
+
 	// Note: contentType can look like: "application/json" or "application/json; charset=utf-8"
 	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
@@ -69,6 +69,14 @@ func WriteAccessDeniedResponse() (*http.Response, error) {
 	return response, err
 }
 
+// WriteBadRequestResponse will create a new bad request response with the specified message
+func WriteBadRequestResponse(message string) (*http.Response, error) {
+	response := &http.Response{}
+	err := RewriteResponse(response, errorResponse{Message: message}, http.StatusBadRequest)
+
+	return response, err
+}
+
 // RewriteAccessDeniedResponse will overwrite the existing response with an access denied response
 func RewriteAccessDeniedResponse(response *http.Response) error {
 	return RewriteResponse(response, errorResponse{Message: "access denied to resource"}, http.StatusForbidden)
@@ -0,0 +1,178 @@
+package security
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/scheduler"
+	"github.com/gorilla/mux"
+
+	"github.com/rs/zerolog/log"
+)
+
+var endpointPathRe = regexp.MustCompile(`^/api/endpoints/([0-9]+)(/|$)`)
+
+// auditResponseWriter captures the status code written by the downstream handlers
+type auditResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (w *auditResponseWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Flush is required by streamed responses (e.g. docker image pull)
+func (w *auditResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack is required by upgraded connections (e.g. docker attach/exec)
+func (w *auditResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response writer does not support hijacking")
+	}
+
+	w.statusCode = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// auditLogCleanupInterval is the interval between two removals of the outdated audit log entries
+const auditLogCleanupInterval = 24 * time.Hour
+
+// StartAuditLogCleanup periodically removes the audit log entries older than the retention of the settings
+func StartAuditLogCleanup(scheduler *scheduler.Scheduler, dataStore dataservices.DataStore) {
+	scheduler.StartJobEvery(auditLogCleanupInterval, func() error {
+		err := CleanupAuditLogs(dataStore, time.Now())
+		if err != nil {
+			log.Warn().Err(err).Msg("unable to clean up the audit log")
+		}
+
+		return nil
+	})
+}
+
+// CleanupAuditLogs removes the audit log entries older than the retention of the settings at the time t
+func CleanupAuditLogs(dataStore dataservices.DataStore, t time.Time) error {
+	settings, err := dataStore.Settings().Settings()
+	if err != nil {
+		return err
+	}
+
+	retentionDays := settings.AuditLogRetentionDays
+	if retentionDays == 0 {
+		retentionDays = portainer.DefaultAuditLogRetentionDays
+	}
+
+	return dataStore.AuditLog().DeleteAuditLogsBefore(t.AddDate(0, 0, -retentionDays).Unix())
+}
+
+// mwAuditOperation records every mutating request in the audit log once it has been handled.
+// It relies on the token data being available in the request context.
+func (bouncer *RequestBouncer) mwAuditOperation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isMutatingMethod(r.Method) || RetrieveAuditLog(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tokenData, err := RetrieveTokenData(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := newAuditLog(r, tokenData)
+		rw := &auditResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(rw, r.WithContext(StoreAuditLog(r, entry)))
+
+		entry.StatusCode = rw.statusCode
+		entry.Outcome = portainer.AuditLogOutcomeSuccess
+		if rw.statusCode >= http.StatusBadRequest {
+			entry.Outcome = portainer.AuditLogOutcomeFailure
+		}
+
+		err = bouncer.dataStore.AuditLog().Create(entry)
+		if err != nil {
+			log.Warn().Err(err).Str("method", entry.Method).Str("path", entry.Path).Msg("unable to persist the audit log entry")
+		}
+	})
+}
+
+func newAuditLog(r *http.Request, tokenData *portainer.TokenData) *portainer.AuditLog {
+	entry := &portainer.AuditLog{
+		Timestamp:  time.Now().Unix(),
+		UserID:     tokenData.ID,
+		Username:   tokenData.Username,
+		AuthMethod: portainer.AuditLogAuthMethodJWT,
+		Method:     r.Method,
+		Path:       auditRequestPath(r),
+	}
+
+	if tokenData.APIKeyID != 0 {
+		entry.AuthMethod = portainer.AuditLogAuthMethodAPIKey
+		entry.APIKeyID = tokenData.APIKeyID
+	}
+
+	vars := mux.Vars(r)
+
+	match := endpointPathRe.FindStringSubmatch(entry.Path)
+	if match == nil {
+		entry.ResourceID = vars["id"]
+		return entry
+	}
+
+	endpointID, _ := strconv.Atoi(match[1])
+	entry.EndpointID = portainer.EndpointID(endpointID)
+
+	// environment(endpoint) scoped routes identify the targeted resource with extra route variables
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		if key != "id" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	values := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, vars[key])
+	}
+	entry.ResourceID = strings.Join(values, "/")
+
+	return entry
+}
+
+// auditRequestPath returns the original path of the request.
+// The query is left out on purpose as it can contain credentials (token, X-API-KEY).
+func auditRequestPath(r *http.Request) string {
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		return u.Path
+	}
+
+	return r.URL.Path
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
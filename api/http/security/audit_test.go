@@ -0,0 +1,106 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/apikey"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/jwt"
+	"github.com/gorilla/mux"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_mwAuditOperation(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+
+	bouncer := NewRequestBouncer(store, jwtService, apikey.NewAPIKeyService(nil, nil))
+
+	router := mux.NewRouter()
+	router.Handle("/api/endpoints/{id}/kubernetes/helm/{release}", bouncer.mwAuditOperation(testHandler200))
+	router.Handle("/api/stacks/{id}", bouncer.mwAuditOperation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})))
+
+	serve := func(method, url string, tokenData *portainer.TokenData) {
+		req := httptest.NewRequest(method, url, nil)
+		req = req.WithContext(StoreTokenData(req, tokenData))
+
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("read requests are not audited", func(t *testing.T) {
+		serve(http.MethodGet, "/api/stacks/1", &portainer.TokenData{ID: 1, Username: "admin"})
+
+		logs, err := store.AuditLog().AuditLogs()
+		is.NoError(err)
+		is.Empty(logs)
+	})
+
+	t.Run("mutating requests are audited", func(t *testing.T) {
+		serve(http.MethodDelete, "/api/endpoints/2/kubernetes/helm/nginx?token=secret", &portainer.TokenData{ID: 1, Username: "admin"})
+		serve(http.MethodPut, "/api/stacks/3", &portainer.TokenData{ID: 4, Username: "ci", APIKeyID: 5})
+
+		logs, err := store.AuditLog().AuditLogs()
+		is.NoError(err)
+		is.Len(logs, 2)
+
+		is.Equal(portainer.UserID(1), logs[0].UserID)
+		is.Equal(portainer.AuditLogAuthMethodJWT, logs[0].AuthMethod)
+		is.Equal(portainer.EndpointID(2), logs[0].EndpointID)
+		is.Equal("/api/endpoints/2/kubernetes/helm/nginx", logs[0].Path, "query should not be recorded")
+		is.Equal("nginx", logs[0].ResourceID)
+		is.Equal(http.StatusOK, logs[0].StatusCode)
+		is.Equal(portainer.AuditLogOutcomeSuccess, logs[0].Outcome)
+
+		is.Equal("ci", logs[1].Username)
+		is.Equal(portainer.AuditLogAuthMethodAPIKey, logs[1].AuthMethod)
+		is.Equal(portainer.APIKeyID(5), logs[1].APIKeyID)
+		is.Equal(portainer.EndpointID(0), logs[1].EndpointID)
+		is.Equal("3", logs[1].ResourceID)
+		is.Equal(http.StatusForbidden, logs[1].StatusCode)
+		is.Equal(portainer.AuditLogOutcomeFailure, logs[1].Outcome)
+	})
+}
+
+func Test_CleanupAuditLogs(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	now := time.Now()
+
+	old := &portainer.AuditLog{Timestamp: now.AddDate(0, 0, -portainer.DefaultAuditLogRetentionDays-1).Unix()}
+	recent := &portainer.AuditLog{Timestamp: now.AddDate(0, 0, -10).Unix()}
+	is.NoError(store.AuditLog().Create(old))
+	is.NoError(store.AuditLog().Create(recent))
+
+	is.NoError(CleanupAuditLogs(store, now))
+
+	entries, err := store.AuditLog().AuditLogs()
+	is.NoError(err)
+	is.Len(entries, 1)
+	is.Equal(recent.ID, entries[0].ID)
+
+	settings, err := store.Settings().Settings()
+	is.NoError(err)
+	settings.AuditLogRetentionDays = 7
+	is.NoError(store.Settings().UpdateSettings(settings))
+
+	is.NoError(CleanupAuditLogs(store, now))
+
+	entries, err = store.AuditLog().AuditLogs()
+	is.NoError(err)
+	is.Empty(entries)
+}
@@ -2,8 +2,10 @@ package security
 
 import (
 	"net/http"
+	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
 )
 
 // IsAdmin returns true if the logged-in user is an admin
@@ -144,16 +146,19 @@ func AuthorizedRegistryAccess(registry *portainer.Registry, user *portainer.User
 	return AuthorizedAccess(user.ID, teamMemberships, registryEndpointAccesses.UserAccessPolicies, registryEndpointAccesses.TeamAccessPolicies)
 }
 
-// AuthorizedAccess verifies the userID or memberships are authorized to use an object per the supplied access policies
+// AuthorizedAccess verifies the userID or memberships are authorized to use an object per the supplied access policies.
+// The expired access policies which are not revoked yet do not authorize anymore.
 func AuthorizedAccess(userID portainer.UserID, memberships []portainer.TeamMembership, userAccessPolicies portainer.UserAccessPolicies, teamAccessPolicies portainer.TeamAccessPolicies) bool {
-	_, userAccess := userAccessPolicies[userID]
-	if userAccess {
+	now := time.Now()
+
+	policy, userAccess := userAccessPolicies[userID]
+	if userAccess && !authorization.IsPolicyExpired(policy, now) {
 		return true
 	}
 
 	for _, membership := range memberships {
-		_, teamAccess := teamAccessPolicies[membership.TeamID]
-		if teamAccess {
+		policy, teamAccess := teamAccessPolicies[membership.TeamID]
+		if teamAccess && !authorization.IsPolicyExpired(policy, now) {
 			return true
 		}
 	}
@@ -0,0 +1,23 @@
+package security
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AuthorizedAccess_expiredGrants(t *testing.T) {
+	is := assert.New(t)
+
+	expired := time.Now().Add(-time.Minute).Unix()
+	later := time.Now().Add(time.Hour).Unix()
+
+	memberships := []portainer.TeamMembership{{UserID: 1, TeamID: 1}}
+
+	is.True(AuthorizedAccess(1, memberships, portainer.UserAccessPolicies{1: {RoleID: 1, ExpiresAt: later}}, nil))
+	is.False(AuthorizedAccess(1, memberships, portainer.UserAccessPolicies{1: {RoleID: 1, ExpiresAt: expired}}, nil))
+	is.False(AuthorizedAccess(1, memberships, nil, portainer.TeamAccessPolicies{1: {RoleID: 1, ExpiresAt: expired}}))
+	is.True(AuthorizedAccess(1, memberships, portainer.UserAccessPolicies{1: {RoleID: 1, ExpiresAt: expired}}, portainer.TeamAccessPolicies{1: {RoleID: 1}}))
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -166,7 +169,10 @@ func (bouncer *RequestBouncer) AuthorizedEdgeEndpointOperation(r *http.Request,
 // mwAuthenticatedUser authenticates a request by
 // - adding a secure handlers to the response
 // - authenticating the request with a valid token
+// - recording mutating requests in the audit log, including the ones denied by the API key restrictions
 func (bouncer *RequestBouncer) mwAuthenticatedUser(h http.Handler) http.Handler {
+	h = bouncer.mwCheckAPIKeyRestrictions(h)
+	h = bouncer.mwAuditOperation(h)
 	h = bouncer.mwAuthenticateFirst([]tokenLookup{
 		bouncer.JWTAuthLookup,
 		bouncer.apiKeyLookup,
@@ -322,6 +328,7 @@ func (bouncer *RequestBouncer) apiKeyLookup(r *http.Request) *portainer.TokenDat
 		ID:       user.ID,
 		Username: user.Username,
 		Role:     user.Role,
+		APIKeyID: apiKey.ID,
 	}
 	if _, err := bouncer.jwtService.GenerateToken(tokenData); err != nil {
 		return nil
@@ -334,8 +341,153 @@ func (bouncer *RequestBouncer) apiKeyLookup(r *http.Request) *portainer.TokenDat
 	return tokenData
 }
 
-// extractBearerToken extracts the Bearer token from the request header or query parameter and returns the token.
+// mwCheckAPIKeyRestrictions rejects the requests authenticated with an API key which are not allowed by its restrictions,
+// and warns the clients of the API keys about to expire
+func (bouncer *RequestBouncer) mwCheckAPIKeyRestrictions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokenData, err := RetrieveTokenData(r)
+		if err != nil || tokenData.APIKeyID == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rawAPIKey, ok := extractAPIKey(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		_, apiKey, err := bouncer.apiKeyService.GetDigestUserAndKey(bouncer.apiKeyService.HashRaw(rawAPIKey))
+		if err != nil {
+			httperror.WriteError(w, http.StatusUnauthorized, "A valid authorisation token is missing", httperrors.ErrUnauthorized)
+			return
+		}
+
+		apiPath := requestAPIPath(r)
+
+		endpointIDs, err := bouncer.requestEndpointIDs(r, apiPath)
+		if err != nil {
+			httperror.WriteError(w, http.StatusInternalServerError, "Unable to retrieve the environment of the request", err)
+			return
+		}
+
+		err = apikey.CheckRestrictions(&apiKey.Restrictions, r.Method, apiPath, endpointIDs)
+		if err != nil {
+			httperror.WriteError(w, http.StatusForbidden, "Access denied by the restrictions of the API key", err)
+			return
+		}
+
+		if apiKey.ExpiresAt > 0 && time.Until(time.Unix(apiKey.ExpiresAt, 0)) < apikey.ExpiryWarningPeriod {
+			expiresAt := time.Unix(apiKey.ExpiresAt, 0).UTC().Format(time.RFC3339)
+			w.Header().Set("Warning", fmt.Sprintf(`299 - "The API key expires on %s"`, expiresAt))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestAPIPath returns the path of a request relative to /api, as received by Portainer before the handlers
+// stripped their prefixes
+func requestAPIPath(r *http.Request) string {
+	p := r.URL.Path
+	if u, err := url.ParseRequestURI(r.RequestURI); err == nil {
+		p = u.Path
+	}
+
+	return strings.TrimPrefix(path.Clean("/"+p), "/api")
+}
+
+// requestEndpointIDs returns the environments(endpoints) targeted by a request, from its path, its endpointId
+// query parameter and the environment(endpoint) of the stack or webhook it targets
+func (bouncer *RequestBouncer) requestEndpointIDs(r *http.Request, apiPath string) ([]portainer.EndpointID, error) {
+	var endpointIDs []portainer.EndpointID
+
+	segments := strings.Split(strings.Trim(apiPath, "/"), "/")
+	if len(segments) >= 2 {
+		switch segments[0] {
+		case "endpoints", "docker", "kubernetes":
+			if id, err := strconv.Atoi(segments[1]); err == nil {
+				endpointIDs = append(endpointIDs, portainer.EndpointID(id))
+			}
+		case "stacks", "webhooks":
+			if len(segments) >= 3 && segments[0] == "stacks" && segments[2] == "migrate" {
+				// the target environment(endpoint) of a migration is only known by the handler
+				return nil, nil
+			}
+
+			endpointID, err := bouncer.resourceEndpointID(segments[0], segments[1])
+			if err != nil {
+				return nil, err
+			}
+
+			if endpointID != 0 {
+				endpointIDs = append(endpointIDs, endpointID)
+			}
+		}
+	}
+
+	for k, v := range r.URL.Query() {
+		if strings.EqualFold(k, "endpointId") && len(v) > 0 {
+			if id, err := strconv.Atoi(v[0]); err == nil {
+				endpointIDs = append(endpointIDs, portainer.EndpointID(id))
+			}
+		}
+	}
+
+	return endpointIDs, nil
+}
+
+// resourceEndpointID returns the environment(endpoint) of a stack or a webhook, or 0 when it does not exist
+func (bouncer *RequestBouncer) resourceEndpointID(resourceType, identifier string) (portainer.EndpointID, error) {
+	id, err := strconv.Atoi(identifier)
+	if err != nil {
+		return 0, nil
+	}
+
+	var endpointID portainer.EndpointID
+	switch resourceType {
+	case "stacks":
+		stack, err := bouncer.dataStore.Stack().Stack(portainer.StackID(id))
+		if err != nil {
+			return 0, ignoreNotFound(bouncer.dataStore, err)
+		}
+		endpointID = stack.EndpointID
+	case "webhooks":
+		webhook, err := bouncer.dataStore.Webhook().Webhook(portainer.WebhookID(id))
+		if err != nil {
+			return 0, ignoreNotFound(bouncer.dataStore, err)
+		}
+		endpointID = webhook.EndpointID
+	}
+
+	return endpointID, nil
+}
+
+func ignoreNotFound(dataStore dataservices.DataStore, err error) error {
+	if dataStore.IsErrObjectNotFound(err) {
+		return nil
+	}
+
+	return err
+}
+
+// extractBearerToken extracts the Bearer token from the request header or query parameter, or from the session
+// cookie of a browser, and returns the token.
 func extractBearerToken(r *http.Request) (string, error) {
+	token, err := extractHeaderOrQueryToken(r)
+	if err == nil {
+		return token, nil
+	}
+
+	if cookie, cookieErr := r.Cookie(SessionCookieName); cookieErr == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+
+	return "", err
+}
+
+// extractHeaderOrQueryToken extracts the Bearer token from the request header or query parameter and returns the token.
+func extractHeaderOrQueryToken(r *http.Request) (string, error) {
 	// Optionally, token might be set via the "token" query parameter.
 	// For example, in websocket requests
 	token := r.URL.Query().Get("token")
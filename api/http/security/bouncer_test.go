@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/apikey"
@@ -154,6 +155,20 @@ func Test_extractBearerToken(t *testing.T) {
 			is.NoError(err)
 		}
 	}
+
+	t.Run("the session cookie is used when there is no bearer token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "session"})
+
+		token, err := extractBearerToken(req)
+		is.NoError(err)
+		is.Equal("session", token)
+
+		req.Header.Set("Authorization", "Bearer header")
+		token, err = extractBearerToken(req)
+		is.NoError(err)
+		is.Equal("header", token, "the Authorization header takes precedence over the session cookie")
+	})
 }
 
 func Test_extractAPIKeyHeader(t *testing.T) {
@@ -289,7 +304,7 @@ func Test_apiKeyLookup(t *testing.T) {
 	})
 
 	t.Run("valid x-api-key header succeeds api-key lookup", func(t *testing.T) {
-		rawAPIKey, _, err := apiKeyService.GenerateApiKey(*user, "test")
+		rawAPIKey, apiKey, err := apiKeyService.GenerateApiKey(*user, "test")
 		is.NoError(err)
 
 		req := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -297,7 +312,7 @@ func Test_apiKeyLookup(t *testing.T) {
 
 		token := bouncer.apiKeyLookup(req)
 
-		expectedToken := &portainer.TokenData{ID: user.ID, Username: user.Username, Role: portainer.StandardUserRole}
+		expectedToken := &portainer.TokenData{ID: user.ID, Username: user.Username, Role: portainer.StandardUserRole, APIKeyID: apiKey.ID}
 		is.Equal(expectedToken, token)
 	})
 
@@ -311,7 +326,7 @@ func Test_apiKeyLookup(t *testing.T) {
 
 		token := bouncer.apiKeyLookup(req)
 
-		expectedToken := &portainer.TokenData{ID: user.ID, Username: user.Username, Role: portainer.StandardUserRole}
+		expectedToken := &portainer.TokenData{ID: user.ID, Username: user.Username, Role: portainer.StandardUserRole, APIKeyID: apiKey.ID}
 		is.Equal(expectedToken, token)
 	})
 
@@ -325,7 +340,7 @@ func Test_apiKeyLookup(t *testing.T) {
 
 		token := bouncer.apiKeyLookup(req)
 
-		expectedToken := &portainer.TokenData{ID: user.ID, Username: user.Username, Role: portainer.StandardUserRole}
+		expectedToken := &portainer.TokenData{ID: user.ID, Username: user.Username, Role: portainer.StandardUserRole, APIKeyID: apiKey.ID}
 		is.Equal(expectedToken, token)
 
 		_, apiKeyUpdated, err := apiKeyService.GetDigestUserAndKey(apiKey.Digest)
@@ -334,3 +349,107 @@ func Test_apiKeyLookup(t *testing.T) {
 		is.True(apiKeyUpdated.LastUsed > apiKey.LastUsed)
 	})
 }
+
+func Test_apiKeyRestrictions(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	user := &portainer.User{ID: 2, Username: "standard", Role: portainer.StandardUserRole}
+	err := store.User().Create(user)
+	is.NoError(err, "error creating user")
+
+	jwtService, err := jwt.NewService("1h", store)
+	is.NoError(err, "Error initiating jwt service")
+	apiKeyService := apikey.NewAPIKeyService(store.APIKeyRepository(), store.User())
+	bouncer := NewRequestBouncer(store, jwtService, apiKeyService)
+
+	h := bouncer.AuthenticatedAccess(testHandler200)
+
+	serve := func(method, target, rawAPIKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Add("x-api-key", rawAPIKey)
+
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+
+		return rr
+	}
+
+	t.Run("expired api-key is rejected", func(t *testing.T) {
+		rawAPIKey, apiKey, err := apiKeyService.GenerateRestrictedApiKey(*user, "expired", time.Now().Add(-time.Minute).Unix(), portainer.APIKeyRestrictions{})
+		is.NoError(err)
+		defer apiKeyService.DeleteAPIKey(apiKey.ID)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Add("x-api-key", rawAPIKey)
+		is.Nil(bouncer.apiKeyLookup(req))
+
+		is.Equal(http.StatusUnauthorized, serve(http.MethodGet, "/api/stacks", rawAPIKey).Code)
+	})
+
+	t.Run("api-key about to expire is warned", func(t *testing.T) {
+		rawAPIKey, apiKey, err := apiKeyService.GenerateRestrictedApiKey(*user, "expiring", time.Now().Add(time.Hour).Unix(), portainer.APIKeyRestrictions{})
+		is.NoError(err)
+		defer apiKeyService.DeleteAPIKey(apiKey.ID)
+
+		rr := serve(http.MethodGet, "/api/stacks", rawAPIKey)
+		is.Equal(http.StatusOK, rr.Code)
+		is.Contains(rr.Header().Get("Warning"), "The API key expires on")
+	})
+
+	t.Run("read-only api-key is limited to read requests", func(t *testing.T) {
+		rawAPIKey, apiKey, err := apiKeyService.GenerateRestrictedApiKey(*user, "read-only", 0, portainer.APIKeyRestrictions{ReadOnly: true})
+		is.NoError(err)
+		defer apiKeyService.DeleteAPIKey(apiKey.ID)
+
+		rr := serve(http.MethodGet, "/api/stacks", rawAPIKey)
+		is.Equal(http.StatusOK, rr.Code)
+		is.Empty(rr.Header().Get("Warning"))
+
+		is.Equal(http.StatusForbidden, serve(http.MethodPost, "/api/stacks", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodGet, "/api/websocket/exec?endpointId=1&id=abc", rawAPIKey).Code)
+
+		logs, err := store.AuditLog().AuditLogs()
+		is.NoError(err)
+		is.Len(logs, 1, "denied mutating request should be audited")
+		is.Equal(apiKey.ID, logs[0].APIKeyID)
+		is.Equal(http.StatusForbidden, logs[0].StatusCode)
+		is.Equal(portainer.AuditLogOutcomeFailure, logs[0].Outcome)
+	})
+
+	t.Run("api-key is limited to its environments and scopes", func(t *testing.T) {
+		rawAPIKey, apiKey, err := apiKeyService.GenerateRestrictedApiKey(*user, "ci", 0, portainer.APIKeyRestrictions{
+			EndpointIDs: []portainer.EndpointID{1},
+			Scopes: []portainer.APIKeyScope{
+				{Path: "/stacks"},
+				{Path: "/webhooks"},
+				{Path: "/endpoints/*/docker/containers", Methods: []string{http.MethodGet}},
+			},
+		})
+		is.NoError(err)
+		defer apiKeyService.DeleteAPIKey(apiKey.ID)
+
+		is.NoError(store.Stack().Create(&portainer.Stack{ID: 4, Name: "allowed", EndpointID: 1}))
+		is.NoError(store.Stack().Create(&portainer.Stack{ID: 5, Name: "other", EndpointID: 2}))
+		is.NoError(store.Webhook().Create(&portainer.Webhook{ID: 1, Token: "token", EndpointID: 2}))
+
+		is.Equal(http.StatusOK, serve(http.MethodPost, "/api/stacks?endpointId=1", rawAPIKey).Code)
+		is.Equal(http.StatusOK, serve(http.MethodPut, "/api/stacks/3/git/redeploy?endpointId=1", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodPost, "/api/stacks?endpointId=2", rawAPIKey).Code)
+		is.Equal(http.StatusOK, serve(http.MethodGet, "/api/endpoints/1/docker/containers/json", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodPost, "/api/endpoints/1/docker/containers/create", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodGet, "/api/endpoints/2/docker/containers/json", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodGet, "/api/users", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodGet, "/api/stacks/../users", rawAPIKey).Code)
+
+		is.Equal(http.StatusOK, serve(http.MethodPost, "/api/stacks/4/start?endpointId=1", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodPost, "/api/stacks/5/start?endpointId=1", rawAPIKey).Code, "the environment of the stack should be checked")
+		is.Equal(http.StatusForbidden, serve(http.MethodPut, "/api/stacks/5", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodGet, "/api/stacks/5", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodPut, "/api/webhooks/1", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodPost, "/api/stacks/4/migrate?endpointId=1", rawAPIKey).Code)
+		is.Equal(http.StatusForbidden, serve(http.MethodPost, "/api/webhooks", rawAPIKey).Code, "state-changing requests without environment should be denied")
+	})
+}
@@ -15,6 +15,7 @@ type (
 const (
 	contextAuthenticationKey contextKey = iota
 	contextRestrictedRequest
+	contextAuditLog
 )
 
 // StoreTokenData stores a TokenData object inside the request context and returns the enhanced context.
@@ -49,3 +50,16 @@ func RetrieveRestrictedRequestContext(request *http.Request) (*RestrictedRequest
 	requestContext := contextData.(*RestrictedRequestContext)
 	return requestContext, nil
 }
+
+// StoreAuditLog stores the audit log entry of the request inside the request context
+// and returns the enhanced context.
+func StoreAuditLog(request *http.Request, entry *portainer.AuditLog) context.Context {
+	return context.WithValue(request.Context(), contextAuditLog, entry)
+}
+
+// RetrieveAuditLog returns the audit log entry stored in the request context, nil if the request is not audited.
+// The entry can be enriched by the downstream handlers and proxies (e.g. with the targeted resource identifier).
+func RetrieveAuditLog(request *http.Request) *portainer.AuditLog {
+	entry, _ := request.Context().Value(contextAuditLog).(*portainer.AuditLog)
+	return entry
+}
@@ -0,0 +1,214 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+
+	httperrors "github.com/cloudogu/portainer-ce/api/http/errors"
+	"github.com/pkg/errors"
+	httperror "github.com/portainer/libhttp/error"
+)
+
+const (
+	// CSRFCookieName is the cookie holding the CSRF token of a browser session, read by the frontend
+	CSRFCookieName = "XSRF-TOKEN"
+	// CSRFHeaderName is the header the CSRF token must be sent in with the state-changing requests
+	CSRFHeaderName = "X-XSRF-TOKEN"
+	// SessionCookieName is the HttpOnly cookie holding the JWT of a browser session
+	SessionCookieName = "portainer_session"
+
+	csrfNonceSize = 16
+)
+
+var errCSRFTokenInvalid = errors.New("invalid or missing CSRF token")
+
+// CSRFProtection protects the state-changing requests authenticated with the session cookie against
+// cross-site request forgery. The requests authenticated with an API key or with an Authorization header
+// are not concerned, the browser does not send them on its own.
+//
+// A token bound to the session is issued on login, in a cookie the frontend copies in the
+// CSRFHeaderName header of its requests. The token is reissued on the safe requests when it is missing
+// or no longer valid, e.g. after a restart of Portainer.
+type CSRFProtection struct {
+	secret         []byte
+	trustedOrigins map[string]bool
+}
+
+// NewCSRFProtection creates a CSRFProtection. The requests sent from the trusted origins, e.g. https://portainer.example.com,
+// are accepted along with the ones sent from the origin Portainer is served on.
+func NewCSRFProtection(trustedOrigins []string) (*CSRFProtection, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "unable to generate the CSRF secret")
+	}
+
+	origins := make(map[string]bool, len(trustedOrigins))
+	for _, origin := range trustedOrigins {
+		origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+
+	return &CSRFProtection{
+		secret:         secret,
+		trustedOrigins: origins,
+	}, nil
+}
+
+// Middleware rejects the state-changing requests authenticated with the session cookie which are sent from
+// an untrusted origin or without a valid CSRF token
+func (csrf *CSRFProtection) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, ok := sessionCookieToken(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if isSafeMethod(r.Method) {
+			if cookie, err := r.Cookie(CSRFCookieName); err != nil || !csrf.validToken(cookie.Value, session) {
+				csrf.IssueToken(w, r, session)
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !csrf.trustedOrigin(r) {
+			httperror.WriteError(w, http.StatusForbidden, "Cross-origin request denied", httperrors.ErrResourceAccessDenied)
+			return
+		}
+
+		if !csrf.validToken(r.Header.Get(CSRFHeaderName), session) {
+			httperror.WriteError(w, http.StatusForbidden, "Invalid CSRF token", errCSRFTokenInvalid)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IssueToken sets the session cookie holding the JWT of a browser session, and the cookie holding
+// a new CSRF token bound to that session
+func (csrf *CSRFProtection) IssueToken(w http.ResponseWriter, r *http.Request, session string) {
+	nonce := make([]byte, csrfNonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    session,
+		Path:     "/",
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(append(nonce, csrf.signature(nonce, session)...)),
+		Path:     "/",
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// ClearToken removes the session cookie and the cookie holding the CSRF token
+func (csrf *CSRFProtection) ClearToken(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookieName,
+		Path:     "/",
+		MaxAge:   -1,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// sessionCookieToken returns the JWT of a request authenticated with the session cookie. The requests sending
+// an API key or a bearer token are authenticated with those and are not concerned.
+func sessionCookieToken(r *http.Request) (string, bool) {
+	if _, ok := extractAPIKey(r); ok {
+		return "", false
+	}
+
+	if _, err := extractHeaderOrQueryToken(r); err == nil {
+		return "", false
+	}
+
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+
+	return cookie.Value, true
+}
+
+func (csrf *CSRFProtection) signature(nonce []byte, session string) []byte {
+	mac := hmac.New(sha256.New, csrf.secret)
+	mac.Write(nonce)
+	mac.Write([]byte(session))
+
+	return mac.Sum(nil)
+}
+
+func (csrf *CSRFProtection) validToken(token string, session string) bool {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != csrfNonceSize+sha256.Size {
+		return false
+	}
+
+	nonce, signature := raw[:csrfNonceSize], raw[csrfNonceSize:]
+
+	return hmac.Equal(signature, csrf.signature(nonce, session))
+}
+
+// trustedOrigin checks the Origin header of a request, or its Referer when there is no Origin,
+// against the host of the request and the trusted origins. The requests without any are accepted.
+func (csrf *CSRFProtection) trustedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer := r.Header.Get("Referer")
+		if referer == "" {
+			return true
+		}
+
+		u, err := url.Parse(referer)
+		if err != nil {
+			return false
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	return csrf.trustedOrigins[strings.ToLower(u.Scheme+"://"+u.Host)]
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CSRFProtection(t *testing.T) {
+	is := assert.New(t)
+
+	const session = "session-jwt"
+
+	csrf, err := NewCSRFProtection([]string{"https://portainer.example.com/"})
+	is.NoError(err)
+
+	handler := csrf.Middleware(testHandler200)
+
+	// the cookies issued on login
+	login := httptest.NewRecorder()
+	csrf.IssueToken(login, httptest.NewRequest(http.MethodPost, "/api/auth", nil), session)
+	cookies := login.Result().Cookies()
+	is.Len(cookies, 2)
+	is.Equal(SessionCookieName, cookies[0].Name)
+	is.True(cookies[0].HttpOnly, "the session cookie must not be readable by the frontend")
+	is.Equal(session, cookies[0].Value)
+	is.Equal(CSRFCookieName, cookies[1].Name)
+	csrfToken := cookies[1].Value
+
+	otherCSRF, err := NewCSRFProtection(nil)
+	is.NoError(err)
+	foreign := httptest.NewRecorder()
+	otherCSRF.IssueToken(foreign, httptest.NewRequest(http.MethodPost, "/api/auth", nil), session)
+	foreignToken := foreign.Result().Cookies()[1].Value
+
+	tests := []struct {
+		name           string
+		method         string
+		session        bool
+		headers        map[string]string
+		wantStatusCode int
+	}{
+		{
+			name:           "anonymous requests are not concerned",
+			method:         http.MethodPost,
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "safe requests do not require a token",
+			method:         http.MethodGet,
+			session:        true,
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "requests authenticated with an API key are not concerned",
+			method:         http.MethodPost,
+			session:        true,
+			headers:        map[string]string{apiKeyHeader: "ptr_key", "Origin": "https://evil.example.com"},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "requests authenticated with a bearer token are not concerned",
+			method:         http.MethodPost,
+			session:        true,
+			headers:        map[string]string{"Authorization": "Bearer jwt", "Origin": "https://evil.example.com"},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "session requests without a token are rejected",
+			method:         http.MethodPost,
+			session:        true,
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "session requests with a token issued by another instance are rejected",
+			method:         http.MethodDelete,
+			session:        true,
+			headers:        map[string]string{CSRFHeaderName: foreignToken},
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "session requests with a valid token are accepted",
+			method:         http.MethodPut,
+			session:        true,
+			headers:        map[string]string{CSRFHeaderName: csrfToken},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "session requests from the same origin are accepted",
+			method:         http.MethodPost,
+			session:        true,
+			headers:        map[string]string{CSRFHeaderName: csrfToken, "Origin": "http://example.com"},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "session requests from a trusted origin are accepted",
+			method:         http.MethodPost,
+			session:        true,
+			headers:        map[string]string{CSRFHeaderName: csrfToken, "Origin": "https://portainer.example.com"},
+			wantStatusCode: http.StatusOK,
+		},
+		{
+			name:           "session requests from an untrusted origin are rejected",
+			method:         http.MethodPost,
+			session:        true,
+			headers:        map[string]string{CSRFHeaderName: csrfToken, "Origin": "https://evil.example.com"},
+			wantStatusCode: http.StatusForbidden,
+		},
+		{
+			name:           "session requests referred by an untrusted origin are rejected",
+			method:         http.MethodPost,
+			session:        true,
+			headers:        map[string]string{CSRFHeaderName: csrfToken, "Referer": "https://evil.example.com/page"},
+			wantStatusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/stacks", nil)
+			if tt.session {
+				req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: session})
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			is.Equal(tt.wantStatusCode, rr.Code)
+		})
+	}
+
+	t.Run("safe session requests without a valid token get a new one", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/stacks", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: session})
+		req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: foreignToken})
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		cookies := rr.Result().Cookies()
+		is.Len(cookies, 2)
+		is.True(csrf.validToken(cookies[1].Value, session))
+		is.False(csrf.validToken(cookies[1].Value, "other-session"), "the token must be bound to the session")
+	})
+}
@@ -1,6 +1,8 @@
 package security
 
 import (
+	"unicode"
+
 	portainer "github.com/cloudogu/portainer-ce/api"
 
 	"github.com/rs/zerolog/log"
@@ -29,7 +31,30 @@ func (c *passwordStrengthChecker) Check(password string) bool {
 		return true
 	}
 
-	return len(password) >= s.InternalAuthSettings.RequiredPasswordLength
+	return len(password) >= s.InternalAuthSettings.RequiredPasswordLength && meetsComplexity(password, &s.InternalAuthSettings)
+}
+
+// meetsComplexity returns true if the password contains the kinds of characters required by the settings
+func meetsComplexity(password string, settings *portainer.InternalAuthSettings) bool {
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		case !unicode.IsLetter(c):
+			hasSpecial = true
+		}
+	}
+
+	return (hasUpper || !settings.RequireUppercase) &&
+		(hasLower || !settings.RequireLowercase) &&
+		(hasDigit || !settings.RequireDigit) &&
+		(hasSpecial || !settings.RequireSpecialCharacter)
 }
 
 type settingsService interface {
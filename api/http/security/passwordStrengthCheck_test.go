@@ -36,14 +36,48 @@ func TestStrengthCheck(t *testing.T) {
 	}
 }
 
+func TestStrengthCheck_Complexity(t *testing.T) {
+	checker := NewPasswordStrengthChecker(settingsStub{
+		minLength: 8,
+		rules: portainer.InternalAuthSettings{
+			RequireUppercase:        true,
+			RequireLowercase:        true,
+			RequireDigit:            true,
+			RequireSpecialCharacter: true,
+		},
+	})
+
+	tests := []struct {
+		password   string
+		wantStrong bool
+	}{
+		{"Portainer1!", true},
+		{"Pörtainer1 ", true},
+		{"Port1!", false},
+		{"portainer1!", false},
+		{"PORTAINER1!", false},
+		{"Portainer!!", false},
+		{"Portainer11", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			if gotStrong := checker.Check(tt.password); gotStrong != tt.wantStrong {
+				t.Errorf("StrengthCheck() = %v, want %v", gotStrong, tt.wantStrong)
+			}
+		})
+	}
+}
+
 type settingsStub struct {
 	minLength int
+	rules     portainer.InternalAuthSettings
 }
 
 func (s settingsStub) Settings() (*portainer.Settings, error) {
+	settings := s.rules
+	settings.RequiredPasswordLength = s.minLength
+
 	return &portainer.Settings{
-		InternalAuthSettings: portainer.InternalAuthSettings{
-			RequiredPasswordLength: s.minLength,
-		},
+		InternalAuthSettings: settings,
 	}, nil
 }
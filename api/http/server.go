@@ -10,11 +10,13 @@ import (
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/adminmonitor"
 	"github.com/cloudogu/portainer-ce/api/apikey"
+	operations "github.com/cloudogu/portainer-ce/api/backup"
 	"github.com/cloudogu/portainer-ce/api/crypto"
 	"github.com/cloudogu/portainer-ce/api/dataservices"
 	"github.com/cloudogu/portainer-ce/api/demo"
 	"github.com/cloudogu/portainer-ce/api/docker"
 	"github.com/cloudogu/portainer-ce/api/http/handler"
+	"github.com/cloudogu/portainer-ce/api/http/handler/auditlogs"
 	"github.com/cloudogu/portainer-ce/api/http/handler/auth"
 	"github.com/cloudogu/portainer-ce/api/http/handler/backup"
 	"github.com/cloudogu/portainer-ce/api/http/handler/customtemplates"
@@ -33,7 +35,9 @@ import (
 	"github.com/cloudogu/portainer-ce/api/http/handler/hostmanagement/openamt"
 	kubehandler "github.com/cloudogu/portainer-ce/api/http/handler/kubernetes"
 	"github.com/cloudogu/portainer-ce/api/http/handler/ldap"
+	"github.com/cloudogu/portainer-ce/api/http/handler/metrics"
 	"github.com/cloudogu/portainer-ce/api/http/handler/motd"
+	"github.com/cloudogu/portainer-ce/api/http/handler/notifications"
 	"github.com/cloudogu/portainer-ce/api/http/handler/registries"
 	"github.com/cloudogu/portainer-ce/api/http/handler/resourcecontrols"
 	"github.com/cloudogu/portainer-ce/api/http/handler/roles"
@@ -50,6 +54,7 @@ import (
 	"github.com/cloudogu/portainer-ce/api/http/handler/users"
 	"github.com/cloudogu/portainer-ce/api/http/handler/webhooks"
 	"github.com/cloudogu/portainer-ce/api/http/handler/websocket"
+	"github.com/cloudogu/portainer-ce/api/http/middlewares"
 	"github.com/cloudogu/portainer-ce/api/http/offlinegate"
 	"github.com/cloudogu/portainer-ce/api/http/proxy"
 	"github.com/cloudogu/portainer-ce/api/http/proxy/factory/kubernetes"
@@ -60,6 +65,7 @@ import (
 	"github.com/cloudogu/portainer-ce/api/internal/upgrade"
 	k8s "github.com/cloudogu/portainer-ce/api/kubernetes"
 	"github.com/cloudogu/portainer-ce/api/kubernetes/cli"
+	notifier "github.com/cloudogu/portainer-ce/api/notifications"
 	"github.com/cloudogu/portainer-ce/api/scheduler"
 	"github.com/cloudogu/portainer-ce/api/stacks/deployments"
 	"github.com/portainer/portainer/pkg/libhelm"
@@ -87,6 +93,7 @@ type Server struct {
 	OpenAMTService              portainer.OpenAMTService
 	APIKeyService               apikey.APIKeyService
 	JWTService                  dataservices.JWTService
+	CSRFTrustedOrigins          []string
 	LDAPService                 portainer.LDAPService
 	OAuthService                portainer.OAuthService
 	SwarmStackManager           portainer.SwarmStackManager
@@ -103,6 +110,7 @@ type Server struct {
 	ShutdownCtx                 context.Context
 	ShutdownTrigger             context.CancelFunc
 	StackDeployer               deployments.StackDeployer
+	NotificationService         *notifier.Service
 	DemoService                 *demo.Service
 	UpgradeService              upgrade.Service
 }
@@ -116,8 +124,22 @@ func (server *Server) Start() error {
 	rateLimiter := security.NewRateLimiter(10, 1*time.Second, 1*time.Hour)
 	offlineGate := offlinegate.NewOfflineGate()
 
+	backupScheduleService := operations.NewScheduleService(server.Scheduler, offlineGate, server.DataStore, server.FileService.GetDatastorePath())
+	err := backupScheduleService.Start()
+	if err != nil {
+		log.Error().Err(err).Msg("unable to schedule the automatic backups")
+	}
+
 	passwordStrengthChecker := security.NewPasswordStrengthChecker(server.DataStore.Settings())
 
+	csrfProtection, err := security.NewCSRFProtection(server.CSRFTrustedOrigins)
+	if err != nil {
+		return err
+	}
+
+	var auditLogHandler = auditlogs.NewHandler(requestBouncer)
+	auditLogHandler.DataStore = server.DataStore
+
 	var authHandler = auth.NewHandler(requestBouncer, rateLimiter, passwordStrengthChecker)
 	authHandler.DataStore = server.DataStore
 	authHandler.CryptoService = server.CryptoService
@@ -126,6 +148,7 @@ func (server *Server) Start() error {
 	authHandler.ProxyManager = server.ProxyManager
 	authHandler.KubernetesTokenCacheManager = kubernetesTokenCacheManager
 	authHandler.OAuthService = server.OAuthService
+	authHandler.CSRFProtection = csrfProtection
 
 	adminMonitor := adminmonitor.New(5*time.Minute, server.DataStore, server.ShutdownCtx)
 	adminMonitor.Start()
@@ -142,6 +165,7 @@ func (server *Server) Start() error {
 
 	var roleHandler = roles.NewHandler(requestBouncer)
 	roleHandler.DataStore = server.DataStore
+	roleHandler.KubernetesClientFactory = server.KubernetesClientFactory
 
 	var customTemplatesHandler = customtemplates.NewHandler(requestBouncer)
 	customTemplatesHandler.DataStore = server.DataStore
@@ -161,6 +185,7 @@ func (server *Server) Start() error {
 	edgeStacksHandler.FileService = server.FileService
 	edgeStacksHandler.GitService = server.GitService
 	edgeStacksHandler.KubernetesDeployer = server.KubernetesDeployer
+	edgeStacksHandler.NotificationService = server.NotificationService
 
 	var edgeTemplatesHandler = edgetemplates.NewHandler(requestBouncer)
 	edgeTemplatesHandler.DataStore = server.DataStore
@@ -203,8 +228,16 @@ func (server *Server) Start() error {
 	ldapHandler.FileService = server.FileService
 	ldapHandler.LDAPService = server.LDAPService
 
+	var metricsHandler = metrics.NewHandler(requestBouncer)
+	metricsHandler.DataStore = server.DataStore
+	metricsHandler.ReverseTunnelService = server.ReverseTunnelService
+
 	var motdHandler = motd.NewHandler(requestBouncer)
 
+	var notificationHandler = notifications.NewHandler(requestBouncer)
+	notificationHandler.DataStore = server.DataStore
+	notificationHandler.NotificationService = server.NotificationService
+
 	var registryHandler = registries.NewHandler(requestBouncer)
 	registryHandler.DataStore = server.DataStore
 	registryHandler.FileService = server.FileService
@@ -220,6 +253,7 @@ func (server *Server) Start() error {
 	settingsHandler.JWTService = server.JWTService
 	settingsHandler.LDAPService = server.LDAPService
 	settingsHandler.SnapshotService = server.SnapshotService
+	settingsHandler.BackupScheduleService = backupScheduleService
 
 	var sslHandler = sslhandler.NewHandler(requestBouncer)
 	sslHandler.SSLService = server.SSLService
@@ -233,6 +267,7 @@ func (server *Server) Start() error {
 
 	var stackHandler = stacks.NewHandler(requestBouncer)
 	stackHandler.DataStore = server.DataStore
+	stackHandler.AuthorizationService = server.AuthorizationService
 	stackHandler.DockerClientFactory = server.DockerClientFactory
 	stackHandler.FileService = server.FileService
 	stackHandler.KubernetesClientFactory = server.KubernetesClientFactory
@@ -242,6 +277,7 @@ func (server *Server) Start() error {
 	stackHandler.SwarmStackManager = server.SwarmStackManager
 	stackHandler.ComposeStackManager = server.ComposeStackManager
 	stackHandler.StackDeployer = server.StackDeployer
+	stackHandler.NotificationService = server.NotificationService
 
 	var storybookHandler = storybook.NewHandler(server.AssetsPath)
 
@@ -277,13 +313,16 @@ func (server *Server) Start() error {
 	websocketHandler.SignatureService = server.SignatureService
 	websocketHandler.ReverseTunnelService = server.ReverseTunnelService
 	websocketHandler.KubernetesClientFactory = server.KubernetesClientFactory
+	websocketHandler.AuthorizationService = server.AuthorizationService
 
 	var webhookHandler = webhooks.NewHandler(requestBouncer)
 	webhookHandler.DataStore = server.DataStore
 	webhookHandler.DockerClientFactory = server.DockerClientFactory
+	webhookHandler.StackDeployer = server.StackDeployer
 
 	server.Handler = &handler.Handler{
 		RoleHandler:            roleHandler,
+		AuditLogHandler:        auditLogHandler,
 		AuthHandler:            authHandler,
 		BackupHandler:          backupHandler,
 		CustomTemplatesHandler: customTemplatesHandler,
@@ -301,7 +340,9 @@ func (server *Server) Start() error {
 		LDAPHandler:            ldapHandler,
 		HelmTemplatesHandler:   helmTemplatesHandler,
 		KubernetesHandler:      kubernetesHandler,
+		MetricsHandler:         metricsHandler,
 		MOTDHandler:            motdHandler,
+		NotificationHandler:    notificationHandler,
 		OpenAMTHandler:         openAMTHandler,
 		FDOHandler:             fdoHandler,
 		RegistryHandler:        registryHandler,
@@ -321,7 +362,7 @@ func (server *Server) Start() error {
 		WebhookHandler:         webhookHandler,
 	}
 
-	handler := adminMonitor.WithRedirect(offlineGate.WaitingMiddleware(time.Minute, server.Handler))
+	handler := adminMonitor.WithRedirect(offlineGate.WaitingMiddleware(time.Minute, middlewares.WithMetrics(csrfProtection.Middleware(server.Handler))))
 	if server.HTTPEnabled {
 		go func() {
 			log.Info().Str("bind_address", server.BindAddress).Msg("starting HTTP server")
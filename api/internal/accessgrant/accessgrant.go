@@ -0,0 +1,103 @@
+package accessgrant
+
+import (
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
+
+	"github.com/pkg/errors"
+)
+
+// maxReasonLength is the maximum length of the reason of an access grant
+const maxReasonLength = 256
+
+// PreparePolicies completes and validates the access policies sent to replace the current access policies of an
+// environment(endpoint) or group. The policies sent without expiry for the same role keep the expiry and the reason
+// of the current policies, clients unaware of the access grants do not turn them into permanent access. The policies
+// sent as permanent clear the expiry of the current policies instead.
+// The new or changed policies cannot be expired already.
+func PreparePolicies(userPolicies, currentUserPolicies portainer.UserAccessPolicies, teamPolicies, currentTeamPolicies portainer.TeamAccessPolicies, t time.Time) error {
+	err := keepExpiry(userPolicies, currentUserPolicies)
+	if err != nil {
+		return errors.WithMessage(err, "invalid user access policy")
+	}
+
+	err = keepExpiry(teamPolicies, currentTeamPolicies)
+	if err != nil {
+		return errors.WithMessage(err, "invalid team access policy")
+	}
+
+	err = validate(userPolicies, currentUserPolicies, t)
+	if err != nil {
+		return errors.WithMessage(err, "invalid user access policy")
+	}
+
+	err = validate(teamPolicies, currentTeamPolicies, t)
+	if err != nil {
+		return errors.WithMessage(err, "invalid team access policy")
+	}
+
+	return nil
+}
+
+func keepExpiry[K comparable](policies, currentPolicies map[K]portainer.AccessPolicy) error {
+	for id, policy := range policies {
+		if policy.Permanent {
+			if policy.ExpiresAt != 0 {
+				return errors.Errorf("%v cannot be permanent and expire", id)
+			}
+
+			policy.Permanent = false
+			policies[id] = policy
+
+			continue
+		}
+
+		current, ok := currentPolicies[id]
+		if !ok || policy.ExpiresAt != 0 || policy.RoleID != current.RoleID {
+			continue
+		}
+
+		policy.ExpiresAt = current.ExpiresAt
+		if policy.Reason == "" {
+			policy.Reason = current.Reason
+		}
+
+		policies[id] = policy
+	}
+
+	return nil
+}
+
+func validate[K comparable](policies, currentPolicies map[K]portainer.AccessPolicy, t time.Time) error {
+	for id, policy := range policies {
+		if len(policy.Reason) > maxReasonLength {
+			return errors.Errorf("reason of %v cannot be longer than %d characters", id, maxReasonLength)
+		}
+
+		if current, ok := currentPolicies[id]; ok && current == policy {
+			continue
+		}
+
+		if policy.ExpiresAt < 0 || authorization.IsPolicyExpired(policy, t) {
+			return errors.Errorf("expiry of %v must be in the future", id)
+		}
+	}
+
+	return nil
+}
+
+// RemoveExpired removes the expired access policies and returns the identifiers of their users or teams
+func RemoveExpired[K comparable](policies map[K]portainer.AccessPolicy, t time.Time) []K {
+	var ids []K
+
+	for id, policy := range policies {
+		if authorization.IsPolicyExpired(policy, t) {
+			delete(policies, id)
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
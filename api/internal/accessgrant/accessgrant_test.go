@@ -0,0 +1,78 @@
+package accessgrant
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PreparePolicies(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Unix(1700000000, 0)
+	tomorrow := now.Add(24 * time.Hour).Unix()
+
+	current := portainer.UserAccessPolicies{
+		1: {RoleID: 1, ExpiresAt: tomorrow, Reason: "on-call"},
+		2: {RoleID: 1, ExpiresAt: now.Add(-time.Minute).Unix()},
+	}
+
+	t.Run("the policies sent without expiry keep the expiry of the current grants", func(t *testing.T) {
+		policies := portainer.UserAccessPolicies{1: {RoleID: 1}, 3: {RoleID: 1}}
+
+		is.NoError(PreparePolicies(policies, current, nil, nil, now))
+		is.Equal(portainer.AccessPolicy{RoleID: 1, ExpiresAt: tomorrow, Reason: "on-call"}, policies[1])
+		is.Equal(portainer.AccessPolicy{RoleID: 1}, policies[3])
+	})
+
+	t.Run("a grant of another role does not keep the expiry", func(t *testing.T) {
+		policies := portainer.UserAccessPolicies{1: {RoleID: 2}}
+
+		is.NoError(PreparePolicies(policies, current, nil, nil, now))
+		is.Equal(portainer.AccessPolicy{RoleID: 2}, policies[1])
+	})
+
+	t.Run("a grant sent as permanent clears the expiry", func(t *testing.T) {
+		policies := portainer.UserAccessPolicies{1: {RoleID: 1, Permanent: true}}
+
+		is.NoError(PreparePolicies(policies, current, nil, nil, now))
+		is.Equal(portainer.AccessPolicy{RoleID: 1}, policies[1])
+	})
+
+	t.Run("a grant cannot be permanent and expire", func(t *testing.T) {
+		err := PreparePolicies(portainer.UserAccessPolicies{1: {RoleID: 1, ExpiresAt: tomorrow, Permanent: true}}, current, nil, nil, now)
+		is.Error(err)
+	})
+
+	t.Run("an expired grant which is not revoked yet can be sent back", func(t *testing.T) {
+		policies := portainer.UserAccessPolicies{2: {RoleID: 1}}
+
+		is.NoError(PreparePolicies(policies, current, nil, nil, now))
+	})
+
+	t.Run("a new grant must expire in the future", func(t *testing.T) {
+		err := PreparePolicies(nil, nil, portainer.TeamAccessPolicies{1: {RoleID: 1, ExpiresAt: now.Unix()}}, nil, now)
+		is.Error(err)
+
+		err = PreparePolicies(portainer.UserAccessPolicies{1: {RoleID: 1, ExpiresAt: -1}}, nil, nil, nil, now)
+		is.Error(err)
+	})
+}
+
+func Test_RemoveExpired(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Unix(1700000000, 0)
+
+	policies := portainer.TeamAccessPolicies{
+		1: {RoleID: 1},
+		2: {RoleID: 1, ExpiresAt: now.Unix()},
+		3: {RoleID: 1, ExpiresAt: now.Add(time.Hour).Unix()},
+	}
+
+	is.Equal([]portainer.TeamID{2}, RemoveExpired(policies, now))
+	is.Len(policies, 2)
+	is.NotContains(policies, portainer.TeamID(2))
+}
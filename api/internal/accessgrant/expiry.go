@@ -0,0 +1,153 @@
+package accessgrant
+
+import (
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
+	"github.com/cloudogu/portainer-ce/api/internal/endpointutils"
+	"github.com/cloudogu/portainer-ce/api/scheduler"
+
+	"github.com/rs/zerolog/log"
+)
+
+// expiryCheckInterval is the interval between two revocations of the expired access grants
+const expiryCheckInterval = time.Minute
+
+// StartExpiryJob periodically revokes the expired access grants of the environments(endpoints) and groups
+func StartExpiryJob(scheduler *scheduler.Scheduler, dataStore dataservices.DataStore, authorizationService *authorization.Service) {
+	scheduler.StartJobEvery(expiryCheckInterval, func() error {
+		err := RevokeExpired(dataStore, authorizationService, time.Now())
+		if err != nil {
+			log.Warn().Err(err).Msg("unable to revoke the expired access grants")
+		}
+
+		return nil
+	})
+}
+
+// revocation lists the users and teams whose access grant expired
+type revocation struct {
+	userIDs []portainer.UserID
+	teamIDs []portainer.TeamID
+}
+
+func (r revocation) empty() bool {
+	return len(r.userIDs) == 0 && len(r.teamIDs) == 0
+}
+
+// RevokeExpired removes the access policies of the environments(endpoints) and groups expired at the time t.
+// The namespace access policies and the service accounts of the affected users are then updated on the
+// Kubernetes environments(endpoints).
+func RevokeExpired(dataStore dataservices.DataStore, authorizationService *authorization.Service, t time.Time) error {
+	endpointGroups, err := dataStore.EndpointGroup().EndpointGroups()
+	if err != nil {
+		return err
+	}
+
+	groupRevocations := make(map[portainer.EndpointGroupID]revocation)
+	for _, endpointGroup := range endpointGroups {
+		r := revocation{
+			userIDs: RemoveExpired(endpointGroup.UserAccessPolicies, t),
+			teamIDs: RemoveExpired(endpointGroup.TeamAccessPolicies, t),
+		}
+		if r.empty() {
+			continue
+		}
+
+		err = dataStore.EndpointGroup().UpdateEndpointGroup(endpointGroup.ID, &endpointGroup)
+		if err != nil {
+			return err
+		}
+
+		log.Info().Int("group_id", int(endpointGroup.ID)).Interface("user_ids", r.userIDs).Interface("team_ids", r.teamIDs).Msg("expired environment group access revoked")
+
+		groupRevocations[endpointGroup.ID] = r
+	}
+
+	endpoints, err := dataStore.Endpoint().Endpoints()
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+		r := revocation{
+			userIDs: RemoveExpired(endpoint.UserAccessPolicies, t),
+			teamIDs: RemoveExpired(endpoint.TeamAccessPolicies, t),
+		}
+
+		if !r.empty() {
+			err = dataStore.Endpoint().UpdateEndpoint(endpoint.ID, &endpoint)
+			if err != nil {
+				return err
+			}
+
+			log.Info().Int("endpoint_id", int(endpoint.ID)).Interface("user_ids", r.userIDs).Interface("team_ids", r.teamIDs).Msg("expired environment access revoked")
+		}
+
+		groupRevocation := groupRevocations[endpoint.GroupID]
+		r.userIDs = append(r.userIDs, groupRevocation.userIDs...)
+		r.teamIDs = append(r.teamIDs, groupRevocation.teamIDs...)
+
+		if r.empty() || !endpointutils.IsKubernetesEndpoint(&endpoint) {
+			continue
+		}
+
+		err = updateKubernetesAccess(dataStore, authorizationService, &endpoint, r)
+		if err != nil {
+			log.Warn().Err(err).Int("endpoint_id", int(endpoint.ID)).Msg("unable to update the Kubernetes access after revoking the expired access grants")
+		}
+	}
+
+	return nil
+}
+
+// updateKubernetesAccess removes the namespace access of the users and teams who lost their access to a Kubernetes
+// environment(endpoint), then updates the role bindings of the service accounts of the affected users
+func updateKubernetesAccess(dataStore dataservices.DataStore, authorizationService *authorization.Service, endpoint *portainer.Endpoint, r revocation) error {
+	err := authorizationService.CleanNAPWithOverridePolicies(endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	kubeClient, err := authorizationService.K8sClientFactory.GetKubeClient(endpoint)
+	if err != nil {
+		return err
+	}
+
+	userIDs := make(map[portainer.UserID]bool)
+	for _, userID := range r.userIDs {
+		userIDs[userID] = true
+	}
+
+	for _, teamID := range r.teamIDs {
+		memberships, err := dataStore.TeamMembership().TeamMembershipsByTeamID(teamID)
+		if err != nil {
+			return err
+		}
+
+		for _, membership := range memberships {
+			userIDs[membership.UserID] = true
+		}
+	}
+
+	for userID := range userIDs {
+		memberships, err := dataStore.TeamMembership().TeamMembershipsByUserID(userID)
+		if err != nil {
+			return err
+		}
+
+		teamIDs := make([]int, 0, len(memberships))
+		for _, membership := range memberships {
+			teamIDs = append(teamIDs, int(membership.TeamID))
+		}
+
+		err = kubeClient.SetupUserServiceAccount(int(userID), teamIDs, endpoint.Kubernetes.Configuration.RestrictDefaultNamespace)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
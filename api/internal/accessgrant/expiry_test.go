@@ -0,0 +1,54 @@
+package accessgrant_test
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/internal/accessgrant"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RevokeExpired(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	now := time.Now()
+	expired := now.Add(-time.Minute).Unix()
+	later := now.Add(time.Hour).Unix()
+
+	endpointGroup := &portainer.EndpointGroup{
+		ID:                 2,
+		Name:               "production",
+		UserAccessPolicies: portainer.UserAccessPolicies{1: {RoleID: 1, ExpiresAt: expired, Reason: "on-call"}},
+		TeamAccessPolicies: portainer.TeamAccessPolicies{1: {RoleID: 1}},
+	}
+	is.NoError(store.EndpointGroup().Create(endpointGroup))
+
+	endpoint := &portainer.Endpoint{
+		ID:                 1,
+		Name:               "local",
+		Type:               portainer.DockerEnvironment,
+		GroupID:            endpointGroup.ID,
+		UserAccessPolicies: portainer.UserAccessPolicies{2: {RoleID: 1, ExpiresAt: later}},
+		TeamAccessPolicies: portainer.TeamAccessPolicies{2: {RoleID: 1, ExpiresAt: expired}},
+	}
+	is.NoError(store.Endpoint().Create(endpoint))
+
+	err := accessgrant.RevokeExpired(store, authorization.NewService(store), now)
+	is.NoError(err)
+
+	endpointGroup, err = store.EndpointGroup().EndpointGroup(endpointGroup.ID)
+	is.NoError(err)
+	is.Empty(endpointGroup.UserAccessPolicies)
+	is.Equal(portainer.TeamAccessPolicies{1: {RoleID: 1}}, endpointGroup.TeamAccessPolicies)
+
+	endpoint, err = store.Endpoint().Endpoint(endpoint.ID)
+	is.NoError(err)
+	is.Equal(portainer.UserAccessPolicies{2: {RoleID: 1, ExpiresAt: later}}, endpoint.UserAccessPolicies)
+	is.Empty(endpoint.TeamAccessPolicies)
+}
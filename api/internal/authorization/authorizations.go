@@ -1,6 +1,8 @@
 package authorization
 
 import (
+	"time"
+
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/dataservices"
 	"github.com/cloudogu/portainer-ce/api/kubernetes/cli"
@@ -505,82 +507,96 @@ func getUserEndpointAuthorizations(user *portainer.User, endpoints []portainer.E
 	}
 
 	for _, endpoint := range endpoints {
-		authorizations := getAuthorizationsFromUserEndpointPolicy(user, &endpoint, roles)
-		if len(authorizations) > 0 {
-			endpointAuthorizations[endpoint.ID] = authorizations
-			continue
+		role := getUserEndpointRole(user, &endpoint, roles, userMemberships, groupUserAccessPolicies, groupTeamAccessPolicies)
+		if role != nil {
+			endpointAuthorizations[endpoint.ID] = role.Authorizations
 		}
+	}
 
-		authorizations = getAuthorizationsFromUserEndpointGroupPolicy(user, &endpoint, roles, groupUserAccessPolicies)
-		if len(authorizations) > 0 {
-			endpointAuthorizations[endpoint.ID] = authorizations
-			continue
-		}
+	return endpointAuthorizations
+}
 
-		authorizations = getAuthorizationsFromTeamEndpointPolicies(userMemberships, &endpoint, roles)
-		if len(authorizations) > 0 {
-			endpointAuthorizations[endpoint.ID] = authorizations
-			continue
-		}
+// getUserEndpointRole returns the role granted to a user on an environment(endpoint). The user policy of the
+// environment(endpoint) comes first, then the user policy of its group, then the team policies of the
+// environment(endpoint) and finally the team policies of its group. The expired policies which are not revoked yet
+// do not grant their role anymore.
+func getUserEndpointRole(user *portainer.User, endpoint *portainer.Endpoint, roles []portainer.Role, memberships []portainer.TeamMembership, groupUserAccessPolicies map[portainer.EndpointGroupID]portainer.UserAccessPolicies, groupTeamAccessPolicies map[portainer.EndpointGroupID]portainer.TeamAccessPolicies) *portainer.Role {
+	now := time.Now()
 
-		authorizations = getAuthorizationsFromTeamEndpointGroupPolicies(userMemberships, &endpoint, roles, groupTeamAccessPolicies)
-		if len(authorizations) > 0 {
-			endpointAuthorizations[endpoint.ID] = authorizations
-		}
+	role := getRoleFromUserEndpointPolicy(user, endpoint, roles, now)
+	if role != nil {
+		return role
 	}
 
-	return endpointAuthorizations
+	role = getRoleFromUserEndpointGroupPolicy(user, endpoint, roles, groupUserAccessPolicies, now)
+	if role != nil {
+		return role
+	}
+
+	role = getRoleFromTeamEndpointPolicies(memberships, endpoint, roles, now)
+	if role != nil {
+		return role
+	}
+
+	return getRoleFromTeamEndpointGroupPolicies(memberships, endpoint, roles, groupTeamAccessPolicies, now)
+}
+
+// IsPolicyExpired returns whether an access policy granted until a timestamp is expired at the time t
+func IsPolicyExpired(policy portainer.AccessPolicy, t time.Time) bool {
+	return policy.ExpiresAt != 0 && policy.ExpiresAt <= t.Unix()
 }
 
-func getAuthorizationsFromUserEndpointPolicy(user *portainer.User, endpoint *portainer.Endpoint, roles []portainer.Role) portainer.Authorizations {
+func getRoleFromUserEndpointPolicy(user *portainer.User, endpoint *portainer.Endpoint, roles []portainer.Role, t time.Time) *portainer.Role {
 	policyRoles := make([]portainer.RoleID, 0)
 
 	policy, ok := endpoint.UserAccessPolicies[user.ID]
-	if ok {
+	if ok && !IsPolicyExpired(policy, t) {
 		policyRoles = append(policyRoles, policy.RoleID)
 	}
 
-	return getAuthorizationsFromRoles(policyRoles, roles)
+	return getRoleFromRoles(policyRoles, roles)
 }
 
-func getAuthorizationsFromUserEndpointGroupPolicy(user *portainer.User, endpoint *portainer.Endpoint, roles []portainer.Role, groupAccessPolicies map[portainer.EndpointGroupID]portainer.UserAccessPolicies) portainer.Authorizations {
+func getRoleFromUserEndpointGroupPolicy(user *portainer.User, endpoint *portainer.Endpoint, roles []portainer.Role, groupAccessPolicies map[portainer.EndpointGroupID]portainer.UserAccessPolicies, t time.Time) *portainer.Role {
 	policyRoles := make([]portainer.RoleID, 0)
 
 	policy, ok := groupAccessPolicies[endpoint.GroupID][user.ID]
-	if ok {
+	if ok && !IsPolicyExpired(policy, t) {
 		policyRoles = append(policyRoles, policy.RoleID)
 	}
 
-	return getAuthorizationsFromRoles(policyRoles, roles)
+	return getRoleFromRoles(policyRoles, roles)
 }
 
-func getAuthorizationsFromTeamEndpointPolicies(memberships []portainer.TeamMembership, endpoint *portainer.Endpoint, roles []portainer.Role) portainer.Authorizations {
+func getRoleFromTeamEndpointPolicies(memberships []portainer.TeamMembership, endpoint *portainer.Endpoint, roles []portainer.Role, t time.Time) *portainer.Role {
 	policyRoles := make([]portainer.RoleID, 0)
 
 	for _, membership := range memberships {
 		policy, ok := endpoint.TeamAccessPolicies[membership.TeamID]
-		if ok {
+		if ok && !IsPolicyExpired(policy, t) {
 			policyRoles = append(policyRoles, policy.RoleID)
 		}
 	}
 
-	return getAuthorizationsFromRoles(policyRoles, roles)
+	return getRoleFromRoles(policyRoles, roles)
 }
 
-func getAuthorizationsFromTeamEndpointGroupPolicies(memberships []portainer.TeamMembership, endpoint *portainer.Endpoint, roles []portainer.Role, groupAccessPolicies map[portainer.EndpointGroupID]portainer.TeamAccessPolicies) portainer.Authorizations {
+func getRoleFromTeamEndpointGroupPolicies(memberships []portainer.TeamMembership, endpoint *portainer.Endpoint, roles []portainer.Role, groupAccessPolicies map[portainer.EndpointGroupID]portainer.TeamAccessPolicies, t time.Time) *portainer.Role {
 	policyRoles := make([]portainer.RoleID, 0)
 
 	for _, membership := range memberships {
 		policy, ok := groupAccessPolicies[endpoint.GroupID][membership.TeamID]
-		if ok {
+		if ok && !IsPolicyExpired(policy, t) {
 			policyRoles = append(policyRoles, policy.RoleID)
 		}
 	}
 
-	return getAuthorizationsFromRoles(policyRoles, roles)
+	return getRoleFromRoles(policyRoles, roles)
 }
 
-func getAuthorizationsFromRoles(roleIdentifiers []portainer.RoleID, roles []portainer.Role) portainer.Authorizations {
+// getRoleFromRoles returns the role with the highest priority among the identified roles,
+// nil when none of them exists or when it grants no authorization
+func getRoleFromRoles(roleIdentifiers []portainer.RoleID, roles []portainer.Role) *portainer.Role {
 	var associatedRoles []portainer.Role
 
 	for _, id := range roleIdentifiers {
@@ -592,16 +608,20 @@ func getAuthorizationsFromRoles(roleIdentifiers []portainer.RoleID, roles []port
 		}
 	}
 
-	var authorizations portainer.Authorizations
+	var highestRole *portainer.Role
 	highestPriority := 0
-	for _, role := range associatedRoles {
+	for i, role := range associatedRoles {
 		if role.Priority > highestPriority {
 			highestPriority = role.Priority
-			authorizations = role.Authorizations
+			highestRole = &associatedRoles[i]
 		}
 	}
 
-	return authorizations
+	if highestRole == nil || len(highestRole.Authorizations) == 0 {
+		return nil
+	}
+
+	return highestRole
 }
 
 func (service *Service) UserIsAdminOrAuthorized(userID portainer.UserID, endpointID portainer.EndpointID, authorizations []portainer.Authorization) (bool, error) {
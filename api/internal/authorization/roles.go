@@ -0,0 +1,161 @@
+package authorization
+
+import (
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateRoleAuthorizations checks the authorizations of a custom role. A custom role must grant at least one
+// authorization and can only grant the authorizations of the environment(endpoint) administrator role.
+func ValidateRoleAuthorizations(authorizations portainer.Authorizations) error {
+	if len(authorizations) == 0 {
+		return errors.New("invalid authorizations. must contain at least one authorization")
+	}
+
+	known := DefaultEndpointAuthorizationsForEndpointAdministratorRole()
+	for authorization, granted := range authorizations {
+		if !known[authorization] {
+			return errors.Errorf("invalid authorization %q. unknown environment authorization", authorization)
+		}
+		if !granted {
+			return errors.Errorf("invalid authorization %q. must be granted", authorization)
+		}
+	}
+
+	return nil
+}
+
+// UserEndpointCustomRole returns the custom role granted to a user on an environment(endpoint) by the access policies
+// of the environment(endpoint), of its group and of the teams of the user. It returns nil when the user is granted a
+// built-in role or no role at all. The policies are not looked up when no custom role exists.
+func (service *Service) UserEndpointCustomRole(userID portainer.UserID, endpoint *portainer.Endpoint) (*portainer.Role, error) {
+	roles, err := service.dataStore.Role().Roles()
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasCustomRole(roles) {
+		return nil, nil
+	}
+
+	user, err := service.dataStore.User().User(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := service.dataStore.TeamMembership().TeamMembershipsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	groupUserAccessPolicies := map[portainer.EndpointGroupID]portainer.UserAccessPolicies{}
+	groupTeamAccessPolicies := map[portainer.EndpointGroupID]portainer.TeamAccessPolicies{}
+
+	endpointGroup, err := service.dataStore.EndpointGroup().EndpointGroup(endpoint.GroupID)
+	if err != nil && !service.dataStore.IsErrObjectNotFound(err) {
+		return nil, err
+	}
+	if endpointGroup != nil {
+		groupUserAccessPolicies[endpointGroup.ID] = endpointGroup.UserAccessPolicies
+		groupTeamAccessPolicies[endpointGroup.ID] = endpointGroup.TeamAccessPolicies
+	}
+
+	role := getUserEndpointRole(user, endpoint, roles, memberships, groupUserAccessPolicies, groupTeamAccessPolicies)
+	if role == nil || !role.Custom {
+		return nil, nil
+	}
+
+	return role, nil
+}
+
+// AuthorizedCustomRoleOperation checks that the custom role granted to a user on an environment(endpoint) grants
+// the operation. It returns true when the user is not granted a custom role on the environment(endpoint).
+func (service *Service) AuthorizedCustomRoleOperation(userID portainer.UserID, endpoint *portainer.Endpoint, operation portainer.Authorization) (bool, error) {
+	role, err := service.UserEndpointCustomRole(userID, endpoint)
+	if err != nil {
+		return false, err
+	}
+
+	return role == nil || role.Authorizations[operation], nil
+}
+
+func hasCustomRole(roles []portainer.Role) bool {
+	for _, role := range roles {
+		if role.Custom {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidateAccessPolicyRoles checks that the access policies grant existing roles. A policy without role is valid.
+func (service *Service) ValidateAccessPolicyRoles(userPolicies portainer.UserAccessPolicies, teamPolicies portainer.TeamAccessPolicies) error {
+	roles, err := service.dataStore.Role().Roles()
+	if err != nil {
+		return err
+	}
+
+	return validatePolicyRoles(roles, userPolicies, teamPolicies, true)
+}
+
+// ValidateNamespaceAccessPolicyRoles checks that the namespace access policies of a Kubernetes environment(endpoint)
+// grant existing built-in roles. The authorizations of a custom role are not applied to the service accounts of the
+// users, custom roles cannot be granted on a namespace.
+func (service *Service) ValidateNamespaceAccessPolicyRoles(policies map[string]portainer.K8sNamespaceAccessPolicy) error {
+	roles, err := service.dataStore.Role().Roles()
+	if err != nil {
+		return err
+	}
+
+	for namespace, policy := range policies {
+		err := validatePolicyRoles(roles, policy.UserAccessPolicies, policy.TeamAccessPolicies, false)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid access policies of namespace %s", namespace)
+		}
+	}
+
+	return nil
+}
+
+func validatePolicyRoles(roles []portainer.Role, userPolicies portainer.UserAccessPolicies, teamPolicies portainer.TeamAccessPolicies, allowCustom bool) error {
+	custom := make(map[portainer.RoleID]bool, len(roles))
+	for _, role := range roles {
+		custom[role.ID] = role.Custom
+	}
+
+	for userID, policy := range userPolicies {
+		err := validatePolicyRole(custom, policy.RoleID, allowCustom)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid access policy of user %d", userID)
+		}
+	}
+
+	for teamID, policy := range teamPolicies {
+		err := validatePolicyRole(custom, policy.RoleID, allowCustom)
+		if err != nil {
+			return errors.WithMessagef(err, "invalid access policy of team %d", teamID)
+		}
+	}
+
+	return nil
+}
+
+// validatePolicyRole checks the role of a policy against the existing roles, mapped to whether they are custom roles
+func validatePolicyRole(custom map[portainer.RoleID]bool, roleID portainer.RoleID, allowCustom bool) error {
+	if roleID == 0 {
+		return nil
+	}
+
+	isCustom, exists := custom[roleID]
+	if !exists {
+		return errors.Errorf("role %d does not exist", roleID)
+	}
+
+	if isCustom && !allowCustom {
+		return errors.Errorf("role %d is a custom role", roleID)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package authorization_test
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/internal/authorization"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidateRoleAuthorizations(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(authorization.ValidateRoleAuthorizations(portainer.Authorizations{portainer.OperationDockerContainerLogs: true}))
+	is.Error(authorization.ValidateRoleAuthorizations(portainer.Authorizations{}))
+	is.Error(authorization.ValidateRoleAuthorizations(portainer.Authorizations{portainer.OperationDockerContainerLogs: false}))
+	is.Error(authorization.ValidateRoleAuthorizations(portainer.Authorizations{portainer.OperationPortainerSettingsUpdate: true}))
+	is.Error(authorization.ValidateRoleAuthorizations(portainer.Authorizations{"DockerContainerTeleport": true}))
+}
+
+func Test_UserEndpointCustomRole(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	service := authorization.NewService(store)
+
+	logsRole := &portainer.Role{Name: "Logs", Priority: 1, Custom: true, Authorizations: portainer.Authorizations{portainer.OperationDockerContainerLogs: true}}
+	restartRole := &portainer.Role{Name: "Restart", Priority: 2, Custom: true, Authorizations: portainer.Authorizations{portainer.OperationDockerContainerRestart: true}}
+	readOnlyRole := &portainer.Role{Name: "Read-only user", Priority: 4, Authorizations: portainer.Authorizations{portainer.OperationDockerContainerList: true}}
+	for _, role := range []*portainer.Role{logsRole, restartRole, readOnlyRole} {
+		is.NoError(store.Role().Create(role))
+	}
+
+	for _, user := range []*portainer.User{{ID: 1, Username: "alice"}, {ID: 2, Username: "bob"}, {ID: 3, Username: "carol"}, {ID: 4, Username: "dave"}} {
+		is.NoError(store.User().Create(user))
+	}
+
+	for _, membership := range []*portainer.TeamMembership{{UserID: 2, TeamID: 1}, {UserID: 2, TeamID: 2}} {
+		is.NoError(store.TeamMembership().Create(membership))
+	}
+
+	group := &portainer.EndpointGroup{
+		ID:                 2,
+		Name:               "production",
+		UserAccessPolicies: portainer.UserAccessPolicies{1: {RoleID: restartRole.ID}},
+		TeamAccessPolicies: portainer.TeamAccessPolicies{},
+	}
+	is.NoError(store.EndpointGroup().Create(group))
+
+	endpoint := &portainer.Endpoint{
+		ID:                 1,
+		GroupID:            group.ID,
+		UserAccessPolicies: portainer.UserAccessPolicies{1: {RoleID: logsRole.ID}, 4: {RoleID: readOnlyRole.ID}},
+		TeamAccessPolicies: portainer.TeamAccessPolicies{1: {RoleID: logsRole.ID}, 2: {RoleID: restartRole.ID}},
+	}
+	is.NoError(store.Endpoint().Create(endpoint))
+
+	// the user policy of the environment comes before the policy of its group
+	role, err := service.UserEndpointCustomRole(1, endpoint)
+	is.NoError(err)
+	is.Equal(logsRole.ID, role.ID)
+
+	// the role with the highest priority wins among the team policies
+	role, err = service.UserEndpointCustomRole(2, endpoint)
+	is.NoError(err)
+	is.Equal(restartRole.ID, role.ID)
+
+	role, err = service.UserEndpointCustomRole(3, endpoint)
+	is.NoError(err)
+	is.Nil(role)
+
+	// a built-in role is not a custom role
+	role, err = service.UserEndpointCustomRole(4, endpoint)
+	is.NoError(err)
+	is.Nil(role)
+
+	authorized, err := service.AuthorizedCustomRoleOperation(1, endpoint, portainer.OperationDockerContainerLogs)
+	is.NoError(err)
+	is.True(authorized)
+
+	authorized, err = service.AuthorizedCustomRoleOperation(1, endpoint, portainer.OperationDockerContainerRestart)
+	is.NoError(err)
+	is.False(authorized)
+
+	authorized, err = service.AuthorizedCustomRoleOperation(4, endpoint, portainer.OperationDockerContainerRestart)
+	is.NoError(err)
+	is.True(authorized)
+
+	// an expired policy which is not revoked yet does not grant its role anymore
+	expiredEndpoint := &portainer.Endpoint{
+		ID:                 2,
+		GroupID:            group.ID,
+		UserAccessPolicies: portainer.UserAccessPolicies{1: {RoleID: logsRole.ID, ExpiresAt: time.Now().Add(-time.Minute).Unix()}},
+		TeamAccessPolicies: portainer.TeamAccessPolicies{},
+	}
+	is.NoError(store.Endpoint().Create(expiredEndpoint))
+
+	role, err = service.UserEndpointCustomRole(1, expiredEndpoint)
+	is.NoError(err)
+	is.Equal(restartRole.ID, role.ID)
+
+	is.NoError(service.ValidateAccessPolicyRoles(endpoint.UserAccessPolicies, endpoint.TeamAccessPolicies))
+	is.Error(service.ValidateAccessPolicyRoles(portainer.UserAccessPolicies{3: {RoleID: 42}}, nil))
+
+	// custom roles cannot be granted on a namespace
+	is.NoError(service.ValidateNamespaceAccessPolicyRoles(map[string]portainer.K8sNamespaceAccessPolicy{
+		"default": {UserAccessPolicies: portainer.UserAccessPolicies{1: {RoleID: readOnlyRole.ID}}},
+	}))
+	is.Error(service.ValidateNamespaceAccessPolicyRoles(map[string]portainer.K8sNamespaceAccessPolicy{
+		"default": {TeamAccessPolicies: portainer.TeamAccessPolicies{1: {RoleID: logsRole.ID}}},
+	}))
+}
@@ -0,0 +1,169 @@
+package passwordpolicy
+
+import (
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MaxPasswordHistorySize is the highest number of passwords which can be remembered to prevent their reuse
+	MaxPasswordHistorySize = 24
+	// LastAdministratorLockoutDuration is the duration the last administrator whose account is not locked is locked for,
+	// when the locked accounts stay locked until they are unlocked. It prevents locking out every administrator.
+	LastAdministratorLockoutDuration = 15 * time.Minute
+)
+
+var (
+	// ErrAccountLocked is returned when a user whose account is locked tries to log in
+	ErrAccountLocked = errors.New("the account is locked after too many failed logins, contact an administrator")
+	// ErrPasswordReused is returned when a user sets one of their last passwords again
+	ErrPasswordReused = errors.New("the password was used recently and cannot be reused")
+)
+
+// ValidateSettings checks the password policy of the internal authentication
+func ValidateSettings(settings *portainer.InternalAuthSettings) error {
+	if settings.RequiredPasswordLength < 0 {
+		return errors.New("invalid required password length. must not be negative")
+	}
+	if settings.PasswordHistorySize < 0 || settings.PasswordHistorySize > MaxPasswordHistorySize {
+		return errors.Errorf("invalid password history size. must be between 0 and %d", MaxPasswordHistorySize)
+	}
+	if settings.MaxPasswordAgeDays < 0 {
+		return errors.New("invalid maximum password age. must not be negative")
+	}
+	if settings.MaxFailedLoginAttempts < 0 {
+		return errors.New("invalid maximum number of failed logins. must not be negative")
+	}
+	if settings.LockoutDurationMinutes < 0 {
+		return errors.New("invalid lockout duration. must not be negative")
+	}
+	return nil
+}
+
+// IsLocked returns whether the account of a user is locked at the time t. The account of the last administrator,
+// see IsLastAdministrator, is only locked for LastAdministratorLockoutDuration when the lockout has no duration.
+func IsLocked(user *portainer.User, settings *portainer.InternalAuthSettings, t time.Time, lastAdministrator bool) bool {
+	if user.LockedAt == 0 {
+		return false
+	}
+
+	duration := time.Duration(settings.LockoutDurationMinutes) * time.Minute
+	if duration == 0 {
+		if !lastAdministrator {
+			return true
+		}
+
+		duration = LastAdministratorLockoutDuration
+	}
+
+	return t.Before(time.Unix(user.LockedAt, 0).Add(duration))
+}
+
+// IsLastAdministrator returns whether a user is an administrator and the accounts of the other administrators are
+// locked at the time t
+func IsLastAdministrator(user *portainer.User, administrators []portainer.User, settings *portainer.InternalAuthSettings, t time.Time) bool {
+	if user.Role != portainer.AdministratorRole {
+		return false
+	}
+
+	for i := range administrators {
+		if administrators[i].ID != user.ID && !IsLocked(&administrators[i], settings, t, false) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RecordFailedLogin counts a failed login of a user and locks their account once they reach the maximum number of
+// failed logins. It returns whether the user changed and must be persisted.
+func RecordFailedLogin(user *portainer.User, settings *portainer.InternalAuthSettings, t time.Time, lastAdministrator bool) bool {
+	if settings.MaxFailedLoginAttempts == 0 {
+		return false
+	}
+
+	// the count restarts once a temporary lockout is over
+	if user.LockedAt != 0 && !IsLocked(user, settings, t, lastAdministrator) {
+		Unlock(user)
+	}
+
+	user.FailedLoginAttempts++
+	if user.FailedLoginAttempts >= settings.MaxFailedLoginAttempts && user.LockedAt == 0 {
+		user.LockedAt = t.Unix()
+	}
+
+	return true
+}
+
+// RecordSuccessfulLogin resets the failed logins of a user. It returns whether the user changed and must be persisted.
+func RecordSuccessfulLogin(user *portainer.User) bool {
+	if user.FailedLoginAttempts == 0 && user.LockedAt == 0 {
+		return false
+	}
+
+	Unlock(user)
+
+	return true
+}
+
+// Unlock unlocks the account of a user and resets their failed logins
+func Unlock(user *portainer.User) {
+	user.FailedLoginAttempts = 0
+	user.LockedAt = 0
+}
+
+// IsPasswordExpired returns whether the password of a user is older than the maximum password age at the time t.
+// The age of the passwords set before their change date was recorded is unknown, they do not expire.
+func IsPasswordExpired(user *portainer.User, settings *portainer.InternalAuthSettings, t time.Time) bool {
+	if settings.MaxPasswordAgeDays == 0 || user.PasswordChangedAt == 0 {
+		return false
+	}
+
+	maxAge := time.Duration(settings.MaxPasswordAgeDays) * 24 * time.Hour
+
+	return !t.Before(time.Unix(user.PasswordChangedAt, 0).Add(maxAge))
+}
+
+// CheckReuse returns ErrPasswordReused when the password is one of the last passwords of a user
+func CheckReuse(cryptoService portainer.CryptoService, user *portainer.User, password string, settings *portainer.InternalAuthSettings) error {
+	if settings.PasswordHistorySize == 0 {
+		return nil
+	}
+
+	hashes := append([]string{user.Password}, user.PasswordHistory...)
+	if len(hashes) > settings.PasswordHistorySize {
+		hashes = hashes[:settings.PasswordHistorySize]
+	}
+
+	for _, hash := range hashes {
+		if hash != "" && cryptoService.CompareHashAndData(hash, password) == nil {
+			return ErrPasswordReused
+		}
+	}
+
+	return nil
+}
+
+// SetPassword replaces the password hash of a user and keeps the previous one in their password history
+func SetPassword(user *portainer.User, hash string, settings *portainer.InternalAuthSettings, t time.Time) {
+	history := user.PasswordHistory
+	if user.Password != "" {
+		history = append([]string{user.Password}, history...)
+	}
+
+	// the current password counts in the history size
+	size := settings.PasswordHistorySize - 1
+	if size < 0 {
+		size = 0
+	}
+	if len(history) > size {
+		history = history[:size]
+	}
+
+	user.Password = hash
+	user.PasswordHistory = history
+	user.PasswordChangedAt = t.Unix()
+}
@@ -0,0 +1,137 @@
+package passwordpolicy
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Lockout(t *testing.T) {
+	is := assert.New(t)
+
+	settings := &portainer.InternalAuthSettings{MaxFailedLoginAttempts: 3}
+	user := &portainer.User{ID: 2, Username: "bob"}
+	now := time.Now()
+
+	is.False(RecordFailedLogin(user, &portainer.InternalAuthSettings{}, now, false), "failed logins are not counted when the lockout is disabled")
+
+	for i := 0; i < 2; i++ {
+		is.True(RecordFailedLogin(user, settings, now, false))
+		is.False(IsLocked(user, settings, now, false))
+	}
+
+	is.True(RecordFailedLogin(user, settings, now, false))
+	is.True(IsLocked(user, settings, now, false))
+	is.True(IsLocked(user, settings, now.Add(24*time.Hour), false), "the account stays locked until it is unlocked")
+
+	Unlock(user)
+	is.False(IsLocked(user, settings, now, false))
+	is.Zero(user.FailedLoginAttempts)
+
+	is.True(RecordFailedLogin(user, settings, now, false))
+	is.True(RecordSuccessfulLogin(user))
+	is.Zero(user.FailedLoginAttempts)
+	is.False(RecordSuccessfulLogin(user))
+}
+
+func Test_TemporaryLockout(t *testing.T) {
+	is := assert.New(t)
+
+	settings := &portainer.InternalAuthSettings{MaxFailedLoginAttempts: 2, LockoutDurationMinutes: 15}
+	user := &portainer.User{ID: 2, Username: "bob"}
+	now := time.Now()
+
+	RecordFailedLogin(user, settings, now, false)
+	RecordFailedLogin(user, settings, now, false)
+	is.True(IsLocked(user, settings, now.Add(14*time.Minute), false))
+	is.False(IsLocked(user, settings, now.Add(15*time.Minute), false))
+
+	RecordFailedLogin(user, settings, now.Add(20*time.Minute), false)
+	is.Equal(1, user.FailedLoginAttempts, "the count restarts once the lockout is over")
+	is.False(IsLocked(user, settings, now.Add(20*time.Minute), false))
+}
+
+func Test_LastAdministratorLockout(t *testing.T) {
+	is := assert.New(t)
+
+	settings := &portainer.InternalAuthSettings{MaxFailedLoginAttempts: 1}
+	admin := &portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole}
+	other := portainer.User{ID: 3, Username: "other", Role: portainer.AdministratorRole}
+	now := time.Now()
+
+	administrators := []portainer.User{*admin, other}
+	is.False(IsLastAdministrator(admin, administrators, settings, now))
+	is.False(IsLastAdministrator(&portainer.User{ID: 2, Role: portainer.StandardUserRole}, nil, settings, now))
+
+	RecordFailedLogin(&other, settings, now, false)
+	administrators = []portainer.User{*admin, other}
+	is.True(IsLastAdministrator(admin, administrators, settings, now))
+
+	RecordFailedLogin(admin, settings, now, true)
+	is.True(IsLocked(admin, settings, now, true))
+	is.False(IsLocked(admin, settings, now.Add(LastAdministratorLockoutDuration), true), "the last administrator is only locked temporarily")
+	is.True(IsLocked(&other, settings, now.Add(24*time.Hour), false))
+}
+
+func Test_PasswordExpiry(t *testing.T) {
+	is := assert.New(t)
+
+	settings := &portainer.InternalAuthSettings{MaxPasswordAgeDays: 90}
+	now := time.Now()
+
+	is.False(IsPasswordExpired(&portainer.User{}, settings, now), "the age of a password without change date is unknown")
+
+	user := &portainer.User{PasswordChangedAt: now.Add(-89 * 24 * time.Hour).Unix()}
+	is.False(IsPasswordExpired(user, settings, now))
+	is.True(IsPasswordExpired(user, settings, now.Add(24*time.Hour)))
+	is.False(IsPasswordExpired(user, &portainer.InternalAuthSettings{}, now.Add(24*time.Hour)))
+}
+
+func Test_PasswordHistory(t *testing.T) {
+	is := assert.New(t)
+
+	cryptoService := &crypto.Service{}
+	settings := &portainer.InternalAuthSettings{PasswordHistorySize: 3}
+	user := &portainer.User{ID: 2, Username: "bob"}
+	now := time.Now()
+
+	setPassword := func(password string) {
+		is.NoError(CheckReuse(cryptoService, user, password, settings))
+
+		hash, err := cryptoService.Hash(password)
+		is.NoError(err)
+
+		SetPassword(user, hash, settings, now)
+	}
+
+	setPassword("password-1")
+	is.Empty(user.PasswordHistory)
+	is.Equal(now.Unix(), user.PasswordChangedAt)
+
+	setPassword("password-2")
+	setPassword("password-3")
+	is.Len(user.PasswordHistory, 2)
+
+	is.ErrorIs(CheckReuse(cryptoService, user, "password-3", settings), ErrPasswordReused)
+	is.ErrorIs(CheckReuse(cryptoService, user, "password-1", settings), ErrPasswordReused)
+
+	setPassword("password-4")
+	is.Len(user.PasswordHistory, 2, "the history is limited to the history size")
+	is.NoError(CheckReuse(cryptoService, user, "password-1", settings))
+
+	is.NoError(CheckReuse(cryptoService, user, "password-4", &portainer.InternalAuthSettings{}), "the reuse is allowed when the history is disabled")
+}
+
+func Test_ValidateSettings(t *testing.T) {
+	is := assert.New(t)
+
+	is.NoError(ValidateSettings(&portainer.InternalAuthSettings{RequiredPasswordLength: 12, PasswordHistorySize: MaxPasswordHistorySize, MaxFailedLoginAttempts: 5}))
+	is.Error(ValidateSettings(&portainer.InternalAuthSettings{PasswordHistorySize: MaxPasswordHistorySize + 1}))
+	is.Error(ValidateSettings(&portainer.InternalAuthSettings{MaxPasswordAgeDays: -1}))
+	is.Error(ValidateSettings(&portainer.InternalAuthSettings{MaxFailedLoginAttempts: -1}))
+	is.Error(ValidateSettings(&portainer.InternalAuthSettings{LockoutDurationMinutes: -1}))
+}
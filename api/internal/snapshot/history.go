@@ -0,0 +1,82 @@
+package snapshot
+
+import (
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HistoryRetention is the duration the hourly rollups of the snapshots are kept for
+const HistoryRetention = 30 * 24 * time.Hour
+
+func (service *Service) recordHistory(snapshot *portainer.Snapshot) {
+	err := RecordHistory(service.dataStore, snapshot, time.Now())
+	if err != nil {
+		log.Warn().Err(err).Int("endpoint_id", int(snapshot.EndpointID)).Msg("unable to record the snapshot history")
+	}
+}
+
+// RecordHistory aggregates the snapshot into the rollup of the hour it was taken.
+// The rollups older than the retention are removed whenever a new hour starts.
+func RecordHistory(dataStore dataservices.DataStore, snapshot *portainer.Snapshot, takenAt time.Time) error {
+	hour := takenAt.Truncate(time.Hour).Unix()
+
+	rollup, err := dataStore.SnapshotHistory().Rollup(snapshot.EndpointID, hour)
+	isNewHour := dataStore.IsErrObjectNotFound(err)
+	if isNewHour {
+		rollup = &portainer.SnapshotRollup{EndpointID: snapshot.EndpointID, Time: hour}
+	} else if err != nil {
+		return err
+	}
+
+	aggregateSnapshot(rollup, snapshot)
+
+	err = dataStore.SnapshotHistory().UpdateRollup(rollup)
+	if err != nil {
+		return err
+	}
+
+	if isNewHour {
+		return dataStore.SnapshotHistory().DeleteRollupsBefore(snapshot.EndpointID, takenAt.Add(-HistoryRetention).Unix())
+	}
+
+	return nil
+}
+
+func aggregateSnapshot(rollup *portainer.SnapshotRollup, snapshot *portainer.Snapshot) {
+	rollup.SampleCount++
+
+	if docker := snapshot.Docker; docker != nil {
+		running := docker.RunningContainerCount
+
+		if rollup.SampleCount == 1 || running < rollup.MinRunningContainerCount {
+			rollup.MinRunningContainerCount = running
+		}
+
+		if running > rollup.MaxRunningContainerCount {
+			rollup.MaxRunningContainerCount = running
+		}
+
+		rollup.ContainerCount = docker.RunningContainerCount + docker.StoppedContainerCount
+		rollup.RunningContainerCount = docker.RunningContainerCount
+		rollup.StoppedContainerCount = docker.StoppedContainerCount
+		rollup.HealthyContainerCount = docker.HealthyContainerCount
+		rollup.UnhealthyContainerCount = docker.UnhealthyContainerCount
+		rollup.ImageCount = docker.ImageCount
+		rollup.VolumeCount = docker.VolumeCount
+		rollup.ServiceCount = docker.ServiceCount
+		rollup.StackCount = docker.StackCount
+		rollup.NodeCount = docker.NodeCount
+		rollup.TotalCPU = int64(docker.TotalCPU)
+		rollup.TotalMemory = docker.TotalMemory
+	}
+
+	if kubernetes := snapshot.Kubernetes; kubernetes != nil {
+		rollup.NodeCount = kubernetes.NodeCount
+		rollup.TotalCPU = kubernetes.TotalCPU
+		rollup.TotalMemory = kubernetes.TotalMemory
+	}
+}
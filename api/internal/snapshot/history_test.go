@@ -0,0 +1,71 @@
+package snapshot_test
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/internal/snapshot"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecordHistory(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, false)
+	defer teardown()
+
+	const endpointID = portainer.EndpointID(1)
+	now := time.Date(2023, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	record := func(takenAt time.Time, running, stopped int) {
+		s := &portainer.Snapshot{
+			EndpointID: endpointID,
+			Docker: &portainer.DockerSnapshot{
+				RunningContainerCount: running,
+				StoppedContainerCount: stopped,
+				ImageCount:            5,
+				TotalCPU:              4,
+				TotalMemory:           1024,
+			},
+		}
+
+		is.NoError(snapshot.RecordHistory(store, s, takenAt))
+	}
+
+	// expired rollup, removed when a new hour starts
+	record(now.Add(-snapshot.HistoryRetention-time.Hour), 1, 0)
+
+	record(now.Add(5*time.Minute), 3, 1)
+	record(now.Add(10*time.Minute), 1, 3)
+	record(now.Add(15*time.Minute), 2, 2)
+	record(now.Add(time.Hour), 4, 0)
+
+	rollups, err := store.SnapshotHistory().Rollups(endpointID, 0, 0)
+	is.NoError(err)
+	is.Len(rollups, 2)
+
+	first := rollups[0]
+	is.Equal(now.Unix(), first.Time)
+	is.Equal(3, first.SampleCount)
+	is.Equal(1, first.MinRunningContainerCount)
+	is.Equal(3, first.MaxRunningContainerCount)
+	is.Equal(2, first.RunningContainerCount)
+	is.Equal(4, first.ContainerCount)
+	is.Equal(5, first.ImageCount)
+	is.Equal(int64(4), first.TotalCPU)
+	is.Equal(int64(1024), first.TotalMemory)
+
+	is.Equal(now.Add(time.Hour).Unix(), rollups[1].Time)
+
+	rollups, err = store.SnapshotHistory().Rollups(endpointID, now.Add(30*time.Minute).Unix(), 0)
+	is.NoError(err)
+	is.Len(rollups, 1)
+
+	is.NoError(store.SnapshotHistory().DeleteRollups(endpointID))
+	rollups, err = store.SnapshotHistory().Rollups(endpointID, 0, 0)
+	is.NoError(err)
+	is.Empty(rollups)
+}
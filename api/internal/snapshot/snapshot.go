@@ -4,16 +4,32 @@ import (
 	"context"
 	"crypto/tls"
 	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/agent"
 	"github.com/cloudogu/portainer-ce/api/crypto"
 	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/internal/endpointutils"
+	"github.com/cloudogu/portainer-ce/api/metrics"
 
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	// DefaultConcurrency is the number of environments(endpoints) snapshotted at the same time
+	DefaultConcurrency = 10
+	// DefaultTimeout is the duration after which the snapshot of an environment(endpoint) is considered failed
+	DefaultTimeout = time.Minute
+	// maxJitterRatio is the maximum delay of the start of a background snapshot cycle, relative to the snapshot interval
+	maxJitterRatio = 0.1
+)
+
 // Service repesents a service to manage environment(endpoint) snapshots.
 // It provides an interface to start background snapshots as well as
 // specific Docker/Kubernetes environment(endpoint) snapshot methods.
@@ -24,10 +40,16 @@ type Service struct {
 	dockerSnapshotter         portainer.DockerSnapshotter
 	kubernetesSnapshotter     portainer.KubernetesSnapshotter
 	shutdownCtx               context.Context
+	notificationService       portainer.NotificationService
+	concurrency               int
+	timeout                   time.Duration
+	cycleRunning              atomic.Bool
+	inFlightMu                sync.Mutex
+	inFlight                  map[portainer.EndpointID]bool
 }
 
 // NewService creates a new instance of a service
-func NewService(snapshotIntervalFromFlag string, dataStore dataservices.DataStore, dockerSnapshotter portainer.DockerSnapshotter, kubernetesSnapshotter portainer.KubernetesSnapshotter, shutdownCtx context.Context) (*Service, error) {
+func NewService(snapshotIntervalFromFlag string, dataStore dataservices.DataStore, dockerSnapshotter portainer.DockerSnapshotter, kubernetesSnapshotter portainer.KubernetesSnapshotter, shutdownCtx context.Context, notificationService portainer.NotificationService) (*Service, error) {
 	interval, err := parseSnapshotFrequency(snapshotIntervalFromFlag, dataStore)
 	if err != nil {
 		return nil, err
@@ -40,9 +62,25 @@ func NewService(snapshotIntervalFromFlag string, dataStore dataservices.DataStor
 		dockerSnapshotter:         dockerSnapshotter,
 		kubernetesSnapshotter:     kubernetesSnapshotter,
 		shutdownCtx:               shutdownCtx,
+		notificationService:       notificationService,
+		concurrency:               DefaultConcurrency,
+		timeout:                   DefaultTimeout,
+		inFlight:                  make(map[portainer.EndpointID]bool),
 	}, nil
 }
 
+// SetConcurrency sets the number of environments(endpoints) snapshotted at the same time by the background snapshots,
+// and the duration after which the snapshot of an environment(endpoint) is considered failed
+func (service *Service) SetConcurrency(concurrency int, timeout time.Duration) {
+	if concurrency > 0 {
+		service.concurrency = concurrency
+	}
+
+	if timeout > 0 {
+		service.timeout = timeout
+	}
+}
+
 func parseSnapshotFrequency(snapshotInterval string, dataStore dataservices.DataStore) (float64, error) {
 	if snapshotInterval == "" {
 		settings, err := dataStore.Settings().Settings()
@@ -91,6 +129,30 @@ func SupportDirectSnapshot(endpoint *portainer.Endpoint) bool {
 // SnapshotEndpoint will create a snapshot of the environment(endpoint) based on the environment(endpoint) type.
 // If the snapshot is a success, it will be associated to the environment(endpoint).
 func (service *Service) SnapshotEndpoint(endpoint *portainer.Endpoint) error {
+	return service.snapshotEndpoint(context.Background(), endpoint)
+}
+
+// snapshotEndpoint creates the snapshot of an environment(endpoint), the calls to the environment(endpoint)
+// are cancelled with the given context
+func (service *Service) snapshotEndpoint(ctx context.Context, endpoint *portainer.Endpoint) (err error) {
+	if endpoint.Type == portainer.AzureEnvironment {
+		return nil
+	}
+
+	platform := "docker"
+	if endpointutils.IsKubernetesEndpoint(endpoint) {
+		platform = "kubernetes"
+	}
+
+	defer func(start time.Time) {
+		metrics.ObserveDuration(metrics.SnapshotDuration, start, platform)
+		if err != nil {
+			metrics.SnapshotFailures.WithLabelValues(platform).Inc()
+		}
+
+		endpoint.SnapshotStatus = newSnapshotStatus(start, err)
+	}(time.Now())
+
 	if endpoint.Type == portainer.AgentOnDockerEnvironment || endpoint.Type == portainer.AgentOnKubernetesEnvironment {
 		var err error
 		var tlsConfig *tls.Config
@@ -101,7 +163,7 @@ func (service *Service) SnapshotEndpoint(endpoint *portainer.Endpoint) error {
 			}
 		}
 
-		_, version, err := agent.GetAgentVersionAndPlatform(endpoint.URL, tlsConfig)
+		_, version, err := agent.GetAgentVersionAndPlatform(ctx, endpoint.URL, tlsConfig)
 		if err != nil {
 			return err
 		}
@@ -109,18 +171,22 @@ func (service *Service) SnapshotEndpoint(endpoint *portainer.Endpoint) error {
 		endpoint.Agent.Version = version
 	}
 
-	switch endpoint.Type {
-	case portainer.AzureEnvironment:
-		return nil
-	case portainer.KubernetesLocalEnvironment, portainer.AgentOnKubernetesEnvironment, portainer.EdgeAgentOnKubernetesEnvironment:
-		return service.snapshotKubernetesEndpoint(endpoint)
+	if platform == "kubernetes" {
+		return service.snapshotKubernetesEndpoint(ctx, endpoint)
 	}
 
-	return service.snapshotDockerEndpoint(endpoint)
+	return service.snapshotDockerEndpoint(ctx, endpoint)
 }
 
 func (service *Service) Create(snapshot portainer.Snapshot) error {
-	return service.dataStore.Snapshot().Create(&snapshot)
+	err := service.dataStore.Snapshot().Create(&snapshot)
+	if err != nil {
+		return err
+	}
+
+	service.recordHistory(&snapshot)
+
+	return nil
 }
 
 func (service *Service) FillSnapshotData(endpoint *portainer.Endpoint) error {
@@ -151,8 +217,8 @@ func FillSnapshotData(dataStore dataservices.DataStore, endpoint *portainer.Endp
 	return nil
 }
 
-func (service *Service) snapshotKubernetesEndpoint(endpoint *portainer.Endpoint) error {
-	kubernetesSnapshot, err := service.kubernetesSnapshotter.CreateSnapshot(endpoint)
+func (service *Service) snapshotKubernetesEndpoint(ctx context.Context, endpoint *portainer.Endpoint) error {
+	kubernetesSnapshot, err := service.kubernetesSnapshotter.CreateSnapshot(ctx, endpoint)
 	if err != nil {
 		return err
 	}
@@ -160,14 +226,19 @@ func (service *Service) snapshotKubernetesEndpoint(endpoint *portainer.Endpoint)
 	if kubernetesSnapshot != nil {
 		snapshot := &portainer.Snapshot{EndpointID: endpoint.ID, Kubernetes: kubernetesSnapshot}
 
-		return service.dataStore.Snapshot().Create(snapshot)
+		err = service.dataStore.Snapshot().Create(snapshot)
+		if err != nil {
+			return err
+		}
+
+		service.recordHistory(snapshot)
 	}
 
 	return nil
 }
 
-func (service *Service) snapshotDockerEndpoint(endpoint *portainer.Endpoint) error {
-	dockerSnapshot, err := service.dockerSnapshotter.CreateSnapshot(endpoint)
+func (service *Service) snapshotDockerEndpoint(ctx context.Context, endpoint *portainer.Endpoint) error {
+	dockerSnapshot, err := service.dockerSnapshotter.CreateSnapshot(ctx, endpoint)
 	if err != nil {
 		return err
 	}
@@ -175,43 +246,97 @@ func (service *Service) snapshotDockerEndpoint(endpoint *portainer.Endpoint) err
 	if dockerSnapshot != nil {
 		snapshot := &portainer.Snapshot{EndpointID: endpoint.ID, Docker: dockerSnapshot}
 
-		return service.dataStore.Snapshot().Create(snapshot)
+		err = service.dataStore.Snapshot().Create(snapshot)
+		if err != nil {
+			return err
+		}
+
+		service.recordHistory(snapshot)
 	}
 
 	return nil
 }
 
 func (service *Service) startSnapshotLoop() {
-	ticker := time.NewTicker(time.Duration(service.snapshotIntervalInSeconds) * time.Second)
+	ticker := time.NewTicker(time.Duration(service.snapshotIntervalInSeconds * float64(time.Second)))
 
-	err := service.snapshotEndpoints()
-	if err != nil {
-		log.Error().Err(err).Msg("background schedule error (environment snapshot)")
-	}
+	service.startSnapshotCycle(0)
 
 	for {
 		select {
 		case <-ticker.C:
-			err := service.snapshotEndpoints()
-			if err != nil {
-				log.Error().Err(err).Msg("background schedule error (environment snapshot)")
-			}
+			service.startSnapshotCycle(service.snapshotJitter())
 		case <-service.shutdownCtx.Done():
 			log.Debug().Msg("shutting down snapshotting")
 			ticker.Stop()
 			return
 		case interval := <-service.snapshotIntervalCh:
+			service.snapshotIntervalInSeconds = interval.Seconds()
 			ticker.Reset(interval)
 		}
 	}
 }
 
+// snapshotJitter returns a random delay of the start of a snapshot cycle, so that the environments(endpoints)
+// of several Portainer instances are not all snapshotted at the same time
+func (service *Service) snapshotJitter() time.Duration {
+	maxJitter := time.Duration(service.snapshotIntervalInSeconds * maxJitterRatio * float64(time.Second))
+	if maxJitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(maxJitter)))
+}
+
+// startSnapshotCycle snapshots all the environments(endpoints) in the background after the given delay.
+// The cycle is skipped when the previous one is still running.
+func (service *Service) startSnapshotCycle(delay time.Duration) {
+	if !service.cycleRunning.CompareAndSwap(false, true) {
+		log.Warn().Msg("background schedule skipped (environment snapshot), the previous snapshots are still running")
+		return
+	}
+
+	go func() {
+		defer service.cycleRunning.Store(false)
+
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-service.shutdownCtx.Done():
+				return
+			}
+		}
+
+		err := service.snapshotEndpoints()
+		if err != nil {
+			log.Error().Err(err).Msg("background schedule error (environment snapshot)")
+		}
+	}()
+}
+
+// snapshotEndpoints snapshots the environments(endpoints) with a pool of workers, so that a slow
+// or unreachable environment(endpoint) does not delay the snapshots of the others
 func (service *Service) snapshotEndpoints() error {
 	endpoints, err := service.dataStore.Endpoint().Endpoints()
 	if err != nil {
 		return err
 	}
 
+	jobs := make(chan portainer.Endpoint)
+
+	var wg sync.WaitGroup
+	for i := 0; i < service.concurrency && i < len(endpoints); i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for endpoint := range jobs {
+				service.snapshotAndUpdateEndpoint(endpoint)
+			}
+		}()
+	}
+
 	for _, endpoint := range endpoints {
 		if !SupportDirectSnapshot(&endpoint) {
 			continue
@@ -221,42 +346,147 @@ func (service *Service) snapshotEndpoints() error {
 			continue
 		}
 
-		snapshotError := service.SnapshotEndpoint(&endpoint)
+		jobs <- endpoint
+	}
 
-		latestEndpointReference, err := service.dataStore.Endpoint().Endpoint(endpoint.ID)
-		if latestEndpointReference == nil {
-			log.Debug().
-				Str("endpoint", endpoint.Name).
-				Str("URL", endpoint.URL).Err(err).
-				Msg("background schedule error (environment snapshot), environment not found inside the database anymore")
+	close(jobs)
+	wg.Wait()
 
-			continue
-		}
+	return nil
+}
 
-		latestEndpointReference.Status = portainer.EndpointStatusUp
-		if snapshotError != nil {
-			log.Debug().
-				Str("endpoint", endpoint.Name).
-				Str("URL", endpoint.URL).Err(err).
-				Msg("background schedule error (environment snapshot), unable to create snapshot")
+// snapshotAndUpdateEndpoint snapshots an environment(endpoint) and updates its status. The snapshot is cancelled and
+// considered failed when it does not complete within the timeout, and the environment(endpoint) is skipped by the
+// next cycles until it returns.
+func (service *Service) snapshotAndUpdateEndpoint(endpoint portainer.Endpoint) {
+	if !service.startEndpointSnapshot(endpoint.ID) {
+		log.Debug().
+			Str("endpoint", endpoint.Name).
+			Str("URL", endpoint.URL).
+			Msg("background schedule skipped (environment snapshot), the previous snapshot is still running")
+
+		return
+	}
 
-			latestEndpointReference.Status = portainer.EndpointStatusDown
-		}
+	ctx, cancel := context.WithTimeout(service.shutdownCtx, service.timeout)
+	defer cancel()
 
-		latestEndpointReference.Agent.Version = endpoint.Agent.Version
+	snapshotEndpoint := endpoint
+	done := make(chan error, 1)
 
-		err = service.dataStore.Endpoint().UpdateEndpoint(latestEndpointReference.ID, latestEndpointReference)
-		if err != nil {
-			log.Debug().
-				Str("endpoint", endpoint.Name).
-				Str("URL", endpoint.URL).Err(err).
-				Msg("background schedule error (environment snapshot), unable to update environment")
+	go func() {
+		defer service.endEndpointSnapshot(endpoint.ID)
 
-			continue
+		done <- service.snapshotEndpoint(ctx, &snapshotEndpoint)
+	}()
+
+	var snapshotError error
+
+	start := time.Now()
+
+	select {
+	case snapshotError = <-done:
+		endpoint.Agent.Version = snapshotEndpoint.Agent.Version
+		endpoint.SnapshotStatus = snapshotEndpoint.SnapshotStatus
+	case <-ctx.Done():
+		// the snapshot is cancelled, do not wait for the environment(endpoint) client to return
+		snapshotError = ctx.Err()
+	}
+
+	if service.shutdownCtx.Err() != nil {
+		// the snapshot was interrupted by the shutdown, keep the previous status of the environment(endpoint)
+		return
+	}
+
+	if errors.Is(snapshotError, context.DeadlineExceeded) {
+		snapshotError = fmt.Errorf("the snapshot did not complete within %s", service.timeout)
+		endpoint.SnapshotStatus = newSnapshotStatus(start, snapshotError)
+	}
+
+	latestEndpointReference, err := service.dataStore.Endpoint().Endpoint(endpoint.ID)
+	if latestEndpointReference == nil {
+		log.Debug().
+			Str("endpoint", endpoint.Name).
+			Str("URL", endpoint.URL).Err(err).
+			Msg("background schedule error (environment snapshot), environment not found inside the database anymore")
+
+		return
+	}
+
+	previousStatus := latestEndpointReference.Status
+
+	latestEndpointReference.Status = portainer.EndpointStatusUp
+	if snapshotError != nil {
+		log.Debug().
+			Str("endpoint", endpoint.Name).
+			Str("URL", endpoint.URL).Err(snapshotError).
+			Msg("background schedule error (environment snapshot), unable to create snapshot")
+
+		latestEndpointReference.Status = portainer.EndpointStatusDown
+
+		if previousStatus == portainer.EndpointStatusUp {
+			service.notifyEndpointDown(latestEndpointReference, snapshotError)
 		}
 	}
 
-	return nil
+	latestEndpointReference.Agent.Version = endpoint.Agent.Version
+	latestEndpointReference.SnapshotStatus = endpoint.SnapshotStatus
+
+	err = service.dataStore.Endpoint().UpdateEndpoint(latestEndpointReference.ID, latestEndpointReference)
+	if err != nil {
+		log.Debug().
+			Str("endpoint", endpoint.Name).
+			Str("URL", endpoint.URL).Err(err).
+			Msg("background schedule error (environment snapshot), unable to update environment")
+	}
+}
+
+func (service *Service) startEndpointSnapshot(endpointID portainer.EndpointID) bool {
+	service.inFlightMu.Lock()
+	defer service.inFlightMu.Unlock()
+
+	if service.inFlight[endpointID] {
+		return false
+	}
+
+	service.inFlight[endpointID] = true
+
+	return true
+}
+
+func (service *Service) endEndpointSnapshot(endpointID portainer.EndpointID) {
+	service.inFlightMu.Lock()
+	defer service.inFlightMu.Unlock()
+
+	delete(service.inFlight, endpointID)
+}
+
+func newSnapshotStatus(start time.Time, err error) *portainer.EndpointSnapshotStatus {
+	status := &portainer.EndpointSnapshotStatus{
+		Date:     start.Unix(),
+		Duration: time.Since(start).Milliseconds(),
+	}
+
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	return status
+}
+
+func (service *Service) notifyEndpointDown(endpoint *portainer.Endpoint, snapshotError error) {
+	if service.notificationService == nil {
+		return
+	}
+
+	service.notificationService.Notify(portainer.NotificationEvent{
+		Type:         portainer.NotificationEventEndpointDown,
+		Title:        fmt.Sprintf("Environment %s is down", endpoint.Name),
+		Message:      fmt.Sprintf("Unable to reach the environment %s (%s): %s", endpoint.Name, endpoint.URL, snapshotError),
+		EndpointID:   endpoint.ID,
+		ResourceID:   strconv.Itoa(int(endpoint.ID)),
+		ResourceName: endpoint.Name,
+	})
 }
 
 // FetchDockerID fetches info.Swarm.Cluster.ID if environment(endpoint) is swarm and info.ID otherwise
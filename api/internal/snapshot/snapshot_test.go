@@ -0,0 +1,127 @@
+package snapshot_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/cloudogu/portainer-ce/api/internal/snapshot"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type blockingDockerSnapshotter struct {
+	mu        sync.Mutex
+	calls     map[string]int
+	cancelled map[string]int
+	release   chan struct{}
+}
+
+func (snapshotter *blockingDockerSnapshotter) CreateSnapshot(ctx context.Context, endpoint *portainer.Endpoint) (*portainer.DockerSnapshot, error) {
+	snapshotter.mu.Lock()
+	snapshotter.calls[endpoint.Name]++
+	snapshotter.mu.Unlock()
+
+	switch endpoint.Name {
+	case "slow":
+		<-ctx.Done()
+
+		snapshotter.mu.Lock()
+		snapshotter.cancelled[endpoint.Name]++
+		snapshotter.mu.Unlock()
+
+		return nil, ctx.Err()
+	case "stuck":
+		// ignores the cancellation
+		<-snapshotter.release
+	case "down":
+		return nil, errors.New("connection refused")
+	}
+
+	return &portainer.DockerSnapshot{RunningContainerCount: 1}, nil
+}
+
+func (snapshotter *blockingDockerSnapshotter) callCount(name string) int {
+	snapshotter.mu.Lock()
+	defer snapshotter.mu.Unlock()
+
+	return snapshotter.calls[name]
+}
+
+func (snapshotter *blockingDockerSnapshotter) cancelCount(name string) int {
+	snapshotter.mu.Lock()
+	defer snapshotter.mu.Unlock()
+
+	return snapshotter.cancelled[name]
+}
+
+func Test_BackgroundSnapshots(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, false)
+	defer teardown()
+
+	for i, name := range []string{"up", "down", "slow", "stuck"} {
+		is.NoError(store.Endpoint().Create(&portainer.Endpoint{
+			ID:     portainer.EndpointID(i + 1),
+			Name:   name,
+			Type:   portainer.DockerEnvironment,
+			URL:    "tcp://" + name + ":2375",
+			Status: portainer.EndpointStatusUp,
+		}))
+	}
+
+	snapshotter := &blockingDockerSnapshotter{calls: make(map[string]int), cancelled: make(map[string]int), release: make(chan struct{})}
+	defer close(snapshotter.release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service, err := snapshot.NewService("200ms", store, snapshotter, nil, ctx, nil)
+	is.NoError(err)
+	service.SetConcurrency(2, 100*time.Millisecond)
+	service.Start()
+
+	is.Eventually(func() bool {
+		for _, endpointID := range []portainer.EndpointID{3, 4} {
+			endpoint, err := store.Endpoint().Endpoint(endpointID)
+			if err != nil || endpoint.SnapshotStatus == nil {
+				return false
+			}
+		}
+
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	endpoint, err := store.Endpoint().Endpoint(1)
+	is.NoError(err)
+	is.Equal(portainer.EndpointStatusUp, endpoint.Status)
+	is.NotNil(endpoint.SnapshotStatus)
+	is.Empty(endpoint.SnapshotStatus.Error)
+
+	endpoint, err = store.Endpoint().Endpoint(2)
+	is.NoError(err)
+	is.Equal(portainer.EndpointStatusDown, endpoint.Status)
+	is.Equal("connection refused", endpoint.SnapshotStatus.Error)
+
+	endpoint, err = store.Endpoint().Endpoint(3)
+	is.NoError(err)
+	is.Equal(portainer.EndpointStatusDown, endpoint.Status)
+	is.Equal("the snapshot did not complete within 100ms", endpoint.SnapshotStatus.Error)
+
+	endpoint, err = store.Endpoint().Endpoint(4)
+	is.NoError(err)
+	is.Equal(portainer.EndpointStatusDown, endpoint.Status)
+	is.Equal("the snapshot did not complete within 100ms", endpoint.SnapshotStatus.Error)
+
+	is.Eventually(func() bool {
+		return snapshotter.callCount("up") >= 3
+	}, 5*time.Second, 10*time.Millisecond)
+	is.GreaterOrEqual(snapshotter.cancelCount("slow"), 1, "a snapshot exceeding the timeout should be cancelled")
+	is.GreaterOrEqual(snapshotter.callCount("slow"), 2, "an environment whose snapshot was cancelled should be snapshotted again")
+	is.Equal(1, snapshotter.callCount("stuck"), "an environment whose previous snapshot is still running should be skipped")
+}
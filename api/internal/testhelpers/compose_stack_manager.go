@@ -31,3 +31,7 @@ func (manager *composeStackManager) Down(ctx context.Context, stack *portainer.S
 func (manager *composeStackManager) Pull(ctx context.Context, stack *portainer.Stack, endpoint *portainer.Endpoint) error {
 	return nil
 }
+
+func (manager *composeStackManager) Config(ctx context.Context, stack *portainer.Stack) ([]byte, error) {
+	return nil, nil
+}
@@ -10,6 +10,7 @@ import (
 )
 
 type testDatastore struct {
+	auditLog                dataservices.AuditLogService
 	customTemplate          dataservices.CustomTemplateService
 	edgeGroup               dataservices.EdgeGroupService
 	edgeJob                 dataservices.EdgeJobService
@@ -18,7 +19,10 @@ type testDatastore struct {
 	endpointGroup           dataservices.EndpointGroupService
 	endpointRelation        dataservices.EndpointRelationService
 	fdoProfile              dataservices.FDOProfileService
+	gitCredential           dataservices.GitCredentialService
 	helmUserRepository      dataservices.HelmUserRepositoryService
+	notificationChannel     dataservices.NotificationChannelService
+	notificationDelivery    dataservices.NotificationDeliveryService
 	registry                dataservices.RegistryService
 	resourceControl         dataservices.ResourceControlService
 	apiKeyRepositoryService dataservices.APIKeyRepository
@@ -26,12 +30,15 @@ type testDatastore struct {
 	sslSettings             dataservices.SSLSettingsService
 	settings                dataservices.SettingsService
 	snapshot                dataservices.SnapshotService
+	snapshotHistory         dataservices.SnapshotHistoryService
 	stack                   dataservices.StackService
+	stackVersion            dataservices.StackVersionService
 	tag                     dataservices.TagService
 	teamMembership          dataservices.TeamMembershipService
 	team                    dataservices.TeamService
 	tunnelServer            dataservices.TunnelServerService
 	user                    dataservices.UserService
+	userSession             dataservices.UserSessionService
 	version                 dataservices.VersionService
 	webhook                 dataservices.WebhookService
 }
@@ -43,6 +50,7 @@ func (d *testDatastore) Close() error                                       { re
 func (d *testDatastore) CheckCurrentEdition() error                         { return nil }
 func (d *testDatastore) MigrateData() error                                 { return nil }
 func (d *testDatastore) Rollback(force bool) error                          { return nil }
+func (d *testDatastore) AuditLog() dataservices.AuditLogService             { return d.auditLog }
 func (d *testDatastore) CustomTemplate() dataservices.CustomTemplateService { return d.customTemplate }
 func (d *testDatastore) EdgeGroup() dataservices.EdgeGroupService           { return d.edgeGroup }
 func (d *testDatastore) EdgeJob() dataservices.EdgeJobService               { return d.edgeJob }
@@ -56,9 +64,18 @@ func (d *testDatastore) FDOProfile() dataservices.FDOProfileService {
 func (d *testDatastore) EndpointRelation() dataservices.EndpointRelationService {
 	return d.endpointRelation
 }
+func (d *testDatastore) GitCredential() dataservices.GitCredentialService {
+	return d.gitCredential
+}
 func (d *testDatastore) HelmUserRepository() dataservices.HelmUserRepositoryService {
 	return d.helmUserRepository
 }
+func (d *testDatastore) NotificationChannel() dataservices.NotificationChannelService {
+	return d.notificationChannel
+}
+func (d *testDatastore) NotificationDelivery() dataservices.NotificationDeliveryService {
+	return d.notificationDelivery
+}
 func (d *testDatastore) Registry() dataservices.RegistryService { return d.registry }
 func (d *testDatastore) ResourceControl() dataservices.ResourceControlService {
 	return d.resourceControl
@@ -67,10 +84,14 @@ func (d *testDatastore) Role() dataservices.RoleService { return d.role }
 func (d *testDatastore) APIKeyRepository() dataservices.APIKeyRepository {
 	return d.apiKeyRepositoryService
 }
-func (d *testDatastore) Settings() dataservices.SettingsService             { return d.settings }
-func (d *testDatastore) Snapshot() dataservices.SnapshotService             { return d.snapshot }
+func (d *testDatastore) Settings() dataservices.SettingsService { return d.settings }
+func (d *testDatastore) Snapshot() dataservices.SnapshotService { return d.snapshot }
+func (d *testDatastore) SnapshotHistory() dataservices.SnapshotHistoryService {
+	return d.snapshotHistory
+}
 func (d *testDatastore) SSLSettings() dataservices.SSLSettingsService       { return d.sslSettings }
 func (d *testDatastore) Stack() dataservices.StackService                   { return d.stack }
+func (d *testDatastore) StackVersion() dataservices.StackVersionService     { return d.stackVersion }
 func (d *testDatastore) Tag() dataservices.TagService                       { return d.tag }
 func (d *testDatastore) TeamMembership() dataservices.TeamMembershipService { return d.teamMembership }
 func (d *testDatastore) Team() dataservices.TeamService                     { return d.team }
@@ -78,6 +99,9 @@ func (d *testDatastore) TunnelServer() dataservices.TunnelServerService     { re
 func (d *testDatastore) User() dataservices.UserService                     { return d.user }
 func (d *testDatastore) Version() dataservices.VersionService               { return d.version }
 func (d *testDatastore) Webhook() dataservices.WebhookService               { return d.webhook }
+func (d *testDatastore) UserSession() dataservices.UserSessionService {
+	return d.userSession
+}
 
 func (d *testDatastore) IsErrObjectNotFound(e error) bool {
 	return false
@@ -0,0 +1,73 @@
+package twofactor
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret is the SHA1 seed of the test vectors of RFC 6238
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func Test_generateCode(t *testing.T) {
+	is := assert.New(t)
+
+	key := []byte("12345678901234567890")
+
+	// the last 6 digits of the 8 digits test vectors of RFC 6238
+	tests := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, code := range tests {
+		is.Equal(code, generateCode(key, timeStep(time.Unix(unix, 0))), "unexpected code at %d", unix)
+	}
+}
+
+func Test_ValidateCode(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Unix(1111111109, 0)
+
+	step, ok := ValidateCode(rfc6238Secret, "081804", now)
+	is.True(ok)
+	is.Equal(timeStep(now), step)
+
+	_, ok = ValidateCode(strings.ToLower(rfc6238Secret), "081804", now)
+	is.True(ok, "the secret should be case insensitive")
+
+	_, ok = ValidateCode(rfc6238Secret, "081804", now.Add(TOTPPeriod))
+	is.True(ok, "the code of the previous time step should be accepted")
+
+	_, ok = ValidateCode(rfc6238Secret, "081804", now.Add(3*TOTPPeriod))
+	is.False(ok, "the code of an older time step should be rejected")
+
+	_, ok = ValidateCode(rfc6238Secret, "000000", now)
+	is.False(ok)
+
+	_, ok = ValidateCode(rfc6238Secret, "81804", now)
+	is.False(ok)
+
+	_, ok = ValidateCode("not base32!", "081804", now)
+	is.False(ok)
+}
+
+func Test_ProvisioningURI(t *testing.T) {
+	is := assert.New(t)
+
+	uri := ProvisioningURI("Portainer", "jane doe", "JBSWY3DPEHPK3PXP")
+
+	is.True(strings.HasPrefix(uri, "otpauth://totp/Portainer:jane%20doe?"))
+	is.Contains(uri, "secret=JBSWY3DPEHPK3PXP")
+	is.Contains(uri, "issuer=Portainer")
+	is.Contains(uri, "digits=6")
+	is.Contains(uri, "period=30")
+}
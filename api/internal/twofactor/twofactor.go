@@ -0,0 +1,151 @@
+package twofactor
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Issuer is the name the authenticator applications display along with the account name
+	Issuer = "Portainer"
+	// RecoveryCodeCount is the number of recovery codes generated when the second factor is enabled
+	RecoveryCodeCount = 10
+	// recoveryCodeSize is the size of a recovery code in bytes, encoded as 16 base32 characters
+	recoveryCodeSize = 10
+)
+
+var (
+	// ErrAlreadyEnabled is returned when enrolling a user whose second factor is already enabled
+	ErrAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrNoPendingEnrollment is returned when enabling the second factor of a user who did not enroll
+	ErrNoPendingEnrollment = errors.New("no two-factor authentication enrollment in progress")
+	// ErrInvalidCode is returned when a code matches neither the TOTP secret nor an unused recovery code
+	ErrInvalidCode = errors.New("invalid two-factor authentication code")
+)
+
+// Enrollment represents the secret an authenticator application is enrolled with
+type Enrollment struct {
+	// Base32 encoded TOTP secret, for the applications which cannot scan QR codes
+	Secret string `json:"Secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	// otpauth URI to render as a QR code
+	ProvisioningURI string `json:"ProvisioningURI" example:"otpauth://totp/Portainer:admin?secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP&issuer=Portainer"`
+}
+
+// Enroll generates a new pending secret for a user, the second factor is enabled once a code generated
+// from this secret is confirmed with Enable
+func Enroll(user *portainer.User) (*Enrollment, error) {
+	if user.TwoFactor.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate the TOTP secret")
+	}
+
+	user.TwoFactor.PendingSecret = secret
+
+	return &Enrollment{
+		Secret:          secret,
+		ProvisioningURI: ProvisioningURI(Issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable enables the second factor of a user once a code generated from the pending secret is confirmed.
+// It returns the recovery codes of the user, only their hashes are kept.
+func Enable(cryptoService portainer.CryptoService, user *portainer.User, code string, t time.Time) ([]string, error) {
+	if user.TwoFactor.Enabled {
+		return nil, ErrAlreadyEnabled
+	}
+
+	if user.TwoFactor.PendingSecret == "" {
+		return nil, ErrNoPendingEnrollment
+	}
+
+	step, ok := ValidateCode(user.TwoFactor.PendingSecret, normalizeCode(code), t)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes(cryptoService)
+	if err != nil {
+		return nil, err
+	}
+
+	user.TwoFactor = portainer.UserTwoFactor{
+		Enabled:       true,
+		Secret:        user.TwoFactor.PendingSecret,
+		RecoveryCodes: hashes,
+		LastUsedStep:  step,
+	}
+
+	return codes, nil
+}
+
+// Verify checks the second factor of a user. The code is either a TOTP code which was not used yet,
+// or a recovery code which is consumed.
+func Verify(cryptoService portainer.CryptoService, twoFactor *portainer.UserTwoFactor, code string, t time.Time) error {
+	if !twoFactor.Enabled {
+		return ErrInvalidCode
+	}
+
+	code = normalizeCode(code)
+
+	if step, ok := ValidateCode(twoFactor.Secret, code, t); ok {
+		// a code cannot be replayed, nor can an older one
+		if step <= twoFactor.LastUsedStep {
+			return ErrInvalidCode
+		}
+
+		twoFactor.LastUsedStep = step
+		return nil
+	}
+
+	for i, hash := range twoFactor.RecoveryCodes {
+		if cryptoService.CompareHashAndData(hash, code) == nil {
+			twoFactor.RecoveryCodes = append(twoFactor.RecoveryCodes[:i:i], twoFactor.RecoveryCodes[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrInvalidCode
+}
+
+// Reset disables the second factor of a user and removes their secret and recovery codes
+func Reset(user *portainer.User) {
+	user.TwoFactor = portainer.UserTwoFactor{}
+}
+
+func generateRecoveryCodes(cryptoService portainer.CryptoService) (codes []string, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	for i := 0; i < RecoveryCodeCount; i++ {
+		raw := make([]byte, recoveryCodeSize)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, errors.Wrap(err, "unable to generate the recovery codes")
+		}
+
+		code := strings.ToLower(encoding.EncodeToString(raw))
+
+		hash, err := cryptoService.Hash(code)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "unable to hash the recovery codes")
+		}
+
+		codes = append(codes, code[:8]+"-"+code[8:])
+		hashes = append(hashes, hash)
+	}
+
+	return codes, hashes, nil
+}
+
+// normalizeCode removes the separators and spaces users may type, and lowercases the recovery codes
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+}
@@ -0,0 +1,76 @@
+package twofactor
+
+import (
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func codeAt(t *testing.T, secret string, at time.Time) string {
+	code, err := GenerateCode(secret, at)
+	assert.NoError(t, err)
+
+	return code
+}
+
+func Test_TwoFactorLifecycle(t *testing.T) {
+	is := assert.New(t)
+
+	cryptoService := &crypto.Service{}
+	user := &portainer.User{ID: 1, Username: "admin"}
+	now := time.Now()
+
+	_, err := Enable(cryptoService, user, "123456", now)
+	is.ErrorIs(err, ErrNoPendingEnrollment)
+
+	enrollment, err := Enroll(user)
+	is.NoError(err)
+	is.Equal(enrollment.Secret, user.TwoFactor.PendingSecret)
+	is.Contains(enrollment.ProvisioningURI, "otpauth://totp/Portainer:admin?")
+	is.False(user.TwoFactor.Enabled)
+
+	_, err = Enable(cryptoService, user, "not-a-code", now)
+	is.ErrorIs(err, ErrInvalidCode)
+
+	recoveryCodes, err := Enable(cryptoService, user, codeAt(t, enrollment.Secret, now), now)
+	is.NoError(err)
+	is.Len(recoveryCodes, RecoveryCodeCount)
+	is.True(user.TwoFactor.Enabled)
+	is.Equal(enrollment.Secret, user.TwoFactor.Secret)
+	is.Empty(user.TwoFactor.PendingSecret)
+	is.Len(user.TwoFactor.RecoveryCodes, RecoveryCodeCount)
+	is.NotContains(user.TwoFactor.RecoveryCodes, recoveryCodes[0], "only the hashes of the recovery codes should be kept")
+
+	_, err = Enroll(user)
+	is.ErrorIs(err, ErrAlreadyEnabled)
+
+	t.Run("a code cannot be replayed", func(t *testing.T) {
+		is.ErrorIs(Verify(cryptoService, &user.TwoFactor, codeAt(t, enrollment.Secret, now), now), ErrInvalidCode)
+
+		later := now.Add(TOTPPeriod)
+		is.NoError(Verify(cryptoService, &user.TwoFactor, codeAt(t, enrollment.Secret, later), later))
+		is.ErrorIs(Verify(cryptoService, &user.TwoFactor, codeAt(t, enrollment.Secret, later), later), ErrInvalidCode)
+	})
+
+	t.Run("a recovery code can be used once", func(t *testing.T) {
+		is.NoError(Verify(cryptoService, &user.TwoFactor, " "+recoveryCodes[3]+" ", now))
+		is.Len(user.TwoFactor.RecoveryCodes, RecoveryCodeCount-1)
+
+		is.ErrorIs(Verify(cryptoService, &user.TwoFactor, recoveryCodes[3], now), ErrInvalidCode)
+		is.NoError(Verify(cryptoService, &user.TwoFactor, recoveryCodes[4], now))
+		is.Len(user.TwoFactor.RecoveryCodes, RecoveryCodeCount-2)
+	})
+
+	t.Run("reset disables the second factor", func(t *testing.T) {
+		Reset(user)
+
+		is.False(user.TwoFactor.Enabled)
+		is.Empty(user.TwoFactor.Secret)
+		is.Empty(user.TwoFactor.RecoveryCodes)
+		is.ErrorIs(Verify(cryptoService, &user.TwoFactor, recoveryCodes[5], now), ErrInvalidCode)
+	})
+}
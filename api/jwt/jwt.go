@@ -6,6 +6,7 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	"golang.org/x/oauth2"
 	"os"
+	"strconv"
 	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
@@ -18,6 +19,8 @@ import (
 const (
 	// change settings timeout accordingly, current 24h = 86400
 	blocklistTokenTTL = 86400
+	// dockerExtensionTokenTimeout is the lifetime of the tokens in docker desktop extension mode
+	dockerExtensionTokenTimeout = time.Hour * 8760 * 99
 )
 
 // scope represents JWT scopes that are supported in JWT claims.
@@ -152,6 +155,14 @@ func (service *Service) ParseAndVerifyToken(token string) (*portainer.TokenData,
 				OAuthToken: &oauth2.Token{AccessToken: cl.OAuthToken},
 			}
 
+			if cl.StandardClaims.Id != "" {
+				sessionID, err := service.verifySession(cl)
+				if err != nil {
+					return nil, errInvalidJWTToken
+				}
+				tokenData.SessionID = sessionID
+			}
+
 			if tokenData.OAuthToken.AccessToken != "" {
 				if service.tokenBlocklist.IsBlocked(tokenData.OAuthToken.AccessToken) {
 					return nil, errInvalidJWTToken
@@ -192,12 +203,16 @@ func (service *Service) generateSignedToken(data *portainer.TokenData, expiresAt
 	if _, ok := os.LookupEnv("DOCKER_EXTENSION"); ok {
 		// Set expiration to 99 years for docker desktop extension.
 		log.Info().Msg("detected docker desktop extension mode")
-		expiresAt = time.Now().Add(time.Hour * 8760 * 99).Unix()
+		expiresAt = time.Now().Add(dockerExtensionTokenTimeout).Unix()
 	}
 	tokenData := ""
 	if data.OAuthToken != nil {
 		tokenData = data.OAuthToken.AccessToken
 	}
+	sessionID := ""
+	if data.SessionID != 0 {
+		sessionID = strconv.Itoa(int(data.SessionID))
+	}
 	cl := claims{
 		UserID:              int(data.ID),
 		Username:            data.Username,
@@ -206,6 +221,7 @@ func (service *Service) generateSignedToken(data *portainer.TokenData, expiresAt
 		Scope:               scope,
 		ForceChangePassword: data.ForceChangePassword,
 		StandardClaims: jwt.StandardClaims{
+			Id:        sessionID,
 			ExpiresAt: expiresAt,
 			IssuedAt:  time.Now().Unix(),
 		},
@@ -0,0 +1,91 @@
+package jwt
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/scheduler"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// sessionActivityInterval is the minimum interval between two updates of the last seen date of a session
+	sessionActivityInterval = time.Minute
+	// sessionCleanupInterval is the interval between two removals of the expired sessions
+	sessionCleanupInterval = time.Hour
+)
+
+// GenerateSessionToken opens a user session and generates its JWT token. The session is recorded with the IP address
+// and the user agent of the client, and its token is rejected once the session is removed.
+func (service *Service) GenerateSessionToken(data *portainer.TokenData, ipAddress, userAgent string) (string, error) {
+	now := time.Now()
+
+	expiresAt := service.defaultExpireAt()
+	if _, ok := os.LookupEnv("DOCKER_EXTENSION"); ok {
+		expiresAt = now.Add(dockerExtensionTokenTimeout).Unix()
+	}
+
+	session := &portainer.UserSession{
+		UserID:     data.ID,
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
+		IssuedAt:   now.Unix(),
+		LastSeenAt: now.Unix(),
+		ExpiresAt:  expiresAt,
+	}
+
+	err := service.dataStore.UserSession().Create(session)
+	if err != nil {
+		return "", err
+	}
+
+	sessionData := *data
+	sessionData.SessionID = session.ID
+
+	return service.generateSignedToken(&sessionData, expiresAt, defaultScope)
+}
+
+// verifySession checks that the session of a token was not revoked and records the activity of the session
+func (service *Service) verifySession(cl *claims) (portainer.UserSessionID, error) {
+	id, err := strconv.Atoi(cl.StandardClaims.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	session, err := service.dataStore.UserSession().UserSession(portainer.UserSessionID(id))
+	if err != nil {
+		return 0, err
+	}
+
+	if session.UserID != portainer.UserID(cl.UserID) {
+		return 0, errInvalidJWTToken
+	}
+
+	now := time.Now()
+	if now.Sub(time.Unix(session.LastSeenAt, 0)) >= sessionActivityInterval {
+		session.LastSeenAt = now.Unix()
+
+		err = service.dataStore.UserSession().UpdateUserSession(session.ID, session)
+		if err != nil {
+			log.Debug().Err(err).Int("session_id", id).Msg("unable to update the last seen date of the session")
+		}
+	}
+
+	return session.ID, nil
+}
+
+// StartSessionCleanup periodically removes the sessions whose token expired
+func StartSessionCleanup(scheduler *scheduler.Scheduler, dataStore dataservices.DataStore) {
+	scheduler.StartJobEvery(sessionCleanupInterval, func() error {
+		err := dataStore.UserSession().DeleteUserSessionsExpiredBefore(time.Now().Unix())
+		if err != nil {
+			log.Warn().Err(err).Msg("unable to clean up the expired user sessions")
+		}
+
+		return nil
+	})
+}
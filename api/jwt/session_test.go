@@ -0,0 +1,47 @@
+package jwt
+
+import (
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSessionToken(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	user := &portainer.User{ID: 1, Username: "bob", Role: portainer.StandardUserRole}
+	is.NoError(store.User().Create(user))
+
+	svc, err := NewService("8h", store)
+	is.NoError(err, "failed to create a copy of service")
+
+	token, err := svc.GenerateSessionToken(&portainer.TokenData{ID: user.ID, Username: user.Username, Role: user.Role}, "10.0.0.10", "curl/8.0")
+	is.NoError(err)
+
+	tokenData, err := svc.ParseAndVerifyToken(token)
+	is.NoError(err)
+	is.NotZero(tokenData.SessionID)
+
+	session, err := store.UserSession().UserSession(tokenData.SessionID)
+	is.NoError(err)
+	is.Equal(user.ID, session.UserID)
+	is.Equal("10.0.0.10", session.IPAddress)
+	is.Equal("curl/8.0", session.UserAgent)
+	is.Greater(session.ExpiresAt, session.IssuedAt)
+
+	kubeconfigToken, err := svc.GenerateTokenForKubeconfig(tokenData)
+	is.NoError(err)
+
+	is.NoError(store.UserSession().DeleteUserSession(session.ID))
+
+	_, err = svc.ParseAndVerifyToken(token)
+	is.Error(err, "the token of a revoked session must be rejected")
+
+	_, err = svc.ParseAndVerifyToken(kubeconfigToken)
+	is.NoError(err, "the kubeconfig token must not depend on the session it was generated from")
+}
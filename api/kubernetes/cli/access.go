@@ -45,6 +45,28 @@ func (kcl *KubeClient) GetNamespaceAccessPolicies() (map[string]portainer.K8sNam
 	return policies, nil
 }
 
+// IsNamespaceAccessPoliciesConfigMap returns whether the config map of a namespace stores the namespace access policies
+func IsNamespaceAccessPoliciesConfigMap(namespace, name string) bool {
+	return namespace == portainerNamespace && name == portainerConfigMapName
+}
+
+// NamespaceAccessPoliciesFromConfigMapData parses the namespace access policies stored in the data of the
+// config map. It returns nil when the data does not contain the namespace access policies.
+func NamespaceAccessPoliciesFromConfigMapData(data map[string]string) (map[string]portainer.K8sNamespaceAccessPolicy, error) {
+	accessData, ok := data[portainerConfigMapAccessPoliciesKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var policies map[string]portainer.K8sNamespaceAccessPolicy
+	err := json.Unmarshal([]byte(accessData), &policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
 func (kcl *KubeClient) setupNamespaceAccesses(userID int, teamIDs []int, serviceAccountName string, restrictDefaultNamespace bool) error {
 	accessPolicies, err := kcl.GetNamespaceAccessPolicies()
 	if err != nil {
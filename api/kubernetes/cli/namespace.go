@@ -56,10 +56,20 @@ func (kcl *KubeClient) GetNamespace(name string) (portainer.K8sNamespaceInfo, er
 		IsDefault: namespace.Name == defaultNamespace,
 	}
 
+	result.ResourceQuota, err = kcl.getNamespaceResourceQuota(name)
+	if err != nil {
+		return result, errors.Wrap(err, "failed fetching the namespace resource quota")
+	}
+
+	result.LimitRange, err = kcl.getNamespaceLimitRange(name)
+	if err != nil {
+		return result, errors.Wrap(err, "failed fetching the namespace limit range")
+	}
+
 	return result, nil
 }
 
-// CreateNamespace creates a new ingress in a given namespace in a k8s endpoint.
+// CreateNamespace creates a new namespace in a k8s endpoint, along with its resource quota and limit range.
 func (kcl *KubeClient) CreateNamespace(info models.K8sNamespaceDetails) error {
 	client := kcl.cli.CoreV1().Namespaces()
 
@@ -68,7 +78,31 @@ func (kcl *KubeClient) CreateNamespace(info models.K8sNamespaceDetails) error {
 	ns.Annotations = info.Annotations
 
 	_, err := client.Create(context.Background(), &ns, metav1.CreateOptions{})
-	return err
+	if err != nil {
+		return err
+	}
+
+	return kcl.updateNamespaceResources(info)
+}
+
+// updateNamespaceResources applies the resource quota and the limit range of a namespace when they are defined,
+// and removes them when requested. They are left unchanged otherwise.
+func (kcl *KubeClient) updateNamespaceResources(info models.K8sNamespaceDetails) error {
+	if info.ResourceQuota != nil || info.RemoveResourceQuota {
+		err := kcl.UpdateNamespaceResourceQuota(info.Name, info.ResourceQuota)
+		if err != nil {
+			return errors.Wrap(err, "failed updating the namespace resource quota")
+		}
+	}
+
+	if info.LimitRange != nil || info.RemoveLimitRange {
+		err := kcl.UpdateNamespaceLimitRange(info.Name, info.LimitRange)
+		if err != nil {
+			return errors.Wrap(err, "failed updating the namespace limit range")
+		}
+	}
+
+	return nil
 }
 
 func isSystemNamespace(namespace v1.Namespace) bool {
@@ -123,6 +157,7 @@ func (kcl *KubeClient) ToggleSystemState(namespaceName string, isSystem bool) er
 }
 
 // UpdateIngress updates an ingress in a given namespace in a k8s endpoint.
+// The resource quota and the limit range of the namespace are replaced when defined, or removed when requested.
 func (kcl *KubeClient) UpdateNamespace(info models.K8sNamespaceDetails) error {
 	client := kcl.cli.CoreV1().Namespaces()
 
@@ -131,7 +166,11 @@ func (kcl *KubeClient) UpdateNamespace(info models.K8sNamespaceDetails) error {
 	ns.Annotations = info.Annotations
 
 	_, err := client.Update(context.Background(), &ns, metav1.UpdateOptions{})
-	return err
+	if err != nil {
+		return err
+	}
+
+	return kcl.updateNamespaceResources(info)
 }
 
 func (kcl *KubeClient) DeleteNamespace(namespace string) error {
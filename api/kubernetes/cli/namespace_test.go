@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	models "github.com/cloudogu/portainer-ce/api/http/models/kubernetes"
 	"github.com/stretchr/testify/assert"
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -181,3 +182,72 @@ func Test_ToggleSystemState(t *testing.T) {
 
 	})
 }
+
+func Test_NamespaceResourceQuotaAndLimitRange(t *testing.T) {
+	is := assert.New(t)
+
+	kcl := &KubeClient{
+		cli:        kfake.NewSimpleClientset(),
+		instanceID: "instance",
+		lock:       &sync.Mutex{},
+	}
+
+	err := kcl.CreateNamespace(models.K8sNamespaceDetails{
+		Name: "team-a",
+		ResourceQuota: &models.K8sResourceQuota{
+			CPU:     "4",
+			Memory:  "8Gi",
+			Objects: map[string]int64{"pods": 20},
+		},
+		LimitRange: &models.K8sLimitRange{
+			DefaultCPU:    "500m",
+			DefaultMemory: "512Mi",
+		},
+	})
+	is.NoError(err)
+
+	quota, err := kcl.cli.CoreV1().ResourceQuotas("team-a").Get(context.Background(), "portainer-rq-team-a", metav1.GetOptions{})
+	is.NoError(err)
+	is.Equal("4", quota.Spec.Hard.Name(core.ResourceLimitsCPU, "").String())
+	is.Equal("8Gi", quota.Spec.Hard.Name(core.ResourceRequestsMemory, "").String())
+	is.Equal("20", quota.Spec.Hard.Pods().String())
+
+	namespace, err := kcl.GetNamespace("team-a")
+	is.NoError(err)
+	is.Equal("4", namespace.ResourceQuota.Hard["requests.cpu"])
+	is.Equal("500m", namespace.LimitRange.DefaultCPU)
+	is.Equal("512Mi", namespace.LimitRange.DefaultMemory)
+	is.Empty(namespace.LimitRange.MaxCPU)
+
+	err = kcl.UpdateNamespace(models.K8sNamespaceDetails{
+		Name:          "team-a",
+		ResourceQuota: &models.K8sResourceQuota{CPU: "2"},
+	})
+	is.NoError(err)
+
+	namespace, err = kcl.GetNamespace("team-a")
+	is.NoError(err)
+	is.Equal(map[string]string{"requests.cpu": "2", "limits.cpu": "2"}, namespace.ResourceQuota.Hard)
+	is.NotNil(namespace.LimitRange, "the limit range should be kept when not defined")
+	is.Equal("500m", namespace.LimitRange.DefaultCPU)
+
+	err = kcl.UpdateNamespace(models.K8sNamespaceDetails{Name: "team-a"})
+	is.NoError(err)
+
+	namespace, err = kcl.GetNamespace("team-a")
+	is.NoError(err)
+	is.NotNil(namespace.ResourceQuota, "the resource quota should be kept when not defined")
+	is.NotNil(namespace.LimitRange, "the limit range should be kept when not defined")
+
+	err = kcl.UpdateNamespace(models.K8sNamespaceDetails{
+		Name:                "team-a",
+		RemoveResourceQuota: true,
+		RemoveLimitRange:    true,
+	})
+	is.NoError(err)
+
+	namespace, err = kcl.GetNamespace("team-a")
+	is.NoError(err)
+	is.Nil(namespace.ResourceQuota, "the resource quota should be removed when requested")
+	is.Nil(namespace.LimitRange, "the limit range should be removed when requested")
+}
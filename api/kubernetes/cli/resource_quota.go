@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"context"
+
+	models "github.com/cloudogu/portainer-ce/api/http/models/kubernetes"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpdateNamespaceResourceQuota creates or updates the resource quota managed by Portainer in a namespace,
+// the resource quota is removed when quota is nil
+func (kcl *KubeClient) UpdateNamespaceResourceQuota(namespace string, quota *models.K8sResourceQuota) error {
+	client := kcl.cli.CoreV1().ResourceQuotas(namespace)
+	name := namespaceResourceQuotaName(namespace)
+
+	existing, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed fetching the namespace resource quota")
+	}
+	exists := err == nil
+
+	if quota == nil {
+		if !exists {
+			return nil
+		}
+
+		return client.Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+
+	hard, err := resourceQuotaHard(quota)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		_, err = client.Create(context.TODO(), &v1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1.ResourceQuotaSpec{Hard: hard},
+		}, metav1.CreateOptions{})
+		return err
+	}
+
+	existing.Spec.Hard = hard
+	_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+	return err
+}
+
+// UpdateNamespaceLimitRange creates or updates the limit range managed by Portainer in a namespace,
+// the limit range is removed when limitRange is nil
+func (kcl *KubeClient) UpdateNamespaceLimitRange(namespace string, limitRange *models.K8sLimitRange) error {
+	client := kcl.cli.CoreV1().LimitRanges(namespace)
+	name := namespaceLimitRangeName(namespace)
+
+	existing, err := client.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return errors.Wrap(err, "failed fetching the namespace limit range")
+	}
+	exists := err == nil
+
+	if limitRange == nil {
+		if !exists {
+			return nil
+		}
+
+		return client.Delete(context.TODO(), name, metav1.DeleteOptions{})
+	}
+
+	item, err := containerLimitRangeItem(limitRange)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		_, err = client.Create(context.TODO(), &v1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       v1.LimitRangeSpec{Limits: []v1.LimitRangeItem{item}},
+		}, metav1.CreateOptions{})
+		return err
+	}
+
+	existing.Spec.Limits = []v1.LimitRangeItem{item}
+	_, err = client.Update(context.TODO(), existing, metav1.UpdateOptions{})
+	return err
+}
+
+// getNamespaceResourceQuota returns the hard limits and the usage of the resource quota managed by Portainer
+// in a namespace, or nil when there is none
+func (kcl *KubeClient) getNamespaceResourceQuota(namespace string) (*models.K8sResourceQuotaUsage, error) {
+	quota, err := kcl.cli.CoreV1().ResourceQuotas(namespace).Get(context.TODO(), namespaceResourceQuotaName(namespace), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	hard := quota.Status.Hard
+	if len(hard) == 0 {
+		// the status is not computed yet by the quota controller
+		hard = quota.Spec.Hard
+	}
+
+	return &models.K8sResourceQuotaUsage{
+		Hard: resourceListToMap(hard),
+		Used: resourceListToMap(quota.Status.Used),
+	}, nil
+}
+
+// getNamespaceLimitRange returns the container limits of the limit range managed by Portainer in a namespace,
+// or nil when there is none
+func (kcl *KubeClient) getNamespaceLimitRange(namespace string) (*models.K8sLimitRange, error) {
+	limitRange, err := kcl.cli.CoreV1().LimitRanges(namespace).Get(context.TODO(), namespaceLimitRangeName(namespace), metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	result := &models.K8sLimitRange{}
+	for _, item := range limitRange.Spec.Limits {
+		if item.Type != v1.LimitTypeContainer {
+			continue
+		}
+
+		result.DefaultCPU = quantityString(item.Default, v1.ResourceCPU)
+		result.DefaultMemory = quantityString(item.Default, v1.ResourceMemory)
+		result.DefaultRequestCPU = quantityString(item.DefaultRequest, v1.ResourceCPU)
+		result.DefaultRequestMemory = quantityString(item.DefaultRequest, v1.ResourceMemory)
+		result.MaxCPU = quantityString(item.Max, v1.ResourceCPU)
+		result.MaxMemory = quantityString(item.Max, v1.ResourceMemory)
+	}
+
+	return result, nil
+}
+
+func resourceQuotaHard(quota *models.K8sResourceQuota) (v1.ResourceList, error) {
+	hard := v1.ResourceList{}
+
+	quantities := []struct {
+		value string
+		names []v1.ResourceName
+	}{
+		{quota.CPU, []v1.ResourceName{v1.ResourceRequestsCPU, v1.ResourceLimitsCPU}},
+		{quota.Memory, []v1.ResourceName{v1.ResourceRequestsMemory, v1.ResourceLimitsMemory}},
+		{quota.Storage, []v1.ResourceName{v1.ResourceRequestsStorage}},
+	}
+
+	for _, q := range quantities {
+		if q.value == "" {
+			continue
+		}
+
+		quantity, err := resource.ParseQuantity(q.value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid quantity %q", q.value)
+		}
+
+		for _, name := range q.names {
+			hard[name] = quantity
+		}
+	}
+
+	for name, count := range quota.Objects {
+		hard[v1.ResourceName(name)] = *resource.NewQuantity(count, resource.DecimalSI)
+	}
+
+	return hard, nil
+}
+
+func containerLimitRangeItem(limitRange *models.K8sLimitRange) (v1.LimitRangeItem, error) {
+	item := v1.LimitRangeItem{Type: v1.LimitTypeContainer}
+
+	lists := []struct {
+		list   *v1.ResourceList
+		cpu    string
+		memory string
+	}{
+		{&item.Default, limitRange.DefaultCPU, limitRange.DefaultMemory},
+		{&item.DefaultRequest, limitRange.DefaultRequestCPU, limitRange.DefaultRequestMemory},
+		{&item.Max, limitRange.MaxCPU, limitRange.MaxMemory},
+	}
+
+	for _, l := range lists {
+		for name, value := range map[v1.ResourceName]string{v1.ResourceCPU: l.cpu, v1.ResourceMemory: l.memory} {
+			if value == "" {
+				continue
+			}
+
+			quantity, err := resource.ParseQuantity(value)
+			if err != nil {
+				return item, errors.Wrapf(err, "invalid quantity %q", value)
+			}
+
+			if *l.list == nil {
+				*l.list = v1.ResourceList{}
+			}
+			(*l.list)[name] = quantity
+		}
+	}
+
+	return item, nil
+}
+
+func resourceListToMap(list v1.ResourceList) map[string]string {
+	result := make(map[string]string, len(list))
+	for name, quantity := range list {
+		result[string(name)] = quantity.String()
+	}
+
+	return result
+}
+
+func quantityString(list v1.ResourceList, name v1.ResourceName) string {
+	quantity, ok := list[name]
+	if !ok {
+		return ""
+	}
+
+	return quantity.String()
+}
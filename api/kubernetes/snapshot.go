@@ -24,17 +24,17 @@ func NewSnapshotter(clientFactory *cli.ClientFactory) *Snapshotter {
 }
 
 // CreateSnapshot creates a snapshot of a specific Kubernetes environment(endpoint)
-func (snapshotter *Snapshotter) CreateSnapshot(endpoint *portainer.Endpoint) (*portainer.KubernetesSnapshot, error) {
+func (snapshotter *Snapshotter) CreateSnapshot(ctx context.Context, endpoint *portainer.Endpoint) (*portainer.KubernetesSnapshot, error) {
 	client, err := snapshotter.clientFactory.CreateClient(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	return snapshot(client, endpoint)
+	return snapshot(ctx, client, endpoint)
 }
 
-func snapshot(cli *kubernetes.Clientset, endpoint *portainer.Endpoint) (*portainer.KubernetesSnapshot, error) {
-	res := cli.RESTClient().Get().AbsPath("/healthz").Do(context.TODO())
+func snapshot(ctx context.Context, cli *kubernetes.Clientset, endpoint *portainer.Endpoint) (*portainer.KubernetesSnapshot, error) {
+	res := cli.RESTClient().Get().AbsPath("/healthz").Do(ctx)
 	if res.Error() != nil {
 		return nil, res.Error()
 	}
@@ -46,11 +46,16 @@ func snapshot(cli *kubernetes.Clientset, endpoint *portainer.Endpoint) (*portain
 		log.Warn().Str("endpoint", endpoint.Name).Err(err).Msg("unable to snapshot cluster version")
 	}
 
-	err = snapshotNodes(snapshot, cli)
+	err = snapshotNodes(ctx, snapshot, cli)
 	if err != nil {
 		log.Warn().Str("endpoint", endpoint.Name).Err(err).Msg("unable to snapshot cluster nodes")
 	}
 
+	// the snapshot is incomplete when it was cancelled while collecting the resources
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	snapshot.Time = time.Now().Unix()
 	return snapshot, nil
 }
@@ -65,8 +70,8 @@ func snapshotVersion(snapshot *portainer.KubernetesSnapshot, cli *kubernetes.Cli
 	return nil
 }
 
-func snapshotNodes(snapshot *portainer.KubernetesSnapshot, cli *kubernetes.Clientset) error {
-	nodeList, err := cli.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+func snapshotNodes(ctx context.Context, snapshot *portainer.KubernetesSnapshot, cli *kubernetes.Clientset) error {
+	nodeList, err := cli.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return err
 	}
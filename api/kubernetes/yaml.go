@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"regexp"
@@ -53,6 +54,31 @@ func GetHelmAppLabels(name, owner string) map[string]string {
 	}
 }
 
+// HelmPostRendererLabelsEnvVar is the environment variable holding the JSON encoded labels added by the helm post-renderer
+const HelmPostRendererLabelsEnvVar = "PORTAINER_HELM_POST_RENDERER_LABELS"
+
+// RunHelmPostRenderer labels the resources of the release manifest read from in and writes the result to out.
+// Portainer runs itself as the post-renderer of the helm releases it deploys, so that the labels are part of the release
+func RunHelmPostRenderer(in io.Reader, out io.Writer, encodedLabels string) error {
+	var labels map[string]string
+	if err := json.Unmarshal([]byte(encodedLabels), &labels); err != nil {
+		return errors.Wrap(err, "failed to decode the labels of the helm post-renderer")
+	}
+
+	manifest, err := io.ReadAll(in)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the helm release manifest")
+	}
+
+	manifest, err = AddAppLabels(manifest, labels)
+	if err != nil {
+		return errors.Wrap(err, "failed to add application labels")
+	}
+
+	_, err = out.Write(manifest)
+	return err
+}
+
 // AddAppLabels adds required labels to "Resource"->metadata->labels.
 // It'll add those labels to all Resource (nodes with a kind property exluding a list) it can find in provided yaml.
 // Items in the yaml file could either be organised as a list or broken into multi documents.
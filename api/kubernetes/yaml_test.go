@@ -1,6 +1,8 @@
 package kubernetes
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -711,3 +713,17 @@ kind: Namespace
 		})
 	}
 }
+
+func Test_RunHelmPostRenderer(t *testing.T) {
+	is := assert.New(t)
+
+	in := strings.NewReader("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n")
+	var out bytes.Buffer
+
+	err := RunHelmPostRenderer(in, &out, `{"io.portainer.kubernetes.application.stackid":"1"}`)
+	is.NoError(err)
+	is.Contains(out.String(), "io.portainer.kubernetes.application.stackid: \"1\"")
+
+	err = RunHelmPostRenderer(strings.NewReader(""), &out, "labels")
+	is.Error(err, "invalid labels should be rejected")
+}
@@ -0,0 +1,16 @@
+// Package metrics collects internal metrics of the Portainer server and exposes them in the Prometheus text format.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// DefaultBuckets are the histogram buckets used for durations expressed in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Registry holds the metrics of the Portainer server. It is kept apart from the default registry
+// of the Prometheus client so that only the metrics of this package are exposed.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// HTTPRequestDuration measures the latency of the requests served by the API, per handler
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "portainer_http_request_duration_seconds",
+		Help:    "Latency of the HTTP requests served by Portainer, per handler.",
+		Buckets: DefaultBuckets,
+	}, []string{"handler", "method", "code"})
+
+	// ProxyRequests counts the requests proxied to each environment(endpoint)
+	ProxyRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "portainer_proxy_requests_total",
+		Help: "Number of requests proxied to the environments.",
+	}, []string{"endpoint_id", "type", "code"})
+
+	// SnapshotDuration measures the duration of the environment(endpoint) snapshots
+	SnapshotDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "portainer_snapshot_duration_seconds",
+		Help:    "Duration of the environment snapshots.",
+		Buckets: DefaultBuckets,
+	}, []string{"platform"})
+
+	// SnapshotFailures counts the environment(endpoint) snapshots that failed
+	SnapshotFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "portainer_snapshot_failures_total",
+		Help: "Number of environment snapshots that failed.",
+	}, []string{"platform"})
+
+	// DatabaseTransactionDuration measures the duration of the BoltDB transactions
+	DatabaseTransactionDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "portainer_boltdb_transaction_duration_seconds",
+		Help:    "Duration of the BoltDB transactions.",
+		Buckets: []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1},
+	}, []string{"type"})
+
+	// SchedulerJobs is the number of jobs currently scheduled
+	SchedulerJobs = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "portainer_scheduler_jobs",
+		Help: "Number of jobs currently scheduled.",
+	})
+
+	// SchedulerJobRuns counts the runs of the scheduled jobs
+	SchedulerJobRuns = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "portainer_scheduler_job_runs_total",
+		Help: "Number of runs of the scheduled jobs.",
+	}, []string{"outcome"})
+
+	// GitOperationDuration measures the duration of the operations made against remote git repositories
+	GitOperationDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "portainer_git_operation_duration_seconds",
+		Help:    "Duration of the operations made against remote git repositories.",
+		Buckets: DefaultBuckets,
+	}, []string{"operation", "outcome"})
+)
+
+// Outcome returns the outcome label of an operation
+func Outcome(err error) string {
+	if err != nil {
+		return "failure"
+	}
+
+	return "success"
+}
+
+// ObserveDuration adds the time elapsed since start to the histogram matching the label values
+func ObserveDuration(histogram *prometheus.HistogramVec, start time.Time, labelValues ...string) {
+	histogram.WithLabelValues(labelValues...).Observe(time.Since(start).Seconds())
+}
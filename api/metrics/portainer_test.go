@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ObserveDuration(t *testing.T) {
+	is := assert.New(t)
+
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "test_duration_seconds",
+		Help:    "Duration.",
+		Buckets: []float64{0.1, 1, 5},
+	}, []string{"outcome"})
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(histogram)
+
+	ObserveDuration(histogram, time.Now().Add(-2*time.Second), Outcome(errors.New("failed")))
+
+	families, err := registry.Gather()
+	is.NoError(err)
+	is.Len(families, 1)
+
+	metric := families[0].GetMetric()[0]
+	is.Equal("failure", metric.GetLabel()[0].GetValue())
+	is.Equal(uint64(1), metric.GetHistogram().GetSampleCount())
+	is.GreaterOrEqual(metric.GetHistogram().GetSampleSum(), 2.0)
+}
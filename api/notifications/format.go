@@ -0,0 +1,85 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+)
+
+const (
+	// SignatureHeader contains the HMAC-SHA256 signature of the body of the generic webhook messages
+	SignatureHeader = "X-Portainer-Signature"
+	// EventHeader contains the type of the event sent to a generic webhook
+	EventHeader = "X-Portainer-Event"
+)
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+type teamsMessage struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	Summary    string `json:"summary"`
+	ThemeColor string `json:"themeColor"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+// buildRequest creates the HTTP request sending the event to the channel, in the format expected by the channel type
+func buildRequest(channel *portainer.NotificationChannel, event portainer.NotificationEvent) (*http.Request, error) {
+	var payload interface{}
+
+	switch channel.Type {
+	case portainer.NotificationChannelWebhook:
+		payload = event
+	case portainer.NotificationChannelSlack:
+		payload = slackMessage{Text: fmt.Sprintf("*%s*\n%s", event.Title, event.Message)}
+	case portainer.NotificationChannelTeams:
+		payload = teamsMessage{
+			Type:       "MessageCard",
+			Context:    "http://schema.org/extensions",
+			Summary:    event.Title,
+			ThemeColor: "D70000",
+			Title:      event.Title,
+			Text:       event.Message,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported notification channel type %d", channel.Type)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if channel.Type == portainer.NotificationChannelWebhook {
+		req.Header.Set(EventHeader, string(event.Type))
+
+		if channel.Secret != "" {
+			req.Header.Set(SignatureHeader, "sha256="+Sign(body, channel.Secret))
+		}
+	}
+
+	return req, nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the body, receivers can use it to check the origin of a message
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,176 @@
+package notifications
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/scheduler"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultRetryDelay  = 2 * time.Second
+	requestTimeout     = 10 * time.Second
+
+	// deliveryLogRetention is the duration the delivery log entries are kept for
+	deliveryLogRetention = 30 * 24 * time.Hour
+)
+
+// Service sends the events raised by Portainer to the notification channels subscribed to them
+type Service struct {
+	dataStore   dataservices.DataStore
+	client      *http.Client
+	maxAttempts int
+	retryDelay  time.Duration
+}
+
+// NewService creates a new instance of the notification service
+func NewService(dataStore dataservices.DataStore) *Service {
+	return &Service{
+		dataStore:   dataStore,
+		client:      &http.Client{Timeout: requestTimeout},
+		maxAttempts: defaultMaxAttempts,
+		retryDelay:  defaultRetryDelay,
+	}
+}
+
+// StartDeliveryLogCleanup periodically removes the outdated entries of the delivery log
+func (service *Service) StartDeliveryLogCleanup(scheduler *scheduler.Scheduler) {
+	scheduler.StartJobEvery(24*time.Hour, func() error {
+		err := service.dataStore.NotificationDelivery().DeleteNotificationDeliveriesBefore(time.Now().Add(-deliveryLogRetention).Unix())
+		if err != nil {
+			log.Warn().Err(err).Msg("unable to clean up the notification delivery log")
+		}
+
+		return nil
+	})
+}
+
+// Notify sends the event to every enabled channel subscribed to its type.
+// The event is sent in the background, the outcome is recorded in the delivery log.
+func (service *Service) Notify(event portainer.NotificationEvent) {
+	if event.Timestamp == 0 {
+		event.Timestamp = time.Now().Unix()
+	}
+
+	go service.dispatch(event)
+}
+
+func (service *Service) dispatch(event portainer.NotificationEvent) {
+	channels, err := service.dataStore.NotificationChannel().NotificationChannels()
+	if err != nil {
+		log.Error().Err(err).Str("event", string(event.Type)).Msg("unable to retrieve the notification channels")
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range channels {
+		channel := &channels[i]
+		if !channel.Enabled || !IsSubscribed(channel, event.Type) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.Send(channel, event)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// Send delivers the event to the channel, retrying on network and server errors, and records the delivery
+func (service *Service) Send(channel *portainer.NotificationChannel, event portainer.NotificationEvent) *portainer.NotificationDelivery {
+	delivery := &portainer.NotificationDelivery{
+		ChannelID: channel.ID,
+		EventType: event.Type,
+		Timestamp: time.Now().Unix(),
+	}
+
+	for attempt := 1; attempt <= service.maxAttempts; attempt++ {
+		delivery.Attempts = attempt
+
+		retry := service.send(channel, event, delivery)
+		if delivery.Success || !retry {
+			break
+		}
+
+		if attempt < service.maxAttempts {
+			time.Sleep(service.retryDelay * time.Duration(1<<(attempt-1)))
+		}
+	}
+
+	if !delivery.Success {
+		log.Warn().
+			Int("channel_id", int(channel.ID)).
+			Str("event", string(event.Type)).
+			Str("error", delivery.Error).
+			Msg("unable to send the notification")
+	}
+
+	err := service.dataStore.NotificationDelivery().Create(delivery)
+	if err != nil {
+		log.Warn().Err(err).Int("channel_id", int(channel.ID)).Msg("unable to persist the notification delivery")
+	}
+
+	return delivery
+}
+
+// send makes a single attempt, it returns whether a failed attempt can be retried
+func (service *Service) send(channel *portainer.NotificationChannel, event portainer.NotificationEvent, delivery *portainer.NotificationDelivery) bool {
+	req, err := buildRequest(channel, event)
+	if err != nil {
+		delivery.Error = err.Error()
+		return false
+	}
+
+	resp, err := service.client.Do(req)
+	if err != nil {
+		delivery.StatusCode = 0
+		delivery.Error = err.Error()
+		return true
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.Success = true
+		delivery.Error = ""
+		return false
+	}
+
+	delivery.Error = fmt.Sprintf("unexpected status code %d", resp.StatusCode)
+
+	return resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+}
+
+// IsSubscribed returns whether the channel is subscribed to the event type
+func IsSubscribed(channel *portainer.NotificationChannel, eventType portainer.NotificationEventType) bool {
+	for _, subscribed := range channel.EventTypes {
+		if subscribed == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsValidEventType returns whether the event type is raised by Portainer
+func IsValidEventType(eventType portainer.NotificationEventType) bool {
+	switch eventType {
+	case portainer.NotificationEventEndpointDown, portainer.NotificationEventEdgeStackError, portainer.NotificationEventStackAutoUpdateFailure,
+		portainer.NotificationEventAPIKeyExpiring:
+		return true
+	}
+
+	return false
+}
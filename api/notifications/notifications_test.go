@@ -0,0 +1,167 @@
+package notifications
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	rcv := &receiver{statuses: statuses}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		rcv.mu.Lock()
+		defer rcv.mu.Unlock()
+
+		rcv.requests = append(rcv.requests, r)
+		rcv.bodies = append(rcv.bodies, body)
+
+		status := http.StatusOK
+		if len(rcv.statuses) > 0 {
+			status = rcv.statuses[0]
+			rcv.statuses = rcv.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return rcv, srv
+}
+
+func newTestService(t *testing.T) (*Service, func()) {
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+
+	service := NewService(store)
+	service.retryDelay = time.Millisecond
+
+	return service, teardown
+}
+
+var testEvent = portainer.NotificationEvent{
+	Type:       portainer.NotificationEventEndpointDown,
+	Timestamp:  1672671845,
+	Title:      "Environment down",
+	Message:    "Environment local is unreachable",
+	EndpointID: 1,
+}
+
+func Test_Send_genericWebhookIsSigned(t *testing.T) {
+	is := assert.New(t)
+
+	service, teardown := newTestService(t)
+	defer teardown()
+
+	rcv, srv := newReceiver(t)
+	channel := &portainer.NotificationChannel{ID: 1, Type: portainer.NotificationChannelWebhook, URL: srv.URL, Secret: "secret"}
+
+	delivery := service.Send(channel, testEvent)
+	is.True(delivery.Success)
+	is.Equal(1, delivery.Attempts)
+	is.Equal(http.StatusOK, delivery.StatusCode)
+
+	is.Len(rcv.requests, 1)
+	is.Equal("sha256="+Sign(rcv.bodies[0], "secret"), rcv.requests[0].Header.Get(SignatureHeader))
+	is.Equal(string(portainer.NotificationEventEndpointDown), rcv.requests[0].Header.Get(EventHeader))
+
+	var event portainer.NotificationEvent
+	is.NoError(json.Unmarshal(rcv.bodies[0], &event))
+	is.Equal(testEvent, event)
+
+	deliveries, err := service.dataStore.NotificationDelivery().NotificationDeliveries()
+	is.NoError(err)
+	is.Len(deliveries, 1, "the delivery should be recorded")
+}
+
+func Test_Send_retriesOnServerErrors(t *testing.T) {
+	is := assert.New(t)
+
+	service, teardown := newTestService(t)
+	defer teardown()
+
+	rcv, srv := newReceiver(t, http.StatusBadGateway, http.StatusServiceUnavailable)
+	channel := &portainer.NotificationChannel{ID: 1, Type: portainer.NotificationChannelSlack, URL: srv.URL}
+
+	delivery := service.Send(channel, testEvent)
+	is.True(delivery.Success)
+	is.Equal(3, delivery.Attempts)
+	is.Len(rcv.requests, 3)
+}
+
+func Test_Send_doesNotRetryOnClientErrors(t *testing.T) {
+	is := assert.New(t)
+
+	service, teardown := newTestService(t)
+	defer teardown()
+
+	rcv, srv := newReceiver(t, http.StatusNotFound)
+	channel := &portainer.NotificationChannel{ID: 1, Type: portainer.NotificationChannelTeams, URL: srv.URL}
+
+	delivery := service.Send(channel, testEvent)
+	is.False(delivery.Success)
+	is.Equal(1, delivery.Attempts)
+	is.Equal(http.StatusNotFound, delivery.StatusCode)
+	is.NotEmpty(delivery.Error)
+	is.Len(rcv.requests, 1)
+}
+
+func Test_buildRequest_formats(t *testing.T) {
+	is := assert.New(t)
+
+	req, err := buildRequest(&portainer.NotificationChannel{Type: portainer.NotificationChannelSlack, URL: "http://slack"}, testEvent)
+	is.NoError(err)
+	body, _ := io.ReadAll(req.Body)
+	is.JSONEq(`{"text":"*Environment down*\nEnvironment local is unreachable"}`, string(body))
+
+	req, err = buildRequest(&portainer.NotificationChannel{Type: portainer.NotificationChannelTeams, URL: "http://teams"}, testEvent)
+	is.NoError(err)
+	body, _ = io.ReadAll(req.Body)
+
+	var card map[string]string
+	is.NoError(json.Unmarshal(body, &card))
+	is.Equal("MessageCard", card["@type"])
+	is.Equal("Environment down", card["title"])
+	is.Equal("Environment local is unreachable", card["text"])
+}
+
+func Test_dispatch_onlySendsToSubscribedChannels(t *testing.T) {
+	is := assert.New(t)
+
+	service, teardown := newTestService(t)
+	defer teardown()
+
+	subscribed, subscribedSrv := newReceiver(t)
+	other, otherSrv := newReceiver(t)
+	disabled, disabledSrv := newReceiver(t)
+
+	channels := []*portainer.NotificationChannel{
+		{Type: portainer.NotificationChannelWebhook, URL: subscribedSrv.URL, Enabled: true, EventTypes: []portainer.NotificationEventType{portainer.NotificationEventEndpointDown}},
+		{Type: portainer.NotificationChannelWebhook, URL: otherSrv.URL, Enabled: true, EventTypes: []portainer.NotificationEventType{portainer.NotificationEventEdgeStackError}},
+		{Type: portainer.NotificationChannelWebhook, URL: disabledSrv.URL, Enabled: false, EventTypes: []portainer.NotificationEventType{portainer.NotificationEventEndpointDown}},
+	}
+	for _, channel := range channels {
+		is.NoError(service.dataStore.NotificationChannel().Create(channel))
+	}
+
+	service.dispatch(testEvent)
+
+	is.Len(subscribed.requests, 1)
+	is.Empty(other.requests)
+	is.Empty(disabled.requests)
+}
@@ -2,6 +2,7 @@ package binary
 
 import (
 	"bytes"
+	"os"
 	"os/exec"
 	"path"
 	"runtime"
@@ -21,21 +22,30 @@ func NewHelmBinaryPackageManager(binaryPath string) *helmBinaryPackageManager {
 }
 
 // runWithKubeConfig will execute run against the provided Kubernetes cluster with kubeconfig as cli arguments.
-func (hbpm *helmBinaryPackageManager) runWithKubeConfig(command string, args []string, kca *options.KubernetesClusterAccess) ([]byte, error) {
+// The in-cluster configuration is used when no cluster server URL is provided.
+func (hbpm *helmBinaryPackageManager) runWithKubeConfig(command string, args []string, kca *options.KubernetesClusterAccess, env ...string) ([]byte, error) {
 	cmdArgs := make([]string, 0)
 	if kca != nil {
-		cmdArgs = append(cmdArgs, "--kube-apiserver", kca.ClusterServerURL)
+		if kca.ClusterServerURL != "" {
+			cmdArgs = append(cmdArgs, "--kube-apiserver", kca.ClusterServerURL)
+		}
 		cmdArgs = append(cmdArgs, "--kube-token", kca.AuthToken)
-		cmdArgs = append(cmdArgs, "--kube-ca-file", kca.CertificateAuthorityFile)
+		if kca.CertificateAuthorityFile != "" {
+			cmdArgs = append(cmdArgs, "--kube-ca-file", kca.CertificateAuthorityFile)
+		}
+		if kca.InsecureSkipTLSVerify {
+			cmdArgs = append(cmdArgs, "--kube-insecure-skip-tls-verify")
+		}
 	}
 	cmdArgs = append(cmdArgs, args...)
-	return hbpm.run(command, cmdArgs)
+	return hbpm.run(command, cmdArgs, env...)
 }
 
 // run will execute helm command against the provided Kubernetes cluster.
 // The endpointId and authToken are dynamic params (based on the user) that allow helm to execute commands
 // in the context of the current user against specified k8s cluster.
-func (hbpm *helmBinaryPackageManager) run(command string, args []string) ([]byte, error) {
+// The env is added to the environment of the helm process.
+func (hbpm *helmBinaryPackageManager) run(command string, args []string, env ...string) ([]byte, error) {
 	cmdArgs := make([]string, 0)
 	cmdArgs = append(cmdArgs, command)
 	cmdArgs = append(cmdArgs, args...)
@@ -48,6 +58,9 @@ func (hbpm *helmBinaryPackageManager) run(command string, args []string) ([]byte
 	var stderr bytes.Buffer
 	cmd := exec.Command(helmPath, cmdArgs...)
 	cmd.Stderr = &stderr
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 
 	output, err := cmd.Output()
 	if err != nil {
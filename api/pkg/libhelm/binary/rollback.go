@@ -0,0 +1,40 @@
+package binary
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/portainer/portainer/pkg/libhelm/options"
+)
+
+var errRequiredRollbackOptions = errors.New("release name is required")
+
+// Rollback runs `helm rollback <name> [revision] --namespace <namespace>` with specified rollback options.
+// The rollback options translate to CLI arguments which are passed in to the helm binary when executing rollback.
+func (hbpm *helmBinaryPackageManager) Rollback(rollbackOpts options.RollbackOptions) error {
+	if rollbackOpts.Name == "" {
+		return errRequiredRollbackOptions
+	}
+
+	if rollbackOpts.Revision < 0 {
+		return errors.New("revision must be a positive number")
+	}
+
+	args := []string{rollbackOpts.Name}
+	if rollbackOpts.Revision > 0 {
+		args = append(args, strconv.Itoa(rollbackOpts.Revision))
+	}
+	if rollbackOpts.Namespace != "" {
+		args = append(args, "--namespace", rollbackOpts.Namespace)
+	}
+	if rollbackOpts.Wait {
+		args = append(args, "--wait")
+	}
+
+	_, err := hbpm.runWithKubeConfig("rollback", args, rollbackOpts.KubernetesClusterAccess)
+	if err != nil {
+		return errors.Wrap(err, "failed to run helm rollback on specified args")
+	}
+
+	return nil
+}
@@ -5,4 +5,5 @@ type KubernetesClusterAccess struct {
 	ClusterServerURL         string `example:"https://mycompany.k8s.com"`
 	CertificateAuthorityFile string `example:"/data/tls/localhost.crt"`
 	AuthToken                string `example:"ey..."`
+	InsecureSkipTLSVerify    bool
 }
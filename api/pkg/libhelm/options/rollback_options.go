@@ -0,0 +1,11 @@
+package options
+
+// RollbackOptions are portainer supported options for `helm rollback`
+type RollbackOptions struct {
+	Name string
+	// Revision is the release revision to roll back to, 0 rolls back to the previous revision
+	Revision                int
+	Namespace               string
+	Wait                    bool
+	KubernetesClusterAccess *KubernetesClusterAccess
+}
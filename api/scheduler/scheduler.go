@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cloudogu/portainer-ce/api/metrics"
 	"github.com/pkg/errors"
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
@@ -82,25 +83,46 @@ func (s *Scheduler) StopJob(jobID string) error {
 // Returns job id that could be used to stop the given job.
 // When job run returns an error, that job won't be run again.
 func (s *Scheduler) StartJobEvery(duration time.Duration, job func() error) string {
+	return s.startJob(cron.Every(duration), job)
+}
+
+// StartJobWithCronExpression schedules a new job following a standard cron expression (e.g. "0 2 * * *").
+// Returns job id that could be used to stop the given job.
+// When job run returns an error, that job won't be run again.
+func (s *Scheduler) StartJobWithCronExpression(expression string, job func() error) (string, error) {
+	schedule, err := cron.ParseStandard(expression)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse cron expression %q", expression)
+	}
+
+	return s.startJob(schedule, job), nil
+}
+
+func (s *Scheduler) startJob(schedule cron.Schedule, job func() error) string {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	j := cron.FuncJob(func() {
-		if err := job(); err != nil {
+		err := job()
+		metrics.SchedulerJobRuns.WithLabelValues(metrics.Outcome(err)).Inc()
+		if err != nil {
 			log.Debug().Msg("job returned an error")
 			cancel()
 		}
 	})
 
-	entryID := s.crontab.Schedule(cron.Every(duration), j)
+	entryID := s.crontab.Schedule(schedule, j)
 
 	s.mu.Lock()
 	s.activeJobs[entryID] = cancel
 	s.mu.Unlock()
 
+	metrics.SchedulerJobs.Inc()
+
 	go func(entryID cron.EntryID) {
 		<-ctx.Done()
 		log.Debug().Msg("job cancelled, stopping")
 		s.crontab.Remove(entryID)
+		metrics.SchedulerJobs.Dec()
 	}(entryID)
 
 	return strconv.Itoa(int(entryID))
@@ -118,3 +118,18 @@ func Test_StartJobEvery_Concurrently(t *testing.T) {
 
 	<-ctx.Done()
 }
+
+func Test_StartJobWithCronExpression(t *testing.T) {
+	s := NewScheduler(context.Background())
+	defer s.Shutdown()
+
+	job := func() error { return nil }
+
+	_, err := s.StartJobWithCronExpression("not a cron expression", job)
+	assert.Error(t, err, "an invalid expression should be rejected")
+
+	jobID, err := s.StartJobWithCronExpression("0 2 * * *", job)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, jobID)
+	assert.NoError(t, s.StopJob(jobID))
+}
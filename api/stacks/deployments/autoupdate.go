@@ -1,6 +1,8 @@
 package deployments
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
@@ -11,19 +13,43 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func StartAutoupdate(stackID portainer.StackID, interval string, scheduler *scheduler.Scheduler, stackDeployer StackDeployer, datastore dataservices.DataStore, gitService portainer.GitService) (jobID string, e *httperror.HandlerError) {
+func StartAutoupdate(stackID portainer.StackID, interval string, scheduler *scheduler.Scheduler, stackDeployer StackDeployer, datastore dataservices.DataStore, gitService portainer.GitService, notificationService portainer.NotificationService) (jobID string, e *httperror.HandlerError) {
 	d, err := time.ParseDuration(interval)
 	if err != nil {
 		return "", httperror.BadRequest("Unable to parse stack's auto update interval", err)
 	}
 
 	jobID = scheduler.StartJobEvery(d, func() error {
-		return RedeployWhenChanged(stackID, stackDeployer, datastore, gitService)
+		return RedeployAndNotify(stackID, stackDeployer, datastore, gitService, notificationService)
 	})
 
 	return jobID, nil
 }
 
+// RedeployAndNotify redeploys the stack when its git repository changed and notifies about a failed update.
+// The error of the redeployment is returned unchanged.
+func RedeployAndNotify(stackID portainer.StackID, stackDeployer StackDeployer, datastore dataservices.DataStore, gitService portainer.GitService, notificationService portainer.NotificationService) error {
+	err := RedeployWhenChanged(stackID, stackDeployer, datastore, gitService)
+	if err == nil || notificationService == nil {
+		return err
+	}
+
+	stackName := strconv.Itoa(int(stackID))
+	if stack, stackErr := datastore.Stack().Stack(stackID); stackErr == nil {
+		stackName = stack.Name
+	}
+
+	notificationService.Notify(portainer.NotificationEvent{
+		Type:         portainer.NotificationEventStackAutoUpdateFailure,
+		Title:        fmt.Sprintf("Automatic update of the stack %s failed", stackName),
+		Message:      err.Error(),
+		ResourceID:   strconv.Itoa(int(stackID)),
+		ResourceName: stackName,
+	})
+
+	return err
+}
+
 func StopAutoupdate(stackID portainer.StackID, jobID string, scheduler *scheduler.Scheduler) {
 	if jobID == "" {
 		return
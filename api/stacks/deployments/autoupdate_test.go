@@ -0,0 +1,39 @@
+package deployments
+
+import (
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingNotifier struct {
+	events []portainer.NotificationEvent
+}
+
+func (n *recordingNotifier) Notify(event portainer.NotificationEvent) {
+	n.events = append(n.events, event)
+}
+
+func Test_RedeployAndNotify_notifiesFailures(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	err := store.Stack().Create(&portainer.Stack{ID: 1, Name: "web", CreatedBy: "missing", GitConfig: nil})
+	is.NoError(err, "failed to create a stack")
+
+	notifier := &recordingNotifier{}
+
+	err = RedeployAndNotify(1, nil, store, nil, notifier)
+	is.NoError(err)
+	is.Empty(notifier.events, "a successful update should not be notified")
+
+	err = RedeployAndNotify(2, nil, store, nil, notifier)
+	is.Error(err)
+	is.Len(notifier.events, 1)
+	is.Equal(portainer.NotificationEventStackAutoUpdateFailure, notifier.events[0].Type)
+	is.Equal("2", notifier.events[0].ResourceID)
+}
@@ -2,12 +2,17 @@ package deployments
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/git"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/cloudogu/portainer-ce/api/http/security"
+	"github.com/cloudogu/portainer-ce/api/stacks/stackutils"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -53,9 +58,9 @@ func RedeployWhenChanged(stackID portainer.StackID, deployer StackDeployer, data
 		return &StackAuthorMissingErr{int(stack.ID), author}
 	}
 
-	username, password := "", ""
-	if stack.GitConfig.Authentication != nil {
-		username, password = stack.GitConfig.Authentication.Username, stack.GitConfig.Authentication.Password
+	username, password, err := git.GetCredentials(stack.GitConfig.Authentication, stack.GitConfig.URL, datastore.GitCredential())
+	if err != nil {
+		return errors.WithMessagef(err, "failed to resolve the git credentials of the stack %v", stack.ID)
 	}
 
 	newHash, err := gitService.LatestCommitID(stack.GitConfig.URL, stack.GitConfig.ReferenceName, username, password)
@@ -79,7 +84,39 @@ func RedeployWhenChanged(stackID portainer.StackID, deployer StackDeployer, data
 		}
 	}
 
-	if err := cloneGitRepository(gitService, cloneParams); err != nil {
+	if stack.GitConfig.PathFilter != nil {
+		changedFiles, err := cloneWhenWatchedFilesChanged(stack, gitService, cloneParams)
+		if err != nil {
+			return err
+		}
+
+		if len(changedFiles) == 0 {
+			log.Debug().
+				Int("stack_id", int(stackID)).
+				Str("commit", newHash).
+				Msg("no watched file changed, skipping the redeploy")
+
+			stack.GitConfig.ConfigHash = newHash
+			if err := datastore.Stack().UpdateStack(stack.ID, stack); err != nil {
+				return errors.WithMessagef(err, "failed to update the stack %v", stack.ID)
+			}
+
+			return nil
+		}
+
+		log.Info().
+			Int("stack_id", int(stackID)).
+			Str("commit", newHash).
+			Strs("files", changedFiles).
+			Msg("watched files changed, redeploying the stack")
+
+		stack.GitConfig.LastRedeployTrigger = &gittypes.RedeployTrigger{
+			FromHash:     stack.GitConfig.ConfigHash,
+			ToHash:       newHash,
+			ChangedFiles: changedFiles,
+			Date:         time.Now().Unix(),
+		}
+	} else if err := cloneGitRepository(gitService, cloneParams); err != nil {
 		return errors.WithMessagef(err, "failed to do a fresh clone of the stack %v", stack.ID)
 	}
 
@@ -123,6 +160,83 @@ func RedeployWhenChanged(stackID portainer.StackID, deployer StackDeployer, data
 		return errors.WithMessagef(err, "failed to update the stack %v", stack.ID)
 	}
 
+	if err := RecordStackVersion(datastore, stack, author); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to record the stack version")
+	}
+
+	return nil
+}
+
+// RedeployOptions represents the options used when redeploying a stack
+type RedeployOptions struct {
+	// Pull the images before the deployment
+	PullImage bool
+	// Image tags to deploy, indexed by service name. Only supported by compose and swarm stacks
+	ImageTags map[string]string
+}
+
+// RedeployStack redeploys the current files of a stack, using the registries its author can access
+func RedeployStack(stack *portainer.Stack, options RedeployOptions, deployer StackDeployer, datastore dataservices.DataStore) error {
+	author := stack.UpdatedBy
+	if author == "" {
+		author = stack.CreatedBy
+	}
+
+	user, err := datastore.User().UserByUsername(author)
+	if err != nil {
+		return &StackAuthorMissingErr{int(stack.ID), author}
+	}
+
+	endpoint, err := datastore.Endpoint().Endpoint(stack.EndpointID)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to find the environment %v associated to the stack %v", stack.EndpointID, stack.ID)
+	}
+
+	registries, err := getUserRegistries(datastore, user, endpoint.ID)
+	if err != nil {
+		return err
+	}
+
+	switch stack.Type {
+	case portainer.DockerComposeStack, portainer.DockerSwarmStack:
+		deployedStack, cleanup, err := WithImageTagOverrides(stack, options.ImageTags)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to apply the image tags of the stack %v", stack.ID)
+		}
+		defer cleanup()
+
+		if stack.Type == portainer.DockerComposeStack {
+			err = deployer.DeployComposeStack(deployedStack, endpoint, registries, options.PullImage, false)
+			stack.DeploymentStatus = deployedStack.DeploymentStatus
+		} else {
+			prune := stack.Option != nil && stack.Option.Prune
+			err = deployer.DeploySwarmStack(deployedStack, endpoint, registries, prune, options.PullImage)
+		}
+		if err != nil {
+			return errors.WithMessagef(err, "failed to redeploy the stack %v", stack.ID)
+		}
+	case portainer.KubernetesStack:
+		if len(options.ImageTags) > 0 {
+			return errors.New("image tags cannot be overridden for kubernetes stacks")
+		}
+
+		err := deployer.DeployKubernetesStack(stack, endpoint, user)
+		if err != nil {
+			return errors.WithMessagef(err, "failed to redeploy the kubernetes stack %v", stack.ID)
+		}
+	default:
+		return errors.Errorf("cannot redeploy stack, type %v is unsupported", stack.Type)
+	}
+
+	stack.UpdateDate = time.Now().Unix()
+	if err := datastore.Stack().UpdateStack(stack.ID, stack); err != nil {
+		return errors.WithMessagef(err, "failed to update the stack %v", stack.ID)
+	}
+
+	if err := RecordStackVersion(datastore, stack, author); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to record the stack version")
+	}
+
 	return nil
 }
 
@@ -163,6 +277,42 @@ type gitAuth struct {
 	password string
 }
 
+// cloneWhenWatchedFilesChanged clones the repository of a stack scoped by a path filter aside of its project,
+// and replaces the project with the clone only when some watched files changed.
+// It returns the watched files which changed.
+func cloneWhenWatchedFilesChanged(stack *portainer.Stack, gitService portainer.GitService, cloneParams *cloneRepositoryParameters) ([]string, error) {
+	cloneDir, err := os.MkdirTemp(filepath.Dir(stack.ProjectPath), filepath.Base(stack.ProjectPath)+"-")
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to create the clone directory of the stack %v", stack.ID)
+	}
+	defer os.RemoveAll(cloneDir)
+
+	params := *cloneParams
+	params.toDir = cloneDir
+	if err := cloneGitRepository(gitService, &params); err != nil {
+		return nil, errors.WithMessagef(err, "failed to do a fresh clone of the stack %v", stack.ID)
+	}
+
+	changedFiles, err := stackutils.ChangedWatchedFiles(stack, stack.ProjectPath, cloneDir)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to compare the watched files of the stack %v", stack.ID)
+	}
+
+	if len(changedFiles) == 0 {
+		return nil, nil
+	}
+
+	if err := os.RemoveAll(stack.ProjectPath); err != nil {
+		return nil, errors.WithMessagef(err, "failed to remove the previous files of the stack %v", stack.ID)
+	}
+
+	if err := os.Rename(cloneDir, stack.ProjectPath); err != nil {
+		return nil, errors.WithMessagef(err, "failed to move the fresh clone of the stack %v", stack.ID)
+	}
+
+	return changedFiles, nil
+}
+
 func cloneGitRepository(gitService portainer.GitService, cloneParams *cloneRepositoryParameters) error {
 	if cloneParams.auth != nil {
 		return gitService.CloneRepository(cloneParams.toDir, cloneParams.url, cloneParams.ref, cloneParams.auth.username, cloneParams.auth.password)
@@ -0,0 +1,116 @@
+package deployments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// checkoutGitService clones a repository by writing the files of its checkout
+type checkoutGitService struct {
+	gitService
+	files map[string]string
+}
+
+func (g *checkoutGitService) CloneRepository(destination, repositoryURL, referenceName, username, password string) error {
+	return writeCheckout(destination, g.files)
+}
+
+type countingDeployer struct {
+	noopDeployer
+	deployments int
+}
+
+func (d *countingDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage bool, forceRereate bool) error {
+	d.deployments++
+	return nil
+}
+
+func writeCheckout(dir string, files map[string]string) error {
+	for file, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func Test_redeployWhenChanged_PathFilter(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	projectPath := filepath.Join(t.TempDir(), "1")
+
+	files := map[string]string{
+		"app/docker-compose.yml":   "services: {}",
+		"shared/app.env":           "A=1",
+		"other/docker-compose.yml": "services: {}",
+	}
+	is.NoError(writeCheckout(projectPath, files))
+
+	is.NoError(store.Endpoint().Create(&portainer.Endpoint{ID: 1}))
+	is.NoError(store.User().Create(&portainer.User{Username: "user", Role: portainer.AdministratorRole}))
+
+	is.NoError(store.Stack().Create(&portainer.Stack{
+		ID:          1,
+		Type:        portainer.DockerComposeStack,
+		EndpointID:  1,
+		ProjectPath: projectPath,
+		UpdatedBy:   "user",
+		GitConfig: &gittypes.RepoConfig{
+			URL:            "url",
+			ReferenceName:  "ref",
+			ConfigFilePath: "app/docker-compose.yml",
+			ConfigHash:     "hash1",
+			PathFilter:     &gittypes.PathFilter{Globs: []string{"shared/*.env"}},
+		}}))
+
+	t.Run("unrelated changes do not redeploy the stack", func(t *testing.T) {
+		deployer := &countingDeployer{}
+		files["other/docker-compose.yml"] = "services: {web: {}}"
+
+		err := RedeployWhenChanged(1, deployer, store, &checkoutGitService{gitService{nil, "hash2"}, files})
+		is.NoError(err)
+		is.Equal(0, deployer.deployments)
+
+		stack, err := store.Stack().Stack(1)
+		is.NoError(err)
+		is.Equal("hash2", stack.GitConfig.ConfigHash, "the new commit should be recorded")
+		is.Nil(stack.GitConfig.LastRedeployTrigger)
+	})
+
+	t.Run("changes of the watched files redeploy the stack", func(t *testing.T) {
+		deployer := &countingDeployer{}
+		files["shared/app.env"] = "A=2"
+
+		err := RedeployWhenChanged(1, deployer, store, &checkoutGitService{gitService{nil, "hash3"}, files})
+		is.NoError(err)
+		is.Equal(1, deployer.deployments)
+
+		stack, err := store.Stack().Stack(1)
+		is.NoError(err)
+		is.Equal("hash3", stack.GitConfig.ConfigHash)
+		if is.NotNil(stack.GitConfig.LastRedeployTrigger) {
+			is.Equal("hash2", stack.GitConfig.LastRedeployTrigger.FromHash)
+			is.Equal("hash3", stack.GitConfig.LastRedeployTrigger.ToHash)
+			is.Equal([]string{"shared/app.env"}, stack.GitConfig.LastRedeployTrigger.ChangedFiles)
+		}
+
+		content, err := os.ReadFile(filepath.Join(projectPath, "shared", "app.env"))
+		is.NoError(err)
+		is.Equal("A=2", string(content), "the project should be replaced by the fresh clone")
+	})
+}
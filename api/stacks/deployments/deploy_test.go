@@ -2,6 +2,8 @@ package deployments
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -248,3 +250,55 @@ func Test_getUserRegistries(t *testing.T) {
 		assert.ElementsMatch(t, []portainer.Registry{registryReachableByUser, registryReachableByTeam}, registries)
 	})
 }
+
+type recordingDeployer struct {
+	noopDeployer
+	composeStack *portainer.Stack
+	pullImage    bool
+}
+
+func (d *recordingDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, forcePullImage bool, forceRereate bool) error {
+	d.composeStack = stack
+	d.pullImage = forcePullImage
+	return nil
+}
+
+func Test_RedeployStack(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	tmpDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tmpDir, "docker-compose.yml"), []byte("services:\n  web:\n    image: nginx:1.0\n"), 0600)
+	is.NoError(err)
+
+	err = store.User().Create(&portainer.User{ID: 1, Username: "admin", Role: portainer.AdministratorRole})
+	is.NoError(err, "error creating an admin")
+
+	err = store.Endpoint().Create(&portainer.Endpoint{ID: 1})
+	is.NoError(err, "error creating environment")
+
+	stack := &portainer.Stack{
+		ID:          1,
+		CreatedBy:   "admin",
+		EndpointID:  1,
+		ProjectPath: tmpDir,
+		EntryPoint:  "docker-compose.yml",
+		Type:        portainer.DockerComposeStack,
+	}
+	err = store.Stack().Create(stack)
+	is.NoError(err, "failed to create a test stack")
+
+	deployer := &recordingDeployer{}
+	err = RedeployStack(stack, RedeployOptions{PullImage: true, ImageTags: map[string]string{"web": "2.0"}}, deployer, store)
+	is.NoError(err)
+
+	is.True(deployer.pullImage)
+	is.Equal([]string{ImageOverrideFileName}, deployer.composeStack.AdditionalFiles)
+	is.Empty(stack.AdditionalFiles, "the image override should not be persisted")
+
+	err = RedeployStack(&portainer.Stack{ID: 2, CreatedBy: "unknown", EndpointID: 1}, RedeployOptions{}, deployer, store)
+	var authorErr *StackAuthorMissingErr
+	is.ErrorAs(err, &authorErr)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
 	k "github.com/cloudogu/portainer-ce/api/kubernetes"
 )
 
@@ -21,15 +22,22 @@ type stackDeployer struct {
 	swarmStackManager   portainer.SwarmStackManager
 	composeStackManager portainer.ComposeStackManager
 	kubernetesDeployer  portainer.KubernetesDeployer
+	dataStore           dataservices.DataStore
+	dockerClientFactory DockerClientFactory
+	healthChecks        *healthChecks
 }
 
-// NewStackDeployer inits a stackDeployer struct with a SwarmStackManager, a ComposeStackManager and a KubernetesDeployer
-func NewStackDeployer(swarmStackManager portainer.SwarmStackManager, composeStackManager portainer.ComposeStackManager, kubernetesDeployer portainer.KubernetesDeployer) *stackDeployer {
+// NewStackDeployer inits a stackDeployer struct with a SwarmStackManager, a ComposeStackManager and a KubernetesDeployer.
+// The data store and the Docker client factory are used to check the health of the compose stacks deployed with a deploy policy.
+func NewStackDeployer(swarmStackManager portainer.SwarmStackManager, composeStackManager portainer.ComposeStackManager, kubernetesDeployer portainer.KubernetesDeployer, dataStore dataservices.DataStore, dockerClientFactory DockerClientFactory) *stackDeployer {
 	return &stackDeployer{
 		lock:                &sync.Mutex{},
 		swarmStackManager:   swarmStackManager,
 		composeStackManager: composeStackManager,
 		kubernetesDeployer:  kubernetesDeployer,
+		dataStore:           dataStore,
+		dockerClientFactory: dockerClientFactory,
+		healthChecks:        &healthChecks{cancel: make(map[portainer.StackID]*healthCheck)},
 	}
 }
 
@@ -47,6 +55,10 @@ func (d *stackDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *por
 	d.lock.Lock()
 	defer d.lock.Unlock()
 
+	// the health check of the previous deployment no longer applies
+	d.healthChecks.stop(stack.ID)
+	stack.DeploymentStatus = nil
+
 	d.swarmStackManager.Login(registries, endpoint)
 	defer d.swarmStackManager.Logout(endpoint)
 
@@ -61,8 +73,14 @@ func (d *stackDeployer) DeployComposeStack(stack *portainer.Stack, endpoint *por
 	err := d.composeStackManager.Up(context.TODO(), stack, endpoint, forceRereate)
 	if err != nil {
 		d.composeStackManager.Down(context.TODO(), stack, endpoint)
+		return err
+	}
+
+	if stack.Option != nil && stack.Option.DeployPolicy != nil {
+		d.startComposeStackHealthCheck(stack, endpoint, registries)
 	}
-	return err
+
+	return nil
 }
 
 func (d *stackDeployer) DeployKubernetesStack(stack *portainer.Stack, endpoint *portainer.Endpoint, user *portainer.User) error {
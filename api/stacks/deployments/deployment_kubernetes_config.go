@@ -3,6 +3,7 @@ package deployments
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
 	"github.com/cloudogu/portainer-ce/api/filesystem"
@@ -36,9 +37,9 @@ func (config *KubernetesStackDeploymentConfig) GetUsername() string {
 }
 
 func (config *KubernetesStackDeploymentConfig) Deploy() error {
-	fileNames := stackutils.GetStackFilePaths(config.stack, false)
-
-	manifestFilePaths := make([]string, len(fileNames))
+	if config.stack.HelmChart != nil {
+		return config.deployHelmChart()
+	}
 
 	tmpDir, err := os.MkdirTemp("", "kub_deployment")
 	if err != nil {
@@ -47,42 +48,190 @@ func (config *KubernetesStackDeploymentConfig) Deploy() error {
 
 	defer os.RemoveAll(tmpDir)
 
+	manifestFilePaths, err := config.writeManifests(tmpDir)
+	if err != nil {
+		return err
+	}
+
+	output, err := config.kuberneteDeployer.Deploy(config.user.ID, config.endpoint, manifestFilePaths, config.stack.Namespace)
+	if err != nil {
+		return fmt.Errorf("failed to deploy kubernete stack: %w", err)
+	}
+
+	config.output = output
+	return nil
+}
+
+// Preview returns the differences between the live resources and the resources the deployment would apply,
+// computed by a server-side dry-run apply
+func (config *KubernetesStackDeploymentConfig) Preview() (string, error) {
+	if config.stack.HelmChart != nil {
+		return config.previewHelmChart()
+	}
+
+	tmpDir, err := os.MkdirTemp("", "kub_preview")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temp kub preview directory")
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	manifestFilePaths, err := config.writeManifests(tmpDir)
+	if err != nil {
+		return "", err
+	}
+
+	diff, err := config.kuberneteDeployer.Diff(config.user.ID, config.endpoint, manifestFilePaths, config.stack.Namespace)
+	if err != nil {
+		return "", fmt.Errorf("failed to preview kubernetes stack: %w", err)
+	}
+
+	return diff, nil
+}
+
+// writeManifests writes the labelled manifests of the stack inside dir, converting the compose files
+// or rendering the kustomization, and returns their paths
+func (config *KubernetesStackDeploymentConfig) writeManifests(dir string) ([]string, error) {
+	if config.stack.Kustomize != nil {
+		manifestFilePath, err := config.renderKustomization(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{manifestFilePath}, nil
+	}
+
+	fileNames := stackutils.GetStackFilePaths(config.stack, false)
+
+	manifestFilePaths := make([]string, 0, len(fileNames))
 	for _, fileName := range fileNames {
-		manifestFilePath := filesystem.JoinPaths(tmpDir, fileName)
+		manifestFilePath := filesystem.JoinPaths(dir, fileName)
 		manifestContent, err := os.ReadFile(filesystem.JoinPaths(config.stack.ProjectPath, fileName))
 		if err != nil {
-			return errors.Wrap(err, "failed to read manifest file")
+			return nil, errors.Wrap(err, "failed to read manifest file")
 		}
 
 		if config.stack.IsComposeFormat {
 			manifestContent, err = config.kuberneteDeployer.ConvertCompose(manifestContent)
 			if err != nil {
-				return errors.Wrap(err, "failed to convert docker compose file to a kube manifest")
+				return nil, errors.Wrap(err, "failed to convert docker compose file to a kube manifest")
 			}
 		}
 
 		manifestContent, err = k.AddAppLabels(manifestContent, config.appLabels.ToMap())
 		if err != nil {
-			return errors.Wrap(err, "failed to add application labels")
+			return nil, errors.Wrap(err, "failed to add application labels")
 		}
 
 		err = filesystem.WriteToFile(manifestFilePath, []byte(manifestContent))
 		if err != nil {
-			return errors.Wrap(err, "failed to create temp manifest file")
+			return nil, errors.Wrap(err, "failed to create temp manifest file")
 		}
 
 		manifestFilePaths = append(manifestFilePaths, manifestFilePath)
 	}
 
-	output, err := config.kuberneteDeployer.Deploy(config.user.ID, config.endpoint, manifestFilePaths, config.stack.Namespace)
+	return manifestFilePaths, nil
+}
+
+// renderKustomization renders the kustomization of the stack into a single labelled manifest inside dir
+func (config *KubernetesStackDeploymentConfig) renderKustomization(dir string) (string, error) {
+	kustomizationPath := filesystem.JoinPaths(config.stack.ProjectPath, stackutils.GetKustomizationPath(config.stack))
+
+	manifestContent, err := config.kuberneteDeployer.Kustomize(kustomizationPath)
 	if err != nil {
-		return fmt.Errorf("failed to deploy kubernete stack: %w", err)
+		return "", errors.Wrap(err, "failed to render kustomization")
 	}
 
-	config.output = output
+	manifestContent, err = k.AddAppLabels(manifestContent, config.appLabels.ToMap())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to add application labels")
+	}
+
+	manifestFilePath := filesystem.JoinPaths(dir, "kustomization.yml")
+	err = filesystem.WriteToFile(manifestFilePath, manifestContent)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temp manifest file")
+	}
+
+	return manifestFilePath, nil
+}
+
+// deployHelmChart installs or upgrades the release of the stack. The resources of the release are labelled by helm
+// itself, so that the release matches the live resources
+func (config *KubernetesStackDeploymentConfig) deployHelmChart() error {
+	chartPath, valuesFiles := stackutils.GetHelmChartPaths(config.stack)
+
+	manifest, err := config.kuberneteDeployer.DeployHelmChart(config.user.ID, config.endpoint, config.stack.Name, chartPath, valuesFiles, config.stack.Namespace, config.appLabels.ToMap())
+	if err != nil {
+		return fmt.Errorf("failed to deploy helm chart: %w", err)
+	}
+
+	config.output = manifest
 	return nil
 }
 
+// previewHelmChart renders the release of the stack without upgrading it and compares its resources
+// with the live resources
+func (config *KubernetesStackDeploymentConfig) previewHelmChart() (string, error) {
+	chartPath, valuesFiles := stackutils.GetHelmChartPaths(config.stack)
+
+	manifest, err := config.kuberneteDeployer.RenderHelmChart(config.user.ID, config.endpoint, config.stack.Name, chartPath, valuesFiles, config.stack.Namespace, config.appLabels.ToMap())
+	if err != nil {
+		return "", fmt.Errorf("failed to render helm chart: %w", err)
+	}
+
+	diff, err := config.diffHelmResources([]byte(manifest))
+	if err != nil {
+		return "", errors.Wrap(err, "unable to preview the helm release resources")
+	}
+
+	return diff, nil
+}
+
+// diffHelmResources compares the resources of a release manifest individually with the live resources,
+// since the resources of a chart can be deployed to different namespaces
+func (config *KubernetesStackDeploymentConfig) diffHelmResources(manifestContent []byte) (string, error) {
+	resources, err := k.ExtractDocuments(manifestContent, nil)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to extract documents from helm release manifest")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "helm_preview")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create temp helm preview directory")
+	}
+
+	defer os.RemoveAll(tmpDir)
+
+	var output strings.Builder
+	for i, resource := range resources {
+		namespace, err := k.GetNamespace(resource)
+		if err != nil {
+			return "", err
+		}
+
+		if namespace == "" {
+			namespace = config.stack.Namespace
+		}
+
+		manifestFilePath := filesystem.JoinPaths(tmpDir, fmt.Sprintf("resource-%d.yml", i))
+		err = filesystem.WriteToFile(manifestFilePath, resource)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to create temp manifest file")
+		}
+
+		out, err := config.kuberneteDeployer.Diff(config.user.ID, config.endpoint, []string{manifestFilePath}, namespace)
+		if err != nil {
+			return "", err
+		}
+
+		output.WriteString(out)
+	}
+
+	return output.String(), nil
+}
+
 func (config *KubernetesStackDeploymentConfig) GetResponse() string {
 	return config.output
 }
@@ -0,0 +1,174 @@
+package deployments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	k "github.com/cloudogu/portainer-ce/api/kubernetes"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingKubernetesDeployer struct {
+	kustomizedPath  string
+	deployed        string
+	namespaces      []string
+	helmChartPath   string
+	helmValuesFiles []string
+	helmReleaseName string
+	helmLabels      map[string]string
+	diffed          string
+}
+
+func (d *recordingKubernetesDeployer) Deploy(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
+	content, err := os.ReadFile(manifestFiles[0])
+	d.deployed += string(content)
+	d.namespaces = append(d.namespaces, namespace)
+	return "deployed", err
+}
+
+func (d *recordingKubernetesDeployer) Remove(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
+	return "", nil
+}
+
+func (d *recordingKubernetesDeployer) ConvertCompose(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+func (d *recordingKubernetesDeployer) Kustomize(path string) ([]byte, error) {
+	d.kustomizedPath = path
+	return []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"), nil
+}
+
+func (d *recordingKubernetesDeployer) DeployHelmChart(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, chartPath string, valuesFiles []string, namespace string, labels map[string]string) (string, error) {
+	d.helmReleaseName = releaseName
+	d.helmChartPath = chartPath
+	d.helmValuesFiles = valuesFiles
+	d.helmLabels = labels
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n", nil
+}
+
+func (d *recordingKubernetesDeployer) RemoveHelmRelease(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, namespace string) (string, error) {
+	return "", nil
+}
+
+func (d *recordingKubernetesDeployer) Diff(userID portainer.UserID, endpoint *portainer.Endpoint, manifestFiles []string, namespace string) (string, error) {
+	for _, manifestFile := range manifestFiles {
+		content, err := os.ReadFile(manifestFile)
+		if err != nil {
+			return "", err
+		}
+		d.diffed += string(content)
+	}
+
+	d.namespaces = append(d.namespaces, namespace)
+	return "diff", nil
+}
+
+func (d *recordingKubernetesDeployer) RenderHelmChart(userID portainer.UserID, endpoint *portainer.Endpoint, releaseName, chartPath string, valuesFiles []string, namespace string, labels map[string]string) (string, error) {
+	d.helmReleaseName = releaseName
+	d.helmLabels = labels
+	return "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: shared\n  namespace: infra\n", nil
+}
+
+func Test_KubernetesStackDeploymentConfig_DeployRendersKustomization(t *testing.T) {
+	is := assert.New(t)
+
+	stack := &portainer.Stack{
+		ID:          1,
+		Name:        "app",
+		ProjectPath: "/data/compose/1",
+		EntryPoint:  "deploy/kustomization.yaml",
+		Kustomize:   &portainer.KustomizeConfig{Path: "deploy", Overlay: "staging"},
+	}
+
+	deployer := &recordingKubernetesDeployer{}
+	config, err := CreateKubernetesStackDeploymentConfig(stack, deployer, k.KubeAppLabels{StackID: 1, StackName: "app", Owner: "admin", Kind: "git"}, &portainer.User{ID: 1}, &portainer.Endpoint{})
+	is.NoError(err)
+
+	is.NoError(config.Deploy())
+	is.Equal(filepath.Join("/data/compose/1", "deploy", "overlays", "staging"), deployer.kustomizedPath)
+	is.Contains(deployer.deployed, "name: app")
+	is.Contains(deployer.deployed, "io.portainer.kubernetes.application.stackid: \"1\"")
+	is.Equal("deployed", config.GetResponse())
+}
+
+func Test_KubernetesStackDeploymentConfig_DeployUpgradesHelmRelease(t *testing.T) {
+	is := assert.New(t)
+
+	stack := &portainer.Stack{
+		ID:          1,
+		Name:        "app",
+		Namespace:   "apps",
+		ProjectPath: "/data/compose/1",
+		EntryPoint:  "charts/app/Chart.yaml",
+		EndpointID:  2,
+		HelmChart: &portainer.HelmChartConfig{
+			ChartPath:   "charts/app",
+			ValuesFiles: []string{"charts/app/values.yaml"},
+			EnvironmentValuesFiles: map[portainer.EndpointID][]string{
+				1: {"charts/app/values-production.yaml"},
+				2: {"charts/app/values-staging.yaml"},
+			},
+		},
+	}
+
+	deployer := &recordingKubernetesDeployer{}
+	config, err := CreateKubernetesStackDeploymentConfig(stack, deployer, k.KubeAppLabels{StackID: 1, StackName: "app", Owner: "admin", Kind: "git"}, &portainer.User{ID: 1}, &portainer.Endpoint{})
+	is.NoError(err)
+
+	is.NoError(config.Deploy())
+	is.Equal("app", deployer.helmReleaseName)
+	is.Equal(filepath.Join("/data/compose/1", "charts", "app"), deployer.helmChartPath)
+	is.Equal([]string{
+		filepath.Join("/data/compose/1", "charts", "app", "values.yaml"),
+		filepath.Join("/data/compose/1", "charts", "app", "values-staging.yaml"),
+	}, deployer.helmValuesFiles, "the values files of the environment of the stack should be merged last")
+
+	// the resources are labelled by helm, not by applying them again
+	is.Equal("1", deployer.helmLabels["io.portainer.kubernetes.application.stackid"])
+	is.Empty(deployer.deployed)
+}
+
+func Test_KubernetesStackDeploymentConfig_PreviewDiffsManifests(t *testing.T) {
+	is := assert.New(t)
+
+	projectPath := t.TempDir()
+	is.NoError(os.WriteFile(filepath.Join(projectPath, "app.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\n"), 0644))
+	is.NoError(os.WriteFile(filepath.Join(projectPath, "extra.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: extra\n"), 0644))
+
+	stack := &portainer.Stack{
+		ID:              1,
+		Name:            "app",
+		Namespace:       "apps",
+		ProjectPath:     projectPath,
+		EntryPoint:      "app.yaml",
+		AdditionalFiles: []string{"extra.yaml"},
+	}
+
+	deployer := &recordingKubernetesDeployer{}
+	config, err := CreateKubernetesStackDeploymentConfig(stack, deployer, k.KubeAppLabels{StackID: 1, StackName: "app", Owner: "admin", Kind: "content"}, &portainer.User{ID: 1}, &portainer.Endpoint{})
+	is.NoError(err)
+
+	diff, err := config.Preview()
+	is.NoError(err)
+	is.Equal("diff", diff)
+	is.Contains(deployer.diffed, "name: app")
+	is.Contains(deployer.diffed, "name: extra")
+	is.Contains(deployer.diffed, "io.portainer.kubernetes.application.stackid: \"1\"")
+	is.Empty(deployer.deployed, "a preview should not deploy anything")
+
+	stack.HelmChart = &portainer.HelmChartConfig{ChartPath: "charts/app"}
+	deployer = &recordingKubernetesDeployer{}
+	config, err = CreateKubernetesStackDeploymentConfig(stack, deployer, k.KubeAppLabels{StackID: 1, StackName: "app", Owner: "admin", Kind: "git"}, &portainer.User{ID: 1}, &portainer.Endpoint{})
+	is.NoError(err)
+
+	_, err = config.Preview()
+	is.NoError(err)
+	is.Equal("app", deployer.helmReleaseName)
+	is.Equal("1", deployer.helmLabels["io.portainer.kubernetes.application.stackid"])
+	// every resource of the release is compared in its own namespace
+	is.Equal([]string{"apps", "infra"}, deployer.namespaces)
+	is.Empty(deployer.helmChartPath, "a preview should not upgrade the release")
+}
@@ -0,0 +1,460 @@
+package deployments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	dockerclient "github.com/cloudogu/portainer-ce/api/docker"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultStackHealthWindow is the health window of a deploy policy which does not define one
+	DefaultStackHealthWindow = time.Minute
+	// stackHealthPollInterval is the interval between two inspections of the containers of a stack
+	stackHealthPollInterval = 5 * time.Second
+	// stackDeploymentPersistTimeout is how long the result of a health check waits for the checked deployment
+	// to be persisted by the caller of the deployer
+	stackDeploymentPersistTimeout = time.Minute
+	// interruptedHealthCheckMessage is the status message of the health checks which cannot be resumed at startup
+	interruptedHealthCheckMessage = "the health check was interrupted by a restart of Portainer"
+)
+
+// stackDeploymentPersistPollInterval is the interval between two lookups of the persisted deployment of a stack
+var stackDeploymentPersistPollInterval = time.Second
+
+// DockerClientFactory creates the Docker clients used to watch the containers of the compose stacks
+type DockerClientFactory interface {
+	CreateClient(endpoint *portainer.Endpoint, nodeName string, timeout *time.Duration) (*client.Client, error)
+}
+
+// healthChecks keeps the running health checks of the compose stacks, a new deployment of a stack cancels
+// the health check of its previous deployment
+type healthChecks struct {
+	mu     sync.Mutex
+	cancel map[portainer.StackID]*healthCheck
+}
+
+type healthCheck struct {
+	cancel context.CancelFunc
+}
+
+func (c *healthChecks) start(stackID portainer.StackID) (context.Context, *healthCheck) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopLocked(stackID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	check := &healthCheck{cancel: cancel}
+	c.cancel[stackID] = check
+
+	return ctx, check
+}
+
+func (c *healthChecks) stop(stackID portainer.StackID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopLocked(stackID)
+}
+
+func (c *healthChecks) stopLocked(stackID portainer.StackID) {
+	if check, ok := c.cancel[stackID]; ok {
+		check.cancel()
+		delete(c.cancel, stackID)
+	}
+}
+
+func (c *healthChecks) done(stackID portainer.StackID, check *healthCheck) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	check.cancel()
+	if c.cancel[stackID] == check {
+		delete(c.cancel, stackID)
+	}
+}
+
+// stackHealthWindow returns the duration the containers are watched after a deployment
+func stackHealthWindow(policy *portainer.StackDeployPolicy) time.Duration {
+	window, err := time.ParseDuration(policy.HealthWindow)
+	if err != nil || window <= 0 {
+		return DefaultStackHealthWindow
+	}
+
+	return window
+}
+
+// startComposeStackHealthCheck marks the deployment of the stack as being checked and watches its containers
+// in the background. The stack status is updated once the health window elapsed or as soon as a container fails.
+func (d *stackDeployer) startComposeStackHealthCheck(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry) {
+	since := time.Now()
+	stack.DeploymentStatus = &portainer.StackDeploymentStatus{
+		Status: portainer.StackDeploymentChecking,
+		Date:   since.Unix(),
+	}
+
+	d.watchComposeStackHealth(stack, endpoint, registries, since)
+}
+
+// watchComposeStackHealth watches the containers of the deployment of the stack started at since in the background
+func (d *stackDeployer) watchComposeStackHealth(stack *portainer.Stack, endpoint *portainer.Endpoint, registries []portainer.Registry, since time.Time) {
+	stackID, projectName := stack.ID, stack.Name
+	policy := *stack.Option.DeployPolicy
+	endpointCopy := *endpoint
+
+	ctx, check := d.healthChecks.start(stackID)
+
+	go func() {
+		defer d.healthChecks.done(stackID, check)
+
+		err := d.watchComposeStack(ctx, projectName, &endpointCopy, &policy, since)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// the result must not be overwritten by the caller of the deployment persisting the stack
+		if !d.waitForPersistedDeployment(ctx, stackID, since) {
+			return
+		}
+
+		d.lock.Lock()
+		defer d.lock.Unlock()
+
+		// a new deployment of the stack started while waiting for the lock
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			d.updateDeploymentStatus(stackID, &portainer.StackDeploymentStatus{
+				Status: portainer.StackDeploymentHealthy,
+				Date:   time.Now().Unix(),
+			})
+			return
+		}
+
+		log.Warn().Err(err).Int("stack_id", int(stackID)).Msg("the deployment of the stack is unhealthy, rolling back")
+
+		d.rollbackComposeStack(ctx, stackID, &endpointCopy, registries, err)
+	}()
+}
+
+// waitForPersistedDeployment waits until the deployment of the stack started at since is persisted and its revision
+// recorded. It returns false when the deployment is replaced by another one or is never persisted.
+func (d *stackDeployer) waitForPersistedDeployment(ctx context.Context, stackID portainer.StackID, since time.Time) bool {
+	deadline := time.Now().Add(stackDeploymentPersistTimeout)
+
+	ticker := time.NewTicker(stackDeploymentPersistPollInterval)
+	defer ticker.Stop()
+
+	for {
+		persisted, err := d.deploymentPersisted(stackID, since)
+		if err != nil {
+			log.Debug().Err(err).Int("stack_id", int(stackID)).Msg("unable to look up the persisted deployment of the stack")
+		} else if persisted {
+			return true
+		}
+
+		if !time.Now().Before(deadline) {
+			log.Warn().Int("stack_id", int(stackID)).Msg("the checked deployment of the stack was not persisted, discarding the health check")
+			return false
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// deploymentPersisted returns true when the persisted stack is being checked since the specified time
+// and its latest recorded revision matches its current files and settings
+func (d *stackDeployer) deploymentPersisted(stackID portainer.StackID, since time.Time) (bool, error) {
+	stack, err := d.dataStore.Stack().Stack(stackID)
+	if err != nil {
+		return false, err
+	}
+
+	status := stack.DeploymentStatus
+	if status == nil || status.Status != portainer.StackDeploymentChecking || status.Date != since.Unix() {
+		return false, nil
+	}
+
+	latest, err := d.dataStore.StackVersion().LatestStackVersion(stackID)
+	if err != nil {
+		return false, err
+	}
+
+	current, err := currentStackVersion(stack, "")
+	if err != nil {
+		return false, err
+	}
+
+	return sameStackRevision(latest, current), nil
+}
+
+// ResumeHealthChecks resumes the health checks of the compose stacks interrupted by a restart. The stacks which
+// cannot be checked any more are reported as unhealthy instead of staying in the checking status.
+func (d *stackDeployer) ResumeHealthChecks() error {
+	stacks, err := d.dataStore.Stack().Stacks()
+	if err != nil {
+		return errors.WithMessage(err, "unable to retrieve the stacks")
+	}
+
+	for i := range stacks {
+		stack := &stacks[i]
+		if stack.DeploymentStatus == nil || stack.DeploymentStatus.Status != portainer.StackDeploymentChecking {
+			continue
+		}
+
+		if err := d.resumeHealthCheck(stack); err != nil {
+			log.Warn().Err(err).Int("stack_id", int(stack.ID)).Msg("unable to resume the health check of the stack")
+
+			d.updateDeploymentStatus(stack.ID, &portainer.StackDeploymentStatus{
+				Status:  portainer.StackDeploymentUnhealthy,
+				Message: interruptedHealthCheckMessage,
+				Date:    time.Now().Unix(),
+			})
+		}
+	}
+
+	return nil
+}
+
+func (d *stackDeployer) resumeHealthCheck(stack *portainer.Stack) error {
+	if stack.Type != portainer.DockerComposeStack || stack.Option == nil || stack.Option.DeployPolicy == nil {
+		return errors.New("the stack has no deploy policy")
+	}
+
+	endpoint, err := d.dataStore.Endpoint().Endpoint(stack.EndpointID)
+	if err != nil {
+		return errors.WithMessagef(err, "unable to find the environment %d", stack.EndpointID)
+	}
+
+	author := stack.UpdatedBy
+	if author == "" {
+		author = stack.CreatedBy
+	}
+
+	user, err := d.dataStore.User().UserByUsername(author)
+	if err != nil {
+		return errors.WithMessagef(err, "unable to find the author %s", author)
+	}
+
+	registries, err := getUserRegistries(d.dataStore, user, endpoint.ID)
+	if err != nil {
+		return err
+	}
+
+	d.watchComposeStackHealth(stack, endpoint, registries, time.Unix(stack.DeploymentStatus.Date, 0))
+	return nil
+}
+
+// watchComposeStack inspects the containers of a compose project until the health window elapsed,
+// it returns an error as soon as a container fails
+func (d *stackDeployer) watchComposeStack(ctx context.Context, projectName string, endpoint *portainer.Endpoint, policy *portainer.StackDeployPolicy, since time.Time) error {
+	deadline := since.Add(stackHealthWindow(policy))
+
+	var baseline map[string]int
+
+	ticker := time.NewTicker(stackHealthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		final := !time.Now().Before(deadline)
+
+		containers, err := d.inspectComposeContainers(ctx, projectName, endpoint)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if final {
+				return errors.WithMessage(err, "unable to inspect the containers of the stack")
+			}
+
+			log.Debug().Err(err).Str("project", projectName).Msg("unable to inspect the containers of the stack")
+		} else {
+			if baseline == nil {
+				baseline = restartBaseline(containers, since)
+			}
+
+			if err := checkComposeContainers(containers, baseline, policy.MaxRestarts, final); err != nil || final {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *stackDeployer) inspectComposeContainers(ctx context.Context, projectName string, endpoint *portainer.Endpoint) ([]types.ContainerJSON, error) {
+	cli, err := d.dockerClientFactory.CreateClient(endpoint, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	list, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", fmt.Sprintf("%s=%s", dockerclient.ComposeStackNameLabel, projectName))),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]types.ContainerJSON, 0, len(list))
+	for _, container := range list {
+		inspect, err := cli.ContainerInspect(ctx, container.ID)
+		if client.IsErrNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		containers = append(containers, inspect)
+	}
+
+	return containers, nil
+}
+
+// restartBaseline returns the restart counts the restarts of the containers are compared with. The containers
+// which were not recreated by the deployment keep their previous restarts, the new ones start from zero.
+func restartBaseline(containers []types.ContainerJSON, since time.Time) map[string]int {
+	baseline := make(map[string]int)
+	for _, container := range containers {
+		if container.ContainerJSONBase == nil {
+			continue
+		}
+
+		created, err := time.Parse(time.RFC3339Nano, container.Created)
+		if err == nil && created.Before(since) {
+			baseline[container.ID] = container.RestartCount
+		}
+	}
+
+	return baseline
+}
+
+// checkComposeContainers returns an error describing the first failing container of a compose project.
+// The containers whose healthcheck is still starting, or which are restarting, only fail once the health window elapsed.
+func checkComposeContainers(containers []types.ContainerJSON, baseline map[string]int, maxRestarts int, final bool) error {
+	if final && len(containers) == 0 {
+		return errors.New("no container of the stack was found")
+	}
+
+	for _, container := range containers {
+		if container.ContainerJSONBase == nil || container.State == nil {
+			continue
+		}
+
+		name := strings.TrimPrefix(container.Name, "/")
+		state := container.State
+
+		if restarts := container.RestartCount - baseline[container.ID]; restarts > maxRestarts {
+			return errors.Errorf("container %s restarted %d times", name, restarts)
+		}
+
+		if (state.Status == "exited" || state.Status == "dead") && state.ExitCode != 0 {
+			return errors.Errorf("container %s exited with code %d", name, state.ExitCode)
+		}
+
+		if state.Health != nil && state.Health.Status == types.Unhealthy {
+			return errors.Errorf("container %s is unhealthy", name)
+		}
+
+		if !final {
+			continue
+		}
+
+		if state.Restarting {
+			return errors.Errorf("container %s is still restarting", name)
+		}
+
+		if state.Health != nil && state.Health.Status == types.Starting {
+			return errors.Errorf("container %s did not become healthy within the health window", name)
+		}
+	}
+
+	return nil
+}
+
+// rollbackComposeStack redeploys the latest revision of the stack which differs from the unhealthy one
+// and reports the failure in the stack status. It must be called with the deployer lock held.
+func (d *stackDeployer) rollbackComposeStack(ctx context.Context, stackID portainer.StackID, endpoint *portainer.Endpoint, registries []portainer.Registry, reason error) {
+	status := &portainer.StackDeploymentStatus{
+		Status:  portainer.StackDeploymentUnhealthy,
+		Message: reason.Error(),
+		Date:    time.Now().Unix(),
+	}
+
+	stack, err := d.dataStore.Stack().Stack(stackID)
+	if err != nil {
+		log.Error().Err(err).Int("stack_id", int(stackID)).Msg("unable to retrieve the stack to roll back")
+		return
+	}
+
+	previous, err := previousStackVersion(d.dataStore.StackVersion(), stack)
+	if err != nil {
+		status.Message = fmt.Sprintf("%s, %s", status.Message, err)
+		d.updateDeploymentStatus(stackID, status)
+		return
+	}
+
+	err = RestoreStackVersion(stack, previous)
+	if err == nil {
+		d.swarmStackManager.Login(registries, endpoint)
+		err = d.composeStackManager.Up(ctx, stack, endpoint, false)
+		d.swarmStackManager.Logout(endpoint)
+	}
+
+	if err != nil {
+		log.Error().Err(err).Int("stack_id", int(stackID)).Int("version", previous.Version).Msg("unable to roll back the stack")
+
+		status.Message = fmt.Sprintf("%s, the rollback to the revision %d failed: %s", status.Message, previous.Version, err)
+		d.updateDeploymentStatus(stackID, status)
+		return
+	}
+
+	status.RolledBackToVersion = previous.Version
+	stack.DeploymentStatus = status
+	stack.UpdateDate = time.Now().Unix()
+
+	if err := d.dataStore.Stack().UpdateStack(stack.ID, stack); err != nil {
+		log.Error().Err(err).Int("stack_id", int(stackID)).Msg("unable to update the rolled back stack")
+		return
+	}
+
+	if err := RecordStackVersion(d.dataStore, stack, previous.DeployedBy); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stackID)).Msg("unable to record the stack version")
+	}
+}
+
+func (d *stackDeployer) updateDeploymentStatus(stackID portainer.StackID, status *portainer.StackDeploymentStatus) {
+	stack, err := d.dataStore.Stack().Stack(stackID)
+	if err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stackID)).Msg("unable to retrieve the stack to update its deployment status")
+		return
+	}
+
+	stack.DeploymentStatus = status
+	if err := d.dataStore.Stack().UpdateStack(stack.ID, stack); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(stackID)).Msg("unable to update the deployment status of the stack")
+	}
+}
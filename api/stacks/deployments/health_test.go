@@ -0,0 +1,120 @@
+package deployments
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func composeContainer(id string, state *types.ContainerState, restarts int, created time.Time) types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:           id,
+			Name:         "/stack-" + id + "-1",
+			Created:      created.Format(time.RFC3339Nano),
+			State:        state,
+			RestartCount: restarts,
+		},
+	}
+}
+
+func Test_checkComposeContainers(t *testing.T) {
+	is := assert.New(t)
+
+	now := time.Now()
+	running := &types.ContainerState{Status: "running", Running: true}
+	starting := &types.ContainerState{Status: "running", Running: true, Health: &types.Health{Status: types.Starting}}
+
+	is.NoError(checkComposeContainers([]types.ContainerJSON{composeContainer("web", running, 0, now)}, nil, 0, true))
+	is.NoError(checkComposeContainers([]types.ContainerJSON{composeContainer("web", starting, 0, now)}, nil, 0, false), "a starting container should be awaited")
+	is.EqualError(checkComposeContainers([]types.ContainerJSON{composeContainer("web", starting, 0, now)}, nil, 0, true), "container stack-web-1 did not become healthy within the health window")
+	is.EqualError(checkComposeContainers(nil, nil, 0, true), "no container of the stack was found")
+
+	unhealthy := &types.ContainerState{Status: "running", Running: true, Health: &types.Health{Status: types.Unhealthy}}
+	is.EqualError(checkComposeContainers([]types.ContainerJSON{composeContainer("web", unhealthy, 0, now)}, nil, 0, false), "container stack-web-1 is unhealthy")
+
+	exited := &types.ContainerState{Status: "exited", ExitCode: 1}
+	is.EqualError(checkComposeContainers([]types.ContainerJSON{composeContainer("job", exited, 0, now)}, nil, 0, false), "container stack-job-1 exited with code 1")
+	is.NoError(checkComposeContainers([]types.ContainerJSON{composeContainer("job", &types.ContainerState{Status: "exited"}, 0, now)}, nil, 0, true), "a completed container should not fail")
+
+	restarting := &types.ContainerState{Status: "restarting", Restarting: true}
+	is.NoError(checkComposeContainers([]types.ContainerJSON{composeContainer("web", restarting, 2, now)}, nil, 2, false))
+	is.EqualError(checkComposeContainers([]types.ContainerJSON{composeContainer("web", restarting, 3, now)}, nil, 2, false), "container stack-web-1 restarted 3 times")
+	is.EqualError(checkComposeContainers([]types.ContainerJSON{composeContainer("web", restarting, 2, now)}, nil, 2, true), "container stack-web-1 is still restarting")
+	is.NoError(checkComposeContainers([]types.ContainerJSON{composeContainer("web", running, 5, now)}, map[string]int{"web": 5}, 0, true), "the restarts before the deployment should be ignored")
+}
+
+func Test_restartBaseline(t *testing.T) {
+	is := assert.New(t)
+
+	since := time.Now()
+	running := &types.ContainerState{Status: "running", Running: true}
+
+	baseline := restartBaseline([]types.ContainerJSON{
+		composeContainer("kept", running, 4, since.Add(-time.Hour)),
+		composeContainer("recreated", running, 1, since.Add(time.Second)),
+	}, since)
+
+	is.Equal(map[string]int{"kept": 4}, baseline)
+}
+
+func Test_waitForPersistedDeployment(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	projectPath := t.TempDir()
+	is.NoError(writeCheckout(projectPath, map[string]string{"docker-compose.yml": "services:\n  web:\n    image: nginx:1.24\n"}))
+
+	since := time.Now()
+	stack := &portainer.Stack{ID: 1, ProjectPath: projectPath, EntryPoint: "docker-compose.yml"}
+	is.NoError(store.Stack().Create(stack))
+
+	d := &stackDeployer{dataStore: store}
+
+	persisted, err := d.deploymentPersisted(stack.ID, since)
+	is.NoError(err)
+	is.False(persisted, "the deployment is not persisted by the caller yet")
+
+	stack.DeploymentStatus = &portainer.StackDeploymentStatus{Status: portainer.StackDeploymentChecking, Date: since.Unix()}
+	is.NoError(store.Stack().UpdateStack(stack.ID, stack))
+
+	_, err = d.deploymentPersisted(stack.ID, since)
+	is.Error(err, "the revision of the deployment is not recorded yet")
+
+	is.NoError(RecordStackVersion(store, stack, "admin"))
+	is.True(d.waitForPersistedDeployment(context.Background(), stack.ID, since))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	is.False(d.waitForPersistedDeployment(ctx, stack.ID, since.Add(time.Hour)), "a replaced deployment should not be waited for")
+}
+
+func Test_ResumeHealthChecks_reportsInterruptedChecks(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	stack := &portainer.Stack{
+		ID:               1,
+		Type:             portainer.DockerComposeStack,
+		EndpointID:       1,
+		DeploymentStatus: &portainer.StackDeploymentStatus{Status: portainer.StackDeploymentChecking, Date: time.Now().Unix()},
+	}
+	is.NoError(store.Stack().Create(stack))
+
+	d := &stackDeployer{dataStore: store, healthChecks: &healthChecks{cancel: make(map[portainer.StackID]*healthCheck)}}
+	is.NoError(d.ResumeHealthChecks())
+
+	stack, err := store.Stack().Stack(stack.ID)
+	is.NoError(err)
+	is.Equal(portainer.StackDeploymentUnhealthy, stack.DeploymentStatus.Status, "a stack without deploy policy cannot be checked again")
+	is.Equal(interruptedHealthCheckMessage, stack.DeploymentStatus.Message)
+}
@@ -0,0 +1,103 @@
+package deployments
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/stacks/stackutils"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// ImageOverrideFileName is the name of the compose file generated to override the image tags of a stack
+const ImageOverrideFileName = "portainer-image-override.yml"
+
+type composeImages struct {
+	Services map[string]struct {
+		Image string `yaml:"image"`
+	} `yaml:"services"`
+}
+
+type composeImageOverride struct {
+	Services map[string]composeServiceImage `yaml:"services"`
+}
+
+type composeServiceImage struct {
+	Image string `yaml:"image"`
+}
+
+// WithImageTagOverrides returns a copy of the stack deploying the given services with a different image tag.
+// The tags are applied by an extra compose file written inside the stack project, the returned cleanup function removes it.
+func WithImageTagOverrides(stack *portainer.Stack, tags map[string]string) (*portainer.Stack, func(), error) {
+	if len(tags) == 0 {
+		return stack, func() {}, nil
+	}
+
+	images := make(map[string]string)
+	for _, filePath := range stackutils.GetStackFilePaths(stack, true) {
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to read the stack file %s", filepath.Base(filePath))
+		}
+
+		var compose composeImages
+		if err := yaml.Unmarshal(content, &compose); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to parse the stack file %s", filepath.Base(filePath))
+		}
+
+		// later files override the services defined by the previous ones
+		for name, service := range compose.Services {
+			if service.Image != "" {
+				images[name] = service.Image
+			}
+		}
+	}
+
+	override := composeImageOverride{Services: make(map[string]composeServiceImage)}
+
+	services := make([]string, 0, len(tags))
+	for service := range tags {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+
+	for _, service := range services {
+		image, ok := images[service]
+		if !ok {
+			return nil, nil, errors.Errorf("service %s does not exist or does not define an image", service)
+		}
+
+		override.Services[service] = composeServiceImage{Image: ReplaceImageTag(image, tags[service])}
+	}
+
+	content, err := yaml.Marshal(override)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to generate the image override file")
+	}
+
+	overridePath := filepath.Join(stack.ProjectPath, ImageOverrideFileName)
+	if err := os.WriteFile(overridePath, content, 0600); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to write the image override file")
+	}
+
+	overridden := *stack
+	overridden.AdditionalFiles = append(append([]string{}, stack.AdditionalFiles...), ImageOverrideFileName)
+
+	return &overridden, func() { os.Remove(overridePath) }, nil
+}
+
+// ReplaceImageTag replaces the tag (and digest) of an image reference, e.g. "registry:5000/app:1.0" becomes "registry:5000/app:2.0"
+func ReplaceImageTag(image, tag string) string {
+	name := strings.Split(image, "@")[0]
+
+	if tagIndex := strings.LastIndex(name, ":"); tagIndex > strings.LastIndex(name, "/") {
+		name = name[:tagIndex]
+	}
+
+	return fmt.Sprintf("%s:%s", name, tag)
+}
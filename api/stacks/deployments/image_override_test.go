@@ -0,0 +1,67 @@
+package deployments
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReplaceImageTag(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{image: "nginx", expected: "nginx:1.25"},
+		{image: "nginx:latest", expected: "nginx:1.25"},
+		{image: "registry.local:5000/team/nginx", expected: "registry.local:5000/team/nginx:1.25"},
+		{image: "registry.local:5000/team/nginx:1.0", expected: "registry.local:5000/team/nginx:1.25"},
+		{image: "nginx:1.0@sha256:0123456789abcdef", expected: "nginx:1.25"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ReplaceImageTag(test.image, "1.25"), test.image)
+	}
+}
+
+func Test_WithImageTagOverrides(t *testing.T) {
+	is := assert.New(t)
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte(`
+services:
+  web:
+    image: registry.local:5000/web:1.0
+  worker:
+    image: worker:1.0
+`), 0600)
+	is.NoError(err)
+
+	err = os.WriteFile(filepath.Join(dir, "prod.yml"), []byte(`
+services:
+  worker:
+    image: registry.local:5000/worker:1.0
+`), 0600)
+	is.NoError(err)
+
+	stack := &portainer.Stack{ProjectPath: dir, EntryPoint: "docker-compose.yml", AdditionalFiles: []string{"prod.yml"}}
+
+	overridden, cleanup, err := WithImageTagOverrides(stack, map[string]string{"worker": "2.0"})
+	is.NoError(err)
+
+	is.Equal([]string{"prod.yml"}, stack.AdditionalFiles, "the original stack should be left untouched")
+	is.Equal([]string{"prod.yml", ImageOverrideFileName}, overridden.AdditionalFiles)
+
+	content, err := os.ReadFile(filepath.Join(dir, ImageOverrideFileName))
+	is.NoError(err)
+	is.Equal("services:\n    worker:\n        image: registry.local:5000/worker:2.0\n", string(content))
+
+	cleanup()
+	_, err = os.Stat(filepath.Join(dir, ImageOverrideFileName))
+	is.True(os.IsNotExist(err), "the override file should be removed")
+
+	_, _, err = WithImageTagOverrides(stack, map[string]string{"unknown": "2.0"})
+	is.Error(err, "unknown services should be rejected")
+}
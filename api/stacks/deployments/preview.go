@@ -0,0 +1,128 @@
+package deployments
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"sort"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ServiceCreated is the change of a service which is not deployed yet
+	ServiceCreated = "create"
+	// ServiceRecreated is the change of a deployed service whose configuration changed
+	ServiceRecreated = "recreate"
+	// ServiceRemoved is the change of a deployed service which is no longer defined
+	ServiceRemoved = "remove"
+)
+
+// StackPreview represents the changes a deployment would apply to a stack
+type StackPreview struct {
+	// Unified diff between the deployed and the new configuration of the stack.
+	// The rendered compose configurations are compared for the compose and swarm stacks,
+	// the live resources and the result of a server-side dry-run apply for the Kubernetes stacks
+	Diff string `example:"--- deployed\n+++ updated\n@@ -3 +3 @@\n-    image: nginx:1.24\n+    image: nginx:1.25\n"`
+	// Services which would be created, recreated or removed, only for the compose and swarm stacks
+	Services []ServicePreview `json:",omitempty"`
+}
+
+// ServicePreview represents the change a deployment would apply to a service of a compose or swarm stack
+type ServicePreview struct {
+	// Service name
+	Name string `example:"web"`
+	// Change applied to the service: create, recreate or remove
+	Action string `example:"recreate"`
+	// Image of the deployed service
+	PreviousImage string `json:",omitempty" example:"nginx:1.24"`
+	// Image of the updated service
+	Image string `json:",omitempty" example:"nginx:1.25"`
+}
+
+type composeServices struct {
+	Services map[string]map[string]interface{} `yaml:"services"`
+}
+
+// PreviewComposeStack compares the rendered compose configurations of the deployed and of the updated stack,
+// without deploying anything
+func PreviewComposeStack(ctx context.Context, manager portainer.ComposeStackManager, deployed, updated *portainer.Stack) (*StackPreview, error) {
+	deployedConfig, err := manager.Config(ctx, deployed)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to render the deployed stack configuration")
+	}
+
+	updatedConfig, err := manager.Config(ctx, updated)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to render the updated stack configuration")
+	}
+
+	// the relative paths are resolved against the project of each stack, the updated stack being usually
+	// written aside of the deployed one
+	if updated.ProjectPath != deployed.ProjectPath {
+		updatedConfig = bytes.ReplaceAll(updatedConfig, []byte(updated.ProjectPath), []byte(deployed.ProjectPath))
+	}
+
+	return previewComposeConfigs(deployedConfig, updatedConfig)
+}
+
+func previewComposeConfigs(deployedConfig, updatedConfig []byte) (*StackPreview, error) {
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(deployedConfig)),
+		B:        difflib.SplitLines(string(updatedConfig)),
+		FromFile: "deployed",
+		ToFile:   "updated",
+		Context:  3,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compare the stack configurations")
+	}
+
+	var deployedServices, updatedServices composeServices
+	if err := yaml.Unmarshal(deployedConfig, &deployedServices); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the deployed stack configuration")
+	}
+
+	if err := yaml.Unmarshal(updatedConfig, &updatedServices); err != nil {
+		return nil, errors.Wrap(err, "failed to parse the updated stack configuration")
+	}
+
+	services := make([]ServicePreview, 0)
+	for name, service := range updatedServices.Services {
+		deployedService, ok := deployedServices.Services[name]
+		if !ok {
+			services = append(services, ServicePreview{Name: name, Action: ServiceCreated, Image: serviceImage(service)})
+			continue
+		}
+
+		if !reflect.DeepEqual(deployedService, service) {
+			services = append(services, ServicePreview{
+				Name:          name,
+				Action:        ServiceRecreated,
+				PreviousImage: serviceImage(deployedService),
+				Image:         serviceImage(service),
+			})
+		}
+	}
+
+	for name, service := range deployedServices.Services {
+		if _, ok := updatedServices.Services[name]; !ok {
+			services = append(services, ServicePreview{Name: name, Action: ServiceRemoved, PreviousImage: serviceImage(service)})
+		}
+	}
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Name < services[j].Name
+	})
+
+	return &StackPreview{Diff: diff, Services: services}, nil
+}
+
+func serviceImage(service map[string]interface{}) string {
+	image, _ := service["image"].(string)
+	return image
+}
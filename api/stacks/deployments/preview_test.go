@@ -0,0 +1,50 @@
+package deployments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_previewComposeConfigs(t *testing.T) {
+	is := assert.New(t)
+
+	deployed := `name: app
+services:
+  db:
+    image: postgres:15
+  web:
+    image: nginx:1.24
+    ports:
+      - "80:80"
+  worker:
+    image: app/worker:1.0
+`
+
+	updated := `name: app
+services:
+  cache:
+    image: redis:7
+  db:
+    image: postgres:15
+  web:
+    image: nginx:1.25
+    ports:
+      - "80:80"
+`
+
+	preview, err := previewComposeConfigs([]byte(deployed), []byte(updated))
+	is.NoError(err)
+
+	is.Contains(preview.Diff, "-    image: nginx:1.24\n+    image: nginx:1.25\n")
+	is.Equal([]ServicePreview{
+		{Name: "cache", Action: ServiceCreated, Image: "redis:7"},
+		{Name: "web", Action: ServiceRecreated, PreviousImage: "nginx:1.24", Image: "nginx:1.25"},
+		{Name: "worker", Action: ServiceRemoved, PreviousImage: "app/worker:1.0"},
+	}, preview.Services)
+
+	preview, err = previewComposeConfigs([]byte(deployed), []byte(deployed))
+	is.NoError(err)
+	is.Empty(preview.Diff)
+	is.Empty(preview.Services)
+}
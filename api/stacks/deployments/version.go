@@ -0,0 +1,283 @@
+package deployments
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/filesystem"
+	"github.com/cloudogu/portainer-ce/api/stacks/stackutils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// StackVersionRetention is the number of revisions kept in the history of a stack
+	StackVersionRetention = 50
+	// stackVersionMaxSize is the maximum total size of the files stored for a revision
+	stackVersionMaxSize = 10 * 1024 * 1024
+)
+
+var (
+	// ErrStackVersionTooLarge is returned when the files of a stack exceed the size kept for a revision of its history
+	ErrStackVersionTooLarge   = errors.New("the stack files exceed 10MB and cannot be kept in the stack history")
+	errNoPreviousStackVersion = errors.New("no previous revision to roll back to")
+)
+
+// RecordStackVersion stores the deployed files and settings of a stack as a new revision of its history.
+// Nothing is stored when they match the latest revision, and the revisions beyond the retention are removed.
+func RecordStackVersion(datastore dataservices.DataStore, stack *portainer.Stack, deployedBy string) error {
+	stackVersion, err := currentStackVersion(stack, deployedBy)
+	if err != nil {
+		return err
+	}
+
+	latest, err := datastore.StackVersion().LatestStackVersion(stack.ID)
+	if err != nil && !datastore.IsErrObjectNotFound(err) {
+		return errors.WithMessagef(err, "failed to retrieve the latest revision of the stack %v", stack.ID)
+	}
+
+	if latest != nil && sameStackRevision(latest, stackVersion) {
+		return nil
+	}
+
+	if err := datastore.StackVersion().Create(stackVersion); err != nil {
+		return errors.WithMessagef(err, "failed to store the revision of the stack %v", stack.ID)
+	}
+
+	return datastore.StackVersion().DeleteStackVersionsBefore(stack.ID, stackVersion.Version-StackVersionRetention+1)
+}
+
+// ValidateStackVersionSize returns ErrStackVersionTooLarge when the files a stack is deployed from
+// cannot be kept in its history. The other read errors are left to the deployment.
+func ValidateStackVersionSize(stack *portainer.Stack) error {
+	if _, err := readStackVersionFiles(stack); errors.Is(err, ErrStackVersionTooLarge) {
+		return err
+	}
+
+	return nil
+}
+
+// previousStackVersion returns the latest revision of the history of a stack which differs from its current files and settings
+func previousStackVersion(service dataservices.StackVersionService, stack *portainer.Stack) (*portainer.StackVersion, error) {
+	current, err := currentStackVersion(stack, "")
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := service.StackVersions(stack.ID)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to retrieve the history of the stack %v", stack.ID)
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !sameStackRevision(&versions[i], current) {
+			return &versions[i], nil
+		}
+	}
+
+	return nil, errNoPreviousStackVersion
+}
+
+func currentStackVersion(stack *portainer.Stack, deployedBy string) (*portainer.StackVersion, error) {
+	files, err := readStackVersionFiles(stack)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to read the files of the stack %v", stack.ID)
+	}
+
+	stackVersion := &portainer.StackVersion{
+		StackID:         stack.ID,
+		Files:           files,
+		Env:             stack.Env,
+		EntryPoint:      stack.EntryPoint,
+		AdditionalFiles: stack.AdditionalFiles,
+		DeployedBy:      deployedBy,
+		DeploymentDate:  time.Now().Unix(),
+	}
+	if stack.GitConfig != nil {
+		stackVersion.ConfigHash = stack.GitConfig.ConfigHash
+	}
+
+	return stackVersion, nil
+}
+
+// RestoreStackVersion writes the files of a revision in the project of a stack and applies its settings.
+// The files added to the kustomization or chart directory since the revision are removed.
+func RestoreStackVersion(stack *portainer.Stack, stackVersion *portainer.StackVersion) error {
+	if err := removeFilesAddedSince(stack, stackVersion); err != nil {
+		return err
+	}
+
+	for path, content := range stackVersion.Files {
+		if err := filesystem.WriteToFile(filesystem.JoinPaths(stack.ProjectPath, path), []byte(content)); err != nil {
+			return err
+		}
+	}
+
+	stack.Env = stackVersion.Env
+	stack.EntryPoint = stackVersion.EntryPoint
+	stack.AdditionalFiles = stackVersion.AdditionalFiles
+
+	return nil
+}
+
+func removeFilesAddedSince(stack *portainer.Stack, stackVersion *portainer.StackVersion) error {
+	var dir string
+	switch {
+	case stack.Kustomize != nil:
+		dir = stack.Kustomize.Path
+	case stack.HelmChart != nil:
+		dir = stack.HelmChart.ChartPath
+	default:
+		return nil
+	}
+
+	paths, err := listDirectoryFiles(stack.ProjectPath, dir)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to list the files of the stack %v", stack.ID)
+	}
+
+	for _, path := range paths {
+		if _, ok := stackVersion.Files[filepath.ToSlash(filepath.Clean(path))]; ok {
+			continue
+		}
+
+		if err := os.Remove(filesystem.JoinPaths(stack.ProjectPath, path)); err != nil {
+			return errors.WithMessagef(err, "failed to remove the file %s of the stack %v", path, stack.ID)
+		}
+	}
+
+	return nil
+}
+
+// readStackVersionFiles reads the files a stack is deployed from, indexed by their path relative to the stack project.
+// The whole kustomization or chart directory is read for the Kustomize and Helm stacks.
+func readStackVersionFiles(stack *portainer.Stack) (map[string]string, error) {
+	var paths []string
+
+	switch {
+	case stack.Kustomize != nil:
+		dirPaths, err := listDirectoryFiles(stack.ProjectPath, stack.Kustomize.Path)
+		if err != nil {
+			return nil, err
+		}
+		paths = dirPaths
+	case stack.HelmChart != nil:
+		dirPaths, err := listDirectoryFiles(stack.ProjectPath, stack.HelmChart.ChartPath)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(dirPaths, stackutils.GetHelmValuesFiles(stack)...)
+	default:
+		paths = stackutils.GetStackFilePaths(stack, false)
+	}
+
+	files := make(map[string]string, len(paths))
+	size := 0
+	for _, path := range paths {
+		path = filepath.ToSlash(filepath.Clean(path))
+		if _, ok := files[path]; ok {
+			continue
+		}
+
+		content, err := os.ReadFile(filesystem.JoinPaths(stack.ProjectPath, path))
+		if err != nil {
+			return nil, err
+		}
+
+		size += len(content)
+		if size > stackVersionMaxSize {
+			return nil, ErrStackVersionTooLarge
+		}
+
+		files[path] = string(content)
+	}
+
+	return files, nil
+}
+
+// listDirectoryFiles returns the paths of the files of a directory relative to the project path,
+// skipping the git metadata
+func listDirectoryFiles(projectPath, dir string) ([]string, error) {
+	var paths []string
+
+	err := filepath.WalkDir(filesystem.JoinPaths(projectPath, dir), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(projectPath, path)
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, relativePath)
+		return nil
+	})
+
+	return paths, err
+}
+
+func sameStackRevision(a, b *portainer.StackVersion) bool {
+	return a.EntryPoint == b.EntryPoint &&
+		a.ConfigHash == b.ConfigHash &&
+		equalFiles(a.Files, b.Files) &&
+		equalPairs(a.Env, b.Env) &&
+		equalStrings(a.AdditionalFiles, b.AdditionalFiles)
+}
+
+func equalFiles(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for path, content := range a {
+		if other, ok := b[path]; !ok || other != content {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalPairs(a, b []portainer.Pair) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,204 @@
+package deployments
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/datastore"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RecordStackVersion(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	projectPath := t.TempDir()
+	is.NoError(writeCheckout(projectPath, map[string]string{
+		"docker-compose.yml": "services:\n  web:\n    image: nginx:1.24\n",
+		"override.yml":       "services: {}\n",
+	}))
+
+	stack := &portainer.Stack{
+		ID:              1,
+		ProjectPath:     projectPath,
+		EntryPoint:      "docker-compose.yml",
+		AdditionalFiles: []string{"override.yml"},
+		Env:             []portainer.Pair{{Name: "TAG", Value: "1"}},
+	}
+
+	is.NoError(RecordStackVersion(store, stack, "admin"))
+	is.NoError(RecordStackVersion(store, stack, "admin"), "an unchanged revision should not be recorded")
+
+	versions, err := store.StackVersion().StackVersions(stack.ID)
+	is.NoError(err)
+	is.Len(versions, 1)
+	is.Equal(1, versions[0].Version)
+	is.Equal("admin", versions[0].DeployedBy)
+	is.Equal("services: {}\n", versions[0].Files["override.yml"])
+
+	stack.Env = []portainer.Pair{{Name: "TAG", Value: "2"}}
+	is.NoError(RecordStackVersion(store, stack, "bob"))
+
+	versions, err = store.StackVersion().StackVersions(stack.ID)
+	is.NoError(err)
+	is.Len(versions, 2)
+	is.Equal(2, versions[1].Version)
+	is.Equal("bob", versions[1].DeployedBy)
+
+	for i := 3; i <= StackVersionRetention+5; i++ {
+		stack.Env = []portainer.Pair{{Name: "TAG", Value: string(rune('a' + i))}}
+		is.NoError(RecordStackVersion(store, stack, "admin"))
+	}
+
+	versions, err = store.StackVersion().StackVersions(stack.ID)
+	is.NoError(err)
+	is.Len(versions, StackVersionRetention, "the revisions beyond the retention should be removed")
+	is.Equal(6, versions[0].Version)
+}
+
+func Test_RecordStackVersion_Kustomize(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	projectPath := t.TempDir()
+	is.NoError(writeCheckout(projectPath, map[string]string{
+		"deploy/kustomization.yaml":                     "resources: [app.yaml]\n",
+		"deploy/app.yaml":                               "kind: Deployment\n",
+		"deploy/overlays/production/kustomization.yaml": "resources: [../..]\n",
+		"deploy/.git/HEAD":                              "ref: refs/heads/main\n",
+		"README.md":                                     "readme",
+	}))
+
+	stack := &portainer.Stack{
+		ID:          1,
+		Type:        portainer.KubernetesStack,
+		ProjectPath: projectPath,
+		Kustomize:   &portainer.KustomizeConfig{Path: "deploy", Overlay: "production"},
+	}
+
+	is.NoError(RecordStackVersion(store, stack, "admin"))
+
+	stackVersion, err := store.StackVersion().StackVersion(stack.ID, 1)
+	is.NoError(err)
+	is.Len(stackVersion.Files, 3)
+	is.Contains(stackVersion.Files, "deploy/overlays/production/kustomization.yaml")
+}
+
+func Test_RestoreStackVersion(t *testing.T) {
+	is := assert.New(t)
+
+	projectPath := t.TempDir()
+	is.NoError(writeCheckout(projectPath, map[string]string{
+		"docker-compose.yml": "services:\n  web:\n    image: nginx:1.25\n",
+	}))
+
+	stack := &portainer.Stack{
+		ID:          1,
+		ProjectPath: projectPath,
+		EntryPoint:  "docker-compose.yml",
+		Env:         []portainer.Pair{{Name: "TAG", Value: "2"}},
+	}
+
+	err := RestoreStackVersion(stack, &portainer.StackVersion{
+		Files: map[string]string{
+			"docker-compose.yml": "services:\n  web:\n    image: nginx:1.24\n",
+			"override.yml":       "services: {}\n",
+		},
+		Env:             []portainer.Pair{{Name: "TAG", Value: "1"}},
+		EntryPoint:      "docker-compose.yml",
+		AdditionalFiles: []string{"override.yml"},
+	})
+	is.NoError(err)
+
+	content, err := os.ReadFile(filepath.Join(projectPath, "docker-compose.yml"))
+	is.NoError(err)
+	is.Equal("services:\n  web:\n    image: nginx:1.24\n", string(content))
+	is.FileExists(filepath.Join(projectPath, "override.yml"))
+	is.Equal([]portainer.Pair{{Name: "TAG", Value: "1"}}, stack.Env)
+	is.Equal([]string{"override.yml"}, stack.AdditionalFiles)
+}
+
+func Test_RestoreStackVersion_removesAddedKustomizationFiles(t *testing.T) {
+	is := assert.New(t)
+
+	projectPath := t.TempDir()
+	is.NoError(writeCheckout(projectPath, map[string]string{
+		"deploy/kustomization.yaml": "resources:\n  - deployment.yaml\n  - service.yaml\n",
+		"deploy/deployment.yaml":    "kind: Deployment\n",
+		"deploy/service.yaml":       "kind: Service\n",
+		".git/HEAD":                 "ref: refs/heads/main\n",
+	}))
+
+	stack := &portainer.Stack{
+		ID:          1,
+		ProjectPath: projectPath,
+		Kustomize:   &portainer.KustomizeConfig{Path: "deploy"},
+	}
+
+	err := RestoreStackVersion(stack, &portainer.StackVersion{
+		Files: map[string]string{
+			"deploy/kustomization.yaml": "resources:\n  - deployment.yaml\n",
+			"deploy/deployment.yaml":    "kind: Deployment\n",
+		},
+	})
+	is.NoError(err)
+
+	is.NoFileExists(filepath.Join(projectPath, "deploy/service.yaml"))
+	is.FileExists(filepath.Join(projectPath, "deploy/deployment.yaml"))
+	is.FileExists(filepath.Join(projectPath, ".git/HEAD"))
+}
+
+func Test_ValidateStackVersionSize(t *testing.T) {
+	is := assert.New(t)
+
+	projectPath := t.TempDir()
+	is.NoError(writeCheckout(projectPath, map[string]string{
+		"docker-compose.yml": strings.Repeat("#", stackVersionMaxSize+1),
+	}))
+
+	stack := &portainer.Stack{ID: 1, ProjectPath: projectPath, EntryPoint: "docker-compose.yml"}
+	is.ErrorIs(ValidateStackVersionSize(stack), ErrStackVersionTooLarge)
+
+	stack.EntryPoint = "missing.yml"
+	is.NoError(ValidateStackVersionSize(stack))
+}
+
+func Test_previousStackVersion(t *testing.T) {
+	is := assert.New(t)
+
+	_, store, teardown := datastore.MustNewTestStore(t, true, true)
+	defer teardown()
+
+	projectPath := t.TempDir()
+	is.NoError(writeCheckout(projectPath, map[string]string{
+		"docker-compose.yml": "services:\n  web:\n    image: nginx:1.24\n",
+	}))
+
+	stack := &portainer.Stack{
+		ID:          1,
+		ProjectPath: projectPath,
+		EntryPoint:  "docker-compose.yml",
+	}
+
+	is.NoError(RecordStackVersion(store, stack, "admin"))
+
+	_, err := previousStackVersion(store.StackVersion(), stack)
+	is.ErrorIs(err, errNoPreviousStackVersion)
+
+	is.NoError(writeCheckout(projectPath, map[string]string{
+		"docker-compose.yml": "services:\n  web:\n    image: nginx:1.25\n",
+	}))
+	is.NoError(RecordStackVersion(store, stack, "bob"))
+
+	previous, err := previousStackVersion(store.StackVersion(), stack)
+	is.NoError(err)
+	is.Equal(1, previous.Version)
+	is.Equal("admin", previous.DeployedBy)
+}
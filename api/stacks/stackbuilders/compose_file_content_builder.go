@@ -44,6 +44,9 @@ func (b *ComposeStackFileContentBuilder) SetUniqueInfo(payload *StackPayload) Fi
 	b.stack.EntryPoint = filesystem.ComposeFileDefaultName
 	b.stack.Env = payload.Env
 	b.stack.FromAppTemplate = payload.FromAppTemplate
+	if payload.DeployPolicy != nil {
+		b.stack.Option = &portainer.StackOption{DeployPolicy: payload.DeployPolicy}
+	}
 	return b
 }
 
@@ -41,6 +41,9 @@ func (b *ComposeStackFileUploadBuilder) SetUniqueInfo(payload *StackPayload) Fil
 	b.stack.Type = portainer.DockerComposeStack
 	b.stack.EntryPoint = filesystem.ComposeFileDefaultName
 	b.stack.Env = payload.Env
+	if payload.DeployPolicy != nil {
+		b.stack.Option = &portainer.StackOption{DeployPolicy: payload.DeployPolicy}
+	}
 	return b
 }
 
@@ -20,13 +20,15 @@ func CreateComposeStackGitBuilder(securityContext *security.RestrictedRequestCon
 	fileService portainer.FileService,
 	gitService portainer.GitService,
 	scheduler *scheduler.Scheduler,
-	stackDeployer deployments.StackDeployer) *ComposeStackGitBuilder {
+	stackDeployer deployments.StackDeployer,
+	notificationService portainer.NotificationService) *ComposeStackGitBuilder {
 
 	return &ComposeStackGitBuilder{
 		GitMethodStackBuilder: GitMethodStackBuilder{
-			StackBuilder: CreateStackBuilder(dataStore, fileService, stackDeployer),
-			gitService:   gitService,
-			scheduler:    scheduler,
+			StackBuilder:        CreateStackBuilder(dataStore, fileService, stackDeployer),
+			gitService:          gitService,
+			scheduler:           scheduler,
+			notificationService: notificationService,
 		},
 		SecurityContext: securityContext,
 	}
@@ -46,6 +48,9 @@ func (b *ComposeStackGitBuilder) SetUniqueInfo(payload *StackPayload) GitMethodS
 	b.stack.EntryPoint = payload.ComposeFile
 	b.stack.FromAppTemplate = payload.FromAppTemplate
 	b.stack.Env = payload.Env
+	if payload.DeployPolicy != nil {
+		b.stack.Option = &portainer.StackOption{DeployPolicy: payload.DeployPolicy}
+	}
 	return b
 }
 
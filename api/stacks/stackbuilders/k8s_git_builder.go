@@ -26,13 +26,15 @@ func CreateKubernetesStackGitBuilder(dataStore dataservices.DataStore,
 	scheduler *scheduler.Scheduler,
 	stackDeployer deployments.StackDeployer,
 	kuberneteDeployer portainer.KubernetesDeployer,
+	notificationService portainer.NotificationService,
 	user *portainer.User) *KubernetesStackGitBuilder {
 
 	return &KubernetesStackGitBuilder{
 		GitMethodStackBuilder: GitMethodStackBuilder{
-			StackBuilder: CreateStackBuilder(dataStore, fileService, stackDeployer),
-			gitService:   gitService,
-			scheduler:    scheduler,
+			StackBuilder:        CreateStackBuilder(dataStore, fileService, stackDeployer),
+			gitService:          gitService,
+			scheduler:           scheduler,
+			notificationService: notificationService,
 		},
 		stackCreateMut:    &sync.Mutex{},
 		KuberneteDeployer: kuberneteDeployer,
@@ -56,11 +58,25 @@ func (b *KubernetesStackGitBuilder) SetUniqueInfo(payload *StackPayload) GitMeth
 	b.stack.EntryPoint = payload.ManifestFile
 	b.stack.CreatedBy = b.user.Username
 	b.stack.IsComposeFormat = payload.ComposeFormat
+	b.stack.Kustomize = payload.Kustomize
+	b.stack.HelmChart = payload.HelmChart
 	return b
 }
 
 func (b *KubernetesStackGitBuilder) SetGitRepository(payload *StackPayload) GitMethodStackBuildProcess {
 	b.GitMethodStackBuilder.SetGitRepository(payload)
+	if b.hasError() || b.stack.Kustomize == nil || b.stack.EntryPoint != "" {
+		return b
+	}
+
+	manifestFile, err := stackutils.FindKustomizationFile(b.stack.ProjectPath, b.stack.Kustomize.Path)
+	if err != nil {
+		b.err = httperror.BadRequest("Invalid kustomization path in repository", err)
+		return b
+	}
+
+	b.stack.EntryPoint = manifestFile
+	b.stack.GitConfig.ConfigFilePath = manifestFile
 	return b
 }
 
@@ -40,10 +40,24 @@ func (b *StackBuilder) SaveStack() (*portainer.Stack, *httperror.HandlerError) {
 		return nil, b.err
 	}
 
+	if err := deployments.RecordStackVersion(b.dataStore, b.stack, b.stack.CreatedBy); err != nil {
+		log.Warn().Err(err).Int("stack_id", int(b.stack.ID)).Msg("unable to record the stack version")
+	}
+
 	b.doCleanUp = false
 	return b.stack, b.err
 }
 
+// validateStackVersionSize refuses the stacks whose files cannot be kept in the stack history
+func (b *StackBuilder) validateStackVersionSize() bool {
+	if err := deployments.ValidateStackVersionSize(b.stack); err != nil {
+		b.err = httperror.BadRequest(err.Error(), err)
+		return false
+	}
+
+	return true
+}
+
 func (b *StackBuilder) cleanUp() error {
 	if !b.doCleanUp {
 		return nil
@@ -53,6 +53,10 @@ func (b *FileContentMethodStackBuilder) Deploy(payload *StackPayload, endpoint *
 		return b
 	}
 
+	if !b.validateStackVersionSize() {
+		return b
+	}
+
 	// Deploy the stack
 	err := b.deploymentConfiger.Deploy()
 	if err != nil {
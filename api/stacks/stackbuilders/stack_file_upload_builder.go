@@ -62,6 +62,10 @@ func (b *FileUploadMethodStackBuilder) Deploy(payload *StackPayload, endpoint *p
 		return b
 	}
 
+	if !b.validateStackVersionSize() {
+		return b
+	}
+
 	// Deploy the stack
 	err := b.deploymentConfiger.Deploy()
 	if err != nil {
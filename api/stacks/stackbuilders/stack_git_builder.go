@@ -32,8 +32,9 @@ type GitMethodStackBuildProcess interface {
 
 type GitMethodStackBuilder struct {
 	StackBuilder
-	gitService portainer.GitService
-	scheduler  *scheduler.Scheduler
+	gitService          portainer.GitService
+	scheduler           *scheduler.Scheduler
+	notificationService portainer.NotificationService
 }
 
 func (b *GitMethodStackBuilder) SetGeneralInfo(payload *StackPayload, endpoint *portainer.Endpoint) GitMethodStackBuildProcess {
@@ -63,10 +64,18 @@ func (b *GitMethodStackBuilder) SetGitRepository(payload *StackPayload) GitMetho
 			Username: payload.RepositoryConfigPayload.Username,
 			Password: payload.RepositoryConfigPayload.Password,
 		}
+
+		// only the reference is kept, the saved credential is resolved whenever the repository is accessed
+		if payload.GitCredentialID != 0 {
+			repoConfig.Authentication = &gittypes.GitAuthentication{
+				GitCredentialID: payload.GitCredentialID,
+			}
+		}
 	}
 
 	repoConfig.URL = payload.URL
 	repoConfig.ReferenceName = payload.ReferenceName
+	repoConfig.PathFilter = payload.PathFilter
 	repoConfig.ConfigFilePath = payload.ComposeFile
 	if payload.ComposeFile == "" {
 		repoConfig.ConfigFilePath = filesystem.ComposeFileDefaultName
@@ -80,7 +89,7 @@ func (b *GitMethodStackBuilder) SetGitRepository(payload *StackPayload) GitMetho
 	// Set the project path on the disk
 	b.stack.ProjectPath = b.fileService.GetStackProjectPath(stackFolder)
 
-	commitHash, err := stackutils.DownloadGitRepository(b.stack.ID, repoConfig, b.gitService, b.fileService)
+	commitHash, err := stackutils.DownloadGitRepository(b.stack.ID, repoConfig, b.gitService, b.fileService, b.dataStore.GitCredential())
 	if err != nil {
 		b.err = httperror.InternalServerError(err.Error(), err)
 		return b
@@ -97,6 +106,10 @@ func (b *GitMethodStackBuilder) Deploy(payload *StackPayload, endpoint *portaine
 		return b
 	}
 
+	if !b.validateStackVersionSize() {
+		return b
+	}
+
 	// Deploy the stack
 	err := b.deploymentConfiger.Deploy()
 	if err != nil {
@@ -118,7 +131,8 @@ func (b *GitMethodStackBuilder) SetAutoUpdate(payload *StackPayload) GitMethodSt
 			b.scheduler,
 			b.stackDeployer,
 			b.dataStore,
-			b.gitService)
+			b.gitService,
+			b.notificationService)
 		if err != nil {
 			b.err = err
 			return b
@@ -2,6 +2,7 @@ package stackbuilders
 
 import (
 	portainer "github.com/cloudogu/portainer-ce/api"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 )
 
 // StackPayload contains all the fields for creating a stack with all kinds of methods
@@ -29,12 +30,18 @@ type StackPayload struct {
 	Namespace string
 	// Path to the k8s Stack file. Used by k8s git repository method
 	ManifestFile string
+	// Kustomization rendered instead of the manifest files. Used by k8s git repository method
+	Kustomize *portainer.KustomizeConfig
+	// Helm chart deployed as a release instead of the manifest files. Used by k8s git repository method
+	HelmChart *portainer.HelmChartConfig
 	// URL to the k8s Stack file. Used by k8s git repository method
 	ManifestURL string
 	// Path to the Stack file inside the Git repository
 	ComposeFile string `example:"docker-compose.yml" default:"docker-compose.yml"`
 	// Applicable when deploying with multiple stack files
 	AdditionalFiles []string `example:"[nz.compose.yml, uat.compose.yml]"`
+	// Optional health check of the containers of a compose stack after its deployment
+	DeployPolicy *portainer.StackDeployPolicy
 	// Git repository configuration of a stack
 	RepositoryConfigPayload
 }
@@ -52,4 +59,8 @@ type RepositoryConfigPayload struct {
 	// Password used in basic authentication. Required when RepositoryAuthentication is true
 	// and RepositoryGitCredentialID is 0
 	Password string `example:"myGitPassword"`
+	// Identifier of the saved git credential used in basic authentication instead of Username and Password
+	GitCredentialID int `example:"0"`
+	// Optional path filter restricting the automatic redeploys to the changes of the files the stack is deployed from
+	PathFilter *gittypes.PathFilter
 }
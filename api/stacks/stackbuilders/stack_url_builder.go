@@ -53,6 +53,10 @@ func (b *UrlMethodStackBuilder) Deploy(payload *StackPayload, endpoint *portaine
 		return b
 	}
 
+	if !b.validateStackVersionSize() {
+		return b
+	}
+
 	// Deploy the stack
 	err := b.deploymentConfiger.Deploy()
 	if err != nil {
@@ -20,13 +20,15 @@ func CreateSwarmStackGitBuilder(securityContext *security.RestrictedRequestConte
 	fileService portainer.FileService,
 	gitService portainer.GitService,
 	scheduler *scheduler.Scheduler,
-	stackDeployer deployments.StackDeployer) *SwarmStackGitBuilder {
+	stackDeployer deployments.StackDeployer,
+	notificationService portainer.NotificationService) *SwarmStackGitBuilder {
 
 	return &SwarmStackGitBuilder{
 		GitMethodStackBuilder: GitMethodStackBuilder{
-			StackBuilder: CreateStackBuilder(dataStore, fileService, stackDeployer),
-			gitService:   gitService,
-			scheduler:    scheduler,
+			StackBuilder:        CreateStackBuilder(dataStore, fileService, stackDeployer),
+			gitService:          gitService,
+			scheduler:           scheduler,
+			notificationService: notificationService,
 		},
 		SecurityContext: securityContext,
 	}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	"github.com/cloudogu/portainer-ce/api/dataservices"
+	"github.com/cloudogu/portainer-ce/api/git"
 	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/pkg/errors"
 )
@@ -16,18 +18,16 @@ var (
 
 // DownloadGitRepository downloads the target git repository on the disk
 // The first return value represents the commit hash of the downloaded git repository
-func DownloadGitRepository(stackID portainer.StackID, config gittypes.RepoConfig, gitService portainer.GitService, fileService portainer.FileService) (string, error) {
-	username := ""
-	password := ""
-	if config.Authentication != nil {
-		username = config.Authentication.Username
-		password = config.Authentication.Password
+func DownloadGitRepository(stackID portainer.StackID, config gittypes.RepoConfig, gitService portainer.GitService, fileService portainer.FileService, gitCredentialService dataservices.GitCredentialService) (string, error) {
+	username, password, err := git.GetCredentials(config.Authentication, config.URL, gitCredentialService)
+	if err != nil {
+		return "", err
 	}
 
 	stackFolder := fmt.Sprintf("%d", stackID)
 	projectPath := fileService.GetStackProjectPath(stackFolder)
 
-	err := gitService.CloneRepository(projectPath, config.URL, config.ReferenceName, username, password)
+	err = gitService.CloneRepository(projectPath, config.URL, config.ReferenceName, username, password)
 	if err != nil {
 		if err == gittypes.ErrAuthenticationFailure {
 			newErr := ErrInvalidGitCredential
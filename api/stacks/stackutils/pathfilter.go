@@ -0,0 +1,132 @@
+package stackutils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+)
+
+// IsWatchedFile returns true when a file, relative to the repository root, is used to deploy a git stack.
+// The watched files are the ones under the directory of the stack file, the additional files,
+// the values files of a Helm chart and the files matching the globs of the path filter.
+func IsWatchedFile(stack *portainer.Stack, file string) bool {
+	file = path.Clean(filepath.ToSlash(file))
+
+	if stack.GitConfig != nil {
+		stackDir := path.Dir(path.Clean(filepath.ToSlash(stack.GitConfig.ConfigFilePath)))
+		if stackDir == "." || strings.HasPrefix(file, stackDir+"/") {
+			return true
+		}
+	}
+
+	watchedFiles := append([]string{}, stack.AdditionalFiles...)
+	if stack.HelmChart != nil {
+		watchedFiles = append(watchedFiles, GetHelmValuesFiles(stack)...)
+	}
+
+	for _, watchedFile := range watchedFiles {
+		if file == path.Clean(filepath.ToSlash(watchedFile)) {
+			return true
+		}
+	}
+
+	if stack.GitConfig == nil || stack.GitConfig.PathFilter == nil {
+		return false
+	}
+
+	for _, glob := range stack.GitConfig.PathFilter.Globs {
+		if dir, ok := strings.CutSuffix(glob, "/**"); ok {
+			if strings.HasPrefix(file, path.Clean(dir)+"/") {
+				return true
+			}
+			continue
+		}
+
+		if matched, _ := path.Match(glob, file); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ChangedWatchedFiles compares the watched files of two checkouts of the repository of a git stack
+// and returns the ones added, removed or modified, relative to the repository root
+func ChangedWatchedFiles(stack *portainer.Stack, previousDir, currentDir string) ([]string, error) {
+	previous, err := watchedFileDigests(stack, previousDir)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := watchedFileDigests(stack, currentDir)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]string, 0)
+	for file, digest := range current {
+		if previousDigest, ok := previous[file]; !ok || !bytes.Equal(digest, previousDigest) {
+			changed = append(changed, file)
+		}
+	}
+
+	for file := range previous {
+		if _, ok := current[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed, nil
+}
+
+// watchedFileDigests returns the SHA-256 digests of the watched files of a checkout, indexed by their path relative to the repository root
+func watchedFileDigests(stack *portainer.Stack, dir string) (map[string][]byte, error) {
+	digests := make(map[string][]byte)
+
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && filePath == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		file, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		file = filepath.ToSlash(file)
+		if !IsWatchedFile(stack, file) {
+			return nil
+		}
+
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		digest := sha256.Sum256(content)
+		digests[file] = digest[:]
+
+		return nil
+	})
+
+	return digests, err
+}
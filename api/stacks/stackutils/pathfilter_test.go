@@ -0,0 +1,86 @@
+package stackutils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	portainer "github.com/cloudogu/portainer-ce/api"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_IsWatchedFile(t *testing.T) {
+	stack := &portainer.Stack{
+		AdditionalFiles: []string{"overrides/production.yml"},
+		GitConfig: &gittypes.RepoConfig{
+			ConfigFilePath: "apps/web/docker-compose.yml",
+			PathFilter:     &gittypes.PathFilter{Globs: []string{"shared/*.env", "config/**"}},
+		},
+	}
+
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{file: "apps/web/docker-compose.yml", want: true},
+		{file: "apps/web/nginx/nginx.conf", want: true},
+		{file: "apps/api/docker-compose.yml", want: false},
+		{file: "overrides/production.yml", want: true},
+		{file: "overrides/staging.yml", want: false},
+		{file: "shared/web.env", want: true},
+		{file: "shared/nested/web.env", want: false},
+		{file: "config/a/b/c.yml", want: true},
+		{file: "README.md", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsWatchedFile(stack, tt.file))
+		})
+	}
+
+	t.Run("a stack file at the repository root watches every file", func(t *testing.T) {
+		rootStack := &portainer.Stack{GitConfig: &gittypes.RepoConfig{ConfigFilePath: "docker-compose.yml"}}
+		assert.True(t, IsWatchedFile(rootStack, "README.md"))
+	})
+}
+
+func Test_ChangedWatchedFiles(t *testing.T) {
+	is := assert.New(t)
+
+	stack := &portainer.Stack{
+		GitConfig: &gittypes.RepoConfig{
+			ConfigFilePath: "web/docker-compose.yml",
+			PathFilter:     &gittypes.PathFilter{},
+		},
+	}
+
+	write := func(dir string, files map[string]string) {
+		for file, content := range files {
+			filePath := filepath.Join(dir, filepath.FromSlash(file))
+			is.NoError(os.MkdirAll(filepath.Dir(filePath), 0755))
+			is.NoError(os.WriteFile(filePath, []byte(content), 0644))
+		}
+	}
+
+	previous, current := t.TempDir(), t.TempDir()
+	write(previous, map[string]string{
+		"web/docker-compose.yml": "services: {}",
+		"web/removed.conf":       "removed",
+		"api/docker-compose.yml": "services: {}",
+	})
+	write(current, map[string]string{
+		"web/docker-compose.yml": "services: {web: {}}",
+		"web/added.conf":         "added",
+		"api/docker-compose.yml": "services: {api: {}}",
+	})
+
+	changed, err := ChangedWatchedFiles(stack, previous, current)
+	is.NoError(err)
+	is.Equal([]string{"web/added.conf", "web/docker-compose.yml", "web/removed.conf"}, changed)
+
+	changed, err = ChangedWatchedFiles(stack, previous, previous)
+	is.NoError(err)
+	is.Empty(changed)
+}
@@ -27,6 +27,71 @@ func GetStackFilePaths(stack *portainer.Stack, absolute bool) []string {
 	return filePaths
 }
 
+// GetKustomizationPath returns the path of the kustomization directory rendered for a Kubernetes stack,
+// relative to the stack project path
+func GetKustomizationPath(stack *portainer.Stack) string {
+	if stack.Kustomize.Overlay == "" {
+		return filesystem.JoinPaths("", stack.Kustomize.Path)
+	}
+
+	return filesystem.JoinPaths("", stack.Kustomize.Path, "overlays", stack.Kustomize.Overlay)
+}
+
+// kustomizationFileNames are the names of the kustomization file recognized by kustomize
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// FindKustomizationFile returns the path of the kustomization file of the kustomization directory,
+// relative to the project path
+func FindKustomizationFile(projectPath, kustomizationPath string) (string, error) {
+	for _, name := range kustomizationFileNames {
+		filePath := filesystem.JoinPaths("", kustomizationPath, name)
+
+		exists, err := filesystem.FileExists(filesystem.JoinPaths(projectPath, filePath))
+		if err != nil {
+			return "", err
+		}
+		if exists {
+			return filePath, nil
+		}
+	}
+
+	return "", fmt.Errorf("no kustomization file found in %q", kustomizationPath)
+}
+
+// GetHelmValuesFiles returns the paths of the values files of a Kubernetes stack deployed as a Helm release,
+// relative to the stack project path. The values files of the environment(endpoint) of the stack are merged last
+func GetHelmValuesFiles(stack *portainer.Stack) []string {
+	valuesFiles := append([]string{}, stack.HelmChart.ValuesFiles...)
+	return append(valuesFiles, stack.HelmChart.EnvironmentValuesFiles[stack.EndpointID]...)
+}
+
+// GetHelmChartPaths returns the absolute paths of the chart directory and of the values files of a Kubernetes stack
+// deployed as a Helm release
+func GetHelmChartPaths(stack *portainer.Stack) (string, []string) {
+	var valuesFiles []string
+	for _, valuesFile := range GetHelmValuesFiles(stack) {
+		valuesFiles = append(valuesFiles, filesystem.JoinPaths(stack.ProjectPath, valuesFile))
+	}
+
+	return filesystem.JoinPaths(stack.ProjectPath, stack.HelmChart.ChartPath), valuesFiles
+}
+
+// UpdateStackDeployPolicy sets the deploy policy of a compose stack, or removes it when remove is true.
+// The deploy policy is kept when none is specified.
+func UpdateStackDeployPolicy(stack *portainer.Stack, policy *portainer.StackDeployPolicy, remove bool) {
+	switch {
+	case remove:
+		if stack.Option != nil {
+			stack.Option.DeployPolicy = nil
+		}
+	case policy != nil:
+		if stack.Option == nil {
+			stack.Option = &portainer.StackOption{}
+		}
+		stack.Option.DeployPolicy = policy
+	}
+}
+
 // ResourceControlID returns the stack resource control id
 func ResourceControlID(endpointID portainer.EndpointID, name string) string {
 	return fmt.Sprintf("%d_%s", endpointID, name)
@@ -1,6 +1,8 @@
 package stackutils
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
@@ -24,3 +26,55 @@ func Test_GetStackFilePaths(t *testing.T) {
 		assert.ElementsMatch(t, expected, GetStackFilePaths(stack, true))
 	})
 }
+
+func Test_GetKustomizationPath(t *testing.T) {
+	stack := &portainer.Stack{Kustomize: &portainer.KustomizeConfig{Path: "deploy"}}
+
+	t.Run("stack without overlay", func(t *testing.T) {
+		assert.Equal(t, "deploy", GetKustomizationPath(stack))
+	})
+
+	t.Run("stack with overlay", func(t *testing.T) {
+		stack.Kustomize.Overlay = "production"
+		assert.Equal(t, "deploy/overlays/production", GetKustomizationPath(stack))
+	})
+
+	t.Run("path does not escape the project", func(t *testing.T) {
+		stack.Kustomize.Overlay = "../../../etc"
+		assert.Equal(t, "etc", GetKustomizationPath(stack))
+	})
+}
+
+func Test_FindKustomizationFile(t *testing.T) {
+	is := assert.New(t)
+
+	projectPath := t.TempDir()
+	is.NoError(os.MkdirAll(filepath.Join(projectPath, "deploy"), 0755))
+
+	_, err := FindKustomizationFile(projectPath, "deploy")
+	is.Error(err, "a directory without kustomization file should be rejected")
+
+	is.NoError(os.WriteFile(filepath.Join(projectPath, "deploy", "kustomization.yml"), []byte("resources: []\n"), 0644))
+
+	filePath, err := FindKustomizationFile(projectPath, "deploy")
+	is.NoError(err)
+	is.Equal("deploy/kustomization.yml", filePath)
+}
+
+func Test_UpdateStackDeployPolicy(t *testing.T) {
+	is := assert.New(t)
+
+	policy := &portainer.StackDeployPolicy{HealthWindow: "1m"}
+	stack := &portainer.Stack{Option: &portainer.StackOption{Prune: true, DeployPolicy: policy}}
+
+	UpdateStackDeployPolicy(stack, nil, false)
+	is.Equal(policy, stack.Option.DeployPolicy, "the deploy policy should be kept when none is specified")
+
+	UpdateStackDeployPolicy(stack, nil, true)
+	is.Nil(stack.Option.DeployPolicy)
+	is.True(stack.Option.Prune)
+
+	stack.Option = nil
+	UpdateStackDeployPolicy(stack, policy, false)
+	is.Equal(policy, stack.Option.DeployPolicy)
+}
@@ -1,10 +1,13 @@
 package stackutils
 
 import (
+	"path"
+	"strings"
 	"time"
 
 	"github.com/asaskevich/govalidator"
 	portainer "github.com/cloudogu/portainer-ce/api"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/docker/cli/cli/compose/loader"
 	"github.com/docker/cli/cli/compose/types"
 	"github.com/pkg/errors"
@@ -82,6 +85,54 @@ func ValidateStackAutoUpdate(autoUpdate *portainer.StackAutoUpdate) error {
 	return nil
 }
 
+// ValidateStackPathFilter returns an error when a glob of the path filter is not relative to the repository root or is malformed
+func ValidateStackPathFilter(pathFilter *gittypes.PathFilter) error {
+	if pathFilter == nil {
+		return nil
+	}
+
+	for _, glob := range pathFilter.Globs {
+		cleaned := path.Clean(glob)
+		if glob == "" || path.IsAbs(glob) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+			return errors.Errorf("invalid path filter glob %q, it must be relative to the repository root", glob)
+		}
+
+		if _, err := path.Match(strings.TrimSuffix(glob, "/**"), ""); err != nil {
+			return errors.Errorf("invalid path filter glob %q", glob)
+		}
+	}
+
+	return nil
+}
+
+// MinStackHealthWindow is the shortest health window of a stack deploy policy
+const MinStackHealthWindow = 10 * time.Second
+
+// ValidateStackDeployPolicy returns an error when the health window of the deploy policy is malformed or too short,
+// or when its number of tolerated restarts is negative
+func ValidateStackDeployPolicy(policy *portainer.StackDeployPolicy) error {
+	if policy == nil {
+		return nil
+	}
+
+	if policy.HealthWindow != "" {
+		window, err := time.ParseDuration(policy.HealthWindow)
+		if err != nil {
+			return errors.New("invalid HealthWindow format")
+		}
+
+		if window < MinStackHealthWindow {
+			return errors.Errorf("invalid HealthWindow, it must be at least %s", MinStackHealthWindow)
+		}
+	}
+
+	if policy.MaxRestarts < 0 {
+		return errors.New("invalid MaxRestarts, it must not be negative")
+	}
+
+	return nil
+}
+
 func ValidateStackFiles(stack *portainer.Stack, securitySettings *portainer.EndpointSecuritySettings, fileService portainer.FileService) error {
 	for _, file := range GetStackFilePaths(stack, false) {
 		stackContent, err := fileService.GetFileContent(stack.ProjectPath, file)
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	portainer "github.com/cloudogu/portainer-ce/api"
+	gittypes "github.com/cloudogu/portainer-ce/api/git/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,3 +41,20 @@ func Test_ValidateStackAutoUpdate(t *testing.T) {
 		})
 	}
 }
+
+func Test_ValidateStackPathFilter(t *testing.T) {
+	assert.NoError(t, ValidateStackPathFilter(nil))
+	assert.NoError(t, ValidateStackPathFilter(&gittypes.PathFilter{Globs: []string{"shared/*.env", "config/**"}}))
+	assert.Error(t, ValidateStackPathFilter(&gittypes.PathFilter{Globs: []string{"/etc/*"}}))
+	assert.Error(t, ValidateStackPathFilter(&gittypes.PathFilter{Globs: []string{"../other/*"}}))
+	assert.Error(t, ValidateStackPathFilter(&gittypes.PathFilter{Globs: []string{"shared/[.env"}}))
+}
+
+func Test_ValidateStackDeployPolicy(t *testing.T) {
+	assert.NoError(t, ValidateStackDeployPolicy(nil))
+	assert.NoError(t, ValidateStackDeployPolicy(&portainer.StackDeployPolicy{}))
+	assert.NoError(t, ValidateStackDeployPolicy(&portainer.StackDeployPolicy{HealthWindow: "2m", MaxRestarts: 2}))
+	assert.Error(t, ValidateStackDeployPolicy(&portainer.StackDeployPolicy{HealthWindow: "2"}))
+	assert.Error(t, ValidateStackDeployPolicy(&portainer.StackDeployPolicy{HealthWindow: "5s"}))
+	assert.Error(t, ValidateStackDeployPolicy(&portainer.StackDeployPolicy{MaxRestarts: -1}))
+}
@@ -24,5 +24,15 @@ export function onStartupAngular($rootScope, $state, LocalStorage, cfpLoadingBar
       jqXhr.setRequestHeader('Content-Type', 'application/json');
     }
     jqXhr.setRequestHeader('Authorization', 'Bearer ' + LocalStorage.getJWT());
+
+    const csrfToken = getCookie('XSRF-TOKEN');
+    if (csrfToken) {
+      jqXhr.setRequestHeader('X-XSRF-TOKEN', csrfToken);
+    }
   });
 }
+
+function getCookie(name) {
+  const cookie = document.cookie.split('; ').find((c) => c.startsWith(`${name}=`));
+  return cookie ? decodeURIComponent(cookie.substring(name.length + 1)) : '';
+}
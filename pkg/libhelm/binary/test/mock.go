@@ -2,6 +2,7 @@ package test
 
 import (
 	"encoding/json"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -41,6 +42,7 @@ func newMockReleaseElement(installOpts options.InstallOptions) *release.ReleaseE
 	return &release.ReleaseElement{
 		Name:       installOpts.Name,
 		Namespace:  installOpts.Namespace,
+		Revision:   "1",
 		Updated:    "date/time",
 		Status:     "deployed",
 		Chart:      installOpts.Chart,
@@ -113,6 +115,69 @@ func (hpm *helmMockPackageManager) Uninstall(uninstallOpts options.UninstallOpti
 	return nil
 }
 
+// Upgrade a helm chart (not thread safe)
+func (hpm *helmMockPackageManager) Upgrade(upgradeOpts options.UpgradeOptions) (*release.Release, error) {
+	for i, rel := range mockCharts {
+		if rel.Name == upgradeOpts.Name && rel.Namespace == upgradeOpts.Namespace {
+			revision, _ := strconv.Atoi(rel.Revision)
+			mockCharts[i].Chart = upgradeOpts.Chart
+			mockCharts[i].Revision = strconv.Itoa(revision + 1)
+
+			release := newMockRelease(&mockCharts[i])
+			release.Version = revision + 1
+			return release, nil
+		}
+	}
+
+	return nil, errors.New("release not found")
+}
+
+// Rollback a helm chart to a previous revision (not thread safe)
+func (hpm *helmMockPackageManager) Rollback(rollbackOpts options.RollbackOptions) error {
+	for i, rel := range mockCharts {
+		if rel.Name == rollbackOpts.Name && rel.Namespace == rollbackOpts.Namespace {
+			revision, _ := strconv.Atoi(rel.Revision)
+			if rollbackOpts.Revision >= revision+1 {
+				return errors.New("release revision not found")
+			}
+
+			mockCharts[i].Revision = strconv.Itoa(revision + 1)
+			return nil
+		}
+	}
+
+	return errors.New("release not found")
+}
+
+// History of a helm chart, one element per revision (not thread safe)
+func (hpm *helmMockPackageManager) History(historyOpts options.HistoryOptions) ([]release.ReleaseHistoryElement, error) {
+	for _, rel := range mockCharts {
+		if rel.Name == historyOpts.Name && rel.Namespace == historyOpts.Namespace {
+			revision, _ := strconv.Atoi(rel.Revision)
+
+			history := make([]release.ReleaseHistoryElement, 0, revision)
+			for i := 1; i <= revision; i++ {
+				status := "superseded"
+				if i == revision {
+					status = "deployed"
+				}
+
+				history = append(history, release.ReleaseHistoryElement{
+					Revision:   i,
+					Updated:    "date/time",
+					Status:     status,
+					Chart:      rel.Chart,
+					AppVersion: rel.AppVersion,
+				})
+			}
+
+			return history, nil
+		}
+	}
+
+	return nil, errors.New("release not found")
+}
+
 // List a helm chart (not thread safe)
 func (hpm *helmMockPackageManager) List(listOpts options.ListOptions) ([]release.ReleaseElement, error) {
 	return mockCharts, nil
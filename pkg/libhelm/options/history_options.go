@@ -0,0 +1,9 @@
+package options
+
+// HistoryOptions are portainer supported options for `helm history`
+type HistoryOptions struct {
+	Name                    string
+	Namespace               string
+	Max                     int
+	KubernetesClusterAccess *KubernetesClusterAccess
+}
@@ -0,0 +1,24 @@
+package options
+
+// UpgradeOptions are portainer supported options for `helm upgrade`
+type UpgradeOptions struct {
+	Name                    string
+	Chart                   string
+	Namespace               string
+	Repo                    string
+	Version                 string
+	Wait                    bool
+	ValuesFile              string
+	ReuseValues             bool
+	PostRenderer            string
+	KubernetesClusterAccess *KubernetesClusterAccess
+
+	// Install the release when it does not exist yet
+	Install bool
+	// DryRun renders the release without changing it
+	DryRun bool
+	// ValuesFiles are applied after ValuesFile, in order
+	ValuesFiles []string
+	// PostRendererEnv is the environment, in the KEY=value form, given to the post-renderer
+	PostRendererEnv []string
+}
@@ -17,6 +17,17 @@ type ReleaseElement struct {
 	AppVersion string `json:"app_version"`
 }
 
+// ReleaseHistoryElement is a struct that represents a single revision of a release
+// This is the official struct from the helm project (golang codebase) - exported
+type ReleaseHistoryElement struct {
+	Revision    int    `json:"revision"`
+	Updated     string `json:"updated"`
+	Status      string `json:"status"`
+	Chart       string `json:"chart"`
+	AppVersion  string `json:"app_version"`
+	Description string `json:"description"`
+}
+
 // Release describes a deployment of a chart, together with the chart
 // and the variables used to deploy that chart.
 type Release struct {